@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"unicode/utf8"
+	"vk_test_task/config"
+	"vk_test_task/internal/api"
+	"vk_test_task/internal/api/repository/postgres"
+	"vk_test_task/internal/api/repository/redis"
+	"vk_test_task/internal/common"
+	"vk_test_task/internal/utils/encryption"
+	"vk_test_task/internal/utils/totp"
+	tint "vk_test_task/pkg/logger"
+)
+
+const usage = `usage: admin <command> [flags]
+
+commands:
+  create-user    -login <login> [-email <email>] [-admin]
+  promote        -login <login>
+  demote         -login <login>
+  reset-password -login <login>
+  list
+  disable        -login <login>
+  enable         -login <login>
+  unlock         -login <login>
+  mfa-enroll     -login <login>
+  mfa-disable    -login <login>
+
+create-user and reset-password read the password from the ADMIN_PASSWORD
+environment variable or, if it is not set, from the first line of stdin
+`
+
+type app struct {
+	cfg    *config.Config
+	db     api.RepositoryInterface
+	tokens api.TokenRepositoryInterface
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.ParseConfig()
+	logger := tint.NewLogger(config.Logger{Format: cfg.Logger.Format})
+
+	a := app{
+		cfg:    cfg,
+		db:     postgres.NewRepository(cfg, logger),
+		tokens: redis.New(cfg, logger),
+	}
+
+	// Ctrl+C прерывает запросы к БД и редису
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := a.run(ctx, os.Args[1], os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", os.Args[1], err.Error())
+		os.Exit(1)
+	}
+}
+
+func (a app) run(ctx context.Context, command string, args []string) error {
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	login := fs.String("login", "", "user login")
+	email := fs.String("email", "", "user email for password reset")
+	admin := fs.Bool("admin", false, "grant admin role to the created user")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch command {
+	case "create-user":
+		password, err := readPassword()
+		if err != nil {
+			return err
+		}
+		return a.createUser(ctx, *login, password, *email, *admin)
+	case "promote":
+		return a.setAdmin(ctx, *login, true)
+	case "demote":
+		return a.setAdmin(ctx, *login, false)
+	case "reset-password":
+		password, err := readPassword()
+		if err != nil {
+			return err
+		}
+		return a.resetPassword(ctx, *login, password)
+	case "list":
+		return a.list(ctx)
+	case "disable":
+		return a.setDisabled(ctx, *login, true)
+	case "enable":
+		return a.setDisabled(ctx, *login, false)
+	case "unlock":
+		return a.tokens.UnlockAccount(ctx, *login)
+	case "mfa-enroll":
+		return a.enrollMFA(ctx, *login)
+	case "mfa-disable":
+		return a.disableMFA(ctx, *login)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command")
+	}
+}
+
+func (a app) createUser(ctx context.Context, login, password, email string, admin bool) error {
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+
+	userId, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	hashPassword, err := encryption.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	var roles []string
+	if admin {
+		roles = append(roles, common.ROLE_ADMIN)
+	}
+
+	// пользователь и роли создаются одной транзакцией: при ошибке повторный запуск начинает с нуля
+	if err = a.db.SignUp(ctx, login, hashPassword, userId.String(), email, roles...); err != nil {
+		return err
+	}
+
+	fmt.Printf("user %s created with id %s\n", login, userId.String())
+	return nil
+}
+
+func (a app) setAdmin(ctx context.Context, login string, admin bool) error {
+	user, err := a.db.GetUserByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	if admin {
+		return a.db.GrantRole(ctx, user.UserId, common.ROLE_ADMIN)
+	}
+
+	if err = a.db.RevokeRole(ctx, user.UserId, common.ROLE_ADMIN); err != nil {
+		return err
+	}
+
+	// уже выданные access токены содержат роль admin
+	return a.tokens.RevokeAllAccessTokens(ctx, user.UserId)
+}
+
+func (a app) resetPassword(ctx context.Context, login, password string) error {
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+
+	user, err := a.db.GetUserByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	hashPassword, err := encryption.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err = a.db.UpdatePassword(ctx, user.UserId, hashPassword); err != nil {
+		return err
+	}
+
+	return a.revokeTokens(ctx, user.UserId)
+}
+
+func (a app) setDisabled(ctx context.Context, login string, disabled bool) error {
+	user, err := a.db.GetUserByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	if err = a.db.SetUserDisabled(ctx, user.UserId, disabled); err != nil {
+		return err
+	}
+
+	if disabled {
+		return a.revokeTokens(ctx, user.UserId)
+	}
+
+	return nil
+}
+
+// enrollMFA сразу включает 2FA, секрет и резервные коды выводятся один раз
+func (a app) enrollMFA(ctx context.Context, login string) error {
+	user, err := a.db.GetUserByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return err
+	}
+
+	codes, hashes, err := totp.NewRecoveryCodes(common.MFA_RECOVERY_CODES)
+	if err != nil {
+		return err
+	}
+
+	if err = a.db.SaveMFASecret(ctx, user.UserId, secret); err != nil {
+		return err
+	}
+
+	if err = a.db.EnableMFA(ctx, user.UserId, hashes); err != nil {
+		return err
+	}
+
+	fmt.Printf("secret: %s\n", secret)
+	fmt.Printf("uri: %s\n", totp.ProvisioningURI(a.cfg.MFA.Issuer, user.Login, secret))
+	fmt.Println("recovery codes:")
+	for _, code := range codes {
+		fmt.Println(code)
+	}
+
+	return nil
+}
+
+func (a app) disableMFA(ctx context.Context, login string) error {
+	user, err := a.db.GetUserByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	return a.db.DisableMFA(ctx, user.UserId)
+}
+
+func (a app) list(ctx context.Context) error {
+	users, err := a.db.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USER_ID\tLOGIN\tEMAIL\tROLES\tDISABLED")
+	for _, user := range users {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\n", user.UserId, user.Login, user.Email, strings.Join(user.Roles, ","), user.Disabled)
+	}
+
+	return w.Flush()
+}
+
+func (a app) revokeTokens(ctx context.Context, userId string) error {
+	if err := a.tokens.RevokeRefreshTokens(ctx, userId); err != nil {
+		return err
+	}
+
+	return a.tokens.RevokeAllAccessTokens(ctx, userId)
+}
+
+// readPassword берет пароль из ADMIN_PASSWORD или из stdin, чтобы он не попадал в историю шелла и список процессов
+func readPassword() (string, error) {
+	if password, ok := os.LookupEnv("ADMIN_PASSWORD"); ok {
+		return password, nil
+	}
+
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, "password: ")
+	}
+
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return strings.TrimRight(password, "\r\n"), nil
+}
+
+func validatePassword(password string) error {
+	if utf8.RuneCountInString(password) < common.PASSWORD_MINSIZE {
+		return fmt.Errorf("password is too short")
+	}
+	if utf8.RuneCountInString(password) > common.PASSWORD_MAXSIZE {
+		return fmt.Errorf("password is too long")
+	}
+
+	return nil
+}
@@ -1,6 +1,12 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
 	"vk_test_task/config"
 	_ "vk_test_task/docs"
 	"vk_test_task/internal/server"
@@ -16,12 +22,59 @@ import (
 // @securityDefinitions.apiKey AccessTokenAuth
 // @in header
 // @name Authorization
+
+// @securityDefinitions.apiKey ApiKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
 	cfg := config.ParseConfig()
 
-	logger := tint.NewLogger(cfg.Logger.InFile)
+	logger := tint.NewLogger(cfg.Logger)
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if err := runMigrate(ctx, cfg, logger, os.Args[2:]); err != nil {
+			stop()
+			fmt.Fprintf(os.Stderr, "migrate: %s\n", err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+
+	// уровень, формат и выводы логов меняются правкой конфига без перезапуска, остальные секции читаются только при старте.
+	// Логгер пересобирается, только если изменилась секция Logger
+	loggerCfg := cfg.Logger
+	config.OnChange(func(newCfg *config.Config, err error) {
+		if err == nil && reflect.DeepEqual(newCfg.Logger, loggerCfg) {
+			return
+		}
+		if err == nil {
+			err = tint.Reload(newCfg.Logger)
+		}
+		if err != nil {
+			logger.Error(fmt.Sprintf("logger config reload error: %s", err.Error()))
+			return
+		}
+		loggerCfg = newCfg.Logger
+		logger.Info("logger config reloaded")
+	})
 
 	logger.Info("config and logger successfully started")
 
+	// при нескольких экземплярах миграции выполнит первый, остальные дождутся advisory lock и ничего не применят
+	if cfg.Postgres.MigrateOnStart {
+		migrator, closeDB, err := newMigrator(cfg, logger)
+		if err != nil {
+			tint.Fatalf(logger, "migrate error: %s", err.Error())
+		}
+		err = migrator.Up(context.Background())
+		closeDB()
+		if err != nil {
+			tint.Fatalf(logger, "%s", err.Error())
+		}
+	}
+
 	server.Run(cfg, logger)
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"vk_test_task/config"
+	"vk_test_task/internal/api/repository/postgres"
+	"vk_test_task/internal/migrate"
+	"vk_test_task/sql_migrations"
+)
+
+const migrateUsage = `usage: app migrate <command>
+
+commands:
+  up       apply all pending migrations
+  down     roll back the last applied migration
+  status   list migrations and whether they are applied
+  to <N>   apply or roll back migrations until N is the last applied one (0 rolls back everything)
+`
+
+func newMigrator(cfg *config.Config, logger *slog.Logger) (*migrate.Migrator, func(), error) {
+	db, err := postgres.Connect(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("postgres connection error: %s", err.Error())
+	}
+
+	migrator, err := migrate.New(db, sql_migrations.FS, logger)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return migrator, func() { db.Close() }, nil
+}
+
+func runMigrate(ctx context.Context, cfg *config.Config, logger *slog.Logger, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return fmt.Errorf("command required")
+	}
+
+	migrator, closeDB, err := newMigrator(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "to":
+		if len(args) < 2 {
+			return fmt.Errorf("version required")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return migrator.To(ctx, version)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED_AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+
+		return w.Flush()
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return fmt.Errorf("unknown command")
+	}
+}
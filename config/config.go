@@ -1,24 +1,70 @@
 package config
 
 import (
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
 	"os"
 )
 
 type Config struct {
-	Server   Server
-	Logger   Logger
-	Postgres Postgres
-	Redis    Redis
+	Server     Server
+	SignIn     SignIn
+	Mail       Mail
+	MFA        MFA
+	Pagination Pagination
+	Logger     Logger
+	Tracing    Tracing
+	Postgres   Postgres
+	Redis      Redis
 }
 
+// Server - таймауты заданы в секундах, 0 - значение по умолчанию из common.
+// KeyEncryptionKey - 32 байта в base64, ими шифруются ключи подписи токенов перед записью в редис
 type Server struct {
-	Port            string
-	Version         string
-	AccessSecret    string
-	RefreshSecret   string
-	AccessLifetime  int64
-	RefreshLifetime int64
+	Port                  string
+	Version               string
+	AccessLifetime        int64
+	RefreshLifetime       int64
+	KeyRotation           int64
+	KeyEncryptionKey      string
+	PasswordResetLifetime int64
+	ReadTimeout           int64
+	WriteTimeout          int64
+	IdleTimeout           int64
+	ShutdownTimeout       int64
+	ReadinessTimeout      int64
+}
+
+type SignIn struct {
+	MaxAttempts     int64
+	IPMaxAttempts   int64
+	AttemptsWindow  int64
+	LockoutDuration int64
+	BackoffBase     int64
+	BackoffMax      int64
+}
+
+type Mail struct {
+	Driver   string
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	ResetURL string
+}
+
+type MFA struct {
+	RequireForAdmins  bool
+	Issuer            string
+	ChallengeLifetime int64
+	MaxAttempts       int64
+}
+
+// Pagination - размер страницы для списков, если клиент не передал limit, и максимально допустимый limit
+type Pagination struct {
+	DefaultPageSize int
+	MaxPageSize     int
 }
 
 type Redis struct {
@@ -27,19 +73,51 @@ type Redis struct {
 	Database int
 }
 
+// Postgres - MigrateOnStart: применять миграции из sql_migrations при запуске сервера
 type Postgres struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Database string
-	SSLMode  string
+	Host           string
+	Port           string
+	User           string
+	Password       string
+	Database       string
+	SSLMode        string
+	MigrateOnStart bool
 }
 
+// Logger - Level: debug, info (по умолчанию), warn или error. Format: tint (цветной текст, по умолчанию), text или json.
+// Без Sinks логи пишутся в stderr. InFile устарел: без Sinks добавляет к stderr файл logs/api.log
 type Logger struct {
+	Level  string
+	Format string
+	Sinks  []LogSink
 	InFile bool
 }
 
+// LogSink - Type: stderr, file или syslog, Format переопределяет Logger.Format для этого вывода.
+// Для file: MaxSize в мегабайтах, MaxAge в днях, MaxBackups - сколько старых файлов хранить, Compress - сжимать их в gzip.
+// Для syslog: Network и Address пустые - локальный syslog, Tag - имя программы в записях
+type LogSink struct {
+	Type       string
+	Format     string
+	Path       string
+	MaxSize    int
+	MaxAge     int
+	MaxBackups int
+	Compress   bool
+	Network    string
+	Address    string
+	Tag        string
+}
+
+// Tracing - Exporter: otlp, stdout или off (по умолчанию). Endpoint - host:port OTLP/HTTP коллектора,
+// SampleRatio - доля записываемых трейсов от 0 до 1, 0 - значение по умолчанию из common
+type Tracing struct {
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
+}
+
 func ParseConfig() *Config {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -65,3 +143,13 @@ func ParseConfig() *Config {
 
 	return cfg
 }
+
+// OnChange следит за файлом конфига и вызывает fn с перечитанным конфигом после каждого изменения,
+// err - ошибка разбора нового конфига
+func OnChange(fn func(cfg *Config, err error)) {
+	viper.OnConfigChange(func(fsnotify.Event) {
+		cfg := &Config{}
+		fn(cfg, viper.Unmarshal(cfg))
+	})
+	viper.WatchConfig()
+}
@@ -15,11 +15,80 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/actor/create": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "returns public keys for access token verification",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "JWKS",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.JWKS"
+                        }
+                    }
+                }
+            }
+        },
+        "/actors": {
+            "get": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns a page of actors with their films ordered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Actor"
+                ],
+                "summary": "GetActors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page size, default and maximum are set in config",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.GetActorsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
                 "security": [
                     {
                         "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
                 "description": "creates actor instance and returns its uuid. Birth in ISO format (2009-05-27T00:00:00.000Z)",
@@ -54,77 +123,133 @@ const docTemplate = `{
                 }
             }
         },
-        "/actor/delete": {
-            "post": {
+        "/actors/{id}": {
+            "get": {
                 "security": [
                     {
                         "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "deletes actor by its actorId",
-                "consumes": [
+                "description": "returns actor with filmography (film id, name and release date) by actor id",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Actor"
                 ],
-                "summary": "DeleteActor",
+                "summary": "GetActor",
                 "parameters": [
                     {
-                        "description": "actorId",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api_models.DeleteActorParams"
-                        }
+                        "type": "string",
+                        "description": "actor id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ActorDetails"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "actor version for If-Match"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/actor/get": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "return all actors with their films",
-                "produces": [
-                    "application/json"
-                ],
+                "description": "deletes actor by its actorId",
                 "tags": [
                     "Actor"
                 ],
-                "summary": "GetActors",
+                "summary": "DeleteActor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "actor id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from GET, delete fails with 412 if the actor was changed",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/actor/update": {
-            "post": {
+            },
+            "patch": {
                 "security": [
                     {
                         "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "updates actor info. Birth in ISO format (2009-05-27T00:00:00.000Z)",
+                "description": "JSON Merge Patch: only passed fields are changed. Birth in ISO format (2009-05-27T00:00:00.000Z)",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/merge-patch+json"
                 ],
                 "tags": [
                     "Actor"
                 ],
                 "summary": "UpdateActor",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "actor id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "description": "actor info",
                         "name": "input",
@@ -133,23 +258,56 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api_models.UpdateActorParams"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from GET, update fails with 412 if the actor was changed",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/film/create": {
+        "/api_key/create": {
             "post": {
                 "security": [
                     {
                         "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "creates film instance and returns its uuid. Release date in ISO format (2009-05-27T00:00:00.000Z)",
+                "description": "creates a named API key with scopes (permissions) and an optional expiry. The key is returned only once, pass it in X-API-Key header",
                 "consumes": [
                     "application/json"
                 ],
@@ -157,17 +315,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Film"
+                    "APIKey"
                 ],
-                "summary": "CreateFilm",
+                "summary": "CreateAPIKey",
                 "parameters": [
                     {
-                        "description": "film info",
+                        "description": "name, scopes and optional expires_at",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api_models.CreateFilmParams"
+                            "$ref": "#/definitions/api_models.CreateAPIKeyParams"
                         }
                     }
                 ],
@@ -175,72 +333,67 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api_models.CreateFilmParams"
+                            "$ref": "#/definitions/api_models.CreateAPIKeyResponse"
                         }
                     }
                 }
             }
         },
-        "/film/delete": {
-            "post": {
+        "/api_key/get": {
+            "get": {
                 "security": [
                     {
                         "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "deletes film by its filmId",
-                "consumes": [
+                "description": "returns all API keys without secrets, including revoked ones",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Film"
-                ],
-                "summary": "DeleteFilm",
-                "parameters": [
-                    {
-                        "description": "filmId",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api_models.DeleteFilmParams"
-                        }
-                    }
+                    "APIKey"
                 ],
+                "summary": "GetAPIKeys",
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.GetAPIKeysResponse"
+                        }
                     }
                 }
             }
         },
-        "/film/get": {
-            "get": {
+        "/api_key/revoke": {
+            "post": {
                 "security": [
                     {
                         "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "return all films with their actors",
-                "produces": [
+                "description": "revokes API key, it stops working immediately",
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "Film"
+                    "APIKey"
                 ],
-                "summary": "GetFilms",
+                "summary": "RevokeAPIKey",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "sort column",
-                        "name": "sort_by",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "sort asc",
-                        "name": "asc",
-                        "in": "query"
+                        "description": "api key id",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.RevokeAPIKeyParams"
+                        }
                     }
                 ],
                 "responses": {
@@ -250,57 +403,80 @@ const docTemplate = `{
                 }
             }
         },
-        "/film/search": {
+        "/films": {
             "get": {
                 "security": [
                     {
                         "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "accepts path parameters, name prioritized. Defaults: rate, desc",
+                "description": "returns a page of films with their actors",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Film"
                 ],
-                "summary": "SearchFilm",
+                "summary": "GetFilms",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "film name fragment",
-                        "name": "name",
+                        "example": "-rate,name",
+                        "description": "comma separated fields (name, rate, release_date), minus for descending. Default: -rate",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, default and maximum are set in config",
+                        "name": "limit",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "actor name fragment",
-                        "name": "actor_name",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.GetFilmsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
                     }
                 }
-            }
-        },
-        "/film/update": {
+            },
             "post": {
                 "security": [
                     {
                         "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
                     }
                 ],
-                "description": "updates film info. Release date in ISO format (2009-05-27T00:00:00.000Z)",
+                "description": "creates film instance and returns its uuid. Release date in ISO format (2009-05-27T00:00:00.000Z)",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Film"
                 ],
-                "summary": "UpdateFilm",
+                "summary": "CreateFilm",
                 "parameters": [
                     {
                         "description": "film info",
@@ -308,52 +484,816 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api_models.UpdateFilmParams"
+                            "$ref": "#/definitions/api_models.CreateFilmParams"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.CreateFilmParams"
+                        }
                     }
                 }
             }
         },
-        "/sign_in": {
-            "post": {
-                "description": "return access jwt, refresh jwt and access expiration",
-                "consumes": [
-                    "application/json"
+        "/films/search": {
+            "get": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "accepts query parameters, name prioritized",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authorization"
+                    "Film"
                 ],
-                "summary": "SingIn",
+                "summary": "SearchFilm",
                 "parameters": [
                     {
-                        "description": "Auth claims",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api_models.AuthParams"
-                        }
+                        "type": "string",
+                        "description": "film name fragment",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "actor name fragment",
+                        "name": "actor_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "-rate,name",
+                        "description": "comma separated fields (name, rate, release_date), minus for descending. Default: -rate",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size, default and maximum are set in config",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api_models.SignInUseCaseResponse"
+                            "$ref": "#/definitions/api_models.SearchFilmResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/sign_up": {
+        "/films/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns film with actors (id and name) by film id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Film"
+                ],
+                "summary": "GetFilm",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "film id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.FilmDetails"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "film version for If-Match"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "deletes film by its filmId",
+                "tags": [
+                    "Film"
+                ],
+                "summary": "DeleteFilm",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "film id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from GET, delete fails with 412 if the film was changed",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "JSON Merge Patch: only passed fields are changed, null clears description. Release date in ISO format (2009-05-27T00:00:00.000Z).\nactors replaces the whole list, add_actors and remove_actors change single actors",
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json"
+                ],
+                "tags": [
+                    "Film"
+                ],
+                "summary": "UpdateFilm",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "film id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "film info",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.UpdateFilmParams"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from GET, update fails with 412 if the film was changed",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "liveness probe: the process is up and serves requests, dependencies are not checked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Healthz",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.HealthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    }
+                ],
+                "description": "revokes current session and its access token",
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "Logout",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/logout_all": {
+            "post": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    }
+                ],
+                "description": "revokes every session and access token of the user",
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "LogoutAll",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/mfa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    }
+                ],
+                "description": "enables two-factor authentication by a code from the authenticator app, returns single-use recovery codes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "ConfirmMFA",
+                "parameters": [
+                    {
+                        "description": "TOTP code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.MFACodeParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.RecoveryCodesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/mfa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    }
+                ],
+                "description": "disables two-factor authentication, requires a TOTP or recovery code",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "DisableMFA",
+                "parameters": [
+                    {
+                        "description": "TOTP or recovery code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.MFACodeParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/mfa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    }
+                ],
+                "description": "generates a new TOTP secret for the current user. Two-factor authentication is enabled only after /mfa/confirm",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "MFA"
+                ],
+                "summary": "EnrollMFA",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.EnrollMFAResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/change": {
+            "post": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    }
+                ],
+                "description": "changes password of the current user, other sessions are revoked",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "ChangePassword",
+                "parameters": [
+                    {
+                        "description": "old and new passwords",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ChangePasswordParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "sends a single-use password reset token to the user email in the background. Responds 200 whether the login exists or not. Each request counts as a sign in attempt for the login and IP",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "ForgotPassword",
+                "parameters": [
+                    {
+                        "description": "login",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ForgotPasswordParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "seconds until the next attempt is allowed"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "description": "sets a new password by the reset token and revokes all user sessions",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "ResetPassword",
+                "parameters": [
+                    {
+                        "description": "reset token and new password",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ResetPasswordParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "readiness probe: pings postgres and redis and reports each of them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readyz",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ReadinessResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ReadinessResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/refresh": {
+            "post": {
+                "description": "exchanges refresh jwt for a new pair of tokens. Refresh token is rotated on each use, reusing an old one revokes the whole token family",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "Refresh",
+                "parameters": [
+                    {
+                        "description": "refresh token",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.RefreshParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.SignInUseCaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/role/get": {
+            "get": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "returns all roles with their permissions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "GetRoles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.GetRolesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/role/grant": {
+            "post": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "grants role to the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "GrantRole",
+                "parameters": [
+                    {
+                        "description": "user id and role",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.RoleParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/role/revoke": {
+            "post": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "revokes role from the user. Access tokens of the user are revoked, new permissions are applied on /refresh",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Role"
+                ],
+                "summary": "RevokeRole",
+                "parameters": [
+                    {
+                        "description": "user id and role",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.RoleParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    }
+                ],
+                "description": "returns active sessions of the user, current session is marked",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "GetSessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.GetSessionsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/revoke": {
+            "post": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    }
+                ],
+                "description": "revokes one of the user sessions by its id",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "RevokeSession",
+                "parameters": [
+                    {
+                        "description": "session id",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.RevokeSessionParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/sign_in": {
+            "post": {
+                "description": "return access jwt, refresh jwt and access expiration. Each sign in creates a separate session, device is an optional session label. If two-factor authentication is enabled, only mfa_token is returned and sign in must be completed via /sign_in/mfa",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "SingIn",
+                "parameters": [
+                    {
+                        "description": "Auth claims",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.AuthParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.SignInUseCaseResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.ErrorResponse"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "seconds until the next attempt is allowed"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/sign_in/mfa": {
+            "post": {
+                "description": "completes sign in by mfa_token from /sign_in and a TOTP or recovery code, returns access jwt, refresh jwt and access expiration",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "SignInMFA",
+                "parameters": [
+                    {
+                        "description": "mfa token and code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.SignInMFAParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.SignInUseCaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sign_up": {
             "post": {
                 "description": "Accepts login and password, returns nothing",
                 "consumes": [
@@ -380,44 +1320,374 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api_models.AuthParams": {
+        },
+        "/user/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "AccessTokenAuth": []
+                    },
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "removes sign in lockout and failed attempts counter for the login",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "UnlockUser",
+                "parameters": [
+                    {
+                        "description": "login",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api_models.UnlockUserParams"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/version": {
+            "get": {
+                "description": "returns service version, git commit and build time",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Version",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api_models.VersionResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api_models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.ActorAndFilms": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "string"
+                },
+                "birth": {
+                    "type": "string"
+                },
+                "films": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.FilmRef"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sex": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api_models.ActorDetails": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "string"
+                },
+                "birth": {
+                    "type": "string"
+                },
+                "films": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.FilmRef"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sex": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api_models.ActorRef": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.AuthParams": {
+            "type": "object",
+            "properties": {
+                "device": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.ChangePasswordParams": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "old_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.CreateAPIKeyParams": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api_models.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.CreateActorParams": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "type": "string"
+                },
+                "birth": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "sex": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api_models.CreateFilmParams": {
+            "type": "object",
+            "properties": {
+                "actors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "description": {
+                    "type": "string"
+                },
+                "film_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "integer"
+                },
+                "release_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.DependencyStatus": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.EnrollMFAResponse": {
+            "type": "object",
+            "properties": {
+                "provisioning_uri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.ErrorCode": {
+            "type": "string",
+            "enum": [
+                "validation_error",
+                "not_found",
+                "conflict",
+                "precondition_failed",
+                "unauthorized",
+                "forbidden",
+                "too_many_requests",
+                "internal_error"
+            ],
+            "x-enum-varnames": [
+                "CodeValidation",
+                "CodeNotFound",
+                "CodeConflict",
+                "CodePrecondition",
+                "CodeUnauthorized",
+                "CodeForbidden",
+                "CodeTooManyRequests",
+                "CodeInternal"
+            ]
+        },
+        "api_models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "$ref": "#/definitions/api_models.ErrorCode"
+                },
+                "details": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.FieldError": {
             "type": "object",
             "properties": {
-                "login": {
+                "field": {
                     "type": "string"
                 },
-                "password": {
+                "message": {
                     "type": "string"
                 }
             }
         },
-        "api_models.CreateActorParams": {
+        "api_models.FilmAndActors": {
             "type": "object",
             "properties": {
-                "actor_id": {
+                "actors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.ActorRef"
+                    }
+                },
+                "description": {
                     "type": "string"
                 },
-                "birth": {
+                "film_id": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
                 },
-                "sex": {
+                "rate": {
                     "type": "integer"
+                },
+                "release_date": {
+                    "type": "string"
                 }
             }
         },
-        "api_models.CreateFilmParams": {
+        "api_models.FilmDetails": {
             "type": "object",
             "properties": {
                 "actors": {
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/api_models.ActorRef"
                     }
                 },
                 "description": {
@@ -434,21 +1704,286 @@ const docTemplate = `{
                 },
                 "release_date": {
                     "type": "string"
+                },
+                "version": {
+                    "type": "integer"
                 }
             }
         },
-        "api_models.DeleteActorParams": {
+        "api_models.FilmRef": {
             "type": "object",
             "properties": {
-                "actor_id": {
+                "film_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "release_date": {
                     "type": "string"
                 }
             }
         },
-        "api_models.DeleteFilmParams": {
+        "api_models.ForgotPasswordParams": {
             "type": "object",
             "properties": {
-                "film_id": {
+                "login": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.GetAPIKeysResponse": {
+            "type": "object",
+            "properties": {
+                "response": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.APIKey"
+                    }
+                }
+            }
+        },
+        "api_models.GetActorsResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "response": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.ActorAndFilms"
+                    }
+                },
+                "total_estimate": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api_models.GetFilmsResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "response": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.FilmAndActors"
+                    }
+                },
+                "total_estimate": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api_models.GetRolesResponse": {
+            "type": "object",
+            "properties": {
+                "response": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.Role"
+                    }
+                }
+            }
+        },
+        "api_models.GetSessionsResponse": {
+            "type": "object",
+            "properties": {
+                "response": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.Session"
+                    }
+                }
+            }
+        },
+        "api_models.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.JWK"
+                    }
+                }
+            }
+        },
+        "api_models.MFACodeParams": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.ReadinessResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/api_models.DependencyStatus"
+                    }
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api_models.RefreshParams": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.ResetPasswordParams": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.RevokeAPIKeyParams": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.RevokeSessionParams": {
+            "type": "object",
+            "properties": {
+                "session_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.Role": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api_models.RoleParams": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.SearchFilmResponse": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "type": "string"
+                },
+                "response": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api_models.FilmAndActors"
+                    }
+                },
+                "total_estimate": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api_models.Session": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "device": {
+                    "type": "string"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
+        "api_models.SignInMFAParams": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "mfa_token": {
                     "type": "string"
                 }
             }
@@ -462,11 +1997,22 @@ const docTemplate = `{
                 "expiration": {
                     "type": "integer"
                 },
+                "mfa_token": {
+                    "type": "string"
+                },
                 "refresh_token": {
                     "type": "string"
                 }
             }
         },
+        "api_models.UnlockUserParams": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                }
+            }
+        },
         "api_models.UpdateActorParams": {
             "type": "object",
             "properties": {
@@ -474,7 +2020,8 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "birth": {
-                    "type": "string"
+                    "type": "string",
+                    "format": "date-time"
                 },
                 "name": {
                     "type": "string"
@@ -493,6 +2040,12 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "add_actors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "description": {
                     "type": "string"
                 },
@@ -506,6 +2059,27 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "release_date": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "remove_actors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "api_models.VersionResponse": {
+            "type": "object",
+            "properties": {
+                "build_time": {
+                    "type": "string"
+                },
+                "commit": {
+                    "type": "string"
+                },
+                "version": {
                     "type": "string"
                 }
             }
@@ -516,6 +2090,11 @@ const docTemplate = `{
             "type": "apiKey",
             "name": "Authorization",
             "in": "header"
+        },
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
@@ -2,9 +2,10 @@ package api_delivery
 
 import (
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"net/http"
 	"vk_test_task/internal/api/models"
+	log "vk_test_task/pkg/logger"
 )
 
 // CreateActor godoc
@@ -15,25 +16,22 @@ import (
 // @Accept json
 // @Produce json
 // @Success 200 {object} api_models.CreateActorParams
-// @Router /actor/create [post]
+// @Router /actors [post]
 // @Security AccessTokenAuth
+// @Security ApiKeyAuth
 func (h Handler) CreateActor() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var params api_models.CreateActorParams
-		err := json.NewDecoder(r.Body).Decode(&params)
+		err := decodeBody(r, &params)
 		if err != nil {
-			errText := fmt.Sprintf("create actor error: %s", err.Error())
-			h.logger.Error(errText)
-			w.WriteHeader(http.StatusBadRequest)
+			h.writeError(w, r, "create actor error", err)
 			return
 		}
-		h.logger.Info(fmt.Sprintf("/actore/create request. Params: %v", params))
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
 
-		params.ActorId, err = h.uc.CreateActor(params)
+		params.ActorId, err = h.uc.CreateActor(r.Context(), params)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("create actor error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "create actor error", err)
 			return
 		}
 
@@ -45,31 +43,75 @@ func (h Handler) CreateActor() http.HandlerFunc {
 
 // GetActors godoc
 // @Summary GetActors
-// @Description return all actors with their films
+// @Description returns a page of actors with their films ordered by name
 // @Tags Actor
+// @Param limit query int false "page size, default and maximum are set in config"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Produce json
-// @Success 200
-// @Router /actor/get [get]
+// @Success 200 {object} api_models.GetActorsResponse
+// @Failure 400 {object} api_models.ErrorResponse
+// @Router /actors [get]
 // @Security AccessTokenAuth
+// @Security ApiKeyAuth
 func (h Handler) GetActors() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		h.logger.Info(fmt.Sprintf("/actor/get request."))
-		response, err := h.uc.GetActors()
+		var params api_models.GetActorsParams
+
+		if fieldErrors := bindQuery(r.URL.Query(), &params); len(fieldErrors) > 0 {
+			h.writeError(w, r, "get actors error", api_models.NewValidationError("invalid query parameters", fieldErrors...))
+			return
+		}
+
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
+		response, err := h.uc.GetActors(r.Context(), params)
+		if err != nil {
+			h.writeError(w, r, "get actors error", err)
+			return
+		}
+
+		jsonResponse, err := json.Marshal(response)
+		if err != nil {
+			h.writeError(w, r, "get actors error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+	}
+}
+
+// GetActor godoc
+// @Summary GetActor
+// @Description returns actor with filmography (film id, name and release date) by actor id
+// @Tags Actor
+// @Param id path string true "actor id"
+// @Produce json
+// @Success 200 {object} api_models.ActorDetails
+// @Header 200 {string} ETag "actor version for If-Match"
+// @Failure 404 {object} api_models.ErrorResponse
+// @Router /actors/{id} [get]
+// @Security AccessTokenAuth
+// @Security ApiKeyAuth
+func (h Handler) GetActor() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		actorId := r.PathValue("id")
+
+		h.log(r).DebugContext(r.Context(), "request params", slog.String("id", actorId))
+
+		response, err := h.uc.GetActor(r.Context(), actorId)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("get actors error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "get actor error", err)
 			return
 		}
 
-		jsonResponse, err := response.MarshallJSON()
+		jsonResponse, err := json.Marshal(response)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("get actors error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "get actor error", err)
 			return
 		}
 
+		w.Header().Set("ETag", etag(response.Version))
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		w.Write(jsonResponse)
 	}
@@ -77,32 +119,44 @@ func (h Handler) GetActors() http.HandlerFunc {
 
 // UpdateActor godoc
 // @Summary UpdateActor
-// @Description updates actor info. Birth in ISO format (2009-05-27T00:00:00.000Z)
+// @Description JSON Merge Patch: only passed fields are changed. Birth in ISO format (2009-05-27T00:00:00.000Z)
 // @Tags Actor
+// @Param id path string true "actor id"
 // @Param input body api_models.UpdateActorParams true "actor info"
 // @Accept json
+// @Accept application/merge-patch+json
 // @Success 200
-// @Router /actor/update [post]
+// @Failure 400 {object} api_models.ErrorResponse
+// @Param If-Match header string false "ETag from GET, update fails with 412 if the actor was changed"
+// @Failure 404 {object} api_models.ErrorResponse
+// @Failure 409 {object} api_models.ErrorResponse
+// @Failure 412 {object} api_models.ErrorResponse
+// @Router /actors/{id} [patch]
 // @Security AccessTokenAuth
+// @Security ApiKeyAuth
 func (h Handler) UpdateActor() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var params api_models.UpdateActorParams
 
-		err := json.NewDecoder(r.Body).Decode(&params)
+		err := decodeBody(r, &params)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			errText := fmt.Sprintf("/actor/update error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "/actor/update error", err)
+			return
+		}
+		if actorId := r.PathValue("id"); actorId != "" {
+			params.ActorId = actorId
+		}
+		params.IfMatch, err = ifMatchVersions(r)
+		if err != nil {
+			h.writeError(w, r, "/actor/update error", err)
 			return
 		}
 
-		h.logger.Info(fmt.Sprintf("/actor/update request. Params: %v", params))
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
 
-		err = h.uc.UpdateActor(params)
+		err = h.uc.UpdateActor(r.Context(), params)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("/actor/update error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "/actor/update error", err)
 			return
 		}
 
@@ -114,30 +168,39 @@ func (h Handler) UpdateActor() http.HandlerFunc {
 // @Summary DeleteActor
 // @Description deletes actor by its actorId
 // @Tags Actor
-// @Param input body api_models.DeleteActorParams true "actorId"
-// @Accept json
+// @Param id path string true "actor id"
+// @Param If-Match header string false "ETag from GET, delete fails with 412 if the actor was changed"
 // @Success 200
-// @Router /actor/delete [post]
+// @Failure 404 {object} api_models.ErrorResponse
+// @Failure 409 {object} api_models.ErrorResponse
+// @Failure 412 {object} api_models.ErrorResponse
+// @Router /actors/{id} [delete]
 // @Security AccessTokenAuth
+// @Security ApiKeyAuth
 func (h Handler) DeleteActor() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var params api_models.DeleteActorParams
 
-		err := json.NewDecoder(r.Body).Decode(&params)
+		// старый путь /actor/delete принимает id в теле запроса
+		if actorId := r.PathValue("id"); actorId != "" {
+			params.ActorId = actorId
+		} else if err := decodeBody(r, &params); err != nil {
+			h.writeError(w, r, "/actor/delete error", err)
+			return
+		}
+
+		ifMatch, err := ifMatchVersions(r)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			errText := fmt.Sprintf("/actor/delete error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "/actor/delete error", err)
 			return
 		}
+		params.IfMatch = ifMatch
 
-		h.logger.Info(fmt.Sprintf("/actor/delete request. Params: %v", params))
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
 
-		err = h.uc.DeleteActor(params)
+		err = h.uc.DeleteActor(r.Context(), params)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("/actor/delete error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "/actor/delete error", err)
 			return
 		}
 
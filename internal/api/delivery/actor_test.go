@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 	mock_api "vk_test_task/internal/api/mocks"
@@ -41,7 +42,7 @@ func TestHandler_CreateActor(t *testing.T) {
 				Birth: time.Now(),
 			},
 			mockBehaviour: func(params api_models.CreateActorParams) {
-				uc.EXPECT().CreateActor(gomock.Any()).Return("userid", nil)
+				uc.EXPECT().CreateActor(gomock.Any(), gomock.Any()).Return("userid", nil)
 			},
 			wantErr: false,
 		},
@@ -53,7 +54,7 @@ func TestHandler_CreateActor(t *testing.T) {
 				Birth: time.Now(),
 			},
 			mockBehaviour: func(params api_models.CreateActorParams) {
-				uc.EXPECT().CreateActor(gomock.Any()).Return("", fmt.Errorf(""))
+				uc.EXPECT().CreateActor(gomock.Any(), gomock.Any()).Return("", fmt.Errorf(""))
 			},
 			wantErr: true,
 		},
@@ -61,7 +62,7 @@ func TestHandler_CreateActor(t *testing.T) {
 			name: "invalid params",
 			args: api_models.CreateActorParams{},
 			mockBehaviour: func(params api_models.CreateActorParams) {
-				uc.EXPECT().CreateActor(gomock.Any()).Return("", fmt.Errorf(""))
+				uc.EXPECT().CreateActor(gomock.Any(), gomock.Any()).Return("", fmt.Errorf(""))
 			},
 			wantErr: true,
 		},
@@ -107,12 +108,12 @@ func TestHandler_UpdateActor(t *testing.T) {
 			name: "default",
 			args: api_models.UpdateActorParams{
 				ActorId: "id",
-				Name:    "Name",
-				Sex:     1,
-				Birth:   time.Now(),
+				Name:    api_models.NewOptional("Name"),
+				Sex:     api_models.NewOptional(1),
+				Birth:   api_models.NewOptional(time.Now()),
 			},
 			mockBehaviour: func(params api_models.UpdateActorParams) {
-				uc.EXPECT().UpdateActor(gomock.Any()).Return(nil)
+				uc.EXPECT().UpdateActor(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -120,12 +121,12 @@ func TestHandler_UpdateActor(t *testing.T) {
 			name: "bad request",
 			args: api_models.UpdateActorParams{
 				ActorId: "",
-				Name:    "Name",
-				Sex:     1,
-				Birth:   time.Now(),
+				Name:    api_models.NewOptional("Name"),
+				Sex:     api_models.NewOptional(1),
+				Birth:   api_models.NewOptional(time.Now()),
 			},
 			mockBehaviour: func(params api_models.UpdateActorParams) {
-				uc.EXPECT().UpdateActor(gomock.Any()).Return(fmt.Errorf(""))
+				uc.EXPECT().UpdateActor(gomock.Any(), gomock.Any()).Return(fmt.Errorf(""))
 			},
 			wantErr: true,
 		},
@@ -164,20 +165,42 @@ func TestHandler_GetActors(t *testing.T) {
 
 	testTable := []struct {
 		name          string
+		query         string
 		mockBehaviour mockBehaviour
 		wantErr       bool
 	}{
 		{
 			name: "default",
 			mockBehaviour: func() {
-				uc.EXPECT().GetActors().Return(api_models.GetActorsResponse{}, nil)
+				uc.EXPECT().GetActors(gomock.Any(), api_models.GetActorsParams{}).Return(api_models.GetActorsResponse{}, nil)
 			},
 			wantErr: false,
 		},
+		{
+			name:  "page",
+			query: "?limit=10&cursor=abc",
+			mockBehaviour: func() {
+				uc.EXPECT().GetActors(gomock.Any(), api_models.GetActorsParams{Limit: 10, Cursor: "abc"}).
+					Return(api_models.GetActorsResponse{NextCursor: "def"}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:          "invalid limit",
+			query:         "?limit=ten",
+			mockBehaviour: func() {},
+			wantErr:       true,
+		},
+		{
+			name:          "negative limit",
+			query:         "?limit=-1",
+			mockBehaviour: func() {},
+			wantErr:       true,
+		},
 		{
 			name: "internal server error",
 			mockBehaviour: func() {
-				uc.EXPECT().GetActors().Return(api_models.GetActorsResponse{}, fmt.Errorf(""))
+				uc.EXPECT().GetActors(gomock.Any(), api_models.GetActorsParams{}).Return(api_models.GetActorsResponse{}, fmt.Errorf(""))
 			},
 			wantErr: true,
 		},
@@ -189,7 +212,7 @@ func TestHandler_GetActors(t *testing.T) {
 
 			ts := httptest.NewServer(h.GetActors())
 			defer ts.Close()
-			res, _ := http.Post(ts.URL, "application/json", nil)
+			res, _ := http.Post(ts.URL+test.query, "application/json", nil)
 			var response api_models.GetActorsResponse
 			json.NewDecoder(res.Body).Decode(&response)
 
@@ -225,7 +248,7 @@ func TestHandler_DeleteActor(t *testing.T) {
 				ActorId: "id",
 			},
 			mockBehaviour: func(params api_models.DeleteActorParams) {
-				uc.EXPECT().DeleteActor(params).Return(nil)
+				uc.EXPECT().DeleteActor(gomock.Any(), params).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -235,7 +258,7 @@ func TestHandler_DeleteActor(t *testing.T) {
 				ActorId: "",
 			},
 			mockBehaviour: func(params api_models.DeleteActorParams) {
-				uc.EXPECT().DeleteActor(params).Return(fmt.Errorf(""))
+				uc.EXPECT().DeleteActor(gomock.Any(), params).Return(fmt.Errorf(""))
 			},
 			wantErr: true,
 		},
@@ -261,3 +284,75 @@ func TestHandler_DeleteActor(t *testing.T) {
 	}
 
 }
+
+func TestHandler_GetActor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /actors/{id}", h.GetActor())
+
+	testTable := []struct {
+		name          string
+		actorId       string
+		mockBehaviour func(actorId string)
+		wantStatus    int
+	}{
+		{
+			name:    "default",
+			actorId: "id",
+			mockBehaviour: func(actorId string) {
+				uc.EXPECT().GetActor(gomock.Any(), actorId).Return(api_models.ActorDetails{ActorId: actorId, Version: 2}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "not found",
+			actorId: "id",
+			mockBehaviour: func(actorId string) {
+				uc.EXPECT().GetActor(gomock.Any(), actorId).Return(api_models.ActorDetails{}, fmt.Errorf("actor %w", api_models.ErrNotFound))
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.actorId)
+
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+			res, _ := http.Get(ts.URL + "/actors/" + test.actorId)
+
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+			if test.wantStatus == http.StatusOK {
+				assert.Equal(t, `"2"`, res.Header.Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateActorByPath(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PATCH /actors/{id}", h.UpdateActor())
+
+	uc.EXPECT().UpdateActor(gomock.Any(), api_models.UpdateActorParams{ActorId: "id", Name: api_models.NewOptional("Name")}).Return(nil)
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	req, _ := http.NewRequest(http.MethodPatch, ts.URL+"/actors/id", strings.NewReader(`{"name": "Name"}`))
+	res, _ := http.DefaultClient.Do(req)
+
+	assert.Equal(t, "200 OK", res.Status)
+}
@@ -0,0 +1,117 @@
+package api_delivery
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"vk_test_task/internal/api/models"
+	"vk_test_task/internal/middleware"
+	log "vk_test_task/pkg/logger"
+)
+
+// CreateAPIKey godoc
+// @Summary CreateAPIKey
+// @Description creates a named API key with scopes (permissions) and an optional expiry. The key is returned only once, pass it in X-API-Key header
+// @Tags APIKey
+// @Param input body api_models.CreateAPIKeyParams true "name, scopes and optional expires_at"
+// @Accept json
+// @Produce json
+// @Success 200 {object} api_models.CreateAPIKeyResponse
+// @Router /api_key/create [post]
+// @Security AccessTokenAuth
+// @Security ApiKeyAuth
+func (h Handler) CreateAPIKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := middleware.ClaimsFromContext(r.Context())
+		if !ok {
+			h.writeError(w, r, "/api_key/create error", api_models.NewUnauthorizedError("no claims in context"))
+			return
+		}
+
+		var params api_models.CreateAPIKeyParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "/api_key/create error", err)
+			return
+		}
+
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
+
+		resp, err := h.uc.CreateAPIKey(r.Context(), claims, params)
+		if err != nil {
+			h.writeError(w, r, "/api_key/create error", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		jsonResponse, err := json.Marshal(resp)
+		_, err = w.Write(jsonResponse)
+		if err != nil {
+			h.log(r).ErrorContext(r.Context(), "/api_key/create error", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
+
+// GetAPIKeys godoc
+// @Summary GetAPIKeys
+// @Description returns all API keys without secrets, including revoked ones
+// @Tags APIKey
+// @Produce json
+// @Success 200 {object} api_models.GetAPIKeysResponse
+// @Router /api_key/get [get]
+// @Security AccessTokenAuth
+// @Security ApiKeyAuth
+func (h Handler) GetAPIKeys() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, err := h.uc.GetAPIKeys(r.Context())
+		if err != nil {
+			h.writeError(w, r, "/api_key/get error", err)
+			return
+		}
+
+		jsonResponse, err := json.Marshal(response)
+		if err != nil {
+			h.writeError(w, r, "/api_key/get error", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+	}
+}
+
+// RevokeAPIKey godoc
+// @Summary RevokeAPIKey
+// @Description revokes API key, it stops working immediately
+// @Tags APIKey
+// @Param input body api_models.RevokeAPIKeyParams true "api key id"
+// @Accept json
+// @Success 200
+// @Router /api_key/revoke [post]
+// @Security AccessTokenAuth
+// @Security ApiKeyAuth
+func (h Handler) RevokeAPIKey() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params api_models.RevokeAPIKeyParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "/api_key/revoke error", err)
+			return
+		}
+
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
+
+		err = h.uc.RevokeAPIKey(r.Context(), params)
+		if err != nil {
+			h.writeError(w, r, "/api_key/revoke error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
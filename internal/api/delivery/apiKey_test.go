@@ -0,0 +1,180 @@
+package api_delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/lmittmann/tint"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestHandler_CreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	claims := &api_models.AuthClaims{UserId: "id", Permissions: []string{"film:read"}}
+
+	type mockBehaviour func(params api_models.CreateAPIKeyParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.CreateAPIKeyParams
+		claims        *api_models.AuthClaims
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			args:   api_models.CreateAPIKeyParams{Name: "importer", Scopes: []string{"film:read"}},
+			claims: claims,
+			mockBehaviour: func(params api_models.CreateAPIKeyParams) {
+				uc.EXPECT().CreateAPIKey(gomock.Any(), *claims, params).Return(api_models.CreateAPIKeyResponse{Id: "id", Key: "vk_key"}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:   "usecase error",
+			args:   api_models.CreateAPIKeyParams{Name: "importer"},
+			claims: claims,
+			mockBehaviour: func(params api_models.CreateAPIKeyParams) {
+				uc.EXPECT().CreateAPIKey(gomock.Any(), *claims, params).Return(api_models.CreateAPIKeyResponse{}, fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no claims",
+			args:   api_models.CreateAPIKeyParams{Name: "importer", Scopes: []string{"film:read"}},
+			claims: nil,
+			mockBehaviour: func(params api_models.CreateAPIKeyParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(withClaims(test.claims, h.CreateAPIKey()))
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
+
+func TestHandler_GetAPIKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			mockBehaviour: func() {
+				uc.EXPECT().GetAPIKeys(gomock.Any()).Return(api_models.GetAPIKeysResponse{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "usecase error",
+			mockBehaviour: func() {
+				uc.EXPECT().GetAPIKeys(gomock.Any()).Return(api_models.GetAPIKeysResponse{}, fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour()
+
+			ts := httptest.NewServer(h.GetAPIKeys())
+			defer ts.Close()
+			res, _ := http.Get(ts.URL)
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
+
+func TestHandler_RevokeAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(params api_models.RevokeAPIKeyParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.RevokeAPIKeyParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.RevokeAPIKeyParams{Id: "id"},
+			mockBehaviour: func(params api_models.RevokeAPIKeyParams) {
+				uc.EXPECT().RevokeAPIKey(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "usecase error",
+			args: api_models.RevokeAPIKeyParams{},
+			mockBehaviour: func(params api_models.RevokeAPIKeyParams) {
+				uc.EXPECT().RevokeAPIKey(gomock.Any(), params).Return(fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(h.RevokeAPIKey())
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
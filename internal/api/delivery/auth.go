@@ -2,41 +2,40 @@ package api_delivery
 
 import (
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"net/http"
 	"vk_test_task/internal/api/models"
+	"vk_test_task/internal/middleware"
+	log "vk_test_task/pkg/logger"
 )
 
 // SignIn godoc
 // @Summary SingIn
-// @Description return access jwt, refresh jwt and access expiration
+// @Description return access jwt, refresh jwt and access expiration. Each sign in creates a separate session, device is an optional session label. If two-factor authentication is enabled, only mfa_token is returned and sign in must be completed via /sign_in/mfa
 // @Tags Authorization
 // @Param input body api_models.AuthParams true "Auth claims"
 // @Accept json
 // @Produce json
 // @Success 200 {object} api_models.SignInUseCaseResponse
+// @Failure 429 {object} api_models.ErrorResponse
+// @Header 429 {integer} Retry-After "seconds until the next attempt is allowed"
 // @Router /sign_in [post]
 func (h Handler) SignIn() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var params api_models.AuthParams
 
-		err := json.NewDecoder(r.Body).Decode(&params)
+		err := decodeBody(r, &params)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			errText := fmt.Sprintf("sign in error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "sign in error", err)
 			return
 		}
 
-		h.logger.Info(fmt.Sprintf("/sign_in request."))
+		params.IP = clientIP(r)
+		params.UserAgent = r.UserAgent()
 
-		resp, err := h.uc.SignIn(params)
+		resp, err := h.uc.SignIn(r.Context(), params)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			errorResponse, _ := json.Marshal(api_models.ErrorResponse{Description: err.Error()})
-			w.Write(errorResponse)
-			errText := fmt.Sprintf("sign in error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "sign in error", err)
 			return
 		}
 
@@ -45,8 +44,7 @@ func (h Handler) SignIn() http.HandlerFunc {
 		jsonResponse, err := json.Marshal(resp)
 		_, err = w.Write(jsonResponse)
 		if err != nil {
-			errText := fmt.Sprintf("sign in error: %s", err.Error())
-			h.logger.Error(errText)
+			h.log(r).ErrorContext(r.Context(), "sign in error", slog.String("error", err.Error()))
 			return
 		}
 	}
@@ -64,27 +62,207 @@ func (h Handler) SignUp() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var params api_models.AuthParams
 
-		err := json.NewDecoder(r.Body).Decode(&params)
+		err := decodeBody(r, &params)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			errText := fmt.Sprintf("sign in error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "sign in error", err)
 			return
 		}
 
-		h.logger.Info(fmt.Sprintf("/sign_up request."))
+		err = h.uc.SignUp(r.Context(), params)
+		if err != nil {
+			h.writeError(w, r, "sign in error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Header().Set("Content-Type", "application/json")
+	}
+}
+
+// Refresh godoc
+// @Summary Refresh
+// @Description exchanges refresh jwt for a new pair of tokens. Refresh token is rotated on each use, reusing an old one revokes the whole token family
+// @Tags Authorization
+// @Param input body api_models.RefreshParams true "refresh token"
+// @Accept json
+// @Produce json
+// @Success 200 {object} api_models.SignInUseCaseResponse
+// @Router /refresh [post]
+func (h Handler) Refresh() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params api_models.RefreshParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "refresh error", err)
+			return
+		}
+
+		params.IP = clientIP(r)
+		params.UserAgent = r.UserAgent()
+
+		resp, err := h.uc.Refresh(r.Context(), params)
+		if err != nil {
+			h.writeError(w, r, "refresh error", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		jsonResponse, err := json.Marshal(resp)
+		_, err = w.Write(jsonResponse)
+		if err != nil {
+			h.log(r).ErrorContext(r.Context(), "refresh error", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description revokes current session and its access token
+// @Tags Authorization
+// @Success 200
+// @Router /logout [post]
+// @Security AccessTokenAuth
+func (h Handler) Logout() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := middleware.ClaimsFromContext(r.Context())
+		if !ok {
+			h.writeError(w, r, "logout error", api_models.NewUnauthorizedError("no claims in context"))
+			return
+		}
 
-		err = h.uc.SignUp(params)
+		err := h.uc.Logout(r.Context(), claims)
 		if err != nil {
-			errorResponse, _ := json.Marshal(api_models.ErrorResponse{Description: err.Error()})
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write(errorResponse)
-			errText := fmt.Sprintf("sign in error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "logout error", err)
 			return
 		}
 
 		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// LogoutAll godoc
+// @Summary LogoutAll
+// @Description revokes every session and access token of the user
+// @Tags Authorization
+// @Success 200
+// @Router /logout_all [post]
+// @Security AccessTokenAuth
+func (h Handler) LogoutAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := middleware.ClaimsFromContext(r.Context())
+		if !ok {
+			h.writeError(w, r, "logout all error", api_models.NewUnauthorizedError("no claims in context"))
+			return
+		}
+
+		err := h.uc.LogoutAll(r.Context(), claims)
+		if err != nil {
+			h.writeError(w, r, "logout all error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// GetSessions godoc
+// @Summary GetSessions
+// @Description returns active sessions of the user, current session is marked
+// @Tags Authorization
+// @Produce json
+// @Success 200 {object} api_models.GetSessionsResponse
+// @Router /sessions [get]
+// @Security AccessTokenAuth
+func (h Handler) GetSessions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := middleware.ClaimsFromContext(r.Context())
+		if !ok {
+			h.writeError(w, r, "get sessions error", api_models.NewUnauthorizedError("no claims in context"))
+			return
+		}
+
+		response, err := h.uc.GetSessions(r.Context(), claims)
+		if err != nil {
+			h.writeError(w, r, "get sessions error", err)
+			return
+		}
+
+		jsonResponse, err := json.Marshal(response)
+		if err != nil {
+			h.writeError(w, r, "get sessions error", err)
+			return
+		}
+
 		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+	}
+}
+
+// RevokeSession godoc
+// @Summary RevokeSession
+// @Description revokes one of the user sessions by its id
+// @Tags Authorization
+// @Param input body api_models.RevokeSessionParams true "session id"
+// @Accept json
+// @Success 200
+// @Router /sessions/revoke [post]
+// @Security AccessTokenAuth
+func (h Handler) RevokeSession() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := middleware.ClaimsFromContext(r.Context())
+		if !ok {
+			h.writeError(w, r, "revoke session error", api_models.NewUnauthorizedError("no claims in context"))
+			return
+		}
+
+		var params api_models.RevokeSessionParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "revoke session error", err)
+			return
+		}
+
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
+
+		err = h.uc.RevokeSession(r.Context(), claims, params)
+		if err != nil {
+			h.writeError(w, r, "revoke session error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// JWKS godoc
+// @Summary JWKS
+// @Description returns public keys for access token verification
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} api_models.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h Handler) JWKS() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, err := h.uc.GetJWKS(r.Context())
+		if err != nil {
+			h.writeError(w, r, "jwks error", err)
+			return
+		}
+
+		jsonResponse, err := json.Marshal(response)
+		if err != nil {
+			h.writeError(w, r, "jwks error", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
 	}
 }
@@ -12,8 +12,10 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 	mock_api "vk_test_task/internal/api/mocks"
 	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/middleware"
 )
 
 func TestHandler_SignIn(t *testing.T) {
@@ -31,6 +33,7 @@ func TestHandler_SignIn(t *testing.T) {
 		args          api_models.AuthParams
 		mockBehaviour mockBehaviour
 		wantErr       bool
+		retryAfter    string
 	}{
 		{
 			name: "default",
@@ -39,7 +42,7 @@ func TestHandler_SignIn(t *testing.T) {
 				Password: "password",
 			},
 			mockBehaviour: func(params api_models.AuthParams) {
-				uc.EXPECT().SignIn(params).Return(api_models.SignInUseCaseResponse{}, nil)
+				uc.EXPECT().SignIn(gomock.Any(), gomock.Any()).Return(api_models.SignInUseCaseResponse{}, nil)
 			},
 			wantErr: false,
 		},
@@ -50,10 +53,23 @@ func TestHandler_SignIn(t *testing.T) {
 				Password: "",
 			},
 			mockBehaviour: func(params api_models.AuthParams) {
-				uc.EXPECT().SignIn(params).Return(api_models.SignInUseCaseResponse{}, fmt.Errorf(""))
+				uc.EXPECT().SignIn(gomock.Any(), gomock.Any()).Return(api_models.SignInUseCaseResponse{}, fmt.Errorf(""))
 			},
 			wantErr: true,
 		},
+		{
+			name: "too many attempts",
+			args: api_models.AuthParams{
+				Login:    "login",
+				Password: "password",
+			},
+			mockBehaviour: func(params api_models.AuthParams) {
+				uc.EXPECT().SignIn(gomock.Any(), gomock.Any()).Return(api_models.SignInUseCaseResponse{},
+					api_models.TooManyRequestsError{RetryAfter: 1500 * time.Millisecond})
+			},
+			wantErr:    true,
+			retryAfter: "2",
+		},
 	}
 
 	for _, test := range testTable {
@@ -67,6 +83,11 @@ func TestHandler_SignIn(t *testing.T) {
 			var response api_models.SignInUseCaseResponse
 			json.NewDecoder(res.Body).Decode(&response)
 
+			if test.retryAfter != "" {
+				assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+				assert.Equal(t, test.retryAfter, res.Header.Get("Retry-After"))
+			}
+
 			if test.wantErr {
 				assert.NotEqual(t, "200 OK", res.Status)
 			} else {
@@ -100,7 +121,7 @@ func TestHandler_SignUp(t *testing.T) {
 				Password: "password",
 			},
 			mockBehaviour: func(params api_models.AuthParams) {
-				uc.EXPECT().SignUp(params).Return(nil)
+				uc.EXPECT().SignUp(gomock.Any(), params).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -111,7 +132,7 @@ func TestHandler_SignUp(t *testing.T) {
 				Password: "",
 			},
 			mockBehaviour: func(params api_models.AuthParams) {
-				uc.EXPECT().SignUp(params).Return(fmt.Errorf(""))
+				uc.EXPECT().SignUp(gomock.Any(), params).Return(fmt.Errorf(""))
 			},
 			wantErr: true,
 		},
@@ -135,3 +156,338 @@ func TestHandler_SignUp(t *testing.T) {
 	}
 
 }
+
+func TestHandler_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(params api_models.RefreshParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.RefreshParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.RefreshParams{
+				RefreshToken: "token",
+			},
+			mockBehaviour: func(params api_models.RefreshParams) {
+				uc.EXPECT().Refresh(gomock.Any(), gomock.Any()).Return(api_models.SignInUseCaseResponse{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "revoked token",
+			args: api_models.RefreshParams{
+				RefreshToken: "token",
+			},
+			mockBehaviour: func(params api_models.RefreshParams) {
+				uc.EXPECT().Refresh(gomock.Any(), gomock.Any()).Return(api_models.SignInUseCaseResponse{}, fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(h.Refresh())
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+
+}
+
+func TestHandler_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(claims api_models.AuthClaims)
+
+	testTable := []struct {
+		name          string
+		claims        *api_models.AuthClaims
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			claims: &api_models.AuthClaims{UserId: "id"},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				uc.EXPECT().Logout(gomock.Any(), claims).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:   "usecase error",
+			claims: &api_models.AuthClaims{UserId: "id"},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				uc.EXPECT().Logout(gomock.Any(), claims).Return(fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no claims",
+			claims: nil,
+			mockBehaviour: func(claims api_models.AuthClaims) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			var claims api_models.AuthClaims
+			if test.claims != nil {
+				claims = *test.claims
+			}
+			test.mockBehaviour(claims)
+
+			ts := httptest.NewServer(withClaims(test.claims, h.Logout()))
+			defer ts.Close()
+			res, _ := http.Post(ts.URL, "application/json", nil)
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+
+}
+
+func TestHandler_LogoutAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(claims api_models.AuthClaims)
+
+	testTable := []struct {
+		name          string
+		claims        *api_models.AuthClaims
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			claims: &api_models.AuthClaims{UserId: "id"},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				uc.EXPECT().LogoutAll(gomock.Any(), claims).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:   "usecase error",
+			claims: &api_models.AuthClaims{UserId: "id"},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				uc.EXPECT().LogoutAll(gomock.Any(), claims).Return(fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(*test.claims)
+
+			ts := httptest.NewServer(withClaims(test.claims, h.LogoutAll()))
+			defer ts.Close()
+			res, _ := http.Post(ts.URL, "application/json", nil)
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+
+}
+
+func withClaims(claims *api_models.AuthClaims, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if claims != nil {
+			r = r.WithContext(middleware.ContextWithClaims(r.Context(), *claims))
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+func TestHandler_GetSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(claims api_models.AuthClaims)
+
+	testTable := []struct {
+		name          string
+		claims        api_models.AuthClaims
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			claims: api_models.AuthClaims{UserId: "id", SessionId: "sid"},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				uc.EXPECT().GetSessions(gomock.Any(), claims).Return(api_models.GetSessionsResponse{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:   "usecase error",
+			claims: api_models.AuthClaims{UserId: "id", SessionId: "sid"},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				uc.EXPECT().GetSessions(gomock.Any(), claims).Return(api_models.GetSessionsResponse{}, fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.claims)
+
+			ts := httptest.NewServer(withClaims(&test.claims, h.GetSessions()))
+			defer ts.Close()
+			res, _ := http.Get(ts.URL)
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+
+}
+
+func TestHandler_RevokeSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(claims api_models.AuthClaims, params api_models.RevokeSessionParams)
+
+	testTable := []struct {
+		name          string
+		claims        api_models.AuthClaims
+		args          api_models.RevokeSessionParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			claims: api_models.AuthClaims{UserId: "id"},
+			args:   api_models.RevokeSessionParams{SessionId: "sid"},
+			mockBehaviour: func(claims api_models.AuthClaims, params api_models.RevokeSessionParams) {
+				uc.EXPECT().RevokeSession(gomock.Any(), claims, params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:   "usecase error",
+			claims: api_models.AuthClaims{UserId: "id"},
+			args:   api_models.RevokeSessionParams{},
+			mockBehaviour: func(claims api_models.AuthClaims, params api_models.RevokeSessionParams) {
+				uc.EXPECT().RevokeSession(gomock.Any(), claims, params).Return(fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.claims, test.args)
+
+			ts := httptest.NewServer(withClaims(&test.claims, h.RevokeSession()))
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+
+}
+
+func TestHandler_JWKS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			mockBehaviour: func() {
+				uc.EXPECT().GetJWKS(gomock.Any()).Return(api_models.JWKS{Keys: []api_models.JWK{{Kid: "kid"}}}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "usecase error",
+			mockBehaviour: func() {
+				uc.EXPECT().GetJWKS(gomock.Any()).Return(api_models.JWKS{}, fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour()
+
+			ts := httptest.NewServer(h.JWKS())
+			defer ts.Close()
+			res, _ := http.Get(ts.URL)
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
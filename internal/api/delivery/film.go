@@ -2,12 +2,10 @@ package api_delivery
 
 import (
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"net/http"
-	"regexp"
-	"strconv"
 	"vk_test_task/internal/api/models"
-	"vk_test_task/internal/common"
+	log "vk_test_task/pkg/logger"
 )
 
 // CreateFilm godoc
@@ -18,25 +16,22 @@ import (
 // @Accept json
 // @Produce json
 // @Success 200 {object} api_models.CreateFilmParams
-// @Router /film/create [post]
+// @Router /films [post]
 // @Security AccessTokenAuth
+// @Security ApiKeyAuth
 func (h Handler) CreateFilm() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var params api_models.CreateFilmParams
-		err := json.NewDecoder(r.Body).Decode(&params)
+		err := decodeBody(r, &params)
 		if err != nil {
-			errText := fmt.Sprintf("create film error: %s", err.Error())
-			h.logger.Error(errText)
-			w.WriteHeader(http.StatusBadRequest)
+			h.writeError(w, r, "create film error", err)
 			return
 		}
-		h.logger.Info(fmt.Sprintf("/film/create request. Params: %v", params))
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
 
-		params.FilmId, err = h.uc.CreateFilm(params)
+		params.FilmId, err = h.uc.CreateFilm(r.Context(), params)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("create film error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "create film error", err)
 			return
 		}
 
@@ -48,50 +43,133 @@ func (h Handler) CreateFilm() http.HandlerFunc {
 
 // GetFilms godoc
 // @Summary GetFilms
-// @Description return all films with their actors
+// @Description returns a page of films with their actors
 // @Tags Film
-// @Param sort_by query string false "sort column"
-// @Param asc query string false "sort asc"
+// @Param sort query string false "comma separated fields (name, rate, release_date), minus for descending. Default: -rate" example(-rate,name)
+// @Param limit query int false "page size, default and maximum are set in config"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Produce json
-// @Success 200
-// @Router /film/get [get]
+// @Success 200 {object} api_models.GetFilmsResponse
+// @Failure 400 {object} api_models.ErrorResponse
+// @Router /films [get]
 // @Security AccessTokenAuth
+// @Security ApiKeyAuth
 func (h Handler) GetFilms() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		var params api_models.GetFilmsParams
 
-		re, _ := regexp.Compile(`/film/get\?sort_by=(\d)&asc=(\d)`)
-		matches := re.FindStringSubmatch(r.URL.String())
-		if len(matches) == 3 {
-			sortByString := matches[1]
-			isAscendingString := matches[2]
+		if fieldErrors := bindQuery(r.URL.Query(), &params); len(fieldErrors) > 0 {
+			h.writeError(w, r, "get films error", api_models.NewValidationError("invalid query parameters", fieldErrors...))
+			return
+		}
+		if len(params.Sort) == 0 {
+			params.Sort = api_models.DefaultFilmSort
+		}
+
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
+
+		response, err := h.uc.GetFilms(r.Context(), params)
+		if err != nil {
+			h.writeError(w, r, "get films error", err)
+			return
+		}
+
+		jsonResponse, err := json.Marshal(response)
+		if err != nil {
+			h.writeError(w, r, "get films error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+	}
+}
+
+// legacyFilmSortFields - значения sort_by старого /film/get
+var legacyFilmSortFields = map[string]string{"1": "name", "2": "rate", "3": "release_date"}
+
+// GetFilmsLegacy - GetFilms для старого пути /film/get: sort_by (1 - name, 2 - rate, 3 - release_date)
+// и asc (1 - по возрастанию, 2 - по убыванию) переводятся в sort
+func (h Handler) GetFilmsLegacy() http.HandlerFunc {
+	getFilms := h.GetFilms()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if !query.Has("sort_by") && !query.Has("asc") {
+			getFilms(w, r)
+			return
+		}
+
+		var fieldErrors []api_models.FieldError
+		if query.Has("sort") {
+			fieldErrors = append(fieldErrors, api_models.FieldError{Field: "sort", Message: "must not be used with sort_by and asc"})
+		}
+
+		field := "rate"
+		if query.Has("sort_by") {
+			var ok bool
+			if field, ok = legacyFilmSortFields[query.Get("sort_by")]; !ok {
+				fieldErrors = append(fieldErrors, api_models.FieldError{Field: "sort_by", Message: "must be 1, 2 or 3"})
+			}
+		}
+
+		switch query.Get("asc") {
+		case "1":
+		case "", "2":
+			field = "-" + field
+		default:
+			fieldErrors = append(fieldErrors, api_models.FieldError{Field: "asc", Message: "must be 1 or 2"})
+		}
 
-			params.SortBy, _ = strconv.Atoi(sortByString)
-			params.IsAscending, _ = strconv.Atoi(isAscendingString)
-		} else {
-			params.SortBy = common.SORT_FILM_BY_RATE
-			params.IsAscending = common.SORT_FILM_DESC
+		if len(fieldErrors) > 0 {
+			h.writeError(w, r, "get films error", api_models.NewValidationError("invalid query parameters", fieldErrors...))
+			return
 		}
 
-		h.logger.Info(fmt.Sprintf("/film/get request. Params: %v", params))
+		query.Del("sort_by")
+		query.Del("asc")
+		query.Set("sort", field)
+
+		r = r.Clone(r.Context())
+		r.URL.RawQuery = query.Encode()
+
+		getFilms(w, r)
+	}
+}
+
+// GetFilm godoc
+// @Summary GetFilm
+// @Description returns film with actors (id and name) by film id
+// @Tags Film
+// @Param id path string true "film id"
+// @Produce json
+// @Success 200 {object} api_models.FilmDetails
+// @Header 200 {string} ETag "film version for If-Match"
+// @Failure 404 {object} api_models.ErrorResponse
+// @Router /films/{id} [get]
+// @Security AccessTokenAuth
+// @Security ApiKeyAuth
+func (h Handler) GetFilm() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filmId := r.PathValue("id")
+
+		h.log(r).DebugContext(r.Context(), "request params", slog.String("id", filmId))
 
-		response, err := h.uc.GetFilms(params)
+		response, err := h.uc.GetFilm(r.Context(), filmId)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("get films error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "get film error", err)
 			return
 		}
 
-		jsonResponse, err := response.MarshallJSON()
+		jsonResponse, err := json.Marshal(response)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("get films error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "get film error", err)
 			return
 		}
 
+		w.Header().Set("ETag", etag(response.Version))
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		w.Write(jsonResponse)
 	}
@@ -99,32 +177,45 @@ func (h Handler) GetFilms() http.HandlerFunc {
 
 // UpdateFilm godoc
 // @Summary UpdateFilm
-// @Description updates film info. Release date in ISO format (2009-05-27T00:00:00.000Z)
+// @Description JSON Merge Patch: only passed fields are changed, null clears description. Release date in ISO format (2009-05-27T00:00:00.000Z).
+// @Description actors replaces the whole list, add_actors and remove_actors change single actors
 // @Tags Film
+// @Param id path string true "film id"
 // @Param input body api_models.UpdateFilmParams true "film info"
 // @Accept json
+// @Accept application/merge-patch+json
 // @Success 200
-// @Router /film/update [post]
+// @Failure 400 {object} api_models.ErrorResponse
+// @Param If-Match header string false "ETag from GET, update fails with 412 if the film was changed"
+// @Failure 404 {object} api_models.ErrorResponse
+// @Failure 409 {object} api_models.ErrorResponse
+// @Failure 412 {object} api_models.ErrorResponse
+// @Router /films/{id} [patch]
 // @Security AccessTokenAuth
+// @Security ApiKeyAuth
 func (h Handler) UpdateFilm() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var params api_models.UpdateFilmParams
 
-		err := json.NewDecoder(r.Body).Decode(&params)
+		err := decodeBody(r, &params)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			errText := fmt.Sprintf("/film/update error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "/film/update error", err)
+			return
+		}
+		if filmId := r.PathValue("id"); filmId != "" {
+			params.FilmId = filmId
+		}
+		params.IfMatch, err = ifMatchVersions(r)
+		if err != nil {
+			h.writeError(w, r, "/film/update error", err)
 			return
 		}
 
-		h.logger.Info(fmt.Sprintf("/film/update request. Params: %v", params))
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
 
-		err = h.uc.UpdateFilm(params)
+		err = h.uc.UpdateFilm(r.Context(), params)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("/film/update error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "/film/update error", err)
 			return
 		}
 
@@ -136,30 +227,39 @@ func (h Handler) UpdateFilm() http.HandlerFunc {
 // @Summary DeleteFilm
 // @Description deletes film by its filmId
 // @Tags Film
-// @Param input body api_models.DeleteFilmParams true "filmId"
-// @Accept json
+// @Param id path string true "film id"
+// @Param If-Match header string false "ETag from GET, delete fails with 412 if the film was changed"
 // @Success 200
-// @Router /film/delete [post]
+// @Failure 404 {object} api_models.ErrorResponse
+// @Failure 409 {object} api_models.ErrorResponse
+// @Failure 412 {object} api_models.ErrorResponse
+// @Router /films/{id} [delete]
 // @Security AccessTokenAuth
+// @Security ApiKeyAuth
 func (h Handler) DeleteFilm() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var params api_models.DeleteFilmParams
 
-		err := json.NewDecoder(r.Body).Decode(&params)
+		// старый путь /film/delete принимает id в теле запроса
+		if filmId := r.PathValue("id"); filmId != "" {
+			params.FilmId = filmId
+		} else if err := decodeBody(r, &params); err != nil {
+			h.writeError(w, r, "/film/delete error", err)
+			return
+		}
+
+		ifMatch, err := ifMatchVersions(r)
 		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			errText := fmt.Sprintf("/film/delete error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "/film/delete error", err)
 			return
 		}
+		params.IfMatch = ifMatch
 
-		h.logger.Info(fmt.Sprintf("/film/delete request. Params: %v", params))
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
 
-		err = h.uc.DeleteFilm(params)
+		err = h.uc.DeleteFilm(r.Context(), params)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("/film/delete error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "/film/delete error", err)
 			return
 		}
 
@@ -169,58 +269,40 @@ func (h Handler) DeleteFilm() http.HandlerFunc {
 
 // SearchFilm godoc
 // @Summary SearchFilm
-// @Description accepts path parameters, name prioritized. Defaults: rate, desc
+// @Description accepts query parameters, name prioritized
 // @Tags Film
 // @Param name query string false "film name fragment"
 // @Param actor_name query string false "actor name fragment"
+// @Param sort query string false "comma separated fields (name, rate, release_date), minus for descending. Default: -rate" example(-rate,name)
+// @Param limit query int false "page size, default and maximum are set in config"
+// @Param cursor query string false "next_cursor from the previous page"
 // @Produce json
-// @Success 200
-// @Router /film/search [get]
+// @Success 200 {object} api_models.SearchFilmResponse
+// @Failure 400 {object} api_models.ErrorResponse
+// @Router /films/search [get]
 // @Security AccessTokenAuth
+// @Security ApiKeyAuth
 func (h Handler) SearchFilm() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var params api_models.SearchFilmParams
 
-		flag := true
-		re, _ := regexp.Compile(`/film/search\?name=(\w+)`)
-		matches := re.FindStringSubmatch(r.URL.String())
-		if len(matches) == 2 {
-			params.Name = matches[1]
-			flag = false
-		}
-
-		if flag {
-			re, _ = regexp.Compile(`/film/search\?actor_name=(\w+)`)
-			matches = re.FindStringSubmatch(r.URL.String())
-			if len(matches) == 2 {
-				params.ActorName = matches[1]
-				flag = false
-			}
-		}
-
-		if flag {
-			w.WriteHeader(http.StatusBadRequest)
-			errText := fmt.Sprintf("/film/search error: empty params")
-			h.logger.Error(errText)
+		if fieldErrors := bindQuery(r.URL.Query(), &params); len(fieldErrors) > 0 {
+			h.writeError(w, r, "/film/search error", api_models.NewValidationError("invalid query parameters", fieldErrors...))
 			return
 		}
 
-		h.logger.Info(fmt.Sprintf("/film/search request. Params: %v", params))
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
 
-		response, err := h.uc.SearchFilm(params)
+		response, err := h.uc.SearchFilm(r.Context(), params)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("/film/search error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "/film/search error", err)
 			return
 		}
 
-		jsonResponse, err := response.MarshallJSON()
+		jsonResponse, err := json.Marshal(response)
 
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			errText := fmt.Sprintf("search films error: %s", err.Error())
-			h.logger.Error(errText)
+			h.writeError(w, r, "search films error", err)
 			return
 		}
 
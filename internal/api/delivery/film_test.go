@@ -10,11 +10,14 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
 	"time"
 	mock_api "vk_test_task/internal/api/mocks"
 	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
 )
 
 func TestHandler_CreateFilm(t *testing.T) {
@@ -43,7 +46,7 @@ func TestHandler_CreateFilm(t *testing.T) {
 				Actors:      []string{"id1", "id2"},
 			},
 			mockBehaviour: func(params api_models.CreateFilmParams) {
-				uc.EXPECT().CreateFilm(gomock.Any()).Return("", nil)
+				uc.EXPECT().CreateFilm(gomock.Any(), gomock.Any()).Return("", nil)
 			},
 			wantErr: false,
 		},
@@ -57,7 +60,7 @@ func TestHandler_CreateFilm(t *testing.T) {
 				Actors:      []string{"id1", "id2"},
 			},
 			mockBehaviour: func(params api_models.CreateFilmParams) {
-				uc.EXPECT().CreateFilm(gomock.Any()).Return("", fmt.Errorf(""))
+				uc.EXPECT().CreateFilm(gomock.Any(), gomock.Any()).Return("", fmt.Errorf(""))
 			},
 			wantErr: true,
 		},
@@ -103,14 +106,14 @@ func TestHandler_UpdateFilm(t *testing.T) {
 			name: "default",
 			args: api_models.UpdateFilmParams{
 				FilmId:      "id",
-				Name:        "",
-				Description: "desc",
-				ReleaseDate: time.Now(),
-				Rate:        2,
-				Actors:      []string{"id1", "id2"},
+				Name:        api_models.NewOptional(""),
+				Description: api_models.NewOptional("desc"),
+				ReleaseDate: api_models.NewOptional(time.Now()),
+				Rate:        api_models.NewOptional(2),
+				Actors:      api_models.NewOptional([]string{"id1", "id2"}),
 			},
 			mockBehaviour: func(params api_models.UpdateFilmParams) {
-				uc.EXPECT().UpdateFilm(gomock.Any()).Return(nil)
+				uc.EXPECT().UpdateFilm(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -118,14 +121,14 @@ func TestHandler_UpdateFilm(t *testing.T) {
 			name: "bad request",
 			args: api_models.UpdateFilmParams{
 				FilmId:      "",
-				Name:        "",
-				Description: "desc",
-				ReleaseDate: time.Now(),
-				Rate:        2,
-				Actors:      []string{"id1", "id2"},
+				Name:        api_models.NewOptional(""),
+				Description: api_models.NewOptional("desc"),
+				ReleaseDate: api_models.NewOptional(time.Now()),
+				Rate:        api_models.NewOptional(2),
+				Actors:      api_models.NewOptional([]string{"id1", "id2"}),
 			},
 			mockBehaviour: func(params api_models.UpdateFilmParams) {
-				uc.EXPECT().UpdateFilm(gomock.Any()).Return(fmt.Errorf(""))
+				uc.EXPECT().UpdateFilm(gomock.Any(), gomock.Any()).Return(fmt.Errorf(""))
 			},
 			wantErr: true,
 		},
@@ -164,31 +167,85 @@ func TestHandler_GetFilms(t *testing.T) {
 
 	testTable := []struct {
 		name          string
+		query         string
 		args          api_models.GetFilmsParams
 		mockBehaviour mockBehaviour
-		wantErr       bool
+		wantStatus    int
 	}{
 		{
-			name: "default",
+			name:  "default",
+			query: "sort=-rate,name",
 			args: api_models.GetFilmsParams{
-				SortBy:      1,
-				IsAscending: 1,
+				Sort: api_models.Sort{{Field: "rate", Desc: true}, {Field: "name"}},
 			},
 			mockBehaviour: func(params api_models.GetFilmsParams) {
-				uc.EXPECT().GetFilms(params).Return(api_models.GetFilmsResponse{}, nil)
+				uc.EXPECT().GetFilms(gomock.Any(), params).Return(api_models.GetFilmsResponse{}, nil)
 			},
-			wantErr: false,
+			wantStatus: http.StatusOK,
 		},
 		{
-			name: "internal server error",
+			name:  "default sort",
+			query: "",
 			args: api_models.GetFilmsParams{
-				SortBy:      1,
-				IsAscending: 1,
+				Sort: api_models.DefaultFilmSort,
 			},
 			mockBehaviour: func(params api_models.GetFilmsParams) {
-				uc.EXPECT().GetFilms(params).Return(api_models.GetFilmsResponse{}, fmt.Errorf(""))
+				uc.EXPECT().GetFilms(gomock.Any(), params).Return(api_models.GetFilmsResponse{}, nil)
 			},
-			wantErr: true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "explicit ascending",
+			query: "sort=%2Brelease_date",
+			args: api_models.GetFilmsParams{
+				Sort: api_models.Sort{{Field: "release_date"}},
+			},
+			mockBehaviour: func(params api_models.GetFilmsParams) {
+				uc.EXPECT().GetFilms(gomock.Any(), params).Return(api_models.GetFilmsResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "page",
+			query: "sort=name&limit=5&cursor=eyJpZCI6IjEifQ",
+			args: api_models.GetFilmsParams{
+				Sort:   api_models.Sort{{Field: "name"}},
+				Limit:  5,
+				Cursor: "eyJpZCI6IjEifQ",
+			},
+			mockBehaviour: func(params api_models.GetFilmsParams) {
+				uc.EXPECT().GetFilms(gomock.Any(), params).Return(api_models.GetFilmsResponse{NextCursor: "next", TotalEstimate: 10}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "invalid limit",
+			query:         "limit=-5",
+			mockBehaviour: func(params api_models.GetFilmsParams) {},
+			wantStatus:    http.StatusBadRequest,
+		},
+		{
+			name:          "unknown sort field",
+			query:         "sort=budget",
+			mockBehaviour: func(params api_models.GetFilmsParams) {},
+			wantStatus:    http.StatusBadRequest,
+		},
+		{
+			name:          "unknown parameter",
+			query:         "genre=drama",
+			mockBehaviour: func(params api_models.GetFilmsParams) {},
+			wantStatus:    http.StatusBadRequest,
+		},
+		{
+			name:  "internal server error",
+			query: "sort=name",
+			args: api_models.GetFilmsParams{
+				Sort: api_models.Sort{{Field: "name"}},
+			},
+			mockBehaviour: func(params api_models.GetFilmsParams) {
+				uc.EXPECT().GetFilms(gomock.Any(), params).Return(api_models.GetFilmsResponse{}, fmt.Errorf(""))
+			},
+			wantStatus: http.StatusInternalServerError,
 		},
 	}
 
@@ -198,21 +255,100 @@ func TestHandler_GetFilms(t *testing.T) {
 
 			ts := httptest.NewServer(h.GetFilms())
 			defer ts.Close()
-			r, _ := json.Marshal(test.args)
-			res, _ := http.Post(fmt.Sprintf(ts.URL+"/film/get?sort_by=%d&asc=%d",
-				test.args.SortBy, test.args.IsAscending),
-				"application/json", bytes.NewReader(r))
-			var response api_models.GetFilmsResponse
-			json.NewDecoder(res.Body).Decode(&response)
+			res, _ := http.Get(ts.URL + "/films?" + test.query)
 
-			if test.wantErr {
-				assert.NotEqual(t, "200 OK", res.Status)
-			} else {
-				assert.Equal(t, "200 OK", res.Status)
-			}
+			assert.Equal(t, test.wantStatus, res.StatusCode)
 		})
 	}
+}
+
+func TestHandler_GetFilmsLegacy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(params api_models.GetFilmsParams)
+
+	testTable := []struct {
+		name          string
+		query         string
+		args          api_models.GetFilmsParams
+		mockBehaviour mockBehaviour
+		wantStatus    int
+	}{
+		{
+			name:  "name ascending",
+			query: "sort_by=1&asc=1",
+			args: api_models.GetFilmsParams{
+				Sort: api_models.Sort{{Field: "name"}},
+			},
+			mockBehaviour: func(params api_models.GetFilmsParams) {
+				uc.EXPECT().GetFilms(gomock.Any(), params).Return(api_models.GetFilmsResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "release date descending",
+			query: "sort_by=3&asc=2",
+			args: api_models.GetFilmsParams{
+				Sort: api_models.Sort{{Field: "release_date", Desc: true}},
+			},
+			mockBehaviour: func(params api_models.GetFilmsParams) {
+				uc.EXPECT().GetFilms(gomock.Any(), params).Return(api_models.GetFilmsResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "only sort_by",
+			query: "sort_by=2&limit=5",
+			args: api_models.GetFilmsParams{
+				Sort:  api_models.Sort{{Field: "rate", Desc: true}},
+				Limit: 5,
+			},
+			mockBehaviour: func(params api_models.GetFilmsParams) {
+				uc.EXPECT().GetFilms(gomock.Any(), params).Return(api_models.GetFilmsResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "new parameters",
+			query: "sort=name",
+			args: api_models.GetFilmsParams{
+				Sort: api_models.Sort{{Field: "name"}},
+			},
+			mockBehaviour: func(params api_models.GetFilmsParams) {
+				uc.EXPECT().GetFilms(gomock.Any(), params).Return(api_models.GetFilmsResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "invalid sort_by",
+			query:         "sort_by=4&asc=1",
+			mockBehaviour: func(params api_models.GetFilmsParams) {},
+			wantStatus:    http.StatusBadRequest,
+		},
+		{
+			name:          "sort_by with sort",
+			query:         "sort_by=1&sort=name",
+			mockBehaviour: func(params api_models.GetFilmsParams) {},
+			wantStatus:    http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(h.GetFilmsLegacy())
+			defer ts.Close()
+			res, _ := http.Get(ts.URL + "/film/get?" + test.query)
 
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+		})
+	}
 }
 
 func TestHandler_DeleteFilm(t *testing.T) {
@@ -237,7 +373,7 @@ func TestHandler_DeleteFilm(t *testing.T) {
 				FilmId: "id",
 			},
 			mockBehaviour: func(params api_models.DeleteFilmParams) {
-				uc.EXPECT().DeleteFilm(params).Return(nil)
+				uc.EXPECT().DeleteFilm(gomock.Any(), params).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -247,7 +383,7 @@ func TestHandler_DeleteFilm(t *testing.T) {
 				FilmId: "",
 			},
 			mockBehaviour: func(params api_models.DeleteFilmParams) {
-				uc.EXPECT().DeleteFilm(params).Return(fmt.Errorf(""))
+				uc.EXPECT().DeleteFilm(gomock.Any(), params).Return(fmt.Errorf(""))
 			},
 			wantErr: true,
 		},
@@ -298,7 +434,7 @@ func TestHandler_SearchFilm(t *testing.T) {
 			ActorName: "",
 		},
 		mockBehaviour: func(params api_models.SearchFilmParams) {
-			uc.EXPECT().SearchFilm(params).Return(api_models.SearchFilmResponse{}, nil)
+			uc.EXPECT().SearchFilm(gomock.Any(), params).Return(api_models.SearchFilmResponse{}, nil)
 		},
 		wantErr: false,
 	}
@@ -352,7 +488,7 @@ func TestHandler_SearchFilm(t *testing.T) {
 			ActorName: "actor",
 		},
 		mockBehaviour: func(params api_models.SearchFilmParams) {
-			uc.EXPECT().SearchFilm(params).Return(api_models.SearchFilmResponse{}, nil)
+			uc.EXPECT().SearchFilm(gomock.Any(), params).Return(api_models.SearchFilmResponse{}, nil)
 		},
 		wantErr: false,
 	}
@@ -374,3 +510,343 @@ func TestHandler_SearchFilm(t *testing.T) {
 		}
 	})
 }
+
+func TestHandler_SearchFilmQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	testTable := []struct {
+		name          string
+		query         url.Values
+		mockBehaviour func()
+		wantStatus    int
+		wantFields    []api_models.FieldError
+	}{
+		{
+			name:  "cyrillic name with space",
+			query: url.Values{"name": {"Брат 2"}},
+			mockBehaviour: func() {
+				uc.EXPECT().SearchFilm(gomock.Any(), api_models.SearchFilmParams{Name: "Брат 2"}).
+					Return(api_models.SearchFilmResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:  "parameter order does not matter",
+			query: url.Values{"actor_name": {"Сергей Бодров"}, "name": {"Брат"}},
+			mockBehaviour: func() {
+				uc.EXPECT().SearchFilm(gomock.Any(), api_models.SearchFilmParams{Name: "Брат", ActorName: "Сергей Бодров"}).
+					Return(api_models.SearchFilmResponse{}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "repeated parameter",
+			query:         url.Values{"name": {"a", "b"}},
+			mockBehaviour: func() {},
+			wantStatus:    http.StatusBadRequest,
+			wantFields:    []api_models.FieldError{{Field: "name", Message: "must be specified once"}},
+		},
+		{
+			name:          "too long name",
+			query:         url.Values{"name": {strings.Repeat("я", common.FILM_NAME_MAXSIZE+1)}},
+			mockBehaviour: func() {},
+			wantStatus:    http.StatusBadRequest,
+			wantFields: []api_models.FieldError{{Field: "name",
+				Message: fmt.Sprintf("must be at most %d characters", common.FILM_NAME_MAXSIZE)}},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour()
+
+			ts := httptest.NewServer(h.SearchFilm())
+			defer ts.Close()
+			res, _ := http.Get(ts.URL + "/films/search?" + test.query.Encode())
+
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+			if test.wantFields != nil {
+				var response api_models.ErrorResponse
+				json.NewDecoder(res.Body).Decode(&response)
+				assert.Equal(t, test.wantFields, response.Details)
+			}
+		})
+	}
+}
+
+func TestHandler_GetFilm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /films/{id}", h.GetFilm())
+
+	testTable := []struct {
+		name          string
+		filmId        string
+		mockBehaviour func(filmId string)
+		wantStatus    int
+		want          api_models.FilmDetails
+	}{
+		{
+			name:   "default",
+			filmId: "id",
+			mockBehaviour: func(filmId string) {
+				uc.EXPECT().GetFilm(gomock.Any(), filmId).Return(api_models.FilmDetails{
+					FilmId:  filmId,
+					Version: 3,
+					Actors:  []api_models.ActorRef{{ActorId: "a1", Name: "Depp, Johnny"}},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			want: api_models.FilmDetails{
+				FilmId:  "id",
+				Version: 3,
+				Actors:  []api_models.ActorRef{{ActorId: "a1", Name: "Depp, Johnny"}},
+			},
+		},
+		{
+			name:   "not found",
+			filmId: "id",
+			mockBehaviour: func(filmId string) {
+				uc.EXPECT().GetFilm(gomock.Any(), filmId).Return(api_models.FilmDetails{}, fmt.Errorf("film %w", api_models.ErrNotFound))
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "internal server error",
+			filmId: "id",
+			mockBehaviour: func(filmId string) {
+				uc.EXPECT().GetFilm(gomock.Any(), filmId).Return(api_models.FilmDetails{}, fmt.Errorf(""))
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.filmId)
+
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+			res, _ := http.Get(ts.URL + "/films/" + test.filmId)
+
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+			if test.wantStatus == http.StatusOK {
+				var film api_models.FilmDetails
+				assert.NoError(t, json.NewDecoder(res.Body).Decode(&film))
+				assert.Equal(t, test.want, film)
+				assert.Equal(t, `"3"`, res.Header.Get("ETag"))
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateFilmMergePatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PATCH /films/{id}", h.UpdateFilm())
+
+	testTable := []struct {
+		name string
+		body string
+		want api_models.UpdateFilmParams
+	}{
+		{
+			name: "absent fields are not set",
+			body: `{"name": "Avatar"}`,
+			want: api_models.UpdateFilmParams{FilmId: "id", Name: api_models.NewOptional("Avatar")},
+		},
+		{
+			name: "null and zero differ",
+			body: `{"description": null, "rate": 0}`,
+			want: api_models.UpdateFilmParams{
+				FilmId:      "id",
+				Description: api_models.Optional[string]{Set: true, Null: true},
+				Rate:        api_models.NewOptional(0),
+			},
+		},
+		{
+			name: "single actors",
+			body: `{"add_actors": ["a1"], "remove_actors": ["a2"]}`,
+			want: api_models.UpdateFilmParams{FilmId: "id", AddActors: []string{"a1"}, RemoveActors: []string{"a2"}},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			uc.EXPECT().UpdateFilm(gomock.Any(), test.want).Return(nil)
+
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+			req, _ := http.NewRequest(http.MethodPatch, ts.URL+"/films/id", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			res, _ := http.DefaultClient.Do(req)
+
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+		})
+	}
+}
+
+func TestHandler_DeleteFilmByPath(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /films/{id}", h.DeleteFilm())
+
+	uc.EXPECT().DeleteFilm(gomock.Any(), api_models.DeleteFilmParams{FilmId: "id"}).Return(nil)
+
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+"/films/id", nil)
+	res, _ := http.DefaultClient.Do(req)
+
+	assert.Equal(t, "200 OK", res.Status)
+}
+
+func TestHandler_UpdateFilmIfMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PATCH /films/{id}", h.UpdateFilm())
+	mux.HandleFunc("DELETE /films/{id}", h.DeleteFilm())
+
+	params := api_models.UpdateFilmParams{FilmId: "id", Rate: api_models.NewOptional(7)}
+
+	testTable := []struct {
+		name          string
+		method        string
+		ifMatch       string
+		mockBehaviour func()
+		wantStatus    int
+	}{
+		{
+			name:   "without if-match",
+			method: http.MethodPatch,
+			mockBehaviour: func() {
+				uc.EXPECT().UpdateFilm(gomock.Any(), params).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "etag from get",
+			method:  http.MethodPatch,
+			ifMatch: `"4"`,
+			mockBehaviour: func() {
+				withVersion := params
+				withVersion.IfMatch = []int{4}
+				uc.EXPECT().UpdateFilm(gomock.Any(), withVersion).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "weak etag never matches",
+			method:        http.MethodPatch,
+			ifMatch:       `W/"4"`,
+			mockBehaviour: func() {},
+			wantStatus:    http.StatusPreconditionFailed,
+		},
+		{
+			name:    "etag list",
+			method:  http.MethodPatch,
+			ifMatch: `"3", W/"4" , "abc"`,
+			mockBehaviour: func() {
+				withVersion := params
+				withVersion.IfMatch = []int{3}
+				uc.EXPECT().UpdateFilm(gomock.Any(), withVersion).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "any version",
+			method:  http.MethodPatch,
+			ifMatch: "*",
+			mockBehaviour: func() {
+				uc.EXPECT().UpdateFilm(gomock.Any(), params).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:          "unquoted if-match never matches",
+			method:        http.MethodPatch,
+			ifMatch:       "4",
+			mockBehaviour: func() {},
+			wantStatus:    http.StatusPreconditionFailed,
+		},
+		{
+			name:          "foreign etag never matches",
+			method:        http.MethodDelete,
+			ifMatch:       `"abc", W/"0"`,
+			mockBehaviour: func() {},
+			wantStatus:    http.StatusPreconditionFailed,
+		},
+		{
+			name:    "stale version",
+			method:  http.MethodPatch,
+			ifMatch: `"3"`,
+			mockBehaviour: func() {
+				uc.EXPECT().UpdateFilm(gomock.Any(), gomock.Any()).Return(api_models.NewPreconditionError("film version mismatch"))
+			},
+			wantStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:    "concurrent update",
+			method:  http.MethodPatch,
+			ifMatch: `"4"`,
+			mockBehaviour: func() {
+				uc.EXPECT().UpdateFilm(gomock.Any(), gomock.Any()).Return(api_models.NewConflictError("film was modified concurrently"))
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:    "delete with etag",
+			method:  http.MethodDelete,
+			ifMatch: `"2"`,
+			mockBehaviour: func() {
+				uc.EXPECT().DeleteFilm(gomock.Any(), api_models.DeleteFilmParams{FilmId: "id", IfMatch: []int{2}}).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour()
+
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+			req, _ := http.NewRequest(test.method, ts.URL+"/films/id", strings.NewReader(`{"rate": 7}`))
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+			res, _ := http.DefaultClient.Do(req)
+
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+		})
+	}
+}
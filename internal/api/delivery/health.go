@@ -0,0 +1,72 @@
+package api_delivery
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"vk_test_task/internal/api/models"
+)
+
+// Healthz godoc
+// @Summary Healthz
+// @Description liveness probe: the process is up and serves requests, dependencies are not checked
+// @Tags Health
+// @Produce json
+// @Success 200 {object} api_models.HealthResponse
+// @Router /healthz [get]
+func (h Handler) Healthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse, _ := json.Marshal(api_models.HealthResponse{Status: api_models.StatusUp})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+	}
+}
+
+// Readyz godoc
+// @Summary Readyz
+// @Description readiness probe: pings postgres and redis and reports each of them
+// @Tags Health
+// @Produce json
+// @Success 200 {object} api_models.ReadinessResponse
+// @Failure 503 {object} api_models.ReadinessResponse
+// @Router /readyz [get]
+func (h Handler) Readyz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := h.uc.Readiness(r.Context())
+
+		status := http.StatusOK
+		if response.Status != api_models.StatusUp {
+			status = http.StatusServiceUnavailable
+			for name, check := range response.Checks {
+				if check.Err != nil {
+					h.log(r).ErrorContext(r.Context(), "readiness check error", slog.String("check", name), slog.String("error", check.Err.Error()))
+				}
+			}
+		}
+
+		jsonResponse, _ := json.Marshal(response)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write(jsonResponse)
+	}
+}
+
+// Version godoc
+// @Summary Version
+// @Description returns service version, git commit and build time
+// @Tags Health
+// @Produce json
+// @Success 200 {object} api_models.VersionResponse
+// @Router /version [get]
+func (h Handler) Version() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jsonResponse, _ := json.Marshal(h.uc.Version())
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+	}
+}
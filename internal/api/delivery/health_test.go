@@ -0,0 +1,96 @@
+package api_delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/lmittmann/tint"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestHandler_Healthz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	w := httptest.NewRecorder()
+	h.Healthz().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"up"}`, w.Body.String())
+}
+
+func TestHandler_Readyz(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	testTable := []struct {
+		name       string
+		response   api_models.ReadinessResponse
+		wantStatus int
+	}{
+		{
+			name: "ready",
+			response: api_models.ReadinessResponse{Status: api_models.StatusUp, Checks: map[string]api_models.DependencyStatus{
+				"postgres": {Status: api_models.StatusUp},
+				"redis":    {Status: api_models.StatusUp},
+			}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "redis is down",
+			response: api_models.ReadinessResponse{Status: api_models.StatusDown, Checks: map[string]api_models.DependencyStatus{
+				"postgres": {Status: api_models.StatusUp},
+				"redis":    {Status: api_models.StatusDown, Error: "unreachable", Err: errors.New("dial tcp 10.0.0.1:6379: connection refused")},
+			}},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			uc.EXPECT().Readiness(gomock.Any()).Return(test.response)
+
+			w := httptest.NewRecorder()
+			h.Readyz().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, test.wantStatus, w.Code)
+
+			var response api_models.ReadinessResponse
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, test.response.Status, response.Status)
+			assert.NotContains(t, w.Body.String(), "10.0.0.1")
+		})
+	}
+}
+
+func TestHandler_Version(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	uc.EXPECT().Version().Return(api_models.VersionResponse{Version: "1.0.0", Commit: "abc", BuildTime: "2024-01-01T00:00:00Z"})
+
+	w := httptest.NewRecorder()
+	h.Version().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"version":"1.0.0","commit":"abc","build_time":"2024-01-01T00:00:00Z"}`, w.Body.String())
+}
@@ -1,9 +1,18 @@
 package api_delivery
 
 import (
+	"encoding/json"
+	"errors"
 	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
 	"vk_test_task/config"
 	"vk_test_task/internal/api"
+	"vk_test_task/internal/api/models"
+	log "vk_test_task/pkg/logger"
 )
 
 type Handler struct {
@@ -19,3 +28,75 @@ func New(cfg *config.Config, logger *slog.Logger, uc api.UseCaseInterface) Handl
 		uc:     uc,
 	}
 }
+
+// log - логгер запроса с request_id, route и user_id из middleware.RequestLog, вне маршрутизатора (в тестах) - логгер хендлера
+func (h Handler) log(r *http.Request) *slog.Logger {
+	return log.FromContext(r.Context(), h.logger)
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// writeError логирует ошибку с префиксом хендлера и отвечает конвертом ErrorResponse, статус выбирается по коду ошибки
+func (h Handler) writeError(w http.ResponseWriter, r *http.Request, prefix string, err error) {
+	h.log(r).ErrorContext(r.Context(), prefix, slog.String("error", err.Error()))
+
+	status, response := api_models.NewErrorResponse(err, r.Header.Get("X-Request-ID"))
+
+	var limitErr api_models.TooManyRequestsError
+	if errors.As(err, &limitErr) {
+		w.Header().Set("Retry-After", strconv.FormatInt(int64(math.Ceil(limitErr.RetryAfter.Seconds())), 10))
+	}
+
+	jsonResponse, _ := json.Marshal(response)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonResponse)
+}
+
+// decodeBody разбирает JSON тело запроса, ошибка разбора считается ошибкой валидации
+func decodeBody(r *http.Request, dst interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		return api_models.WrapError(api_models.CodeValidation, "invalid request body", err)
+	}
+	return nil
+}
+
+// etag - версия ресурса в виде сильного ETag
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// ifMatchVersions достает версии из If-Match: список ETag через запятую. If-Match использует сильное сравнение
+// (RFC 9110, 13.1.1), поэтому слабые W/"N" никогда не совпадают. Без заголовка или с "*" возвращает nil - версия
+// не проверяется. Если ни один тег не может быть нашей версией - 412
+func ifMatchVersions(r *http.Request) ([]int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return nil, nil
+	}
+
+	var versions []int
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		version, err := strconv.Atoi(tag[1 : len(tag)-1])
+		if err != nil || version < 1 {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	if len(versions) == 0 {
+		return nil, api_models.NewPreconditionError("If-Match does not match any version")
+	}
+
+	return versions, nil
+}
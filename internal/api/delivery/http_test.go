@@ -0,0 +1,119 @@
+package api_delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/lmittmann/tint"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestHandler_ErrorResponse(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	testTable := []struct {
+		name          string
+		body          string
+		mockBehaviour func()
+		wantStatus    int
+		want          api_models.ErrorResponse
+	}{
+		{
+			name: "validation",
+			body: `{"name": "film"}`,
+			mockBehaviour: func() {
+				uc.EXPECT().CreateFilm(gomock.Any(), gomock.Any()).Return("", fmt.Errorf("usecase error: %w",
+					api_models.NewFieldError("rate", "must be between 0 and 10")))
+			},
+			wantStatus: http.StatusBadRequest,
+			want: api_models.ErrorResponse{
+				Code:      api_models.CodeValidation,
+				Message:   "invalid rate",
+				Details:   []api_models.FieldError{{Field: "rate", Message: "must be between 0 and 10"}},
+				RequestId: "request-id",
+			},
+		},
+		{
+			name: "conflict",
+			body: `{"name": "film"}`,
+			mockBehaviour: func() {
+				uc.EXPECT().CreateFilm(gomock.Any(), gomock.Any()).Return("", api_models.NewConflictError("film already exists"))
+			},
+			wantStatus: http.StatusConflict,
+			want: api_models.ErrorResponse{
+				Code:      api_models.CodeConflict,
+				Message:   "film already exists",
+				RequestId: "request-id",
+			},
+		},
+		{
+			name: "forbidden",
+			body: `{"name": "film"}`,
+			mockBehaviour: func() {
+				uc.EXPECT().CreateFilm(gomock.Any(), gomock.Any()).Return("", api_models.NewForbiddenError("forbidden"))
+			},
+			wantStatus: http.StatusForbidden,
+			want: api_models.ErrorResponse{
+				Code:      api_models.CodeForbidden,
+				Message:   "forbidden",
+				RequestId: "request-id",
+			},
+		},
+		{
+			name: "internal error is not disclosed",
+			body: `{"name": "film"}`,
+			mockBehaviour: func() {
+				uc.EXPECT().CreateFilm(gomock.Any(), gomock.Any()).Return("", fmt.Errorf("repository error: connection refused"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			want: api_models.ErrorResponse{
+				Code:      api_models.CodeInternal,
+				Message:   "internal server error",
+				RequestId: "request-id",
+			},
+		},
+		{
+			name:          "invalid body",
+			body:          `{"name": `,
+			mockBehaviour: func() {},
+			wantStatus:    http.StatusBadRequest,
+			want: api_models.ErrorResponse{
+				Code:      api_models.CodeValidation,
+				Message:   "invalid request body",
+				RequestId: "request-id",
+			},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour()
+
+			ts := httptest.NewServer(h.CreateFilm())
+			defer ts.Close()
+			req, _ := http.NewRequest(http.MethodPost, ts.URL, bytes.NewReader([]byte(test.body)))
+			req.Header.Set("X-Request-ID", "request-id")
+			res, _ := http.DefaultClient.Do(req)
+
+			var response api_models.ErrorResponse
+			json.NewDecoder(res.Body).Decode(&response)
+
+			assert.Equal(t, test.wantStatus, res.StatusCode)
+			assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+			assert.Equal(t, test.want, response)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package api_delivery
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"vk_test_task/internal/api/models"
+	"vk_test_task/internal/middleware"
+)
+
+// EnrollMFA godoc
+// @Summary EnrollMFA
+// @Description generates a new TOTP secret for the current user. Two-factor authentication is enabled only after /mfa/confirm
+// @Tags MFA
+// @Produce json
+// @Success 200 {object} api_models.EnrollMFAResponse
+// @Router /mfa/enroll [post]
+// @Security AccessTokenAuth
+func (h Handler) EnrollMFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := middleware.ClaimsFromContext(r.Context())
+		if !ok {
+			h.writeError(w, r, "/mfa/enroll error", api_models.NewUnauthorizedError("no claims in context"))
+			return
+		}
+
+		resp, err := h.uc.EnrollMFA(r.Context(), claims)
+		if err != nil {
+			h.writeError(w, r, "/mfa/enroll error", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		jsonResponse, err := json.Marshal(resp)
+		_, err = w.Write(jsonResponse)
+		if err != nil {
+			h.log(r).ErrorContext(r.Context(), "/mfa/enroll error", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
+
+// ConfirmMFA godoc
+// @Summary ConfirmMFA
+// @Description enables two-factor authentication by a code from the authenticator app, returns single-use recovery codes
+// @Tags MFA
+// @Param input body api_models.MFACodeParams true "TOTP code"
+// @Accept json
+// @Produce json
+// @Success 200 {object} api_models.RecoveryCodesResponse
+// @Router /mfa/confirm [post]
+// @Security AccessTokenAuth
+func (h Handler) ConfirmMFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := middleware.ClaimsFromContext(r.Context())
+		if !ok {
+			h.writeError(w, r, "/mfa/confirm error", api_models.NewUnauthorizedError("no claims in context"))
+			return
+		}
+
+		var params api_models.MFACodeParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "/mfa/confirm error", err)
+			return
+		}
+
+		resp, err := h.uc.ConfirmMFA(r.Context(), claims, params)
+		if err != nil {
+			h.writeError(w, r, "/mfa/confirm error", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		jsonResponse, err := json.Marshal(resp)
+		_, err = w.Write(jsonResponse)
+		if err != nil {
+			h.log(r).ErrorContext(r.Context(), "/mfa/confirm error", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
+
+// DisableMFA godoc
+// @Summary DisableMFA
+// @Description disables two-factor authentication, requires a TOTP or recovery code
+// @Tags MFA
+// @Param input body api_models.MFACodeParams true "TOTP or recovery code"
+// @Accept json
+// @Success 200
+// @Router /mfa/disable [post]
+// @Security AccessTokenAuth
+func (h Handler) DisableMFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := middleware.ClaimsFromContext(r.Context())
+		if !ok {
+			h.writeError(w, r, "/mfa/disable error", api_models.NewUnauthorizedError("no claims in context"))
+			return
+		}
+
+		var params api_models.MFACodeParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "/mfa/disable error", err)
+			return
+		}
+
+		err = h.uc.DisableMFA(r.Context(), claims, params)
+		if err != nil {
+			h.writeError(w, r, "/mfa/disable error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// SignInMFA godoc
+// @Summary SignInMFA
+// @Description completes sign in by mfa_token from /sign_in and a TOTP or recovery code, returns access jwt, refresh jwt and access expiration
+// @Tags Authorization
+// @Param input body api_models.SignInMFAParams true "mfa token and code"
+// @Accept json
+// @Produce json
+// @Success 200 {object} api_models.SignInUseCaseResponse
+// @Router /sign_in/mfa [post]
+func (h Handler) SignInMFA() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params api_models.SignInMFAParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "/sign_in/mfa error", err)
+			return
+		}
+
+		params.IP = clientIP(r)
+		params.UserAgent = r.UserAgent()
+
+		resp, err := h.uc.SignInMFA(r.Context(), params)
+		if err != nil {
+			h.writeError(w, r, "/sign_in/mfa error", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		jsonResponse, err := json.Marshal(resp)
+		_, err = w.Write(jsonResponse)
+		if err != nil {
+			h.log(r).ErrorContext(r.Context(), "/sign_in/mfa error", slog.String("error", err.Error()))
+			return
+		}
+	}
+}
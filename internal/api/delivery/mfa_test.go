@@ -0,0 +1,257 @@
+package api_delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/lmittmann/tint"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestHandler_EnrollMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	claims := &api_models.AuthClaims{UserId: "id", SessionId: "sid"}
+
+	testTable := []struct {
+		name          string
+		claims        *api_models.AuthClaims
+		mockBehaviour func()
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			claims: claims,
+			mockBehaviour: func() {
+				uc.EXPECT().EnrollMFA(gomock.Any(), *claims).Return(api_models.EnrollMFAResponse{Secret: "secret"}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:   "usecase error",
+			claims: claims,
+			mockBehaviour: func() {
+				uc.EXPECT().EnrollMFA(gomock.Any(), *claims).Return(api_models.EnrollMFAResponse{}, fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no claims",
+			claims: nil,
+			mockBehaviour: func() {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour()
+
+			ts := httptest.NewServer(withClaims(test.claims, h.EnrollMFA()))
+			defer ts.Close()
+			res, _ := http.Post(ts.URL, "application/json", nil)
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
+
+func TestHandler_ConfirmMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	claims := &api_models.AuthClaims{UserId: "id", SessionId: "sid"}
+
+	type mockBehaviour func(params api_models.MFACodeParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.MFACodeParams
+		claims        *api_models.AuthClaims
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			args:   api_models.MFACodeParams{Code: "123456"},
+			claims: claims,
+			mockBehaviour: func(params api_models.MFACodeParams) {
+				uc.EXPECT().ConfirmMFA(gomock.Any(), *claims, params).Return(api_models.RecoveryCodesResponse{RecoveryCodes: []string{"code"}}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:   "usecase error",
+			args:   api_models.MFACodeParams{Code: "000000"},
+			claims: claims,
+			mockBehaviour: func(params api_models.MFACodeParams) {
+				uc.EXPECT().ConfirmMFA(gomock.Any(), *claims, params).Return(api_models.RecoveryCodesResponse{}, fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no claims",
+			args:   api_models.MFACodeParams{Code: "123456"},
+			claims: nil,
+			mockBehaviour: func(params api_models.MFACodeParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(withClaims(test.claims, h.ConfirmMFA()))
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
+
+func TestHandler_DisableMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	claims := &api_models.AuthClaims{UserId: "id", SessionId: "sid"}
+
+	type mockBehaviour func(params api_models.MFACodeParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.MFACodeParams
+		claims        *api_models.AuthClaims
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			args:   api_models.MFACodeParams{Code: "123456"},
+			claims: claims,
+			mockBehaviour: func(params api_models.MFACodeParams) {
+				uc.EXPECT().DisableMFA(gomock.Any(), *claims, params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:   "usecase error",
+			args:   api_models.MFACodeParams{Code: "000000"},
+			claims: claims,
+			mockBehaviour: func(params api_models.MFACodeParams) {
+				uc.EXPECT().DisableMFA(gomock.Any(), *claims, params).Return(fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no claims",
+			args:   api_models.MFACodeParams{Code: "123456"},
+			claims: nil,
+			mockBehaviour: func(params api_models.MFACodeParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(withClaims(test.claims, h.DisableMFA()))
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
+
+func TestHandler_SignInMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(params api_models.SignInMFAParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.SignInMFAParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.SignInMFAParams{MFAToken: "token", Code: "123456"},
+			mockBehaviour: func(params api_models.SignInMFAParams) {
+				uc.EXPECT().SignInMFA(gomock.Any(), gomock.Any()).Return(api_models.SignInUseCaseResponse{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "usecase error",
+			args: api_models.SignInMFAParams{MFAToken: "token", Code: "000000"},
+			mockBehaviour: func(params api_models.SignInMFAParams) {
+				uc.EXPECT().SignInMFA(gomock.Any(), gomock.Any()).Return(api_models.SignInUseCaseResponse{}, fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(h.SignInMFA())
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package api_delivery
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"vk_test_task/internal/api/models"
+)
+
+// queryUnmarshaler реализуют типы, которые сами разбирают значение параметра (например api_models.Sort)
+type queryUnmarshaler interface {
+	UnmarshalQuery(value string) error
+}
+
+type queryValidator interface {
+	Validate() []api_models.FieldError
+}
+
+// bindQuery заполняет поля структуры dst с тегом query из параметров запроса.
+// Неизвестные и повторяющиеся параметры считаются ошибкой, после разбора вызывается Validate, если он есть
+func bindQuery(values url.Values, dst interface{}) []api_models.FieldError {
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+
+	var fieldErrors []api_models.FieldError
+	known := make(map[string]bool)
+
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("query")
+		if name == "" {
+			continue
+		}
+		known[name] = true
+
+		raw, ok := values[name]
+		if !ok {
+			continue
+		}
+		if len(raw) > 1 {
+			fieldErrors = append(fieldErrors, api_models.FieldError{Field: name, Message: "must be specified once"})
+			continue
+		}
+
+		if err := setQueryValue(v.Field(i), raw[0]); err != nil {
+			fieldErrors = append(fieldErrors, api_models.FieldError{Field: name, Message: err.Error()})
+		}
+	}
+
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		fieldErrors = append(fieldErrors, api_models.FieldError{Field: name, Message: "unknown parameter"})
+	}
+
+	if len(fieldErrors) > 0 {
+		return fieldErrors
+	}
+
+	if validator, ok := dst.(queryValidator); ok {
+		return validator.Validate()
+	}
+
+	return nil
+}
+
+func setQueryValue(field reflect.Value, raw string) error {
+	if unmarshaler, ok := field.Addr().Interface().(queryUnmarshaler); ok {
+		return unmarshaler.UnmarshalQuery(raw)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		field.SetInt(value)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+		field.SetBool(value)
+	default:
+		return fmt.Errorf("unsupported parameter type")
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package api_delivery
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"vk_test_task/internal/api/models"
+	log "vk_test_task/pkg/logger"
+)
+
+// GetRoles godoc
+// @Summary GetRoles
+// @Description returns all roles with their permissions
+// @Tags Role
+// @Produce json
+// @Success 200 {object} api_models.GetRolesResponse
+// @Router /role/get [get]
+// @Security AccessTokenAuth
+// @Security ApiKeyAuth
+func (h Handler) GetRoles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, err := h.uc.GetRoles(r.Context())
+		if err != nil {
+			h.writeError(w, r, "get roles error", err)
+			return
+		}
+
+		jsonResponse, err := json.Marshal(response)
+		if err != nil {
+			h.writeError(w, r, "get roles error", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(jsonResponse)
+	}
+}
+
+// GrantRole godoc
+// @Summary GrantRole
+// @Description grants role to the user
+// @Tags Role
+// @Param input body api_models.RoleParams true "user id and role"
+// @Accept json
+// @Success 200
+// @Router /role/grant [post]
+// @Security AccessTokenAuth
+// @Security ApiKeyAuth
+func (h Handler) GrantRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params api_models.RoleParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "/role/grant error", err)
+			return
+		}
+
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
+
+		err = h.uc.GrantRole(r.Context(), params)
+		if err != nil {
+			h.writeError(w, r, "/role/grant error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// RevokeRole godoc
+// @Summary RevokeRole
+// @Description revokes role from the user. Access tokens of the user are revoked, new permissions are applied on /refresh
+// @Tags Role
+// @Param input body api_models.RoleParams true "user id and role"
+// @Accept json
+// @Success 200
+// @Router /role/revoke [post]
+// @Security AccessTokenAuth
+// @Security ApiKeyAuth
+func (h Handler) RevokeRole() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params api_models.RoleParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "/role/revoke error", err)
+			return
+		}
+
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
+
+		err = h.uc.RevokeRole(r.Context(), params)
+		if err != nil {
+			h.writeError(w, r, "/role/revoke error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
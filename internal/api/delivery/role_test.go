@@ -0,0 +1,167 @@
+package api_delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/lmittmann/tint"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestHandler_GetRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			mockBehaviour: func() {
+				uc.EXPECT().GetRoles(gomock.Any()).Return(api_models.GetRolesResponse{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "usecase error",
+			mockBehaviour: func() {
+				uc.EXPECT().GetRoles(gomock.Any()).Return(api_models.GetRolesResponse{}, fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour()
+
+			ts := httptest.NewServer(h.GetRoles())
+			defer ts.Close()
+			res, _ := http.Get(ts.URL)
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
+
+func TestHandler_GrantRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(params api_models.RoleParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.RoleParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.RoleParams{UserId: "id", Role: "editor"},
+			mockBehaviour: func(params api_models.RoleParams) {
+				uc.EXPECT().GrantRole(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "usecase error",
+			args: api_models.RoleParams{UserId: "id", Role: "owner"},
+			mockBehaviour: func(params api_models.RoleParams) {
+				uc.EXPECT().GrantRole(gomock.Any(), params).Return(fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(h.GrantRole())
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
+
+func TestHandler_RevokeRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(params api_models.RoleParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.RoleParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.RoleParams{UserId: "id", Role: "editor"},
+			mockBehaviour: func(params api_models.RoleParams) {
+				uc.EXPECT().RevokeRole(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "usecase error",
+			args: api_models.RoleParams{UserId: "id", Role: "editor"},
+			mockBehaviour: func(params api_models.RoleParams) {
+				uc.EXPECT().RevokeRole(gomock.Any(), params).Return(fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(h.RevokeRole())
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
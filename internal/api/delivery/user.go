@@ -0,0 +1,136 @@
+package api_delivery
+
+import (
+	"log/slog"
+	"net/http"
+	"vk_test_task/internal/api/models"
+	"vk_test_task/internal/middleware"
+	log "vk_test_task/pkg/logger"
+)
+
+// UnlockUser godoc
+// @Summary UnlockUser
+// @Description removes sign in lockout and failed attempts counter for the login
+// @Tags User
+// @Param input body api_models.UnlockUserParams true "login"
+// @Accept json
+// @Success 200
+// @Router /user/unlock [post]
+// @Security AccessTokenAuth
+// @Security ApiKeyAuth
+func (h Handler) UnlockUser() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params api_models.UnlockUserParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "/user/unlock error", err)
+			return
+		}
+
+		h.log(r).DebugContext(r.Context(), "request params", slog.Any("params", log.Params(params)))
+
+		err = h.uc.UnlockUser(r.Context(), params)
+		if err != nil {
+			h.writeError(w, r, "/user/unlock error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// ChangePassword godoc
+// @Summary ChangePassword
+// @Description changes password of the current user, other sessions are revoked
+// @Tags User
+// @Param input body api_models.ChangePasswordParams true "old and new passwords"
+// @Accept json
+// @Success 200
+// @Router /password/change [post]
+// @Security AccessTokenAuth
+func (h Handler) ChangePassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := middleware.ClaimsFromContext(r.Context())
+		if !ok {
+			h.writeError(w, r, "/password/change error", api_models.NewUnauthorizedError("no claims in context"))
+			return
+		}
+
+		var params api_models.ChangePasswordParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "/password/change error", err)
+			return
+		}
+
+		err = h.uc.ChangePassword(r.Context(), claims, params)
+		if err != nil {
+			h.writeError(w, r, "/password/change error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// ForgotPassword godoc
+// @Summary ForgotPassword
+// @Description sends a single-use password reset token to the user email in the background. Responds 200 whether the login exists or not. Each request counts as a sign in attempt for the login and IP
+// @Tags User
+// @Param input body api_models.ForgotPasswordParams true "login"
+// @Accept json
+// @Success 200
+// @Failure 429 {object} api_models.ErrorResponse
+// @Header 429 {integer} Retry-After "seconds until the next attempt is allowed"
+// @Router /password/forgot [post]
+func (h Handler) ForgotPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params api_models.ForgotPasswordParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "/password/forgot error", err)
+			return
+		}
+
+		params.IP = clientIP(r)
+
+		err = h.uc.ForgotPassword(r.Context(), params)
+		if err != nil {
+			h.writeError(w, r, "/password/forgot error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// ResetPassword godoc
+// @Summary ResetPassword
+// @Description sets a new password by the reset token and revokes all user sessions
+// @Tags User
+// @Param input body api_models.ResetPasswordParams true "reset token and new password"
+// @Accept json
+// @Success 200
+// @Router /password/reset [post]
+func (h Handler) ResetPassword() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params api_models.ResetPasswordParams
+
+		err := decodeBody(r, &params)
+		if err != nil {
+			h.writeError(w, r, "/password/reset error", err)
+			return
+		}
+
+		err = h.uc.ResetPassword(r.Context(), params)
+		if err != nil {
+			h.writeError(w, r, "/password/reset error", err)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
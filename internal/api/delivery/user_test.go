@@ -0,0 +1,255 @@
+package api_delivery
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/lmittmann/tint"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestHandler_UnlockUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(params api_models.UnlockUserParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.UnlockUserParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.UnlockUserParams{Login: "login"},
+			mockBehaviour: func(params api_models.UnlockUserParams) {
+				uc.EXPECT().UnlockUser(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "usecase error",
+			args: api_models.UnlockUserParams{},
+			mockBehaviour: func(params api_models.UnlockUserParams) {
+				uc.EXPECT().UnlockUser(gomock.Any(), params).Return(fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(h.UnlockUser())
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
+
+func TestHandler_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	claims := &api_models.AuthClaims{UserId: "id", SessionId: "sid"}
+
+	type mockBehaviour func(params api_models.ChangePasswordParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.ChangePasswordParams
+		claims        *api_models.AuthClaims
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			args:   api_models.ChangePasswordParams{OldPassword: "old", NewPassword: "new password"},
+			claims: claims,
+			mockBehaviour: func(params api_models.ChangePasswordParams) {
+				uc.EXPECT().ChangePassword(gomock.Any(), *claims, params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:   "usecase error",
+			args:   api_models.ChangePasswordParams{OldPassword: "wrong", NewPassword: "new password"},
+			claims: claims,
+			mockBehaviour: func(params api_models.ChangePasswordParams) {
+				uc.EXPECT().ChangePassword(gomock.Any(), *claims, params).Return(fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no claims",
+			args:   api_models.ChangePasswordParams{OldPassword: "old", NewPassword: "new password"},
+			claims: nil,
+			mockBehaviour: func(params api_models.ChangePasswordParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(withClaims(test.claims, h.ChangePassword()))
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
+
+func TestHandler_ForgotPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(params api_models.ForgotPasswordParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.ForgotPasswordParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+		retryAfter    string
+	}{
+		{
+			name: "default",
+			args: api_models.ForgotPasswordParams{Login: "login"},
+			mockBehaviour: func(params api_models.ForgotPasswordParams) {
+				params.IP = "127.0.0.1"
+				uc.EXPECT().ForgotPassword(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "usecase error",
+			args: api_models.ForgotPasswordParams{Login: "login"},
+			mockBehaviour: func(params api_models.ForgotPasswordParams) {
+				uc.EXPECT().ForgotPassword(gomock.Any(), gomock.Any()).Return(fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many attempts",
+			args: api_models.ForgotPasswordParams{Login: "login"},
+			mockBehaviour: func(params api_models.ForgotPasswordParams) {
+				uc.EXPECT().ForgotPassword(gomock.Any(), gomock.Any()).Return(api_models.TooManyRequestsError{RetryAfter: time.Minute})
+			},
+			wantErr:    true,
+			retryAfter: "60",
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(h.ForgotPassword())
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.retryAfter != "" {
+				assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+				assert.Equal(t, test.retryAfter, res.Header.Get("Retry-After"))
+			}
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
+
+func TestHandler_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+	h := New(nil, l, uc)
+
+	type mockBehaviour func(params api_models.ResetPasswordParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.ResetPasswordParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.ResetPasswordParams{Token: "token", NewPassword: "new password"},
+			mockBehaviour: func(params api_models.ResetPasswordParams) {
+				uc.EXPECT().ResetPassword(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid token",
+			args: api_models.ResetPasswordParams{Token: "token", NewPassword: "new password"},
+			mockBehaviour: func(params api_models.ResetPasswordParams) {
+				uc.EXPECT().ResetPassword(gomock.Any(), params).Return(fmt.Errorf(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			ts := httptest.NewServer(h.ResetPassword())
+			defer ts.Close()
+			r, _ := json.Marshal(test.args)
+			res, _ := http.Post(ts.URL, "application/json", bytes.NewReader(r))
+
+			if test.wantErr {
+				assert.NotEqual(t, "200 OK", res.Status)
+			} else {
+				assert.Equal(t, "200 OK", res.Status)
+			}
+		})
+	}
+}
@@ -6,17 +6,43 @@ import (
 	"net/http"
 )
 
-// ifacemaker -f actor.go -f auth.go -f film.go -f http.go -s Handler -i HandlerInterface -p api -o ../handler.go
+// ifacemaker -f actor.go -f apiKey.go -f auth.go -f film.go -f health.go -f mfa.go -f role.go -f user.go -f http.go -s Handler -i HandlerInterface -p api -o ../handler.go
 type HandlerInterface interface {
 	CreateActor() http.HandlerFunc
 	GetActors() http.HandlerFunc
+	GetActor() http.HandlerFunc
 	UpdateActor() http.HandlerFunc
 	DeleteActor() http.HandlerFunc
+	CreateAPIKey() http.HandlerFunc
+	GetAPIKeys() http.HandlerFunc
+	RevokeAPIKey() http.HandlerFunc
 	SignIn() http.HandlerFunc
 	SignUp() http.HandlerFunc
+	Refresh() http.HandlerFunc
+	Logout() http.HandlerFunc
+	LogoutAll() http.HandlerFunc
+	GetSessions() http.HandlerFunc
+	RevokeSession() http.HandlerFunc
+	JWKS() http.HandlerFunc
 	CreateFilm() http.HandlerFunc
 	GetFilms() http.HandlerFunc
+	GetFilmsLegacy() http.HandlerFunc
+	GetFilm() http.HandlerFunc
 	UpdateFilm() http.HandlerFunc
 	DeleteFilm() http.HandlerFunc
 	SearchFilm() http.HandlerFunc
+	Healthz() http.HandlerFunc
+	Readyz() http.HandlerFunc
+	Version() http.HandlerFunc
+	EnrollMFA() http.HandlerFunc
+	ConfirmMFA() http.HandlerFunc
+	DisableMFA() http.HandlerFunc
+	SignInMFA() http.HandlerFunc
+	GetRoles() http.HandlerFunc
+	GrantRole() http.HandlerFunc
+	RevokeRole() http.HandlerFunc
+	UnlockUser() http.HandlerFunc
+	ChangePassword() http.HandlerFunc
+	ForgotPassword() http.HandlerFunc
+	ResetPassword() http.HandlerFunc
 }
@@ -5,6 +5,7 @@
 package mock_api
 
 import (
+	context "context"
 	reflect "reflect"
 	api_models "vk_test_task/internal/api/models"
 
@@ -34,175 +35,544 @@ func (m *MockRepositoryInterface) EXPECT() *MockRepositoryInterfaceMockRecorder
 	return m.recorder
 }
 
+// CreateAPIKey mocks base method.
+func (m *MockRepositoryInterface) CreateAPIKey(ctx context.Context, key api_models.APIKey, keyHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, key, keyHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockRepositoryInterfaceMockRecorder) CreateAPIKey(ctx, key, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateAPIKey), ctx, key, keyHash)
+}
+
 // CreateActor mocks base method.
-func (m *MockRepositoryInterface) CreateActor(params api_models.CreateActorParams) error {
+func (m *MockRepositoryInterface) CreateActor(ctx context.Context, params api_models.CreateActorParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateActor", params)
+	ret := m.ctrl.Call(m, "CreateActor", ctx, params)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateActor indicates an expected call of CreateActor.
-func (mr *MockRepositoryInterfaceMockRecorder) CreateActor(params interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) CreateActor(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateActor", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateActor), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateActor", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateActor), ctx, params)
 }
 
 // CreateFilm mocks base method.
-func (m *MockRepositoryInterface) CreateFilm(params api_models.CreateFilmParams) error {
+func (m *MockRepositoryInterface) CreateFilm(ctx context.Context, params api_models.CreateFilmParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateFilm", params)
+	ret := m.ctrl.Call(m, "CreateFilm", ctx, params)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateFilm indicates an expected call of CreateFilm.
-func (mr *MockRepositoryInterfaceMockRecorder) CreateFilm(params interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) CreateFilm(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFilm", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateFilm), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFilm", reflect.TypeOf((*MockRepositoryInterface)(nil).CreateFilm), ctx, params)
 }
 
 // DeleteActor mocks base method.
-func (m *MockRepositoryInterface) DeleteActor(actorId string) error {
+func (m *MockRepositoryInterface) DeleteActor(ctx context.Context, actorId string, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteActor", actorId)
+	ret := m.ctrl.Call(m, "DeleteActor", ctx, actorId, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteActor indicates an expected call of DeleteActor.
-func (mr *MockRepositoryInterfaceMockRecorder) DeleteActor(actorId interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) DeleteActor(ctx, actorId, version interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActor", reflect.TypeOf((*MockRepositoryInterface)(nil).DeleteActor), actorId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActor", reflect.TypeOf((*MockRepositoryInterface)(nil).DeleteActor), ctx, actorId, version)
 }
 
 // DeleteFilm mocks base method.
-func (m *MockRepositoryInterface) DeleteFilm(filmId string) error {
+func (m *MockRepositoryInterface) DeleteFilm(ctx context.Context, filmId string, version int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteFilm", filmId)
+	ret := m.ctrl.Call(m, "DeleteFilm", ctx, filmId, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteFilm indicates an expected call of DeleteFilm.
-func (mr *MockRepositoryInterfaceMockRecorder) DeleteFilm(filmId interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) DeleteFilm(ctx, filmId, version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilm", reflect.TypeOf((*MockRepositoryInterface)(nil).DeleteFilm), ctx, filmId, version)
+}
+
+// DisableMFA mocks base method.
+func (m *MockRepositoryInterface) DisableMFA(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableMFA", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableMFA indicates an expected call of DisableMFA.
+func (mr *MockRepositoryInterfaceMockRecorder) DisableMFA(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableMFA", reflect.TypeOf((*MockRepositoryInterface)(nil).DisableMFA), ctx, userId)
+}
+
+// EnableMFA mocks base method.
+func (m *MockRepositoryInterface) EnableMFA(ctx context.Context, userId string, recoveryCodeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableMFA", ctx, userId, recoveryCodeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableMFA indicates an expected call of EnableMFA.
+func (mr *MockRepositoryInterfaceMockRecorder) EnableMFA(ctx, userId, recoveryCodeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableMFA", reflect.TypeOf((*MockRepositoryInterface)(nil).EnableMFA), ctx, userId, recoveryCodeHashes)
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockRepositoryInterface) GetAPIKeyByHash(ctx context.Context, keyHash string) (api_models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", ctx, keyHash)
+	ret0, _ := ret[0].(api_models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockRepositoryInterfaceMockRecorder) GetAPIKeyByHash(ctx, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockRepositoryInterface)(nil).GetAPIKeyByHash), ctx, keyHash)
+}
+
+// GetAPIKeys mocks base method.
+func (m *MockRepositoryInterface) GetAPIKeys(ctx context.Context) ([]api_models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", ctx)
+	ret0, _ := ret[0].([]api_models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockRepositoryInterfaceMockRecorder) GetAPIKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockRepositoryInterface)(nil).GetAPIKeys), ctx)
+}
+
+// GetActorDetails mocks base method.
+func (m *MockRepositoryInterface) GetActorDetails(ctx context.Context, actorId string) (api_models.ActorDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActorDetails", ctx, actorId)
+	ret0, _ := ret[0].(api_models.ActorDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActorDetails indicates an expected call of GetActorDetails.
+func (mr *MockRepositoryInterfaceMockRecorder) GetActorDetails(ctx, actorId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActorDetails", reflect.TypeOf((*MockRepositoryInterface)(nil).GetActorDetails), ctx, actorId)
+}
+
+// GetActorVersion mocks base method.
+func (m *MockRepositoryInterface) GetActorVersion(ctx context.Context, actorId string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActorVersion", ctx, actorId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActorVersion indicates an expected call of GetActorVersion.
+func (mr *MockRepositoryInterfaceMockRecorder) GetActorVersion(ctx, actorId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilm", reflect.TypeOf((*MockRepositoryInterface)(nil).DeleteFilm), filmId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActorVersion", reflect.TypeOf((*MockRepositoryInterface)(nil).GetActorVersion), ctx, actorId)
 }
 
 // GetActors mocks base method.
-func (m *MockRepositoryInterface) GetActors() (api_models.GetActorsResponse, error) {
+func (m *MockRepositoryInterface) GetActors(ctx context.Context, page api_models.Page) (api_models.GetActorsResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetActors")
+	ret := m.ctrl.Call(m, "GetActors", ctx, page)
 	ret0, _ := ret[0].(api_models.GetActorsResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetActors indicates an expected call of GetActors.
-func (mr *MockRepositoryInterfaceMockRecorder) GetActors() *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) GetActors(ctx, page interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActors", reflect.TypeOf((*MockRepositoryInterface)(nil).GetActors))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActors", reflect.TypeOf((*MockRepositoryInterface)(nil).GetActors), ctx, page)
+}
+
+// GetFilmDetails mocks base method.
+func (m *MockRepositoryInterface) GetFilmDetails(ctx context.Context, filmId string) (api_models.FilmDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilmDetails", ctx, filmId)
+	ret0, _ := ret[0].(api_models.FilmDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilmDetails indicates an expected call of GetFilmDetails.
+func (mr *MockRepositoryInterfaceMockRecorder) GetFilmDetails(ctx, filmId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmDetails", reflect.TypeOf((*MockRepositoryInterface)(nil).GetFilmDetails), ctx, filmId)
+}
+
+// GetFilmVersion mocks base method.
+func (m *MockRepositoryInterface) GetFilmVersion(ctx context.Context, filmId string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilmVersion", ctx, filmId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilmVersion indicates an expected call of GetFilmVersion.
+func (mr *MockRepositoryInterfaceMockRecorder) GetFilmVersion(ctx, filmId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmVersion", reflect.TypeOf((*MockRepositoryInterface)(nil).GetFilmVersion), ctx, filmId)
 }
 
 // GetFilms mocks base method.
-func (m *MockRepositoryInterface) GetFilms(params api_models.GetFilmsParams) (api_models.GetFilmsResponse, error) {
+func (m *MockRepositoryInterface) GetFilms(ctx context.Context, page api_models.Page) (api_models.GetFilmsResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilms", params)
+	ret := m.ctrl.Call(m, "GetFilms", ctx, page)
 	ret0, _ := ret[0].(api_models.GetFilmsResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFilms indicates an expected call of GetFilms.
-func (mr *MockRepositoryInterfaceMockRecorder) GetFilms(params interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) GetFilms(ctx, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilms", reflect.TypeOf((*MockRepositoryInterface)(nil).GetFilms), ctx, page)
+}
+
+// GetMFA mocks base method.
+func (m *MockRepositoryInterface) GetMFA(ctx context.Context, userId string) (api_models.MFA, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMFA", ctx, userId)
+	ret0, _ := ret[0].(api_models.MFA)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMFA indicates an expected call of GetMFA.
+func (mr *MockRepositoryInterfaceMockRecorder) GetMFA(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMFA", reflect.TypeOf((*MockRepositoryInterface)(nil).GetMFA), ctx, userId)
+}
+
+// GetPasswordHash mocks base method.
+func (m *MockRepositoryInterface) GetPasswordHash(ctx context.Context, userId string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordHash", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordHash indicates an expected call of GetPasswordHash.
+func (mr *MockRepositoryInterfaceMockRecorder) GetPasswordHash(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordHash", reflect.TypeOf((*MockRepositoryInterface)(nil).GetPasswordHash), ctx, userId)
+}
+
+// GetRoles mocks base method.
+func (m *MockRepositoryInterface) GetRoles(ctx context.Context) (api_models.GetRolesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoles", ctx)
+	ret0, _ := ret[0].(api_models.GetRolesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoles indicates an expected call of GetRoles.
+func (mr *MockRepositoryInterfaceMockRecorder) GetRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoles", reflect.TypeOf((*MockRepositoryInterface)(nil).GetRoles), ctx)
+}
+
+// GetUserAccess mocks base method.
+func (m *MockRepositoryInterface) GetUserAccess(ctx context.Context, userId string) (api_models.UserAccess, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserAccess", ctx, userId)
+	ret0, _ := ret[0].(api_models.UserAccess)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAccess indicates an expected call of GetUserAccess.
+func (mr *MockRepositoryInterfaceMockRecorder) GetUserAccess(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAccess", reflect.TypeOf((*MockRepositoryInterface)(nil).GetUserAccess), ctx, userId)
+}
+
+// GetUserById mocks base method.
+func (m *MockRepositoryInterface) GetUserById(ctx context.Context, userId string) (api_models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserById", ctx, userId)
+	ret0, _ := ret[0].(api_models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserById indicates an expected call of GetUserById.
+func (mr *MockRepositoryInterfaceMockRecorder) GetUserById(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserById", reflect.TypeOf((*MockRepositoryInterface)(nil).GetUserById), ctx, userId)
+}
+
+// GetUserByLogin mocks base method.
+func (m *MockRepositoryInterface) GetUserByLogin(ctx context.Context, login string) (api_models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByLogin", ctx, login)
+	ret0, _ := ret[0].(api_models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByLogin indicates an expected call of GetUserByLogin.
+func (mr *MockRepositoryInterfaceMockRecorder) GetUserByLogin(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockRepositoryInterface)(nil).GetUserByLogin), ctx, login)
+}
+
+// GrantRole mocks base method.
+func (m *MockRepositoryInterface) GrantRole(ctx context.Context, userId, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", ctx, userId, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockRepositoryInterfaceMockRecorder) GrantRole(ctx, userId, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockRepositoryInterface)(nil).GrantRole), ctx, userId, role)
+}
+
+// ListUsers mocks base method.
+func (m *MockRepositoryInterface) ListUsers(ctx context.Context) ([]api_models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx)
+	ret0, _ := ret[0].([]api_models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockRepositoryInterfaceMockRecorder) ListUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockRepositoryInterface)(nil).ListUsers), ctx)
+}
+
+// Ping mocks base method.
+func (m *MockRepositoryInterface) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockRepositoryInterfaceMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockRepositoryInterface)(nil).Ping), ctx)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockRepositoryInterface) RevokeAPIKey(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockRepositoryInterfaceMockRecorder) RevokeAPIKey(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilms", reflect.TypeOf((*MockRepositoryInterface)(nil).GetFilms), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockRepositoryInterface)(nil).RevokeAPIKey), ctx, id)
+}
+
+// RevokeRole mocks base method.
+func (m *MockRepositoryInterface) RevokeRole(ctx context.Context, userId, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", ctx, userId, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockRepositoryInterfaceMockRecorder) RevokeRole(ctx, userId, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockRepositoryInterface)(nil).RevokeRole), ctx, userId, role)
+}
+
+// SaveMFASecret mocks base method.
+func (m *MockRepositoryInterface) SaveMFASecret(ctx context.Context, userId, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMFASecret", ctx, userId, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMFASecret indicates an expected call of SaveMFASecret.
+func (mr *MockRepositoryInterfaceMockRecorder) SaveMFASecret(ctx, userId, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMFASecret", reflect.TypeOf((*MockRepositoryInterface)(nil).SaveMFASecret), ctx, userId, secret)
 }
 
 // SearchFilmByActorName mocks base method.
-func (m *MockRepositoryInterface) SearchFilmByActorName(actorName string) (api_models.SearchFilmResponse, error) {
+func (m *MockRepositoryInterface) SearchFilmByActorName(ctx context.Context, actorName string, page api_models.Page) (api_models.SearchFilmResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchFilmByActorName", actorName)
+	ret := m.ctrl.Call(m, "SearchFilmByActorName", ctx, actorName, page)
 	ret0, _ := ret[0].(api_models.SearchFilmResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SearchFilmByActorName indicates an expected call of SearchFilmByActorName.
-func (mr *MockRepositoryInterfaceMockRecorder) SearchFilmByActorName(actorName interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) SearchFilmByActorName(ctx, actorName, page interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFilmByActorName", reflect.TypeOf((*MockRepositoryInterface)(nil).SearchFilmByActorName), actorName)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFilmByActorName", reflect.TypeOf((*MockRepositoryInterface)(nil).SearchFilmByActorName), ctx, actorName, page)
 }
 
 // SearchFilmByName mocks base method.
-func (m *MockRepositoryInterface) SearchFilmByName(name string) (api_models.SearchFilmResponse, error) {
+func (m *MockRepositoryInterface) SearchFilmByName(ctx context.Context, name string, page api_models.Page) (api_models.SearchFilmResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchFilmByName", name)
+	ret := m.ctrl.Call(m, "SearchFilmByName", ctx, name, page)
 	ret0, _ := ret[0].(api_models.SearchFilmResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SearchFilmByName indicates an expected call of SearchFilmByName.
-func (mr *MockRepositoryInterfaceMockRecorder) SearchFilmByName(name interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) SearchFilmByName(ctx, name, page interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFilmByName", reflect.TypeOf((*MockRepositoryInterface)(nil).SearchFilmByName), ctx, name, page)
+}
+
+// SetUserDisabled mocks base method.
+func (m *MockRepositoryInterface) SetUserDisabled(ctx context.Context, userId string, disabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserDisabled", ctx, userId, disabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserDisabled indicates an expected call of SetUserDisabled.
+func (mr *MockRepositoryInterfaceMockRecorder) SetUserDisabled(ctx, userId, disabled interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFilmByName", reflect.TypeOf((*MockRepositoryInterface)(nil).SearchFilmByName), name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserDisabled", reflect.TypeOf((*MockRepositoryInterface)(nil).SetUserDisabled), ctx, userId, disabled)
 }
 
 // SignIn mocks base method.
-func (m *MockRepositoryInterface) SignIn(login string) (api_models.SignInRepositoryResponse, error) {
+func (m *MockRepositoryInterface) SignIn(ctx context.Context, login string) (api_models.SignInRepositoryResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignIn", login)
+	ret := m.ctrl.Call(m, "SignIn", ctx, login)
 	ret0, _ := ret[0].(api_models.SignInRepositoryResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignIn indicates an expected call of SignIn.
-func (mr *MockRepositoryInterfaceMockRecorder) SignIn(login interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) SignIn(ctx, login interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockRepositoryInterface)(nil).SignIn), login)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockRepositoryInterface)(nil).SignIn), ctx, login)
 }
 
 // SignUp mocks base method.
-func (m *MockRepositoryInterface) SignUp(login, hashPassword, userId string) error {
+func (m *MockRepositoryInterface) SignUp(ctx context.Context, login, hashPassword, userId, email string, roles ...string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUp", login, hashPassword, userId)
+	varargs := []interface{}{ctx, login, hashPassword, userId, email}
+	for _, a := range roles {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SignUp", varargs...)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SignUp indicates an expected call of SignUp.
-func (mr *MockRepositoryInterfaceMockRecorder) SignUp(login, hashPassword, userId interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) SignUp(ctx, login, hashPassword, userId, email interface{}, roles ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockRepositoryInterface)(nil).SignUp), login, hashPassword, userId)
+	varargs := append([]interface{}{ctx, login, hashPassword, userId, email}, roles...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockRepositoryInterface)(nil).SignUp), varargs...)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockRepositoryInterface) TouchAPIKey(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockRepositoryInterfaceMockRecorder) TouchAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockRepositoryInterface)(nil).TouchAPIKey), ctx, id)
 }
 
 // UpdateActor mocks base method.
-func (m *MockRepositoryInterface) UpdateActor(params api_models.UpdateActorParams) error {
+func (m *MockRepositoryInterface) UpdateActor(ctx context.Context, params api_models.UpdateActorParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateActor", params)
+	ret := m.ctrl.Call(m, "UpdateActor", ctx, params)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateActor indicates an expected call of UpdateActor.
-func (mr *MockRepositoryInterfaceMockRecorder) UpdateActor(params interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateActor(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateActor", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateActor), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateActor", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateActor), ctx, params)
 }
 
 // UpdateFilm mocks base method.
-func (m *MockRepositoryInterface) UpdateFilm(params api_models.UpdateFilmParams) error {
+func (m *MockRepositoryInterface) UpdateFilm(ctx context.Context, params api_models.UpdateFilmParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateFilm", params)
+	ret := m.ctrl.Call(m, "UpdateFilm", ctx, params)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateFilm indicates an expected call of UpdateFilm.
-func (mr *MockRepositoryInterfaceMockRecorder) UpdateFilm(params interface{}) *gomock.Call {
+func (mr *MockRepositoryInterfaceMockRecorder) UpdateFilm(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFilm", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateFilm), ctx, params)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockRepositoryInterface) UpdatePassword(ctx context.Context, userId, hashPassword string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, userId, hashPassword)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockRepositoryInterfaceMockRecorder) UpdatePassword(ctx, userId, hashPassword interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdatePassword), ctx, userId, hashPassword)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockRepositoryInterface) UseRecoveryCode(ctx context.Context, userId, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userId, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockRepositoryInterfaceMockRecorder) UseRecoveryCode(ctx, userId, codeHash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFilm", reflect.TypeOf((*MockRepositoryInterface)(nil).UpdateFilm), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockRepositoryInterface)(nil).UseRecoveryCode), ctx, userId, codeHash)
 }
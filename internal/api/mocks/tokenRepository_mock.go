@@ -5,7 +5,10 @@
 package mock_api
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
+	api_models "vk_test_task/internal/api/models"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -33,10 +36,25 @@ func (m *MockTokenRepositoryInterface) EXPECT() *MockTokenRepositoryInterfaceMoc
 	return m.recorder
 }
 
+// ConsumePasswordResetToken mocks base method.
+func (m *MockTokenRepositoryInterface) ConsumePasswordResetToken(ctx context.Context, token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumePasswordResetToken", ctx, token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumePasswordResetToken indicates an expected call of ConsumePasswordResetToken.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) ConsumePasswordResetToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumePasswordResetToken", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).ConsumePasswordResetToken), ctx, token)
+}
+
 // CreateAccessToken mocks base method.
-func (m *MockTokenRepositoryInterface) CreateAccessToken(userId string, isAdmin bool) (string, int64, error) {
+func (m *MockTokenRepositoryInterface) CreateAccessToken(ctx context.Context, access api_models.UserAccess, sessionId string) (string, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateAccessToken", userId, isAdmin)
+	ret := m.ctrl.Call(m, "CreateAccessToken", ctx, access, sessionId)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
@@ -44,15 +62,45 @@ func (m *MockTokenRepositoryInterface) CreateAccessToken(userId string, isAdmin
 }
 
 // CreateAccessToken indicates an expected call of CreateAccessToken.
-func (mr *MockTokenRepositoryInterfaceMockRecorder) CreateAccessToken(userId, isAdmin interface{}) *gomock.Call {
+func (mr *MockTokenRepositoryInterfaceMockRecorder) CreateAccessToken(ctx, access, sessionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessToken", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).CreateAccessToken), ctx, access, sessionId)
+}
+
+// CreateMFAChallenge mocks base method.
+func (m *MockTokenRepositoryInterface) CreateMFAChallenge(ctx context.Context, challenge api_models.MFAChallenge) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMFAChallenge", ctx, challenge)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMFAChallenge indicates an expected call of CreateMFAChallenge.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) CreateMFAChallenge(ctx, challenge interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccessToken", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).CreateAccessToken), userId, isAdmin)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMFAChallenge", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).CreateMFAChallenge), ctx, challenge)
+}
+
+// CreatePasswordResetToken mocks base method.
+func (m *MockTokenRepositoryInterface) CreatePasswordResetToken(ctx context.Context, userId string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", ctx, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) CreatePasswordResetToken(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).CreatePasswordResetToken), ctx, userId)
 }
 
 // CreateRefreshToken mocks base method.
-func (m *MockTokenRepositoryInterface) CreateRefreshToken(isAdmin bool) (string, int64, error) {
+func (m *MockTokenRepositoryInterface) CreateRefreshToken(ctx context.Context, userId, sessionId string) (string, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateRefreshToken", isAdmin)
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, userId, sessionId)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(int64)
 	ret2, _ := ret[2].(error)
@@ -60,15 +108,15 @@ func (m *MockTokenRepositoryInterface) CreateRefreshToken(isAdmin bool) (string,
 }
 
 // CreateRefreshToken indicates an expected call of CreateRefreshToken.
-func (mr *MockTokenRepositoryInterfaceMockRecorder) CreateRefreshToken(isAdmin interface{}) *gomock.Call {
+func (mr *MockTokenRepositoryInterfaceMockRecorder) CreateRefreshToken(ctx, userId, sessionId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).CreateRefreshToken), isAdmin)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).CreateRefreshToken), ctx, userId, sessionId)
 }
 
 // CreateTokensPair mocks base method.
-func (m *MockTokenRepositoryInterface) CreateTokensPair(userId string, isAdmin bool) (string, string, int64, error) {
+func (m *MockTokenRepositoryInterface) CreateTokensPair(ctx context.Context, access api_models.UserAccess, client api_models.ClientInfo) (string, string, int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTokensPair", userId, isAdmin)
+	ret := m.ctrl.Call(m, "CreateTokensPair", ctx, access, client)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(string)
 	ret2, _ := ret[2].(int64)
@@ -77,38 +125,315 @@ func (m *MockTokenRepositoryInterface) CreateTokensPair(userId string, isAdmin b
 }
 
 // CreateTokensPair indicates an expected call of CreateTokensPair.
-func (mr *MockTokenRepositoryInterfaceMockRecorder) CreateTokensPair(userId, isAdmin interface{}) *gomock.Call {
+func (mr *MockTokenRepositoryInterfaceMockRecorder) CreateTokensPair(ctx, access, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTokensPair", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).CreateTokensPair), ctx, access, client)
+}
+
+// DeleteMFAChallenge mocks base method.
+func (m *MockTokenRepositoryInterface) DeleteMFAChallenge(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMFAChallenge", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMFAChallenge indicates an expected call of DeleteMFAChallenge.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) DeleteMFAChallenge(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMFAChallenge", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).DeleteMFAChallenge), ctx, token)
+}
+
+// GetMFAChallenge mocks base method.
+func (m *MockTokenRepositoryInterface) GetMFAChallenge(ctx context.Context, token string) (api_models.MFAChallenge, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMFAChallenge", ctx, token)
+	ret0, _ := ret[0].(api_models.MFAChallenge)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMFAChallenge indicates an expected call of GetMFAChallenge.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) GetMFAChallenge(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMFAChallenge", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).GetMFAChallenge), ctx, token)
+}
+
+// GetPublicKeys mocks base method.
+func (m *MockTokenRepositoryInterface) GetPublicKeys(ctx context.Context) ([]api_models.JWK, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPublicKeys", ctx)
+	ret0, _ := ret[0].([]api_models.JWK)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPublicKeys indicates an expected call of GetPublicKeys.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) GetPublicKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPublicKeys", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).GetPublicKeys), ctx)
+}
+
+// GetSessions mocks base method.
+func (m *MockTokenRepositoryInterface) GetSessions(ctx context.Context, userId string) ([]api_models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", ctx, userId)
+	ret0, _ := ret[0].([]api_models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) GetSessions(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTokensPair", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).CreateTokensPair), userId, isAdmin)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).GetSessions), ctx, userId)
 }
 
-// UpdateAccessToken mocks base method.
-func (m *MockTokenRepositoryInterface) UpdateAccessToken(userId, refreshToken string) (string, int64, error) {
+// IsAccessTokenRevoked mocks base method.
+func (m *MockTokenRepositoryInterface) IsAccessTokenRevoked(ctx context.Context, claims api_models.AuthClaims) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateAccessToken", userId, refreshToken)
+	ret := m.ctrl.Call(m, "IsAccessTokenRevoked", ctx, claims)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAccessTokenRevoked indicates an expected call of IsAccessTokenRevoked.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) IsAccessTokenRevoked(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAccessTokenRevoked", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).IsAccessTokenRevoked), ctx, claims)
+}
+
+// MarkTOTPCodeUsed mocks base method.
+func (m *MockTokenRepositoryInterface) MarkTOTPCodeUsed(ctx context.Context, userId, code string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkTOTPCodeUsed", ctx, userId, code)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkTOTPCodeUsed indicates an expected call of MarkTOTPCodeUsed.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) MarkTOTPCodeUsed(ctx, userId, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkTOTPCodeUsed", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).MarkTOTPCodeUsed), ctx, userId, code)
+}
+
+// Ping mocks base method.
+func (m *MockTokenRepositoryInterface) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).Ping), ctx)
+}
+
+// RefreshTokensPair mocks base method.
+func (m *MockTokenRepositoryInterface) RefreshTokensPair(ctx context.Context, refreshToken string, claims api_models.RefreshClaims, access api_models.UserAccess, client api_models.ClientInfo) (string, string, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTokensPair", ctx, refreshToken, claims, access, client)
 	ret0, _ := ret[0].(string)
-	ret1, _ := ret[1].(int64)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(int64)
+	ret3, _ := ret[3].(error)
+	return ret0, ret1, ret2, ret3
+}
+
+// RefreshTokensPair indicates an expected call of RefreshTokensPair.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) RefreshTokensPair(ctx, refreshToken, claims, access, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTokensPair", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).RefreshTokensPair), ctx, refreshToken, claims, access, client)
+}
+
+// RegisterMFAAttempt mocks base method.
+func (m *MockTokenRepositoryInterface) RegisterMFAAttempt(ctx context.Context, token string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterMFAAttempt", ctx, token)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterMFAAttempt indicates an expected call of RegisterMFAAttempt.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) RegisterMFAAttempt(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterMFAAttempt", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).RegisterMFAAttempt), ctx, token)
 }
 
-// UpdateAccessToken indicates an expected call of UpdateAccessToken.
-func (mr *MockTokenRepositoryInterfaceMockRecorder) UpdateAccessToken(userId, refreshToken interface{}) *gomock.Call {
+// RegisterMFADisableAttempt mocks base method.
+func (m *MockTokenRepositoryInterface) RegisterMFADisableAttempt(ctx context.Context, userId string, maxAttempts int64) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterMFADisableAttempt", ctx, userId, maxAttempts)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterMFADisableAttempt indicates an expected call of RegisterMFADisableAttempt.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) RegisterMFADisableAttempt(ctx, userId, maxAttempts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAccessToken", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).UpdateAccessToken), userId, refreshToken)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterMFADisableAttempt", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).RegisterMFADisableAttempt), ctx, userId, maxAttempts)
+}
+
+// RegisterPasswordResetRequest mocks base method.
+func (m *MockTokenRepositoryInterface) RegisterPasswordResetRequest(ctx context.Context, login, ip string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterPasswordResetRequest", ctx, login, ip)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterPasswordResetRequest indicates an expected call of RegisterPasswordResetRequest.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) RegisterPasswordResetRequest(ctx, login, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterPasswordResetRequest", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).RegisterPasswordResetRequest), ctx, login, ip)
+}
+
+// RegisterSignInFailure mocks base method.
+func (m *MockTokenRepositoryInterface) RegisterSignInFailure(ctx context.Context, login, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterSignInFailure", ctx, login, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterSignInFailure indicates an expected call of RegisterSignInFailure.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) RegisterSignInFailure(ctx, login, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterSignInFailure", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).RegisterSignInFailure), ctx, login, ip)
+}
+
+// ResetSignInFailures mocks base method.
+func (m *MockTokenRepositoryInterface) ResetSignInFailures(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetSignInFailures", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetSignInFailures indicates an expected call of ResetSignInFailures.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) ResetSignInFailures(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetSignInFailures", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).ResetSignInFailures), ctx, login)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockTokenRepositoryInterface) RevokeAccessToken(ctx context.Context, tokenId string, exp int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", ctx, tokenId, exp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) RevokeAccessToken(ctx, tokenId, exp interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).RevokeAccessToken), ctx, tokenId, exp)
+}
+
+// RevokeAllAccessTokens mocks base method.
+func (m *MockTokenRepositoryInterface) RevokeAllAccessTokens(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllAccessTokens", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllAccessTokens indicates an expected call of RevokeAllAccessTokens.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) RevokeAllAccessTokens(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllAccessTokens", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).RevokeAllAccessTokens), ctx, userId)
+}
+
+// RevokeRefreshTokens mocks base method.
+func (m *MockTokenRepositoryInterface) RevokeRefreshTokens(ctx context.Context, userId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokens", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokens indicates an expected call of RevokeRefreshTokens.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) RevokeRefreshTokens(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokens", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).RevokeRefreshTokens), ctx, userId)
+}
+
+// RevokeSession mocks base method.
+func (m *MockTokenRepositoryInterface) RevokeSession(ctx context.Context, userId, sessionId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, userId, sessionId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) RevokeSession(ctx, userId, sessionId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).RevokeSession), ctx, userId, sessionId)
+}
+
+// SignInRetryAfter mocks base method.
+func (m *MockTokenRepositoryInterface) SignInRetryAfter(ctx context.Context, login, ip string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInRetryAfter", ctx, login, ip)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignInRetryAfter indicates an expected call of SignInRetryAfter.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) SignInRetryAfter(ctx, login, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInRetryAfter", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).SignInRetryAfter), ctx, login, ip)
+}
+
+// UnlockAccount mocks base method.
+func (m *MockTokenRepositoryInterface) UnlockAccount(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockAccount", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockAccount indicates an expected call of UnlockAccount.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) UnlockAccount(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockAccount", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).UnlockAccount), ctx, login)
+}
+
+// VerifyAccessToken mocks base method.
+func (m *MockTokenRepositoryInterface) VerifyAccessToken(ctx context.Context, tokenString string) (api_models.AuthClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAccessToken", ctx, tokenString)
+	ret0, _ := ret[0].(api_models.AuthClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAccessToken indicates an expected call of VerifyAccessToken.
+func (mr *MockTokenRepositoryInterfaceMockRecorder) VerifyAccessToken(ctx, tokenString interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAccessToken", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).VerifyAccessToken), ctx, tokenString)
 }
 
 // VerifyRefreshToken mocks base method.
-func (m *MockTokenRepositoryInterface) VerifyRefreshToken(userId, tokenString string) (bool, error) {
+func (m *MockTokenRepositoryInterface) VerifyRefreshToken(ctx context.Context, tokenString string) (api_models.RefreshClaims, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "VerifyRefreshToken", userId, tokenString)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "VerifyRefreshToken", ctx, tokenString)
+	ret0, _ := ret[0].(api_models.RefreshClaims)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // VerifyRefreshToken indicates an expected call of VerifyRefreshToken.
-func (mr *MockTokenRepositoryInterfaceMockRecorder) VerifyRefreshToken(userId, tokenString interface{}) *gomock.Call {
+func (mr *MockTokenRepositoryInterfaceMockRecorder) VerifyRefreshToken(ctx, tokenString interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyRefreshToken", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).VerifyRefreshToken), userId, tokenString)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyRefreshToken", reflect.TypeOf((*MockTokenRepositoryInterface)(nil).VerifyRefreshToken), ctx, tokenString)
 }
@@ -5,6 +5,7 @@
 package mock_api
 
 import (
+	context "context"
 	reflect "reflect"
 	api_models "vk_test_task/internal/api/models"
 
@@ -34,162 +35,524 @@ func (m *MockUseCaseInterface) EXPECT() *MockUseCaseInterfaceMockRecorder {
 	return m.recorder
 }
 
+// AuthenticateAPIKey mocks base method.
+func (m *MockUseCaseInterface) AuthenticateAPIKey(ctx context.Context, key string) (api_models.AuthClaims, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", ctx, key)
+	ret0, _ := ret[0].(api_models.AuthClaims)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey.
+func (mr *MockUseCaseInterfaceMockRecorder) AuthenticateAPIKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockUseCaseInterface)(nil).AuthenticateAPIKey), ctx, key)
+}
+
+// ChangePassword mocks base method.
+func (m *MockUseCaseInterface) ChangePassword(ctx context.Context, claims api_models.AuthClaims, params api_models.ChangePasswordParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, claims, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUseCaseInterfaceMockRecorder) ChangePassword(ctx, claims, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUseCaseInterface)(nil).ChangePassword), ctx, claims, params)
+}
+
+// ConfirmMFA mocks base method.
+func (m *MockUseCaseInterface) ConfirmMFA(ctx context.Context, claims api_models.AuthClaims, params api_models.MFACodeParams) (api_models.RecoveryCodesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmMFA", ctx, claims, params)
+	ret0, _ := ret[0].(api_models.RecoveryCodesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmMFA indicates an expected call of ConfirmMFA.
+func (mr *MockUseCaseInterfaceMockRecorder) ConfirmMFA(ctx, claims, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmMFA", reflect.TypeOf((*MockUseCaseInterface)(nil).ConfirmMFA), ctx, claims, params)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockUseCaseInterface) CreateAPIKey(ctx context.Context, claims api_models.AuthClaims, params api_models.CreateAPIKeyParams) (api_models.CreateAPIKeyResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, claims, params)
+	ret0, _ := ret[0].(api_models.CreateAPIKeyResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockUseCaseInterfaceMockRecorder) CreateAPIKey(ctx, claims, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockUseCaseInterface)(nil).CreateAPIKey), ctx, claims, params)
+}
+
 // CreateActor mocks base method.
-func (m *MockUseCaseInterface) CreateActor(params api_models.CreateActorParams) (string, error) {
+func (m *MockUseCaseInterface) CreateActor(ctx context.Context, params api_models.CreateActorParams) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateActor", params)
+	ret := m.ctrl.Call(m, "CreateActor", ctx, params)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateActor indicates an expected call of CreateActor.
-func (mr *MockUseCaseInterfaceMockRecorder) CreateActor(params interface{}) *gomock.Call {
+func (mr *MockUseCaseInterfaceMockRecorder) CreateActor(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateActor", reflect.TypeOf((*MockUseCaseInterface)(nil).CreateActor), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateActor", reflect.TypeOf((*MockUseCaseInterface)(nil).CreateActor), ctx, params)
 }
 
 // CreateFilm mocks base method.
-func (m *MockUseCaseInterface) CreateFilm(params api_models.CreateFilmParams) (string, error) {
+func (m *MockUseCaseInterface) CreateFilm(ctx context.Context, params api_models.CreateFilmParams) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateFilm", params)
+	ret := m.ctrl.Call(m, "CreateFilm", ctx, params)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreateFilm indicates an expected call of CreateFilm.
-func (mr *MockUseCaseInterfaceMockRecorder) CreateFilm(params interface{}) *gomock.Call {
+func (mr *MockUseCaseInterfaceMockRecorder) CreateFilm(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFilm", reflect.TypeOf((*MockUseCaseInterface)(nil).CreateFilm), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFilm", reflect.TypeOf((*MockUseCaseInterface)(nil).CreateFilm), ctx, params)
 }
 
 // DeleteActor mocks base method.
-func (m *MockUseCaseInterface) DeleteActor(params api_models.DeleteActorParams) error {
+func (m *MockUseCaseInterface) DeleteActor(ctx context.Context, params api_models.DeleteActorParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteActor", params)
+	ret := m.ctrl.Call(m, "DeleteActor", ctx, params)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteActor indicates an expected call of DeleteActor.
-func (mr *MockUseCaseInterfaceMockRecorder) DeleteActor(params interface{}) *gomock.Call {
+func (mr *MockUseCaseInterfaceMockRecorder) DeleteActor(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActor", reflect.TypeOf((*MockUseCaseInterface)(nil).DeleteActor), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteActor", reflect.TypeOf((*MockUseCaseInterface)(nil).DeleteActor), ctx, params)
 }
 
 // DeleteFilm mocks base method.
-func (m *MockUseCaseInterface) DeleteFilm(params api_models.DeleteFilmParams) error {
+func (m *MockUseCaseInterface) DeleteFilm(ctx context.Context, params api_models.DeleteFilmParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteFilm", params)
+	ret := m.ctrl.Call(m, "DeleteFilm", ctx, params)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteFilm indicates an expected call of DeleteFilm.
-func (mr *MockUseCaseInterfaceMockRecorder) DeleteFilm(params interface{}) *gomock.Call {
+func (mr *MockUseCaseInterfaceMockRecorder) DeleteFilm(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilm", reflect.TypeOf((*MockUseCaseInterface)(nil).DeleteFilm), ctx, params)
+}
+
+// DisableMFA mocks base method.
+func (m *MockUseCaseInterface) DisableMFA(ctx context.Context, claims api_models.AuthClaims, params api_models.MFACodeParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableMFA", ctx, claims, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableMFA indicates an expected call of DisableMFA.
+func (mr *MockUseCaseInterfaceMockRecorder) DisableMFA(ctx, claims, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableMFA", reflect.TypeOf((*MockUseCaseInterface)(nil).DisableMFA), ctx, claims, params)
+}
+
+// EnrollMFA mocks base method.
+func (m *MockUseCaseInterface) EnrollMFA(ctx context.Context, claims api_models.AuthClaims) (api_models.EnrollMFAResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnrollMFA", ctx, claims)
+	ret0, _ := ret[0].(api_models.EnrollMFAResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnrollMFA indicates an expected call of EnrollMFA.
+func (mr *MockUseCaseInterfaceMockRecorder) EnrollMFA(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnrollMFA", reflect.TypeOf((*MockUseCaseInterface)(nil).EnrollMFA), ctx, claims)
+}
+
+// ForgotPassword mocks base method.
+func (m *MockUseCaseInterface) ForgotPassword(ctx context.Context, params api_models.ForgotPasswordParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockUseCaseInterfaceMockRecorder) ForgotPassword(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilm", reflect.TypeOf((*MockUseCaseInterface)(nil).DeleteFilm), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockUseCaseInterface)(nil).ForgotPassword), ctx, params)
+}
+
+// GetAPIKeys mocks base method.
+func (m *MockUseCaseInterface) GetAPIKeys(ctx context.Context) (api_models.GetAPIKeysResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeys", ctx)
+	ret0, _ := ret[0].(api_models.GetAPIKeysResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeys indicates an expected call of GetAPIKeys.
+func (mr *MockUseCaseInterfaceMockRecorder) GetAPIKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeys", reflect.TypeOf((*MockUseCaseInterface)(nil).GetAPIKeys), ctx)
+}
+
+// GetActor mocks base method.
+func (m *MockUseCaseInterface) GetActor(ctx context.Context, actorId string) (api_models.ActorDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActor", ctx, actorId)
+	ret0, _ := ret[0].(api_models.ActorDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActor indicates an expected call of GetActor.
+func (mr *MockUseCaseInterfaceMockRecorder) GetActor(ctx, actorId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActor", reflect.TypeOf((*MockUseCaseInterface)(nil).GetActor), ctx, actorId)
 }
 
 // GetActors mocks base method.
-func (m *MockUseCaseInterface) GetActors() (api_models.GetActorsResponse, error) {
+func (m *MockUseCaseInterface) GetActors(ctx context.Context, params api_models.GetActorsParams) (api_models.GetActorsResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetActors")
+	ret := m.ctrl.Call(m, "GetActors", ctx, params)
 	ret0, _ := ret[0].(api_models.GetActorsResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetActors indicates an expected call of GetActors.
-func (mr *MockUseCaseInterfaceMockRecorder) GetActors() *gomock.Call {
+func (mr *MockUseCaseInterfaceMockRecorder) GetActors(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActors", reflect.TypeOf((*MockUseCaseInterface)(nil).GetActors), ctx, params)
+}
+
+// GetFilm mocks base method.
+func (m *MockUseCaseInterface) GetFilm(ctx context.Context, filmId string) (api_models.FilmDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilm", ctx, filmId)
+	ret0, _ := ret[0].(api_models.FilmDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilm indicates an expected call of GetFilm.
+func (mr *MockUseCaseInterfaceMockRecorder) GetFilm(ctx, filmId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActors", reflect.TypeOf((*MockUseCaseInterface)(nil).GetActors))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilm", reflect.TypeOf((*MockUseCaseInterface)(nil).GetFilm), ctx, filmId)
 }
 
 // GetFilms mocks base method.
-func (m *MockUseCaseInterface) GetFilms(params api_models.GetFilmsParams) (api_models.GetFilmsResponse, error) {
+func (m *MockUseCaseInterface) GetFilms(ctx context.Context, params api_models.GetFilmsParams) (api_models.GetFilmsResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetFilms", params)
+	ret := m.ctrl.Call(m, "GetFilms", ctx, params)
 	ret0, _ := ret[0].(api_models.GetFilmsResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetFilms indicates an expected call of GetFilms.
-func (mr *MockUseCaseInterfaceMockRecorder) GetFilms(params interface{}) *gomock.Call {
+func (mr *MockUseCaseInterfaceMockRecorder) GetFilms(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilms", reflect.TypeOf((*MockUseCaseInterface)(nil).GetFilms), ctx, params)
+}
+
+// GetJWKS mocks base method.
+func (m *MockUseCaseInterface) GetJWKS(ctx context.Context) (api_models.JWKS, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetJWKS", ctx)
+	ret0, _ := ret[0].(api_models.JWKS)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetJWKS indicates an expected call of GetJWKS.
+func (mr *MockUseCaseInterfaceMockRecorder) GetJWKS(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetJWKS", reflect.TypeOf((*MockUseCaseInterface)(nil).GetJWKS), ctx)
+}
+
+// GetRoles mocks base method.
+func (m *MockUseCaseInterface) GetRoles(ctx context.Context) (api_models.GetRolesResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoles", ctx)
+	ret0, _ := ret[0].(api_models.GetRolesResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoles indicates an expected call of GetRoles.
+func (mr *MockUseCaseInterfaceMockRecorder) GetRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoles", reflect.TypeOf((*MockUseCaseInterface)(nil).GetRoles), ctx)
+}
+
+// GetSessions mocks base method.
+func (m *MockUseCaseInterface) GetSessions(ctx context.Context, claims api_models.AuthClaims) (api_models.GetSessionsResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", ctx, claims)
+	ret0, _ := ret[0].(api_models.GetSessionsResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockUseCaseInterfaceMockRecorder) GetSessions(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockUseCaseInterface)(nil).GetSessions), ctx, claims)
+}
+
+// GrantRole mocks base method.
+func (m *MockUseCaseInterface) GrantRole(ctx context.Context, params api_models.RoleParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GrantRole", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GrantRole indicates an expected call of GrantRole.
+func (mr *MockUseCaseInterfaceMockRecorder) GrantRole(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GrantRole", reflect.TypeOf((*MockUseCaseInterface)(nil).GrantRole), ctx, params)
+}
+
+// Logout mocks base method.
+func (m *MockUseCaseInterface) Logout(ctx context.Context, claims api_models.AuthClaims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, claims)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockUseCaseInterfaceMockRecorder) Logout(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockUseCaseInterface)(nil).Logout), ctx, claims)
+}
+
+// LogoutAll mocks base method.
+func (m *MockUseCaseInterface) LogoutAll(ctx context.Context, claims api_models.AuthClaims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutAll", ctx, claims)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutAll indicates an expected call of LogoutAll.
+func (mr *MockUseCaseInterfaceMockRecorder) LogoutAll(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutAll", reflect.TypeOf((*MockUseCaseInterface)(nil).LogoutAll), ctx, claims)
+}
+
+// Readiness mocks base method.
+func (m *MockUseCaseInterface) Readiness(ctx context.Context) api_models.ReadinessResponse {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Readiness", ctx)
+	ret0, _ := ret[0].(api_models.ReadinessResponse)
+	return ret0
+}
+
+// Readiness indicates an expected call of Readiness.
+func (mr *MockUseCaseInterfaceMockRecorder) Readiness(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Readiness", reflect.TypeOf((*MockUseCaseInterface)(nil).Readiness), ctx)
+}
+
+// Refresh mocks base method.
+func (m *MockUseCaseInterface) Refresh(ctx context.Context, params api_models.RefreshParams) (api_models.SignInUseCaseResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, params)
+	ret0, _ := ret[0].(api_models.SignInUseCaseResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockUseCaseInterfaceMockRecorder) Refresh(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockUseCaseInterface)(nil).Refresh), ctx, params)
+}
+
+// ResetPassword mocks base method.
+func (m *MockUseCaseInterface) ResetPassword(ctx context.Context, params api_models.ResetPasswordParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUseCaseInterfaceMockRecorder) ResetPassword(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUseCaseInterface)(nil).ResetPassword), ctx, params)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockUseCaseInterface) RevokeAPIKey(ctx context.Context, params api_models.RevokeAPIKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockUseCaseInterfaceMockRecorder) RevokeAPIKey(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockUseCaseInterface)(nil).RevokeAPIKey), ctx, params)
+}
+
+// RevokeRole mocks base method.
+func (m *MockUseCaseInterface) RevokeRole(ctx context.Context, params api_models.RoleParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRole", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRole indicates an expected call of RevokeRole.
+func (mr *MockUseCaseInterfaceMockRecorder) RevokeRole(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRole", reflect.TypeOf((*MockUseCaseInterface)(nil).RevokeRole), ctx, params)
+}
+
+// RevokeSession mocks base method.
+func (m *MockUseCaseInterface) RevokeSession(ctx context.Context, claims api_models.AuthClaims, params api_models.RevokeSessionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, claims, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockUseCaseInterfaceMockRecorder) RevokeSession(ctx, claims, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilms", reflect.TypeOf((*MockUseCaseInterface)(nil).GetFilms), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockUseCaseInterface)(nil).RevokeSession), ctx, claims, params)
 }
 
 // SearchFilm mocks base method.
-func (m *MockUseCaseInterface) SearchFilm(params api_models.SearchFilmParams) (api_models.SearchFilmResponse, error) {
+func (m *MockUseCaseInterface) SearchFilm(ctx context.Context, params api_models.SearchFilmParams) (api_models.SearchFilmResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchFilm", params)
+	ret := m.ctrl.Call(m, "SearchFilm", ctx, params)
 	ret0, _ := ret[0].(api_models.SearchFilmResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SearchFilm indicates an expected call of SearchFilm.
-func (mr *MockUseCaseInterfaceMockRecorder) SearchFilm(params interface{}) *gomock.Call {
+func (mr *MockUseCaseInterfaceMockRecorder) SearchFilm(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFilm", reflect.TypeOf((*MockUseCaseInterface)(nil).SearchFilm), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFilm", reflect.TypeOf((*MockUseCaseInterface)(nil).SearchFilm), ctx, params)
 }
 
 // SignIn mocks base method.
-func (m *MockUseCaseInterface) SignIn(params api_models.AuthParams) (api_models.SignInUseCaseResponse, error) {
+func (m *MockUseCaseInterface) SignIn(ctx context.Context, params api_models.AuthParams) (api_models.SignInUseCaseResponse, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignIn", params)
+	ret := m.ctrl.Call(m, "SignIn", ctx, params)
 	ret0, _ := ret[0].(api_models.SignInUseCaseResponse)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SignIn indicates an expected call of SignIn.
-func (mr *MockUseCaseInterfaceMockRecorder) SignIn(params interface{}) *gomock.Call {
+func (mr *MockUseCaseInterfaceMockRecorder) SignIn(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockUseCaseInterface)(nil).SignIn), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockUseCaseInterface)(nil).SignIn), ctx, params)
+}
+
+// SignInMFA mocks base method.
+func (m *MockUseCaseInterface) SignInMFA(ctx context.Context, params api_models.SignInMFAParams) (api_models.SignInUseCaseResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInMFA", ctx, params)
+	ret0, _ := ret[0].(api_models.SignInUseCaseResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignInMFA indicates an expected call of SignInMFA.
+func (mr *MockUseCaseInterfaceMockRecorder) SignInMFA(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInMFA", reflect.TypeOf((*MockUseCaseInterface)(nil).SignInMFA), ctx, params)
 }
 
 // SignUp mocks base method.
-func (m *MockUseCaseInterface) SignUp(params api_models.AuthParams) error {
+func (m *MockUseCaseInterface) SignUp(ctx context.Context, params api_models.AuthParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUp", params)
+	ret := m.ctrl.Call(m, "SignUp", ctx, params)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SignUp indicates an expected call of SignUp.
-func (mr *MockUseCaseInterfaceMockRecorder) SignUp(params interface{}) *gomock.Call {
+func (mr *MockUseCaseInterfaceMockRecorder) SignUp(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockUseCaseInterface)(nil).SignUp), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockUseCaseInterface)(nil).SignUp), ctx, params)
+}
+
+// UnlockUser mocks base method.
+func (m *MockUseCaseInterface) UnlockUser(ctx context.Context, params api_models.UnlockUserParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnlockUser", ctx, params)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnlockUser indicates an expected call of UnlockUser.
+func (mr *MockUseCaseInterfaceMockRecorder) UnlockUser(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockUser", reflect.TypeOf((*MockUseCaseInterface)(nil).UnlockUser), ctx, params)
 }
 
 // UpdateActor mocks base method.
-func (m *MockUseCaseInterface) UpdateActor(params api_models.UpdateActorParams) error {
+func (m *MockUseCaseInterface) UpdateActor(ctx context.Context, params api_models.UpdateActorParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateActor", params)
+	ret := m.ctrl.Call(m, "UpdateActor", ctx, params)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateActor indicates an expected call of UpdateActor.
-func (mr *MockUseCaseInterfaceMockRecorder) UpdateActor(params interface{}) *gomock.Call {
+func (mr *MockUseCaseInterfaceMockRecorder) UpdateActor(ctx, params interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateActor", reflect.TypeOf((*MockUseCaseInterface)(nil).UpdateActor), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateActor", reflect.TypeOf((*MockUseCaseInterface)(nil).UpdateActor), ctx, params)
 }
 
 // UpdateFilm mocks base method.
-func (m *MockUseCaseInterface) UpdateFilm(params api_models.UpdateFilmParams) error {
+func (m *MockUseCaseInterface) UpdateFilm(ctx context.Context, params api_models.UpdateFilmParams) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateFilm", params)
+	ret := m.ctrl.Call(m, "UpdateFilm", ctx, params)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateFilm indicates an expected call of UpdateFilm.
-func (mr *MockUseCaseInterfaceMockRecorder) UpdateFilm(params interface{}) *gomock.Call {
+func (mr *MockUseCaseInterfaceMockRecorder) UpdateFilm(ctx, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFilm", reflect.TypeOf((*MockUseCaseInterface)(nil).UpdateFilm), ctx, params)
+}
+
+// Version mocks base method.
+func (m *MockUseCaseInterface) Version() api_models.VersionResponse {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version")
+	ret0, _ := ret[0].(api_models.VersionResponse)
+	return ret0
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockUseCaseInterfaceMockRecorder) Version() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFilm", reflect.TypeOf((*MockUseCaseInterface)(nil).UpdateFilm), params)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockUseCaseInterface)(nil).Version))
 }
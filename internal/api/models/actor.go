@@ -1,9 +1,6 @@
 package api_models
 
 import (
-	"database/sql"
-	"encoding/json"
-	"strings"
 	"time"
 )
 
@@ -14,65 +11,59 @@ type CreateActorParams struct {
 	Birth   time.Time `json:"birth"`
 }
 
-type ActorAndFilms struct {
-	ActorId string         `json:"actor_id"`
-	Name    string         `json:"name"`
-	Sex     int            `json:"sex"`
-	Birth   time.Time      `json:"birth"`
-	Films   sql.NullString `json:"films"` //обычный массив не подходит, т.к. запрос возвращает строку. Строка не подходит т.к. postgres экранирует кавычки у строк с пробелами, и не экранирует ничего у строек без пробелов.
+// ActorDetails - актер с фильмографией, отдается в GET /actors/{id}
+type ActorDetails struct {
+	ActorId string    `json:"actor_id"`
+	Name    string    `json:"name"`
+	Sex     int       `json:"sex"`
+	Birth   time.Time `json:"birth"`
+	Version int       `json:"version"`
+	Films   []FilmRef `json:"films"`
 }
 
-func (a ActorAndFilms) MarshallJSON() (map[string]interface{}, error) {
-	trimmed := strings.TrimPrefix(a.Films.String, "{")
-	trimmed = strings.TrimSuffix(trimmed, "}")
-
-	result := strings.Split(trimmed, ",")
-
-	jsonMap := make(map[string]interface{})
-
-	jsonMap["actor_id"] = a.ActorId
-	jsonMap["name"] = a.Name
-	jsonMap["sex"] = a.Sex
-	jsonMap["birth"] = a.Birth
-
-	if len(result) > 0 && result[0] == "NULL" {
-		jsonMap["films"] = []string{}
-	} else {
-		jsonMap["films"] = result
-	}
-
-	return jsonMap, nil
+// ActorRef - актер в составе фильма
+type ActorRef struct {
+	ActorId string `json:"actor_id"`
+	Name    string `json:"name"`
 }
 
-type GetActorsResponse struct {
-	Response []ActorAndFilms `json:"response"`
+type ActorAndFilms struct {
+	ActorId string    `json:"actor_id"`
+	Name    string    `json:"name"`
+	Sex     int       `json:"sex"`
+	Birth   time.Time `json:"birth"`
+	Version int       `json:"version"`
+	Films   []FilmRef `json:"films"`
 }
 
-func (r GetActorsResponse) MarshallJSON() ([]byte, error) {
-	jsonMap := make(map[string]interface{})
-
-	var result []map[string]interface{}
-
-	for _, v := range r.Response {
-		jsonSingle, err := v.MarshallJSON()
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, jsonSingle)
-	}
+type GetActorsParams struct {
+	Limit  int    `json:"limit" query:"limit"`
+	Cursor string `json:"cursor" query:"cursor"`
+}
 
-	jsonMap["response"] = result
+func (p GetActorsParams) Validate() []FieldError {
+	return validateLimit(p.Limit)
+}
 
-	return json.Marshal(jsonMap)
+type GetActorsResponse struct {
+	Response      []ActorAndFilms `json:"response"`
+	NextCursor    string          `json:"next_cursor,omitempty"`
+	TotalEstimate int64           `json:"total_estimate"`
 }
 
+// UpdateActorParams - частичное обновление актера: меняются только переданные поля
 type UpdateActorParams struct {
-	ActorId string    `json:"actor_id"`
-	Name    string    `json:"name"`
-	Sex     int       `json:"sex"`
-	Birth   time.Time `json:"birth"`
+	ActorId string              `json:"actor_id"`
+	Name    Optional[string]    `json:"name" swaggertype:"string"`
+	Sex     Optional[int]       `json:"sex" swaggertype:"integer"`
+	Birth   Optional[time.Time] `json:"birth" swaggertype:"string" format:"date-time"`
+	// IfMatch - версии из If-Match, пусто если заголовка нет
+	IfMatch []int `json:"-"`
+	// Version - текущая версия, usecase подставляет ее в условие обновления
+	Version int `json:"-"`
 }
 
 type DeleteActorParams struct {
 	ActorId string `json:"actor_id"`
+	IfMatch []int  `json:"-"`
 }
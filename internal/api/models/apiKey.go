@@ -0,0 +1,34 @@
+package api_models
+
+import "time"
+
+type APIKey struct {
+	Id         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	UserId     string     `json:"user_id"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+type CreateAPIKeyParams struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type CreateAPIKeyResponse struct {
+	Id  string `json:"id"`
+	Key string `json:"key"`
+}
+
+type GetAPIKeysResponse struct {
+	Response []APIKey `json:"response"`
+}
+
+type RevokeAPIKeyParams struct {
+	Id string `json:"id"`
+}
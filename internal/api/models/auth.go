@@ -1,35 +1,99 @@
 package api_models
 
-import "github.com/golang-jwt/jwt/v5"
+import (
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"slices"
+	"time"
+)
 
 type RefreshClaims struct {
-	IsAdmin       bool `json:"is_admin"`
-	jwt.MapClaims `json:"claims"`
+	UserId    string `json:"user_id"`
+	SessionId string `json:"session_id"`
+	Type      string `json:"typ"`
+	jwt.RegisteredClaims
 }
 
 type AuthClaims struct {
-	UserId        string `json:"user_id"`
-	IsAdmin       bool   `json:"is_admin"`
-	jwt.MapClaims `json:"claims"`
+	UserId      string   `json:"user_id"`
+	SessionId   string   `json:"session_id"`
+	Type        string   `json:"typ"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	jwt.RegisteredClaims
+}
+
+func (c AuthClaims) TokenId() string {
+	return c.ID
+}
+
+func (c AuthClaims) HasPermission(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
+
+type TooManyRequestsError struct {
+	RetryAfter time.Duration
+}
+
+func (e TooManyRequestsError) Error() string {
+	return fmt.Sprintf("too many sign in attempts, retry after %s", e.RetryAfter.Round(time.Second))
 }
 
 type SignInRepositoryResponse struct {
 	UserId       string
 	HashPassword string
-	IsAdmin      bool
+	Disabled     bool
 }
 
 type SignInUseCaseResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-	Expiration   int64  `json:"expiration"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Expiration   int64  `json:"expiration,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
 }
 
 type AuthParams struct {
-	Login    string `json:"login"`
-	Password string `json:"password"`
+	Login     string `json:"login"`
+	Password  string `json:"password"`
+	Email     string `json:"email"`
+	Device    string `json:"device"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type RefreshParams struct {
+	RefreshToken string `json:"refresh_token"`
+	IP           string `json:"-"`
+	UserAgent    string `json:"-"`
+}
+
+type ClientInfo struct {
+	Device    string
+	IP        string
+	UserAgent string
+}
+
+type Session struct {
+	SessionId  string    `json:"session_id"`
+	Device     string    `json:"device"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"user_agent"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Current    bool      `json:"current"`
+}
+
+type GetSessionsResponse struct {
+	Response []Session `json:"response"`
+}
+
+type RevokeSessionParams struct {
+	SessionId string `json:"session_id"`
 }
 
 type ErrorResponse struct {
-	Description string `json:"description"`
+	Code      ErrorCode    `json:"code"`
+	Message   string       `json:"message"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestId string       `json:"request_id,omitempty"`
 }
@@ -0,0 +1,148 @@
+package api_models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+type ErrorCode string
+
+const (
+	CodeValidation      ErrorCode = "validation_error"
+	CodeNotFound        ErrorCode = "not_found"
+	CodeConflict        ErrorCode = "conflict"
+	CodePrecondition    ErrorCode = "precondition_failed"
+	CodeUnauthorized    ErrorCode = "unauthorized"
+	CodeForbidden       ErrorCode = "forbidden"
+	CodeTooManyRequests ErrorCode = "too_many_requests"
+	CodeInternal        ErrorCode = "internal_error"
+)
+
+func (c ErrorCode) HTTPStatus() int {
+	switch c {
+	case CodeValidation:
+		return http.StatusBadRequest
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeConflict:
+		return http.StatusConflict
+	case CodePrecondition:
+		return http.StatusPreconditionFailed
+	case CodeUnauthorized:
+		return http.StatusUnauthorized
+	case CodeForbidden:
+		return http.StatusForbidden
+	case CodeTooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Error - доменная ошибка. Клиент получает Code, Message и Details, Err попадает только в логи
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Details []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.Message, e.Err.Error())
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is сравнивает ошибки по коду, поэтому errors.Is(err, ErrNotFound) верно для любой ошибки с кодом not_found
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+var (
+	ErrValidation   = &Error{Code: CodeValidation, Message: "validation error"}
+	ErrNotFound     = &Error{Code: CodeNotFound, Message: "not found"}
+	ErrConflict     = &Error{Code: CodeConflict, Message: "conflict"}
+	ErrPrecondition = &Error{Code: CodePrecondition, Message: "precondition failed"}
+	ErrUnauthorized = &Error{Code: CodeUnauthorized, Message: "unauthorized"}
+	ErrForbidden    = &Error{Code: CodeForbidden, Message: "forbidden"}
+)
+
+// FieldError описывает ошибку в конкретном параметре запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func NewValidationError(message string, details ...FieldError) error {
+	return &Error{Code: CodeValidation, Message: message, Details: details}
+}
+
+// NewFieldError - ошибка валидации одного поля
+func NewFieldError(field, message string) error {
+	return &Error{
+		Code:    CodeValidation,
+		Message: fmt.Sprintf("invalid %s", field),
+		Details: []FieldError{{Field: field, Message: message}},
+	}
+}
+
+func NewNotFoundError(message string) error {
+	return &Error{Code: CodeNotFound, Message: message}
+}
+
+func NewConflictError(message string) error {
+	return &Error{Code: CodeConflict, Message: message}
+}
+
+// NewPreconditionError - версия из If-Match не совпала с текущей
+func NewPreconditionError(message string) error {
+	return &Error{Code: CodePrecondition, Message: message}
+}
+
+func NewUnauthorizedError(message string) error {
+	return &Error{Code: CodeUnauthorized, Message: message}
+}
+
+func NewForbiddenError(message string) error {
+	return &Error{Code: CodeForbidden, Message: message}
+}
+
+// WrapError скрывает исходную ошибку от клиента, но сохраняет ее для логов и errors.Is
+func WrapError(code ErrorCode, message string, err error) error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// NewErrorResponse собирает тело ответа и статус. Ошибки без кода считаются внутренними и не раскрываются клиенту
+func NewErrorResponse(err error, requestId string) (int, ErrorResponse) {
+	var limitErr TooManyRequestsError
+	if errors.As(err, &limitErr) {
+		return CodeTooManyRequests.HTTPStatus(), ErrorResponse{
+			Code:      CodeTooManyRequests,
+			Message:   limitErr.Error(),
+			RequestId: requestId,
+		}
+	}
+
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Code.HTTPStatus(), ErrorResponse{
+			Code:      domainErr.Code,
+			Message:   domainErr.Message,
+			Details:   domainErr.Details,
+			RequestId: requestId,
+		}
+	}
+
+	return CodeInternal.HTTPStatus(), ErrorResponse{
+		Code:      CodeInternal,
+		Message:   "internal server error",
+		RequestId: requestId,
+	}
+}
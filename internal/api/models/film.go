@@ -1,10 +1,11 @@
 package api_models
 
 import (
-	"database/sql"
-	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
+	"vk_test_task/internal/common"
 )
 
 type CreateFilmParams struct {
@@ -16,101 +17,102 @@ type CreateFilmParams struct {
 	Actors      []string  `json:"actors"`
 }
 
+// UpdateFilmParams - частичное обновление фильма: меняются только переданные поля, null в description очищает описание.
+// Actors заменяет весь список актеров, AddActors и RemoveActors добавляют и убирают отдельных актеров
 type UpdateFilmParams struct {
-	FilmId      string    `json:"film_id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	ReleaseDate time.Time `json:"release_date"`
-	Rate        int       `json:"rate"`
-	Actors      []string  `json:"actors"`
+	FilmId       string              `json:"film_id"`
+	Name         Optional[string]    `json:"name" swaggertype:"string"`
+	Description  Optional[string]    `json:"description" swaggertype:"string"`
+	ReleaseDate  Optional[time.Time] `json:"release_date" swaggertype:"string" format:"date-time"`
+	Rate         Optional[int]       `json:"rate" swaggertype:"integer"`
+	Actors       Optional[[]string]  `json:"actors" swaggertype:"array,string"`
+	AddActors    []string            `json:"add_actors"`
+	RemoveActors []string            `json:"remove_actors"`
+	// IfMatch - версии из If-Match, пусто если заголовка нет
+	IfMatch []int `json:"-"`
+	// Version - текущая версия, usecase подставляет ее в условие обновления
+	Version int `json:"-"`
 }
 
 type GetFilmsParams struct {
-	SortBy      int `json:"sort_by"`
-	IsAscending int `json:"is_ascending"`
+	Sort   Sort   `json:"sort" query:"sort"`
+	Limit  int    `json:"limit" query:"limit"`
+	Cursor string `json:"cursor" query:"cursor"`
 }
 
-type FilmAndActors struct {
-	FilmId      string         `json:"film_id"`
-	Name        string         `json:"name"`
-	Description string         `json:"description"`
-	Rate        int            `json:"rate"`
-	ReleaseDate string         `json:"release_date"`
-	Actors      sql.NullString `json:"actors"` //обычный массив не подходит, т.к. запрос возвращает строку. Строка не подходит т.к. postgres экранирует кавычки у строк с пробелами, и не экранирует ничего у строек без пробелов.
+func (p GetFilmsParams) Validate() []FieldError {
+	return append(p.Sort.validate("sort", FilmSortFields), validateLimit(p.Limit)...)
 }
 
-func (a FilmAndActors) MarshallJSON() (map[string]interface{}, error) {
-	trimmed := strings.TrimPrefix(a.Actors.String, "{")
-	trimmed = strings.TrimSuffix(trimmed, "}")
-
-	result := strings.Split(trimmed, ",")
-
-	jsonMap := make(map[string]interface{})
-
-	jsonMap["film_id"] = a.FilmId
-	jsonMap["name"] = a.Name
-	jsonMap["description"] = a.Description
-	jsonMap["rate"] = a.Rate
-	jsonMap["release_date"] = a.ReleaseDate
-
-	if len(result) > 0 && result[0] == "NULL" {
-		jsonMap["actors"] = []string{}
-	} else {
-		jsonMap["actors"] = result
-	}
-
-	return jsonMap, nil
+// FilmDetails - фильм со всеми актерами, отдается в GET /films/{id}
+type FilmDetails struct {
+	FilmId      string     `json:"film_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Rate        int        `json:"rate"`
+	ReleaseDate string     `json:"release_date"`
+	Version     int        `json:"version"`
+	Actors      []ActorRef `json:"actors"`
 }
 
-func (r GetFilmsResponse) MarshallJSON() ([]byte, error) {
-	jsonMap := make(map[string]interface{})
-
-	var result []map[string]interface{}
-
-	for _, v := range r.Response {
-		jsonSingle, err := v.MarshallJSON()
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, jsonSingle)
-	}
-
-	jsonMap["response"] = result
+// FilmRef - фильм в фильмографии актера
+type FilmRef struct {
+	FilmId      string `json:"film_id"`
+	Name        string `json:"name"`
+	ReleaseDate string `json:"release_date"`
+}
 
-	return json.Marshal(jsonMap)
+type FilmAndActors struct {
+	FilmId      string     `json:"film_id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Rate        int        `json:"rate"`
+	ReleaseDate string     `json:"release_date"`
+	Actors      []ActorRef `json:"actors"`
 }
 
 type GetFilmsResponse struct {
-	Response []FilmAndActors `json:"response"`
+	Response      []FilmAndActors `json:"response"`
+	NextCursor    string          `json:"next_cursor,omitempty"`
+	TotalEstimate int64           `json:"total_estimate"`
 }
 
 type DeleteFilmParams struct {
-	FilmId string `json:"film_id"`
+	FilmId  string `json:"film_id"`
+	IfMatch []int  `json:"-"`
 }
 
 type SearchFilmParams struct {
-	Name      string `json:"name"`
-	ActorName string `json:"actor_name"`
-}
-
-type SearchFilmResponse struct {
-	Response []FilmAndActors `json:"response"`
+	Name      string `json:"name" query:"name"`
+	ActorName string `json:"actor_name" query:"actor_name"`
+	Sort      Sort   `json:"sort" query:"sort"`
+	Limit     int    `json:"limit" query:"limit"`
+	Cursor    string `json:"cursor" query:"cursor"`
 }
 
-func (r SearchFilmResponse) MarshallJSON() ([]byte, error) {
-	jsonMap := make(map[string]interface{})
-
-	var result []map[string]interface{}
+func (p SearchFilmParams) Validate() []FieldError {
+	var fieldErrors []FieldError
 
-	for _, v := range r.Response {
-		jsonSingle, err := v.MarshallJSON()
-		if err != nil {
-			return nil, err
-		}
-		result = append(result, jsonSingle)
+	if strings.TrimSpace(p.Name) == "" && strings.TrimSpace(p.ActorName) == "" {
+		fieldErrors = append(fieldErrors, FieldError{Field: "name", Message: "name or actor_name is required"})
+	}
+	if utf8.RuneCountInString(p.Name) > common.FILM_NAME_MAXSIZE {
+		fieldErrors = append(fieldErrors, FieldError{Field: "name",
+			Message: fmt.Sprintf("must be at most %d characters", common.FILM_NAME_MAXSIZE)})
+	}
+	if utf8.RuneCountInString(p.ActorName) > common.ACTOR_NAME_MAXSIZE {
+		fieldErrors = append(fieldErrors, FieldError{Field: "actor_name",
+			Message: fmt.Sprintf("must be at most %d characters", common.ACTOR_NAME_MAXSIZE)})
 	}
 
-	jsonMap["response"] = result
+	fieldErrors = append(fieldErrors, p.Sort.validate("sort", FilmSortFields)...)
+	fieldErrors = append(fieldErrors, validateLimit(p.Limit)...)
 
-	return json.Marshal(jsonMap)
+	return fieldErrors
+}
+
+type SearchFilmResponse struct {
+	Response      []FilmAndActors `json:"response"`
+	NextCursor    string          `json:"next_cursor,omitempty"`
+	TotalEstimate int64           `json:"total_estimate"`
 }
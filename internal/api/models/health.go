@@ -0,0 +1,29 @@
+package api_models
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+// DependencyStatus - результат проверки одной зависимости. Err попадает только в логи
+type DependencyStatus struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+	Err       error  `json:"-"`
+}
+
+type ReadinessResponse struct {
+	Status string                      `json:"status"`
+	Checks map[string]DependencyStatus `json:"checks"`
+}
+
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+}
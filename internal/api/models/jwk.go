@@ -0,0 +1,14 @@
+package api_models
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	X   string `json:"x"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
@@ -0,0 +1,34 @@
+package api_models
+
+type MFA struct {
+	Secret  string
+	Enabled bool
+}
+
+type MFAChallenge struct {
+	UserId    string `json:"user_id"`
+	Login     string `json:"login"`
+	Device    string `json:"device"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+}
+
+type SignInMFAParams struct {
+	MFAToken  string `json:"mfa_token"`
+	Code      string `json:"code"`
+	IP        string `json:"-"`
+	UserAgent string `json:"-"`
+}
+
+type MFACodeParams struct {
+	Code string `json:"code"`
+}
+
+type EnrollMFAResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,39 @@
+package api_models
+
+import (
+	"encoding/json"
+)
+
+// Optional - поле частичного обновления по JSON Merge Patch (RFC 7396).
+// Различает три случая: поле не передано (Set=false), передан null (Null=true) и передано значение, в том числе нулевое
+type Optional[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func NewOptional[T any](value T) Optional[T] {
+	return Optional[T]{Set: true, Value: value}
+}
+
+// UnmarshalJSON вызывается только для ключей, которые есть в теле запроса
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &o.Value)
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+// HasValue - поле передано и не равно null
+func (o Optional[T]) HasValue() bool {
+	return o.Set && !o.Null
+}
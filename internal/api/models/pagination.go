@@ -0,0 +1,47 @@
+package api_models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+func validateLimit(limit int) []FieldError {
+	if limit < 0 {
+		return []FieldError{{Field: "limit", Message: "must be positive"}}
+	}
+	return nil
+}
+
+// Page - параметры одной страницы keyset пагинации
+type Page struct {
+	Sort  Sort
+	Limit int
+	After *Cursor
+}
+
+// Cursor хранит значения полей сортировки и id последней записи страницы.
+// Клиент получает его как непрозрачную строку в next_cursor и передает обратно без изменений
+type Cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	Id     string   `json:"id"`
+}
+
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(value string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, NewFieldError("cursor", "malformed cursor")
+	}
+
+	var cursor Cursor
+	if err = json.Unmarshal(data, &cursor); err != nil || cursor.Id == "" {
+		return Cursor{}, NewFieldError("cursor", "malformed cursor")
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,22 @@
+package api_models
+
+type UserAccess struct {
+	UserId      string
+	Roles       []string
+	Permissions []string
+	Disabled    bool
+}
+
+type Role struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+type GetRolesResponse struct {
+	Response []Role `json:"response"`
+}
+
+type RoleParams struct {
+	UserId string `json:"user_id"`
+	Role   string `json:"role"`
+}
@@ -0,0 +1,78 @@
+package api_models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FilmSortFields - поля, по которым можно сортировать фильмы
+var FilmSortFields = []string{"name", "rate", "release_date"}
+
+// DefaultFilmSort используется, если параметр sort не передан
+var DefaultFilmSort = Sort{{Field: "rate", Desc: true}}
+
+// ActorSort - актеры всегда отдаются по имени
+var ActorSort = Sort{{Field: "name"}}
+
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// Sort задается в запросе списком полей через запятую, минус перед полем - сортировка по убыванию: sort=-rate,name
+type Sort []SortField
+
+func (s *Sort) UnmarshalQuery(value string) error {
+	var sort Sort
+
+	for _, part := range strings.Split(value, ",") {
+		// "+" в query string декодируется в пробел, поэтому "+name" приходит как " name"
+		part = strings.TrimSpace(part)
+
+		field := SortField{Field: strings.TrimPrefix(part, "+")}
+		if strings.HasPrefix(part, "-") {
+			field = SortField{Field: strings.TrimPrefix(part, "-"), Desc: true}
+		}
+		if field.Field == "" {
+			return fmt.Errorf("empty sort field")
+		}
+
+		sort = append(sort, field)
+	}
+
+	*s = sort
+	return nil
+}
+
+func (s Sort) String() string {
+	parts := make([]string, 0, len(s))
+	for _, field := range s {
+		if field.Desc {
+			parts = append(parts, "-"+field.Field)
+		} else {
+			parts = append(parts, field.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (s Sort) validate(name string, allowed []string) []FieldError {
+	var fieldErrors []FieldError
+
+	seen := make(map[string]bool)
+	for _, field := range s {
+		if !slices.Contains(allowed, field.Field) {
+			fieldErrors = append(fieldErrors, FieldError{Field: name,
+				Message: fmt.Sprintf("unknown sort field %q, allowed: %s", field.Field, strings.Join(allowed, ", "))})
+			continue
+		}
+		if seen[field.Field] {
+			fieldErrors = append(fieldErrors, FieldError{Field: name,
+				Message: fmt.Sprintf("duplicate sort field %q", field.Field)})
+		}
+		seen[field.Field] = true
+	}
+
+	return fieldErrors
+}
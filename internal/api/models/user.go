@@ -0,0 +1,28 @@
+package api_models
+
+type User struct {
+	UserId   string   `json:"user_id"`
+	Login    string   `json:"login"`
+	Email    string   `json:"email"`
+	Roles    []string `json:"roles"`
+	Disabled bool     `json:"disabled"`
+}
+
+type UnlockUserParams struct {
+	Login string `json:"login"`
+}
+
+type ChangePasswordParams struct {
+	OldPassword string `json:"old_password"`
+	NewPassword string `json:"new_password"`
+}
+
+type ForgotPasswordParams struct {
+	Login string `json:"login"`
+	IP    string `json:"-"`
+}
+
+type ResetPasswordParams struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
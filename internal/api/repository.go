@@ -3,23 +3,49 @@
 package api
 
 import (
+	"context"
 	api_models "vk_test_task/internal/api/models"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 )
 
-// ifacemaker -f actor.go -f auth.go -f film.go -f postgres.go -s Repository -i RepositoryInterface -p api -o ../repository.go -y
+// ifacemaker -f actor.go -f apiKey.go -f auth.go -f film.go -f mfa.go -f role.go -f user.go -f postgres.go -s Repository -i RepositoryInterface -p api -o ../repository.go -y
 type RepositoryInterface interface {
-	CreateActor(params api_models.CreateActorParams) error
-	GetActors() (api_models.GetActorsResponse, error)
-	UpdateActor(params api_models.UpdateActorParams) error
-	DeleteActor(actorId string) error
-	SignIn(login string) (api_models.SignInRepositoryResponse, error)
-	SignUp(login, hashPassword, userId string) error
-	CreateFilm(params api_models.CreateFilmParams) error
-	GetFilms(params api_models.GetFilmsParams) (api_models.GetFilmsResponse, error)
-	UpdateFilm(params api_models.UpdateFilmParams) error
-	DeleteFilm(filmId string) error
-	SearchFilmByName(name string) (api_models.SearchFilmResponse, error)
-	SearchFilmByActorName(actorName string) (api_models.SearchFilmResponse, error)
+	CreateActor(ctx context.Context, params api_models.CreateActorParams) error
+	GetActors(ctx context.Context, page api_models.Page) (api_models.GetActorsResponse, error)
+	GetActorDetails(ctx context.Context, actorId string) (api_models.ActorDetails, error)
+	UpdateActor(ctx context.Context, params api_models.UpdateActorParams) error
+	GetActorVersion(ctx context.Context, actorId string) (int, error)
+	DeleteActor(ctx context.Context, actorId string, version int) error
+	CreateAPIKey(ctx context.Context, key api_models.APIKey, keyHash string) error
+	GetAPIKeys(ctx context.Context) ([]api_models.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (api_models.APIKey, error)
+	TouchAPIKey(ctx context.Context, id string) error
+	RevokeAPIKey(ctx context.Context, id string) error
+	SignIn(ctx context.Context, login string) (api_models.SignInRepositoryResponse, error)
+	SignUp(ctx context.Context, login, hashPassword, userId, email string, roles ...string) error
+	CreateFilm(ctx context.Context, params api_models.CreateFilmParams) error
+	GetFilms(ctx context.Context, page api_models.Page) (api_models.GetFilmsResponse, error)
+	GetFilmDetails(ctx context.Context, filmId string) (api_models.FilmDetails, error)
+	UpdateFilm(ctx context.Context, params api_models.UpdateFilmParams) error
+	GetFilmVersion(ctx context.Context, filmId string) (int, error)
+	DeleteFilm(ctx context.Context, filmId string, version int) error
+	SearchFilmByName(ctx context.Context, name string, page api_models.Page) (api_models.SearchFilmResponse, error)
+	SearchFilmByActorName(ctx context.Context, actorName string, page api_models.Page) (api_models.SearchFilmResponse, error)
+	GetMFA(ctx context.Context, userId string) (api_models.MFA, error)
+	SaveMFASecret(ctx context.Context, userId, secret string) error
+	EnableMFA(ctx context.Context, userId string, recoveryCodeHashes []string) error
+	DisableMFA(ctx context.Context, userId string) error
+	UseRecoveryCode(ctx context.Context, userId, codeHash string) (bool, error)
+	GetUserAccess(ctx context.Context, userId string) (api_models.UserAccess, error)
+	GetRoles(ctx context.Context) (api_models.GetRolesResponse, error)
+	GrantRole(ctx context.Context, userId, role string) error
+	RevokeRole(ctx context.Context, userId, role string) error
+	GetUserByLogin(ctx context.Context, login string) (api_models.User, error)
+	GetUserById(ctx context.Context, userId string) (api_models.User, error)
+	ListUsers(ctx context.Context) ([]api_models.User, error)
+	GetPasswordHash(ctx context.Context, userId string) (string, error)
+	UpdatePassword(ctx context.Context, userId, hashPassword string) error
+	SetUserDisabled(ctx context.Context, userId string, disabled bool) error
+	Ping(ctx context.Context) error
 }
@@ -2,18 +2,19 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
-	"github.com/jackc/pgx/v5"
+	"unicode/utf8"
 	api_models "vk_test_task/internal/api/models"
 	"vk_test_task/internal/common"
 )
 
-func (r Repository) CreateActor(params api_models.CreateActorParams) error {
+func (r Repository) CreateActor(ctx context.Context, params api_models.CreateActorParams) error {
 	if params.ActorId == "" {
 		return errors.New("invalid actorId")
 	}
-	if len(params.Name) > common.ACTOR_NAME_MAXSIZE {
+	if utf8.RuneCountInString(params.Name) > common.ACTOR_NAME_MAXSIZE {
 		return errors.New("name is too long")
 	}
 	if params.Sex != common.ACTOR_SEX_MALE &&
@@ -23,7 +24,7 @@ func (r Repository) CreateActor(params api_models.CreateActorParams) error {
 
 	query := `insert into actor(id, name, sex, birth) values($1, $2, $3, $4)`
 
-	_, err := r.db.Exec(query, params.ActorId, params.Name, params.Sex, params.Birth)
+	_, err := r.db.ExecContext(ctx, query, params.ActorId, params.Name, params.Sex, params.Birth)
 
 	if err != nil {
 		return fmt.Errorf("repository error: %s", err.Error())
@@ -31,16 +32,27 @@ func (r Repository) CreateActor(params api_models.CreateActorParams) error {
 	return nil
 }
 
-func (r Repository) GetActors() (api_models.GetActorsResponse, error) {
-	query := `select actor.*, array_agg(film.name) as films
+func (r Repository) GetActors(ctx context.Context, page api_models.Page) (api_models.GetActorsResponse, error) {
+	columns, desc := keysetColumns(actorSortColumns, "actor.id", page.Sort)
+	condition, args, err := keysetCondition(columns, desc, page.After, 1)
+	if err != nil {
+		return api_models.GetActorsResponse{}, err
+	}
+
+	query := fmt.Sprintf(`select actor.name, actor.sex, actor.birth, actor.version, actor.id,
+		coalesce(json_agg(json_build_object('film_id', film.id, 'name', film.name, 'release_date', film.date_released)
+			order by film.date_released, film.id) filter (where film.id is not null), '[]') as films
 	from actor
 	left join film_actor on actor.id = film_actor.actor_id
 	left join film on film_actor.film_id = film.id
-	group by actor.id`
+	where %s
+	group by actor.id
+	order by %s
+	limit %d`, condition, orderBy(columns, desc), page.Limit+1)
 
-	var response api_models.GetActorsResponse
+	response := api_models.GetActorsResponse{Response: []api_models.ActorAndFilms{}}
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return api_models.GetActorsResponse{}, fmt.Errorf("repository error: %s", err.Error())
 	}
@@ -50,8 +62,8 @@ func (r Repository) GetActors() (api_models.GetActorsResponse, error) {
 		var actorAndFilms api_models.ActorAndFilms
 
 		err = rows.Scan(&actorAndFilms.Name, &actorAndFilms.Sex,
-			&actorAndFilms.Birth, &actorAndFilms.ActorId,
-			&actorAndFilms.Films)
+			&actorAndFilms.Birth, &actorAndFilms.Version, &actorAndFilms.ActorId,
+			jsonColumn{&actorAndFilms.Films})
 
 		if err != nil {
 			return api_models.GetActorsResponse{}, fmt.Errorf("repository error: %s", err.Error())
@@ -60,48 +72,120 @@ func (r Repository) GetActors() (api_models.GetActorsResponse, error) {
 		response.Response = append(response.Response, actorAndFilms)
 	}
 
+	if len(response.Response) > page.Limit {
+		response.Response = response.Response[:page.Limit]
+		last := response.Response[len(response.Response)-1]
+		cursor := api_models.Cursor{Sort: page.Sort.String(), Values: []string{last.Name}, Id: last.ActorId}
+		response.NextCursor = cursor.Encode()
+	}
+
+	response.TotalEstimate, err = r.estimateRows(ctx, "actor")
+	if err != nil {
+		return api_models.GetActorsResponse{}, err
+	}
+
 	return response, nil
 }
 
-func (r Repository) UpdateActor(params api_models.UpdateActorParams) error {
-	if params.ActorId == "" {
-		return fmt.Errorf("repository error: invalid actor id")
+// actorSortColumns - актеры сортируются только по имени, см. api_models.ActorSort
+var actorSortColumns = map[string]sortColumn{
+	"name": {expr: "actor.name", cast: "text"},
+}
+
+func (r Repository) GetActorDetails(ctx context.Context, actorId string) (api_models.ActorDetails, error) {
+	if actorId == "" {
+		return api_models.ActorDetails{}, fmt.Errorf("repository error: invalid actor id")
 	}
-	var queryName, querySex, queryBirth = "name", "sex", "birth"
-	if params.Name != "" {
-		queryName = "@name"
+
+	query := `select name, sex, birth, version, id from actor where id = $1`
+
+	actor := api_models.ActorDetails{Films: []api_models.FilmRef{}}
+
+	err := r.db.QueryRowContext(ctx, query, actorId).Scan(&actor.Name, &actor.Sex, &actor.Birth, &actor.Version, &actor.ActorId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return api_models.ActorDetails{}, api_models.NewNotFoundError("actor not found")
 	}
-	if params.Sex != 0 {
-		querySex = "@sex"
+	if err != nil {
+		return api_models.ActorDetails{}, fmt.Errorf("repository error: %s", err.Error())
 	}
-	if !params.Birth.IsZero() {
-		queryBirth = "@birth"
+
+	filmsQuery := `select film.id, film.name, film.date_released
+	from film_actor
+	join film on film.id = film_actor.film_id
+	where film_actor.actor_id = $1
+	order by film.date_released, film.id`
+
+	rows, err := r.db.QueryContext(ctx, filmsQuery, actorId)
+	if err != nil {
+		return api_models.ActorDetails{}, fmt.Errorf("repository error: %s", err.Error())
 	}
+	defer rows.Close()
 
-	query := fmt.Sprintf(`update actor set name = %s, sex = %s, birth = %s where id = @id`,
-		queryName, querySex, queryBirth)
+	for rows.Next() {
+		var film api_models.FilmRef
+		if err = rows.Scan(&film.FilmId, &film.Name, &film.ReleaseDate); err != nil {
+			return api_models.ActorDetails{}, fmt.Errorf("repository error: %s", err.Error())
+		}
+		actor.Films = append(actor.Films, film)
+	}
+	if err = rows.Err(); err != nil {
+		return api_models.ActorDetails{}, fmt.Errorf("repository error: %s", err.Error())
+	}
 
-	args := pgx.NamedArgs{
-		"name":  params.Name,
-		"sex":   params.Sex,
-		"birth": params.Birth,
-		"id":    params.ActorId,
+	return actor, nil
+}
+
+func (r Repository) UpdateActor(ctx context.Context, params api_models.UpdateActorParams) error {
+	if params.ActorId == "" {
+		return fmt.Errorf("repository error: invalid actor id")
+	}
+
+	var set setClause
+	if params.Name.Set {
+		set.add("name", params.Name.Value)
+	}
+	if params.Sex.Set {
+		set.add("sex", params.Sex.Value)
+	}
+	if params.Birth.Set {
+		set.add("birth", params.Birth.Value)
 	}
 
-	_, err := r.db.Exec(query, args)
+	set.bumpVersion()
+	query := fmt.Sprintf(`update actor set %s where id = $%d and version = $%d`,
+		set.String(), len(set.args)+1, len(set.args)+2)
 
+	result, err := r.db.ExecContext(ctx, query, append(set.args, params.ActorId, params.Version)...)
 	if err != nil {
 		return fmt.Errorf("repository error: %s", err.Error())
 	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	} else if affected == 0 {
+		return api_models.NewConflictError("actor was modified concurrently")
+	}
 
 	return nil
 }
 
-func (r Repository) DeleteActor(actorId string) error {
+func (r Repository) GetActorVersion(ctx context.Context, actorId string) (int, error) {
+	var version int
+	err := r.db.QueryRowContext(ctx, `select version from actor where id = $1`, actorId).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, api_models.NewNotFoundError("actor not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return version, nil
+}
+
+func (r Repository) DeleteActor(ctx context.Context, actorId string, version int) error {
 	if actorId == "" {
 		return fmt.Errorf("repository err: invalid actor id")
 	}
-	tx, err := r.db.BeginTx(context.Background(), nil)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("repository error: transaction error: %s", err.Error())
 	}
@@ -109,17 +193,22 @@ func (r Repository) DeleteActor(actorId string) error {
 
 	relationQuery := `delete from film_actor where actor_id = $1`
 
-	_, err = tx.Exec(relationQuery, actorId)
+	_, err = tx.ExecContext(ctx, relationQuery, actorId)
 	if err != nil {
 		return fmt.Errorf("repository error: %s", err.Error())
 	}
 
-	query := `delete from actor where id = $1`
+	query := `delete from actor where id = $1 and version = $2`
 
-	_, err = tx.Exec(query, actorId)
+	result, err := tx.ExecContext(ctx, query, actorId, version)
 	if err != nil {
 		return fmt.Errorf("repository error: %s", err.Error())
 	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	} else if affected == 0 {
+		return api_models.NewConflictError("actor was modified concurrently")
+	}
 
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("repository error: %s", err.Error())
@@ -1,6 +1,8 @@
 package postgres
 
 import (
+	"context"
+	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
@@ -82,7 +84,7 @@ func TestRepository_CreateActor(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehaviour(testCase.args)
 
-			err = r.CreateActor(testCase.args)
+			err = r.CreateActor(context.Background(), testCase.args)
 
 			if testCase.wantErr {
 				assert.Error(t, err)
@@ -107,10 +109,50 @@ func TestRepository_GetActors(t *testing.T) {
 	r := Repository{db: sqlx.NewDb(db, "pgx")}
 
 	t.Run("default", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "name", "sex", "birth", "films"}).AddRow("", "", "", "", "")
-		mock.ExpectQuery(`select actor.*, array_agg`).WithoutArgs().WillReturnRows(rows)
+		rows := sqlmock.NewRows([]string{"name", "sex", "birth", "version", "id", "films"}).
+			AddRow("Brad Pitt", 1, time.Now(), 4, "id", []byte(`[{"film_id":"f1","name":"Mr. & Mrs. Smith, \"director's cut\"","release_date":"2005-06-07"}]`))
+		mock.ExpectQuery(`select actor.name, .*json_agg.*where true`).WithoutArgs().WillReturnRows(rows)
+		mock.ExpectQuery(`select case when reltuples < 0`).WithArgs("actor").
+			WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(1))
+
+		response, err := r.GetActors(context.Background(), api_models.Page{Sort: api_models.ActorSort, Limit: 20})
+		assert.NoError(t, err)
+		assert.Empty(t, response.NextCursor)
+		assert.Equal(t, 4, response.Response[0].Version)
+		assert.Equal(t, []api_models.FilmRef{
+			{FilmId: "f1", Name: `Mr. & Mrs. Smith, "director's cut"`, ReleaseDate: "2005-06-07"},
+		}, response.Response[0].Films)
 
-		_, err = r.GetActors()
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("next page", func(t *testing.T) {
+		birth := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"name", "sex", "birth", "version", "id", "films"}).
+			AddRow("Brad Pitt", 1, birth, 1, "id2", nil).
+			AddRow("Cate Blanchett", 2, birth, 1, "id3", nil)
+		mock.ExpectQuery(`where \(\(actor.name > \$1::text::text\) or \(actor.name = \$1::text::text and actor.id > \$2::text::text\)\)
+	group by actor.id
+	order by actor.name, actor.id
+	limit 2`).WithArgs("Angelina Jolie", "id1").WillReturnRows(rows)
+		mock.ExpectQuery(`select case when reltuples < 0`).WithArgs("actor").
+			WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(nil))
+		mock.ExpectQuery(`select count\(\*\) from actor`).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+		after := api_models.Cursor{Sort: "name", Values: []string{"Angelina Jolie"}, Id: "id1"}
+		response, err := r.GetActors(context.Background(), api_models.Page{Sort: api_models.ActorSort, Limit: 1, After: &after})
+
+		assert.NoError(t, err)
+		assert.Len(t, response.Response, 1)
+		assert.Equal(t, "Brad Pitt", response.Response[0].Name)
+		assert.Equal(t, int64(3), response.TotalEstimate)
+
+		next, err := api_models.DecodeCursor(response.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, api_models.Cursor{Sort: "name", Values: []string{"Brad Pitt"}, Id: "id2"}, next)
 
 		if err = mock.ExpectationsWereMet(); err != nil {
 			t.Fatal(err)
@@ -133,26 +175,49 @@ func TestRepository_DeleteActor(t *testing.T) {
 		name          string
 		mockBehaviour mockBehaviour
 		args          api_models.DeleteActorParams
+		version       int
 		wantErr       bool
+		wantErrIs     error
 	}{
 		{
 			name: "default",
 			args: api_models.DeleteActorParams{
 				ActorId: "id1",
 			},
+			version: 2,
 			mockBehaviour: func(params api_models.DeleteActorParams) {
 				mock.ExpectBegin()
 
 				mock.ExpectExec("delete from film_actor").
 					WithArgs(params.ActorId).WillReturnResult(sqlmock.NewResult(1, 1))
 
-				mock.ExpectExec("delete from actor").
-					WithArgs(params.ActorId).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`delete from actor where id = \$1 and version = \$2`).
+					WithArgs(params.ActorId, 2).WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit()
 			},
 			wantErr: false,
 		},
+		{
+			name: "version changed",
+			args: api_models.DeleteActorParams{
+				ActorId: "id1",
+			},
+			version: 2,
+			mockBehaviour: func(params api_models.DeleteActorParams) {
+				mock.ExpectBegin()
+
+				mock.ExpectExec("delete from film_actor").
+					WithArgs(params.ActorId).WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectExec("delete from actor").
+					WithArgs(params.ActorId, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.ExpectRollback()
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrConflict,
+		},
 		{
 			name: "no actor_id",
 			args: api_models.DeleteActorParams{
@@ -168,10 +233,13 @@ func TestRepository_DeleteActor(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehaviour(testCase.args)
 
-			err = r.DeleteActor(testCase.args.ActorId)
+			err = r.DeleteActor(context.Background(), testCase.args.ActorId, testCase.version)
 
 			if testCase.wantErr {
 				assert.Error(t, err)
+				if testCase.wantErrIs != nil {
+					assert.ErrorIs(t, err, testCase.wantErrIs)
+				}
 			} else {
 				if err = mock.ExpectationsWereMet(); err != nil {
 					t.Fatal(err)
@@ -184,7 +252,6 @@ func TestRepository_DeleteActor(t *testing.T) {
 }
 
 func TestRepository_UpdateActor(t *testing.T) {
-
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("An error occurred while creating mock: %s", err)
@@ -200,35 +267,67 @@ func TestRepository_UpdateActor(t *testing.T) {
 		mockBehaviour mockBehaviour
 		args          api_models.UpdateActorParams
 		wantErr       bool
+		wantErrIs     error
 	}{
 		{
 			name: "default",
 			args: api_models.UpdateActorParams{
 				ActorId: "id1",
-				Name:    "name",
-				Sex:     1,
-				Birth:   time.Unix(202020, 0),
+				Name:    api_models.NewOptional("name"),
+				Sex:     api_models.NewOptional(1),
+				Birth:   api_models.NewOptional(time.Unix(202020, 0)),
+				Version: 3,
 			},
 			mockBehaviour: func(params api_models.UpdateActorParams) {
+				mock.ExpectExec(`update actor set name = \$1, sex = \$2, birth = \$3, version = version \+ 1 where id = \$4 and version = \$5`).
+					WithArgs("name", 1, time.Unix(202020, 0), "id1", 3).WillReturnResult(sqlmock.NewResult(0, 1))
 			},
 			wantErr: false,
 		},
 		{
-			name: "default",
+			name: "only sex",
 			args: api_models.UpdateActorParams{
 				ActorId: "id1",
+				Sex:     api_models.NewOptional(2),
+				Version: 1,
 			},
 			mockBehaviour: func(params api_models.UpdateActorParams) {
+				mock.ExpectExec(`update actor set sex = \$1, version = version \+ 1 where id = \$2 and version = \$3`).
+					WithArgs(2, "id1", 1).WillReturnResult(sqlmock.NewResult(0, 1))
 			},
 			wantErr: false,
 		},
+		{
+			name: "empty patch",
+			args: api_models.UpdateActorParams{
+				ActorId: "id1",
+				Version: 1,
+			},
+			mockBehaviour: func(params api_models.UpdateActorParams) {
+				mock.ExpectExec(`update actor set version = version \+ 1 where id = \$1 and version = \$2`).
+					WithArgs("id1", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "version changed",
+			args: api_models.UpdateActorParams{
+				ActorId: "id1",
+				Name:    api_models.NewOptional("name"),
+				Version: 1,
+			},
+			mockBehaviour: func(params api_models.UpdateActorParams) {
+				mock.ExpectExec(`update actor set name = \$1, version = version \+ 1 where id = \$2 and version = \$3`).
+					WithArgs("name", "id1", 1).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrConflict,
+		},
 		{
 			name: "no actor_id",
 			args: api_models.UpdateActorParams{
 				ActorId: "",
-				Name:    "name",
-				Sex:     1,
-				Birth:   time.Unix(202020, 0),
+				Name:    api_models.NewOptional("name"),
 			},
 			mockBehaviour: func(params api_models.UpdateActorParams) {
 			},
@@ -240,17 +339,123 @@ func TestRepository_UpdateActor(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehaviour(testCase.args)
 
-			err = r.UpdateActor(testCase.args)
+			err = r.UpdateActor(context.Background(), testCase.args)
 
 			if testCase.wantErr {
 				assert.Error(t, err)
-			} else {
-				if err = mock.ExpectationsWereMet(); err != nil {
-					t.Fatal(err)
+				if testCase.wantErrIs != nil {
+					assert.ErrorIs(t, err, testCase.wantErrIs)
 				}
+			} else {
 				assert.NoError(t, err)
 			}
-
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
 		})
 	}
 }
+
+func TestRepository_GetActorDetails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	t.Run("default", func(t *testing.T) {
+		birth := time.Date(1976, 8, 19, 0, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"name", "sex", "birth", "version", "id"}).
+			AddRow("Sam Worthington", 1, birth, 4, "id")
+		mock.ExpectQuery(`select name, sex, birth, version, id from actor`).WithArgs("id").WillReturnRows(rows)
+		films := sqlmock.NewRows([]string{"id", "name", "date_released"}).
+			AddRow("f1", "Terminator Salvation", "2009-05-14").
+			AddRow("f2", "Avatar", "2009-12-10")
+		mock.ExpectQuery(`select film.id, film.name, film.date_released\s+from film_actor`).WithArgs("id").WillReturnRows(films)
+
+		actor, err := r.GetActorDetails(context.Background(), "id")
+
+		assert.NoError(t, err)
+		assert.Equal(t, api_models.ActorDetails{
+			ActorId: "id",
+			Name:    "Sam Worthington",
+			Sex:     1,
+			Birth:   birth,
+			Version: 4,
+			Films: []api_models.FilmRef{
+				{FilmId: "f1", Name: "Terminator Salvation", ReleaseDate: "2009-05-14"},
+				{FilmId: "f2", Name: "Avatar", ReleaseDate: "2009-12-10"},
+			},
+		}, actor)
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("interrupted film rows", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"name", "sex", "birth", "version", "id"}).
+			AddRow("Sam Worthington", 1, time.Date(1976, 8, 19, 0, 0, 0, 0, time.UTC), 4, "id")
+		mock.ExpectQuery(`from actor where id`).WithArgs("id").WillReturnRows(rows)
+		films := sqlmock.NewRows([]string{"id", "name", "date_released"}).
+			AddRow("f1", "Terminator Salvation", "2009-05-14").
+			AddRow("f2", "Avatar", "2009-12-10").
+			RowError(1, fmt.Errorf("connection reset"))
+		mock.ExpectQuery(`from film_actor`).WithArgs("id").WillReturnRows(films)
+
+		_, err := r.GetActorDetails(context.Background(), "id")
+
+		assert.Error(t, err)
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"name", "sex", "birth", "version", "id"})
+		mock.ExpectQuery(`from actor where id`).WithArgs("id").WillReturnRows(rows)
+
+		_, err := r.GetActorDetails(context.Background(), "id")
+
+		assert.ErrorIs(t, err, api_models.ErrNotFound)
+	})
+
+	t.Run("no actor id", func(t *testing.T) {
+		_, err := r.GetActorDetails(context.Background(), "")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRepository_GetActorVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	t.Run("default", func(t *testing.T) {
+		mock.ExpectQuery(`select version from actor where id = \$1`).WithArgs("id").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(5))
+
+		version, err := r.GetActorVersion(context.Background(), "id")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, version)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(`select version from actor`).WithArgs("id").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}))
+
+		_, err := r.GetActorVersion(context.Background(), "id")
+
+		assert.ErrorIs(t, err, api_models.ErrNotFound)
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+)
+
+const apiKeySelect = `select api_key.id, api_key.name, api_key.prefix, api_key.user_id,
+	api_key.expires_at, api_key.last_used_at, api_key.revoked_at, api_key.created_at, api_key_scope.permission
+	from api_key
+	left join api_key_scope on api_key_scope.key_id = api_key.id`
+
+func (r Repository) CreateAPIKey(ctx context.Context, key api_models.APIKey, keyHash string) error {
+	if key.Id == "" || key.UserId == "" {
+		return fmt.Errorf("repository error: invalid api key")
+	}
+	if key.Name == "" || utf8.RuneCountInString(key.Name) > common.API_KEY_NAME_MAXSIZE {
+		return fmt.Errorf("repository error: invalid api key name")
+	}
+	if keyHash == "" {
+		return fmt.Errorf("repository error: invalid api key hash")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository error: transaction error: %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	query := `insert into api_key (id, name, prefix, key_hash, user_id, expires_at) values ($1, $2, $3, $4, $5, $6)`
+
+	_, err = tx.ExecContext(ctx, query, key.Id, key.Name, key.Prefix, keyHash, key.UserId, key.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	for _, scope := range key.Scopes {
+		_, err = tx.ExecContext(ctx, `insert into api_key_scope (key_id, permission) values ($1, $2)`, key.Id, scope)
+		if err != nil {
+			return fmt.Errorf("repository error: %s", err.Error())
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("repository error: transaction error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r Repository) GetAPIKeys(ctx context.Context) ([]api_models.APIKey, error) {
+	query := apiKeySelect + `
+	order by api_key.created_at, api_key.id, api_key_scope.permission`
+
+	return r.queryAPIKeys(ctx, query)
+}
+
+func (r Repository) GetAPIKeyByHash(ctx context.Context, keyHash string) (api_models.APIKey, error) {
+	if keyHash == "" {
+		return api_models.APIKey{}, fmt.Errorf("repository error: invalid api key hash")
+	}
+
+	query := apiKeySelect + `
+	where api_key.key_hash = $1
+	order by api_key_scope.permission`
+
+	keys, err := r.queryAPIKeys(ctx, query, keyHash)
+	if err != nil {
+		return api_models.APIKey{}, err
+	}
+
+	if len(keys) == 0 {
+		return api_models.APIKey{}, api_models.NewNotFoundError("api key not found")
+	}
+
+	return keys[0], nil
+}
+
+// TouchAPIKey обновляет время последнего использования не чаще раза в минуту, чтобы не писать в базу на каждый запрос
+func (r Repository) TouchAPIKey(ctx context.Context, id string) error {
+	query := `update api_key set last_used_at = now()
+	where id = $1 and (last_used_at is null or last_used_at < now() - interval '1 minute')`
+
+	_, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r Repository) RevokeAPIKey(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("repository error: invalid api key id")
+	}
+
+	result, err := r.db.ExecContext(ctx, `update api_key set revoked_at = now() where id = $1 and revoked_at is null`, id)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+	if affected == 0 {
+		return api_models.NewNotFoundError("api key not found")
+	}
+
+	return nil
+}
+
+func (r Repository) queryAPIKeys(ctx context.Context, query string, args ...interface{}) ([]api_models.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %s", err.Error())
+	}
+	defer rows.Close()
+
+	var keys []api_models.APIKey
+
+	for rows.Next() {
+		var key api_models.APIKey
+		var scope *string
+
+		err = rows.Scan(&key.Id, &key.Name, &key.Prefix, &key.UserId,
+			&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt, &scope)
+		if err != nil {
+			return nil, fmt.Errorf("repository error: %s", err.Error())
+		}
+
+		if len(keys) == 0 || keys[len(keys)-1].Id != key.Id {
+			key.Scopes = []string{}
+			keys = append(keys, key)
+		}
+		if scope != nil {
+			last := &keys[len(keys)-1]
+			last.Scopes = append(last.Scopes, *scope)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,403 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+)
+
+func TestRepository_CreateAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	type args struct {
+		key     api_models.APIKey
+		keyHash string
+	}
+
+	testTable := []struct {
+		name          string
+		args          args
+		mockBehaviour func(args args)
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: args{
+				key: api_models.APIKey{
+					Id:     "id",
+					Name:   "importer",
+					Prefix: "vk_abcdefgh",
+					UserId: "userId",
+					Scopes: []string{"film:create", "film:read"},
+				},
+				keyHash: "hash",
+			},
+			mockBehaviour: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`insert into api_key`).
+					WithArgs(args.key.Id, args.key.Name, args.key.Prefix, args.keyHash, args.key.UserId, args.key.ExpiresAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				for _, scope := range args.key.Scopes {
+					mock.ExpectExec(`insert into api_key_scope`).
+						WithArgs(args.key.Id, scope).WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown scope",
+			args: args{
+				key: api_models.APIKey{
+					Id:     "id",
+					Name:   "importer",
+					Prefix: "vk_abcdefgh",
+					UserId: "userId",
+					Scopes: []string{"unknown"},
+				},
+				keyHash: "hash",
+			},
+			mockBehaviour: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`insert into api_key`).
+					WithArgs(args.key.Id, args.key.Name, args.key.Prefix, args.keyHash, args.key.UserId, args.key.ExpiresAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`insert into api_key_scope`).
+					WithArgs(args.key.Id, "unknown").WillReturnError(fmt.Errorf("foreign key violation"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "cyrillic name of max length",
+			args: args{
+				key: api_models.APIKey{
+					Id:     "id",
+					Name:   strings.Repeat("ключ", common.API_KEY_NAME_MAXSIZE/4),
+					Prefix: "vk_abcdefgh",
+					UserId: "userId",
+				},
+				keyHash: "hash",
+			},
+			mockBehaviour: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`insert into api_key`).
+					WithArgs(args.key.Id, args.key.Name, args.key.Prefix, args.keyHash, args.key.UserId, args.key.ExpiresAt).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "no name",
+			args: args{
+				key:     api_models.APIKey{Id: "id", UserId: "userId"},
+				keyHash: "hash",
+			},
+			mockBehaviour: func(args args) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "too long name",
+			args: args{
+				key:     api_models.APIKey{Id: "id", Name: strings.Repeat("к", common.API_KEY_NAME_MAXSIZE+1), UserId: "userId"},
+				keyHash: "hash",
+			},
+			mockBehaviour: func(args args) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.args)
+
+			err := r.CreateAPIKey(context.Background(), testCase.args.key, testCase.args.keyHash)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_GetAPIKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "name", "prefix", "user_id", "expires_at", "last_used_at", "revoked_at", "created_at", "permission"}
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		want          []api_models.APIKey
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			mockBehaviour: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow("1", "importer", "vk_aaaaaaaa", "userId", nil, nil, nil, created, "film:create").
+					AddRow("1", "importer", "vk_aaaaaaaa", "userId", nil, nil, nil, created, "film:read").
+					AddRow("2", "old", "vk_bbbbbbbb", "userId", nil, nil, created, created, nil)
+				mock.ExpectQuery(`select api_key.id, api_key.name, api_key.prefix, api_key.user_id`).
+					WillReturnRows(rows)
+			},
+			want: []api_models.APIKey{
+				{Id: "1", Name: "importer", Prefix: "vk_aaaaaaaa", UserId: "userId", Scopes: []string{"film:create", "film:read"}, CreatedAt: created},
+				{Id: "2", Name: "old", Prefix: "vk_bbbbbbbb", UserId: "userId", Scopes: []string{}, RevokedAt: &created, CreatedAt: created},
+			},
+			wantErr: false,
+		},
+		{
+			name: "db error",
+			mockBehaviour: func() {
+				mock.ExpectQuery(`select api_key.id, api_key.name, api_key.prefix, api_key.user_id`).
+					WillReturnError(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "interrupted rows",
+			mockBehaviour: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow("1", "importer", "vk_aaaaaaaa", "userId", nil, nil, nil, created, "film:create").
+					AddRow("2", "old", "vk_bbbbbbbb", "userId", nil, nil, created, created, nil).
+					RowError(1, fmt.Errorf("connection reset"))
+				mock.ExpectQuery(`select api_key.id, api_key.name, api_key.prefix, api_key.user_id`).
+					WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			keys, err := r.GetAPIKeys(context.Background())
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, keys)
+			}
+		})
+	}
+}
+
+func TestRepository_GetAPIKeyByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	columns := []string{"id", "name", "prefix", "user_id", "expires_at", "last_used_at", "revoked_at", "created_at", "permission"}
+
+	testTable := []struct {
+		name          string
+		keyHash       string
+		mockBehaviour func(keyHash string)
+		want          api_models.APIKey
+		wantErr       bool
+	}{
+		{
+			name:    "default",
+			keyHash: "hash",
+			mockBehaviour: func(keyHash string) {
+				rows := sqlmock.NewRows(columns).
+					AddRow("1", "importer", "vk_aaaaaaaa", "userId", nil, created, nil, created, "film:read")
+				mock.ExpectQuery(`select api_key.id, api_key.name, api_key.prefix, api_key.user_id`).
+					WithArgs(keyHash).WillReturnRows(rows)
+			},
+			want: api_models.APIKey{
+				Id:         "1",
+				Name:       "importer",
+				Prefix:     "vk_aaaaaaaa",
+				UserId:     "userId",
+				Scopes:     []string{"film:read"},
+				LastUsedAt: &created,
+				CreatedAt:  created,
+			},
+			wantErr: false,
+		},
+		{
+			name:    "not found",
+			keyHash: "hash",
+			mockBehaviour: func(keyHash string) {
+				mock.ExpectQuery(`select api_key.id, api_key.name, api_key.prefix, api_key.user_id`).
+					WithArgs(keyHash).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			wantErr: true,
+		},
+		{
+			name:    "no hash",
+			keyHash: "",
+			mockBehaviour: func(keyHash string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.keyHash)
+
+			key, err := r.GetAPIKeyByHash(context.Background(), testCase.keyHash)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, key)
+			}
+		})
+	}
+}
+
+func TestRepository_TouchAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			mockBehaviour: func() {
+				mock.ExpectExec(`update api_key set last_used_at`).
+					WithArgs("id").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "db error",
+			mockBehaviour: func() {
+				mock.ExpectExec(`update api_key set last_used_at`).
+					WithArgs("id").WillReturnError(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			err := r.TouchAPIKey(context.Background(), "id")
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_RevokeAPIKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	testTable := []struct {
+		name          string
+		id            string
+		mockBehaviour func(id string)
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			id:   "id",
+			mockBehaviour: func(id string) {
+				mock.ExpectExec(`update api_key set revoked_at`).
+					WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			id:   "id",
+			mockBehaviour: func(id string) {
+				mock.ExpectExec(`update api_key set revoked_at`).
+					WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+		},
+		{
+			name: "no id",
+			id:   "",
+			mockBehaviour: func(id string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.id)
+
+			err := r.RevokeAPIKey(context.Background(), testCase.id)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
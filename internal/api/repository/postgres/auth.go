@@ -1,19 +1,21 @@
 package postgres
 
 import (
+	"context"
 	"fmt"
+	"unicode/utf8"
 	api_models "vk_test_task/internal/api/models"
 	"vk_test_task/internal/common"
 )
 
-func (r Repository) SignIn(login string) (api_models.SignInRepositoryResponse, error) {
+func (r Repository) SignIn(ctx context.Context, login string) (api_models.SignInRepositoryResponse, error) {
 	if login == "" {
 		return api_models.SignInRepositoryResponse{}, fmt.Errorf("repository error: invalid login")
 	}
 
-	query := `select user_id, password, is_admin from "user"  where login = $1`
+	query := `select user_id, password, disabled from "user"  where login = $1`
 
-	rows, err := r.db.Query(query, login)
+	rows, err := r.db.QueryContext(ctx, query, login)
 	if err != nil {
 		return api_models.SignInRepositoryResponse{}, fmt.Errorf("repository error: %s", err)
 	}
@@ -21,19 +23,26 @@ func (r Repository) SignIn(login string) (api_models.SignInRepositoryResponse, e
 
 	var response api_models.SignInRepositoryResponse
 
-	rows.Next()
+	// только отсутствие логина - неверные данные, сбой базы не должен считаться неудачной попыткой входа
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return api_models.SignInRepositoryResponse{}, fmt.Errorf("repository error: %s", err)
+		}
+		return api_models.SignInRepositoryResponse{}, api_models.NewUnauthorizedError("wrong login or password")
+	}
 
-	err = rows.Scan(&response.UserId, &response.HashPassword, &response.IsAdmin)
+	err = rows.Scan(&response.UserId, &response.HashPassword, &response.Disabled)
 	if err != nil {
-		return api_models.SignInRepositoryResponse{}, fmt.Errorf("wrong login or password")
+		return api_models.SignInRepositoryResponse{}, fmt.Errorf("repository error: %s", err)
 	}
 
 	return response, nil
 }
 
-func (r Repository) SignUp(login, hashPassword, userId string) error {
-	if len(login) > common.LOGIN_MAXSIZE || len(login) < common.LOGIN_MINSIZE {
-		return fmt.Errorf("invalid login")
+// SignUp создает пользователя с ролью viewer и дополнительными ролями roles в одной транзакции
+func (r Repository) SignUp(ctx context.Context, login, hashPassword, userId, email string, roles ...string) error {
+	if utf8.RuneCountInString(login) > common.LOGIN_MAXSIZE || utf8.RuneCountInString(login) < common.LOGIN_MINSIZE {
+		return api_models.NewFieldError("login", fmt.Sprintf("length must be between %d and %d", common.LOGIN_MINSIZE, common.LOGIN_MAXSIZE))
 	}
 	if hashPassword == "" {
 		return fmt.Errorf("invalid password")
@@ -41,23 +50,47 @@ func (r Repository) SignUp(login, hashPassword, userId string) error {
 	if userId == "" {
 		return fmt.Errorf("invalid userId")
 	}
+	if utf8.RuneCountInString(email) > common.EMAIL_MAXSIZE {
+		return api_models.NewFieldError("email", fmt.Sprintf("must be at most %d characters", common.EMAIL_MAXSIZE))
+	}
 
 	checkQuery := `select exists(select 1 from "user" where login = $1)`
 
 	var exists bool
-	r.db.QueryRow(checkQuery, login).Scan(&exists)
+	r.db.QueryRowContext(ctx, checkQuery, login).Scan(&exists)
 
 	if exists {
-		return fmt.Errorf("login already exists")
+		return api_models.NewConflictError("login already exists")
 	}
 
-	query := `insert into "user" (user_id, login, password, is_admin) values ($1, $2, $3, $4)`
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository error: transaction error: %s", err.Error())
+	}
+	defer tx.Rollback()
 
-	_, err := r.db.Exec(query, userId, login, hashPassword, false)
+	query := `insert into "user" (user_id, login, password, email) values ($1, $2, $3, nullif($4, ''))`
 
+	_, err = tx.ExecContext(ctx, query, userId, login, hashPassword, email)
+	if isUniqueViolation(err, "user_email_key") {
+		return api_models.NewConflictError("email already exists")
+	}
 	if err != nil {
 		return fmt.Errorf("repository error: %s", err.Error())
 	}
 
+	roleQuery := `insert into user_role (user_id, role) values ($1, $2)`
+
+	for _, role := range append([]string{common.ROLE_VIEWER}, roles...) {
+		_, err = tx.ExecContext(ctx, roleQuery, userId, role)
+		if err != nil {
+			return fmt.Errorf("repository error: %s", err.Error())
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("repository error: transaction error: %s", err.Error())
+	}
+
 	return nil
 }
@@ -1,10 +1,14 @@
 package postgres
 
 import (
+	"context"
+	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	api_models "vk_test_task/internal/api/models"
 )
 
 func TestRepository_SignIn(t *testing.T) {
@@ -23,13 +27,14 @@ func TestRepository_SignIn(t *testing.T) {
 		mockBehaviour mockBehaviour
 		login         string
 		wantErr       bool
+		wantErrIs     error
 	}{
 		{
 			name:  "default",
 			login: "login",
 			mockBehaviour: func(login string) {
-				rows := sqlmock.NewRows([]string{"user_id", "password", "is_admin"}).AddRow("", "", "")
-				mock.ExpectQuery(`select user_id, password, is_admin`).
+				rows := sqlmock.NewRows([]string{"user_id", "password", "disabled"}).AddRow("", "", false)
+				mock.ExpectQuery(`select user_id, password, disabled`).
 					WithArgs(login).WillReturnRows(rows)
 			},
 			wantErr: false,
@@ -41,16 +46,52 @@ func TestRepository_SignIn(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:  "unknown login",
+			login: "login",
+			mockBehaviour: func(login string) {
+				rows := sqlmock.NewRows([]string{"user_id", "password", "disabled"})
+				mock.ExpectQuery(`select user_id, password, disabled`).
+					WithArgs(login).WillReturnRows(rows)
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrUnauthorized,
+		},
+		{
+			name:  "db error",
+			login: "login",
+			mockBehaviour: func(login string) {
+				mock.ExpectQuery(`select user_id, password, disabled`).
+					WithArgs(login).WillReturnError(fmt.Errorf("connection refused"))
+			},
+			wantErr: true,
+		},
+		{
+			name:  "interrupted rows",
+			login: "login",
+			mockBehaviour: func(login string) {
+				rows := sqlmock.NewRows([]string{"user_id", "password", "disabled"}).
+					AddRow("", "", false).RowError(0, fmt.Errorf("connection reset"))
+				mock.ExpectQuery(`select user_id, password, disabled`).
+					WithArgs(login).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehaviour(testCase.login)
 
-			_, err := r.SignIn(testCase.login)
+			_, err := r.SignIn(context.Background(), testCase.login)
 
 			if testCase.wantErr {
 				assert.Error(t, err)
+				if testCase.wantErrIs != nil {
+					assert.ErrorIs(t, err, testCase.wantErrIs)
+				} else {
+					assert.NotErrorIs(t, err, api_models.ErrUnauthorized)
+				}
 			} else {
 				if err = mock.ExpectationsWereMet(); err != nil {
 					t.Fatal(err)
@@ -77,6 +118,8 @@ func TestRepository_SignUp(t *testing.T) {
 		login        string
 		hashPassword string
 		userId       string
+		email        string
+		roles        []string
 	}
 
 	testTable := []struct {
@@ -84,6 +127,7 @@ func TestRepository_SignUp(t *testing.T) {
 		mockBehaviour mockBehaviour
 		args          args
 		wantErr       bool
+		wantErrIs     error
 	}{
 		{
 			name: "default",
@@ -91,18 +135,100 @@ func TestRepository_SignUp(t *testing.T) {
 				login:        "login",
 				hashPassword: "hp",
 				userId:       "userid",
+				email:        "user@example.com",
 			},
 			mockBehaviour: func(login, hashPasword, userId string) {
 				rows := sqlmock.NewRows([]string{"exists"}).AddRow("")
 				mock.ExpectQuery(`select exists`).
 					WithArgs(login).WillReturnRows(rows)
 
+				mock.ExpectBegin()
 				mock.ExpectExec("insert into \"user\"").
-					WithArgs(userId, login, hashPasword, false).
+					WithArgs(userId, login, hashPasword, "user@example.com").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("insert into user_role").
+					WithArgs(userId, "viewer").
 					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
 			},
 			wantErr: false,
 		},
+		{
+			name: "with admin role",
+			args: args{
+				login:        "login",
+				hashPassword: "hp",
+				userId:       "userid",
+				roles:        []string{"admin"},
+			},
+			mockBehaviour: func(login, hashPasword, userId string) {
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow("")
+				mock.ExpectQuery(`select exists`).
+					WithArgs(login).WillReturnRows(rows)
+
+				mock.ExpectBegin()
+				mock.ExpectExec("insert into \"user\"").
+					WithArgs(userId, login, hashPasword, "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("insert into user_role").
+					WithArgs(userId, "viewer").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("insert into user_role").
+					WithArgs(userId, "admin").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown role rolls back",
+			args: args{
+				login:        "login",
+				hashPassword: "hp",
+				userId:       "userid",
+				roles:        []string{"unknown"},
+			},
+			mockBehaviour: func(login, hashPasword, userId string) {
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow("")
+				mock.ExpectQuery(`select exists`).
+					WithArgs(login).WillReturnRows(rows)
+
+				mock.ExpectBegin()
+				mock.ExpectExec("insert into \"user\"").
+					WithArgs(userId, login, hashPasword, "").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("insert into user_role").
+					WithArgs(userId, "viewer").
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec("insert into user_role").
+					WithArgs(userId, "unknown").
+					WillReturnError(fmt.Errorf("foreign key violation"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "email already exists",
+			args: args{
+				login:        "login",
+				hashPassword: "hp",
+				userId:       "userid",
+				email:        "user@example.com",
+			},
+			mockBehaviour: func(login, hashPasword, userId string) {
+				rows := sqlmock.NewRows([]string{"exists"}).AddRow("")
+				mock.ExpectQuery(`select exists`).
+					WithArgs(login).WillReturnRows(rows)
+
+				mock.ExpectBegin()
+				mock.ExpectExec("insert into \"user\"").
+					WithArgs(userId, login, hashPasword, "user@example.com").
+					WillReturnError(&pgconn.PgError{Code: "23505", ConstraintName: "user_email_key"})
+				mock.ExpectRollback()
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrConflict,
+		},
 		{
 			name: "no login",
 			args: args{
@@ -142,10 +268,13 @@ func TestRepository_SignUp(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehaviour(testCase.args.login, testCase.args.hashPassword, testCase.args.userId)
 
-			err = r.SignUp(testCase.args.login, testCase.args.hashPassword, testCase.args.userId)
+			err = r.SignUp(context.Background(), testCase.args.login, testCase.args.hashPassword, testCase.args.userId, testCase.args.email, testCase.args.roles...)
 
 			if testCase.wantErr {
 				assert.Error(t, err)
+				if testCase.wantErrIs != nil {
+					assert.ErrorIs(t, err, testCase.wantErrIs)
+				}
 			} else {
 				if err = mock.ExpectationsWereMet(); err != nil {
 					t.Fatal(err)
@@ -2,19 +2,20 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
-	"github.com/jackc/pgx/v5"
+	"strconv"
 	"strings"
 	api_models "vk_test_task/internal/api/models"
-	"vk_test_task/internal/common"
 )
 
-func (r Repository) CreateFilm(params api_models.CreateFilmParams) error {
+func (r Repository) CreateFilm(ctx context.Context, params api_models.CreateFilmParams) error {
 	if params.FilmId == "" {
 		return fmt.Errorf("repository error: invalid film id")
 	}
 
-	tx, err := r.db.BeginTx(context.Background(), nil)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("repository error: transaction error: %s", err.Error())
 	}
@@ -22,36 +23,15 @@ func (r Repository) CreateFilm(params api_models.CreateFilmParams) error {
 
 	query := `insert into film(name, description, date_released, rate, id) values ($1, $2, $3, $4, $5)`
 
-	_, err = tx.Exec(query, params.Name, params.Description, params.ReleaseDate, params.Rate, params.FilmId)
+	_, err = tx.ExecContext(ctx, query, params.Name, params.Description, params.ReleaseDate, params.Rate, params.FilmId)
 
 	if err != nil {
 		return fmt.Errorf("repository error: %s", err.Error())
 	}
 
-	if len(params.Actors) == 0 {
-		if err = tx.Commit(); err != nil {
-			return fmt.Errorf("repository error: transaction error: %s", err.Error())
-		}
-		return nil
-	}
-
-	relationQuery := `insert into film_actor(film_id, actor_id) values`
-
-	for i := 0; i < len(params.Actors); i++ {
-		relationQuery += fmt.Sprintf(` ($1, $%d),`, i+2)
-	}
-	if len(params.Actors) > 0 {
-		relationQuery = strings.TrimSuffix(relationQuery, ",")
-	}
-	args := []interface{}{params.FilmId}
-	for _, v := range params.Actors {
-		args = append(args, v)
-	}
-	_, err = tx.Exec(relationQuery, args...)
-
+	err = insertFilmActors(ctx, tx, params.FilmId, params.Actors, "actors")
 	if err != nil {
-		fmt.Println(relationQuery, append([]string{params.FilmId}, params.Actors...))
-		return fmt.Errorf("repository error: %s", err.Error())
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -61,103 +41,152 @@ func (r Repository) CreateFilm(params api_models.CreateFilmParams) error {
 	return nil
 }
 
-func (r Repository) GetFilms(params api_models.GetFilmsParams) (api_models.GetFilmsResponse, error) {
-	var queryAscending, querySortBy = "desc", "rate"
-	if params.IsAscending == common.SORT_FILM_ASC {
-		queryAscending = ""
-	}
-
-	switch params.SortBy {
-	case common.SORT_FILM_BY_NAME:
-		querySortBy = "name"
-	case common.SORT_FILM_BY_RELEASE_DATE:
-		querySortBy = "date_released"
+func (r Repository) GetFilms(ctx context.Context, page api_models.Page) (api_models.GetFilmsResponse, error) {
+	columns, desc := keysetColumns(filmSortColumns, "film.id", page.Sort)
+	condition, args, err := keysetCondition(columns, desc, page.After, 1)
+	if err != nil {
+		return api_models.GetFilmsResponse{}, err
 	}
 
-	query := fmt.Sprintf(`select film.*, array_agg(actor.name) as actors
+	query := fmt.Sprintf(`select %s
 	from film
 	left join film_actor on film.id = film_actor.film_id
 	left join actor on actor.id = film_actor.actor_id
-	group by film.id, film.%s
-    order by film.%s %s`, querySortBy, querySortBy, queryAscending)
+	where %s
+	group by film.id
+	order by %s
+	limit %d`, filmListColumns, condition, orderBy(columns, desc), page.Limit+1)
+
+	films, err := r.queryFilms(ctx, query, args...)
+	if err != nil {
+		return api_models.GetFilmsResponse{}, err
+	}
 
 	var response api_models.GetFilmsResponse
+	response.Response, response.NextCursor = filmsPage(films, page)
 
-	rows, err := r.db.Query(query)
+	response.TotalEstimate, err = r.estimateRows(ctx, "film")
 	if err != nil {
-		return api_models.GetFilmsResponse{}, fmt.Errorf("repository error: %s", err.Error())
+		return api_models.GetFilmsResponse{}, err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var filmAndActors api_models.FilmAndActors
+	return response, nil
+}
 
-		err = rows.Scan(&filmAndActors.Name, &filmAndActors.Description,
-			&filmAndActors.ReleaseDate, &filmAndActors.Rate, &filmAndActors.FilmId,
-			&filmAndActors.Actors)
+func (r Repository) GetFilmDetails(ctx context.Context, filmId string) (api_models.FilmDetails, error) {
+	if filmId == "" {
+		return api_models.FilmDetails{}, fmt.Errorf("repository error: invalid film id")
+	}
 
-		if err != nil {
-			return api_models.GetFilmsResponse{}, fmt.Errorf("repository error: %s", err.Error())
-		}
+	query := `select name, description, date_released, rate, version, id from film where id = $1`
 
-		response.Response = append(response.Response, filmAndActors)
+	film := api_models.FilmDetails{Actors: []api_models.ActorRef{}}
+
+	err := r.db.QueryRowContext(ctx, query, filmId).Scan(&film.Name, &film.Description,
+		&film.ReleaseDate, &film.Rate, &film.Version, &film.FilmId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return api_models.FilmDetails{}, api_models.NewNotFoundError("film not found")
+	}
+	if err != nil {
+		return api_models.FilmDetails{}, fmt.Errorf("repository error: %s", err.Error())
 	}
 
-	return response, nil
+	actorsQuery := `select actor.id, actor.name
+	from film_actor
+	join actor on actor.id = film_actor.actor_id
+	where film_actor.film_id = $1
+	order by actor.name, actor.id`
+
+	rows, err := r.db.QueryContext(ctx, actorsQuery, filmId)
+	if err != nil {
+		return api_models.FilmDetails{}, fmt.Errorf("repository error: %s", err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var actor api_models.ActorRef
+		if err = rows.Scan(&actor.ActorId, &actor.Name); err != nil {
+			return api_models.FilmDetails{}, fmt.Errorf("repository error: %s", err.Error())
+		}
+		film.Actors = append(film.Actors, actor)
+	}
+	if err = rows.Err(); err != nil {
+		return api_models.FilmDetails{}, fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return film, nil
 }
 
-func (r Repository) UpdateFilm(params api_models.UpdateFilmParams) error {
+func (r Repository) UpdateFilm(ctx context.Context, params api_models.UpdateFilmParams) error {
 	if params.FilmId == "" {
 		return fmt.Errorf("repository error: invalid filmId")
 	}
 
-	tx, err := r.db.BeginTx(context.Background(), nil)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("repository error: transaction error: %s", err.Error())
 	}
 	defer tx.Rollback()
 
-	var name, description, releaseDate, rate = "name", "description", "date_released", "rate"
-	if params.Name != "" {
-		name = "@name"
+	var set setClause
+	if params.Name.Set {
+		set.add("name", params.Name.Value)
 	}
-	if params.Description != "" {
-		description = "@description"
+	if params.Description.Set {
+		// null очищает описание, пустая строка вместо NULL, чтобы чтение не ломалось
+		set.add("description", params.Description.Value)
 	}
-	if !params.ReleaseDate.IsZero() {
-		releaseDate = "@date_released"
+	if params.ReleaseDate.Set {
+		set.add("date_released", params.ReleaseDate.Value)
 	}
-	if params.Rate == 0 {
-		rate = "@rate"
+	if params.Rate.Set {
+		set.add("rate", params.Rate.Value)
 	}
 
-	query := fmt.Sprintf(`update film set 
-                name = %s, description = %s, date_released = %s, rate = %s where id = @id`,
-		name, description, releaseDate, rate)
-
-	args := pgx.NamedArgs{
-		"name":          params.Name,
-		"description":   params.Description,
-		"date_released": params.ReleaseDate,
-		"id":            params.FilmId,
-	}
+	// версия растет при любом изменении, в том числе только состава актеров
+	set.bumpVersion()
+	query := fmt.Sprintf(`update film set %s where id = $%d and version = $%d`,
+		set.String(), len(set.args)+1, len(set.args)+2)
 
-	_, err = tx.Exec(query, args)
+	result, err := tx.ExecContext(ctx, query, append(set.args, params.FilmId, params.Version)...)
 	if err != nil {
 		return fmt.Errorf("repository error: %s", err.Error())
 	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	} else if affected == 0 {
+		return api_models.NewConflictError("film was modified concurrently")
+	}
 
-	if len(params.Actors) == 0 {
-		if err = tx.Commit(); err != nil {
-			return fmt.Errorf("repository error: transaction error: %s", err.Error())
+	if params.Actors.Set {
+		_, err = tx.ExecContext(ctx, `delete from film_actor where film_id = $1`, params.FilmId)
+		if err != nil {
+			return fmt.Errorf("repository error: %s", err.Error())
+		}
+		err = insertFilmActors(ctx, tx, params.FilmId, params.Actors.Value, "actors")
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(params.RemoveActors) > 0 {
+		placeholders := make([]string, 0, len(params.RemoveActors))
+		args := []interface{}{params.FilmId}
+		for _, actorId := range params.RemoveActors {
+			args = append(args, actorId)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+
+		query := fmt.Sprintf(`delete from film_actor where film_id = $1 and actor_id in (%s)`, strings.Join(placeholders, ", "))
+		_, err = tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("repository error: %s", err.Error())
 		}
-		return nil
 	}
 
-	relationDeleteQuery := `delete from film_actor where film_id = $1`
-	_, err = tx.Exec(relationDeleteQuery, params.FilmId)
+	err = insertFilmActors(ctx, tx, params.FilmId, params.AddActors, "add_actors")
 	if err != nil {
-		return fmt.Errorf("repository error: %s", err.Error())
+		return err
 	}
 
 	if err = tx.Commit(); err != nil {
@@ -167,8 +196,47 @@ func (r Repository) UpdateFilm(params api_models.UpdateFilmParams) error {
 	return nil
 }
 
-func (r Repository) DeleteFilm(filmId string) error {
-	tx, err := r.db.BeginTx(context.Background(), nil)
+// insertFilmActors привязывает актеров к фильму, уже привязанные пропускаются.
+// Неизвестный id актера - ошибка валидации поля field
+func insertFilmActors(ctx context.Context, tx *sql.Tx, filmId string, actorIds []string, field string) error {
+	if len(actorIds) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(actorIds))
+	args := []interface{}{filmId}
+	for _, actorId := range actorIds {
+		args = append(args, actorId)
+		values = append(values, fmt.Sprintf("($1, $%d)", len(args)))
+	}
+
+	query := fmt.Sprintf(`insert into film_actor(film_id, actor_id) values %s on conflict do nothing`, strings.Join(values, ", "))
+	_, err := tx.ExecContext(ctx, query, args...)
+	if isForeignKeyViolation(err) {
+		return api_models.NewFieldError(field, "unknown actor id")
+	}
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r Repository) GetFilmVersion(ctx context.Context, filmId string) (int, error) {
+	var version int
+	err := r.db.QueryRowContext(ctx, `select version from film where id = $1`, filmId).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, api_models.NewNotFoundError("film not found")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return version, nil
+}
+
+func (r Repository) DeleteFilm(ctx context.Context, filmId string, version int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("repository error: transaction error: %s", err.Error())
 	}
@@ -176,17 +244,22 @@ func (r Repository) DeleteFilm(filmId string) error {
 
 	relationQuery := `delete from film_actor where film_id = $1`
 
-	_, err = tx.Exec(relationQuery, filmId)
+	_, err = tx.ExecContext(ctx, relationQuery, filmId)
 	if err != nil {
 		return fmt.Errorf("repository error: %s", err.Error())
 	}
 
-	query := `delete from film where id = $1`
+	query := `delete from film where id = $1 and version = $2`
 
-	_, err = tx.Exec(query, filmId)
+	result, err := tx.ExecContext(ctx, query, filmId, version)
 	if err != nil {
 		return fmt.Errorf("repository error: %s", err.Error())
 	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	} else if affected == 0 {
+		return api_models.NewConflictError("film was modified concurrently")
+	}
 
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("repository error: %s", err.Error())
@@ -195,84 +268,115 @@ func (r Repository) DeleteFilm(filmId string) error {
 	return nil
 }
 
-func (r Repository) SearchFilmByName(name string) (api_models.SearchFilmResponse, error) {
+func (r Repository) SearchFilmByName(ctx context.Context, name string, page api_models.Page) (api_models.SearchFilmResponse, error) {
 	if name == "" {
 		return api_models.SearchFilmResponse{}, fmt.Errorf("repository error: invalid name")
 	}
 
-	query := fmt.Sprintf(`select film.*, array_agg(actor.name) as actors
-	from film
-	left join film_actor on film.id = film_actor.film_id
-	left join actor on actor.id = film_actor.actor_id
-	where film.name ilike $1
-	group by film.id
-	`)
-
-	var response api_models.SearchFilmResponse
-
-	regex := fmt.Sprintf("%%%s%%", name)
-
-	rows, err := r.db.Query(query, regex)
-	if err != nil {
-		return api_models.SearchFilmResponse{}, fmt.Errorf("repository error: %s", err.Error())
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var filmAndActors api_models.FilmAndActors
-
-		err = rows.Scan(&filmAndActors.Name, &filmAndActors.Description,
-			&filmAndActors.ReleaseDate, &filmAndActors.Rate,
-			&filmAndActors.FilmId, &filmAndActors.Actors)
-
-		if err != nil {
-			return api_models.SearchFilmResponse{}, fmt.Errorf("repository error: %s", err.Error())
-		}
+	return r.searchFilms(ctx, `film.name ilike $1`, fmt.Sprintf("%%%s%%", name), page)
+}
 
-		response.Response = append(response.Response, filmAndActors)
+func (r Repository) SearchFilmByActorName(ctx context.Context, actorName string, page api_models.Page) (api_models.SearchFilmResponse, error) {
+	if actorName == "" {
+		return api_models.SearchFilmResponse{}, fmt.Errorf("repository error: invalid name")
 	}
 
-	return response, nil
+	return r.searchFilms(ctx, `film.id in (select film_actor.film_id
+		from film_actor
+		join actor on actor.id = film_actor.actor_id
+		where actor.name ilike $1)`, fmt.Sprintf("%%%s%%", actorName), page)
 }
 
-func (r Repository) SearchFilmByActorName(actorName string) (api_models.SearchFilmResponse, error) {
-	if actorName == "" {
-		return api_models.SearchFilmResponse{}, fmt.Errorf("repository error: invalid name")
+// searchFilms отдает страницу фильмов, подходящих под filter с единственным параметром $1.
+// Для поиска считается точное число совпадений, оценка по статистике таблицы тут бесполезна
+func (r Repository) searchFilms(ctx context.Context, filter string, pattern string, page api_models.Page) (api_models.SearchFilmResponse, error) {
+	columns, desc := keysetColumns(filmSortColumns, "film.id", page.Sort)
+	condition, args, err := keysetCondition(columns, desc, page.After, 2)
+	if err != nil {
+		return api_models.SearchFilmResponse{}, err
 	}
 
-	query := fmt.Sprintf(`with films as (select film.*, array_agg(actor.name) as actors
+	query := fmt.Sprintf(`select %s
 	from film
 	left join film_actor on film.id = film_actor.film_id
 	left join actor on actor.id = film_actor.actor_id
-	group by film.id)
+	where %s and %s
+	group by film.id
+	order by %s
+	limit %d`, filmListColumns, filter, condition, orderBy(columns, desc), page.Limit+1)
 
-	select films.*
-	from films
-	where array_to_string(actors, ' ') ilike $1`)
+	films, err := r.queryFilms(ctx, query, append([]interface{}{pattern}, args...)...)
+	if err != nil {
+		return api_models.SearchFilmResponse{}, err
+	}
 
 	var response api_models.SearchFilmResponse
+	response.Response, response.NextCursor = filmsPage(films, page)
 
-	regex := fmt.Sprintf("%%%s%%", actorName)
-
-	rows, err := r.db.Query(query, regex)
+	err = r.db.QueryRowContext(ctx, fmt.Sprintf(`select count(*) from film where %s`, filter), pattern).Scan(&response.TotalEstimate)
 	if err != nil {
 		return api_models.SearchFilmResponse{}, fmt.Errorf("repository error: %s", err.Error())
 	}
+
+	return response, nil
+}
+
+func (r Repository) queryFilms(ctx context.Context, query string, args ...interface{}) ([]api_models.FilmAndActors, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %s", err.Error())
+	}
 	defer rows.Close()
 
+	films := []api_models.FilmAndActors{}
 	for rows.Next() {
 		var filmAndActors api_models.FilmAndActors
 
 		err = rows.Scan(&filmAndActors.Name, &filmAndActors.Description,
 			&filmAndActors.ReleaseDate, &filmAndActors.Rate,
-			&filmAndActors.FilmId, &filmAndActors.Actors)
+			&filmAndActors.FilmId, jsonColumn{&filmAndActors.Actors})
 
 		if err != nil {
-			return api_models.SearchFilmResponse{}, fmt.Errorf("repository error: %s", err.Error())
+			return nil, fmt.Errorf("repository error: %s", err.Error())
 		}
 
-		response.Response = append(response.Response, filmAndActors)
+		films = append(films, filmAndActors)
 	}
 
-	return response, nil
+	return films, nil
+}
+
+// filmsPage обрезает лишнюю строку, запрошенную сверх limit, и по последнему фильму страницы строит курсор
+func filmsPage(films []api_models.FilmAndActors, page api_models.Page) ([]api_models.FilmAndActors, string) {
+	if len(films) <= page.Limit {
+		return films, ""
+	}
+	films = films[:page.Limit]
+	last := films[len(films)-1]
+
+	cursor := api_models.Cursor{Sort: page.Sort.String(), Id: last.FilmId}
+	for _, field := range page.Sort {
+		switch field.Field {
+		case "name":
+			cursor.Values = append(cursor.Values, last.Name)
+		case "rate":
+			cursor.Values = append(cursor.Values, strconv.Itoa(last.Rate))
+		case "release_date":
+			cursor.Values = append(cursor.Values, last.ReleaseDate)
+		}
+	}
+
+	return films, cursor.Encode()
+}
+
+// filmListColumns - колонки фильма и его актеры одним json массивом, отсортированным по имени
+const filmListColumns = `film.name, film.description, film.date_released, film.rate, film.id,
+	coalesce(json_agg(json_build_object('actor_id', actor.id, 'name', actor.name) order by actor.name, actor.id)
+		filter (where actor.id is not null), '[]') as actors`
+
+// filmSortColumns сопоставляет поля сортировки из запроса с колонками, в запрос попадают только они
+var filmSortColumns = map[string]sortColumn{
+	"name":         {expr: "film.name", cast: "text"},
+	"rate":         {expr: "film.rate", cast: "integer"},
+	"release_date": {expr: "film.date_released", cast: "date"},
 }
@@ -1,14 +1,15 @@
 package postgres
 
 import (
+	"context"
+	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
-	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
 	api_models "vk_test_task/internal/api/models"
-	"vk_test_task/internal/common"
 )
 
 func TestRepository_CreateFilm(t *testing.T) {
@@ -94,7 +95,7 @@ func TestRepository_CreateFilm(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehaviour(testCase.args)
 
-			err = r.CreateFilm(testCase.args)
+			err = r.CreateFilm(context.Background(), testCase.args)
 
 			if testCase.wantErr {
 				assert.Error(t, err)
@@ -118,77 +119,98 @@ func TestRepository_GetFilms(t *testing.T) {
 
 	r := Repository{db: sqlx.NewDb(db, "pgx")}
 
-	type mockBehaviour func(params api_models.GetFilmsParams)
+	type mockBehaviour func(page api_models.Page)
+
+	expectEstimate := func() {
+		mock.ExpectQuery(`select case when reltuples < 0`).WithArgs("film").
+			WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(100))
+	}
 
 	testTable := []struct {
 		name          string
 		mockBehaviour mockBehaviour
-		args          api_models.GetFilmsParams
+		args          api_models.Page
 		wantErr       bool
 	}{
 		{
 			name: "default",
-			args: api_models.GetFilmsParams{
-				SortBy:      common.SORT_FILM_BY_NAME,
-				IsAscending: common.SORT_FILM_ASC,
+			args: api_models.Page{
+				Sort:  api_models.Sort{{Field: "name"}},
+				Limit: 20,
 			},
-			mockBehaviour: func(params api_models.GetFilmsParams) {
+			mockBehaviour: func(page api_models.Page) {
 				rows := sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "id", "actors"}).
-					AddRow("", "", "", "", "", "")
+					AddRow("", "", time.Now(), 10, "", nil)
 
-				mock.ExpectQuery("select film.*, array_agg").WillReturnRows(rows)
+				mock.ExpectQuery(`select film.name, .*json_agg.*where true.*order by film.name, film.id\s+limit 21`).WillReturnRows(rows)
+				expectEstimate()
 			},
 			wantErr: false,
 		},
 		{
-			name: "no sort by",
-			args: api_models.GetFilmsParams{
-				SortBy:      0,
-				IsAscending: common.SORT_FILM_ASC,
+			name: "several fields",
+			args: api_models.Page{
+				Sort:  api_models.Sort{{Field: "rate", Desc: true}, {Field: "release_date"}},
+				Limit: 20,
 			},
-			mockBehaviour: func(params api_models.GetFilmsParams) {
+			mockBehaviour: func(page api_models.Page) {
 				rows := sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "id", "actors"}).
-					AddRow("", "", "", "", "", "")
+					AddRow("", "", time.Now(), 10, "", nil)
 
-				mock.ExpectQuery("select film.*, array_agg").WillReturnRows(rows)
+				mock.ExpectQuery(`order by film.rate desc, film.date_released, film.id`).WillReturnRows(rows)
+				expectEstimate()
 			},
 			wantErr: false,
 		},
 		{
-			name: "no is asc",
-			args: api_models.GetFilmsParams{
-				SortBy:      common.SORT_FILM_BY_RELEASE_DATE,
-				IsAscending: 0,
+			name: "unknown field is ignored",
+			args: api_models.Page{
+				Sort:  api_models.Sort{{Field: "id; drop table film"}},
+				Limit: 20,
 			},
-			mockBehaviour: func(params api_models.GetFilmsParams) {
+			mockBehaviour: func(page api_models.Page) {
 				rows := sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "id", "actors"}).
-					AddRow("", "", "", "", "", "")
+					AddRow("", "", time.Now(), 10, "", nil)
 
-				mock.ExpectQuery("select film.*, array_agg").WillReturnRows(rows)
+				mock.ExpectQuery(`order by film.id\s+limit 21$`).WillReturnRows(rows)
+				expectEstimate()
 			},
 			wantErr: false,
 		},
 		{
-			name: "no sort by and no is asc",
-			args: api_models.GetFilmsParams{
-				SortBy:      0,
-				IsAscending: 0,
+			name: "after cursor",
+			args: api_models.Page{
+				Sort:  api_models.Sort{{Field: "rate", Desc: true}},
+				Limit: 20,
+				After: &api_models.Cursor{Sort: "-rate", Values: []string{"7"}, Id: "id1"},
 			},
-			mockBehaviour: func(params api_models.GetFilmsParams) {
+			mockBehaviour: func(page api_models.Page) {
 				rows := sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "id", "actors"}).
-					AddRow("", "", time.Now(), 10, "", pq.StringArray{})
+					AddRow("", "", time.Now(), 7, "id2", []byte("[]"))
 
-				mock.ExpectQuery("").WillReturnRows(rows)
+				mock.ExpectQuery(`where \(\(film.rate < \$1::text::integer\) or \(film.rate = \$1::text::integer and film.id > \$2::text::text\)\)`).
+					WithArgs("7", "id1").WillReturnRows(rows)
+				expectEstimate()
 			},
 			wantErr: false,
 		},
+		{
+			name: "cursor does not match sort",
+			args: api_models.Page{
+				Sort:  api_models.Sort{{Field: "rate", Desc: true}, {Field: "name"}},
+				Limit: 20,
+				After: &api_models.Cursor{Sort: "-rate", Values: []string{"7"}, Id: "id1"},
+			},
+			mockBehaviour: func(page api_models.Page) {},
+			wantErr:       true,
+		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehaviour(testCase.args)
 
-			_, err = r.GetFilms(testCase.args)
+			_, err = r.GetFilms(context.Background(), testCase.args)
 
 			if testCase.wantErr {
 				assert.Error(t, err)
@@ -203,6 +225,41 @@ func TestRepository_GetFilms(t *testing.T) {
 	}
 }
 
+func TestRepository_GetFilmsNextCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	rows := sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "id", "actors"}).
+		AddRow("Avatar", "", "2009-12-10T00:00:00Z", 8, "id1", nil).
+		AddRow("Titanic", "", "1997-11-01T00:00:00Z", 8, "id2", nil).
+		AddRow("Alien", "", "1979-05-25T00:00:00Z", 7, "id3", nil)
+	mock.ExpectQuery(`order by film.rate desc, film.date_released, film.id\s+limit 3`).
+		WillReturnRows(rows)
+	mock.ExpectQuery(`select case when reltuples < 0`).WithArgs("film").
+		WillReturnRows(sqlmock.NewRows([]string{"reltuples"}).AddRow(3))
+
+	response, err := r.GetFilms(context.Background(), api_models.Page{
+		Sort:  api_models.Sort{{Field: "rate", Desc: true}, {Field: "release_date"}},
+		Limit: 2,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, response.Response, 2)
+	assert.Equal(t, int64(3), response.TotalEstimate)
+
+	next, err := api_models.DecodeCursor(response.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, api_models.Cursor{Sort: "-rate,release_date", Values: []string{"8", "1997-11-01T00:00:00Z"}, Id: "id2"}, next)
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestRepository_DeleteFilm(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -218,26 +275,49 @@ func TestRepository_DeleteFilm(t *testing.T) {
 		name          string
 		mockBehaviour mockBehaviour
 		args          api_models.DeleteFilmParams
+		version       int
 		wantErr       bool
+		wantErrIs     error
 	}{
 		{
 			name: "default",
 			args: api_models.DeleteFilmParams{
 				FilmId: "id1",
 			},
+			version: 2,
 			mockBehaviour: func(params api_models.DeleteFilmParams) {
 				mock.ExpectBegin()
 
 				mock.ExpectExec("delete from film_actor").
 					WithArgs(params.FilmId).WillReturnResult(sqlmock.NewResult(1, 1))
 
-				mock.ExpectExec("delete from film").
-					WithArgs(params.FilmId).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`delete from film where id = \$1 and version = \$2`).
+					WithArgs(params.FilmId, 2).WillReturnResult(sqlmock.NewResult(1, 1))
 
 				mock.ExpectCommit()
 			},
 			wantErr: false,
 		},
+		{
+			name: "version changed",
+			args: api_models.DeleteFilmParams{
+				FilmId: "id1",
+			},
+			version: 2,
+			mockBehaviour: func(params api_models.DeleteFilmParams) {
+				mock.ExpectBegin()
+
+				mock.ExpectExec("delete from film_actor").
+					WithArgs(params.FilmId).WillReturnResult(sqlmock.NewResult(1, 1))
+
+				mock.ExpectExec("delete from film").
+					WithArgs(params.FilmId, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+
+				mock.ExpectRollback()
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrConflict,
+		},
 		{
 			name: "no film_id",
 			args: api_models.DeleteFilmParams{
@@ -253,10 +333,13 @@ func TestRepository_DeleteFilm(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehaviour(testCase.args)
 
-			err = r.DeleteFilm(testCase.args.FilmId)
+			err = r.DeleteFilm(context.Background(), testCase.args.FilmId, testCase.version)
 
 			if testCase.wantErr {
 				assert.Error(t, err)
+				if testCase.wantErrIs != nil {
+					assert.ErrorIs(t, err, testCase.wantErrIs)
+				}
 			} else {
 				if err = mock.ExpectationsWereMet(); err != nil {
 					t.Fatal(err)
@@ -290,9 +373,11 @@ func TestRepository_SearchFilmByName(t *testing.T) {
 			fName: "film1",
 			mockBehaviour: func(name string) {
 				rows := sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "id", "actors"}).
-					AddRow("", "", time.Now(), 10, "", pq.StringArray{})
+					AddRow("", "", time.Now(), 10, "", []byte("[]"))
 
-				mock.ExpectQuery("").WithArgs("%" + name + "%").WillReturnRows(rows)
+				mock.ExpectQuery(`select film.name, .*json_agg`).WithArgs("%" + name + "%").WillReturnRows(rows)
+				mock.ExpectQuery(`select count\(\*\) from film where`).WithArgs("%" + name + "%").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 			},
 			wantErr: false,
 		},
@@ -309,7 +394,7 @@ func TestRepository_SearchFilmByName(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehaviour(testCase.fName)
 
-			_, err = r.SearchFilmByName(testCase.fName)
+			_, err = r.SearchFilmByName(context.Background(), testCase.fName, api_models.Page{Sort: api_models.DefaultFilmSort, Limit: 20})
 
 			if testCase.wantErr {
 				assert.Error(t, err)
@@ -346,9 +431,11 @@ func TestRepository_SearchFilmByActorName(t *testing.T) {
 			fName: "film1",
 			mockBehaviour: func(name string) {
 				rows := sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "id", "actors"}).
-					AddRow("", "", time.Now(), 10, "", pq.StringArray{})
+					AddRow("", "", time.Now(), 10, "", []byte("[]"))
 
-				mock.ExpectQuery("").WithArgs("%" + name + "%").WillReturnRows(rows)
+				mock.ExpectQuery(`select film.name, .*json_agg`).WithArgs("%" + name + "%").WillReturnRows(rows)
+				mock.ExpectQuery(`select count\(\*\) from film where`).WithArgs("%" + name + "%").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
 			},
 			wantErr: false,
 		},
@@ -365,7 +452,7 @@ func TestRepository_SearchFilmByActorName(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehaviour(testCase.fName)
 
-			_, err = r.SearchFilmByActorName(testCase.fName)
+			_, err = r.SearchFilmByActorName(context.Background(), testCase.fName, api_models.Page{Sort: api_models.DefaultFilmSort, Limit: 20})
 
 			if testCase.wantErr {
 				assert.Error(t, err)
@@ -391,52 +478,125 @@ func TestRepository_UpdateFilm(t *testing.T) {
 
 	type mockBehaviour func(params api_models.UpdateFilmParams)
 
+	released := time.Date(2009, 12, 10, 0, 0, 0, 0, time.UTC)
+
 	testTable := []struct {
 		name          string
 		mockBehaviour mockBehaviour
 		args          api_models.UpdateFilmParams
 		wantErr       bool
+		wantErrIs     error
 	}{
 		{
-			name: "default",
+			name: "all fields",
 			args: api_models.UpdateFilmParams{
-				FilmId: "id1",
-				Name: "name" +
-					"",
-				Description: "desc",
-				ReleaseDate: time.Now(),
-				Rate:        10,
-				Actors:      []string{"id1", "id2"},
+				FilmId:      "id1",
+				Name:        api_models.NewOptional("name"),
+				Description: api_models.NewOptional("desc"),
+				ReleaseDate: api_models.NewOptional(released),
+				Rate:        api_models.NewOptional(10),
+				Actors:      api_models.NewOptional([]string{"a1", "a2"}),
+				Version:     2,
 			},
 			mockBehaviour: func(params api_models.UpdateFilmParams) {
 				mock.ExpectBegin()
+				mock.ExpectExec(`update film set name = \$1, description = \$2, date_released = \$3, rate = \$4, version = version \+ 1 where id = \$5 and version = \$6`).
+					WithArgs("name", "desc", released, 10, "id1", 2).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`delete from film_actor where film_id = \$1$`).
+					WithArgs("id1").WillReturnResult(sqlmock.NewResult(0, 3))
+				mock.ExpectExec(`insert into film_actor\(film_id, actor_id\) values \(\$1, \$2\), \(\$1, \$3\) on conflict do nothing`).
+					WithArgs("id1", "a1", "a2").WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
 			},
-			wantErr: false,
 		},
 		{
-			name: "no actors",
+			name: "rate set to zero and description cleared",
 			args: api_models.UpdateFilmParams{
 				FilmId:      "id1",
-				Name:        "name",
-				Description: "desc",
-				ReleaseDate: time.Now(),
-				Rate:        10,
-				Actors:      []string{},
+				Description: api_models.Optional[string]{Set: true, Null: true},
+				Rate:        api_models.NewOptional(0),
+				Version:     1,
 			},
 			mockBehaviour: func(params api_models.UpdateFilmParams) {
 				mock.ExpectBegin()
+				mock.ExpectExec(`update film set description = \$1, rate = \$2, version = version \+ 1 where id = \$3 and version = \$4`).
+					WithArgs("", 0, "id1", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			},
-			wantErr: false,
+		},
+		{
+			name: "clear actors",
+			args: api_models.UpdateFilmParams{
+				FilmId:  "id1",
+				Actors:  api_models.Optional[[]string]{Set: true, Null: true},
+				Version: 1,
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`update film set version = version \+ 1 where id = \$1 and version = \$2`).
+					WithArgs("id1", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`delete from film_actor where film_id = \$1$`).
+					WithArgs("id1").WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "add and remove single actors",
+			args: api_models.UpdateFilmParams{
+				FilmId:       "id1",
+				AddActors:    []string{"a3"},
+				RemoveActors: []string{"a1", "a2"},
+				Version:      1,
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`update film set version = version \+ 1 where id = \$1 and version = \$2`).
+					WithArgs("id1", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`delete from film_actor where film_id = \$1 and actor_id in \(\$2, \$3\)`).
+					WithArgs("id1", "a1", "a2").WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec(`insert into film_actor\(film_id, actor_id\) values \(\$1, \$2\) on conflict do nothing`).
+					WithArgs("id1", "a3").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "unknown actor",
+			args: api_models.UpdateFilmParams{
+				FilmId:    "id1",
+				AddActors: []string{"unknown"},
+				Version:   1,
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`update film set version`).
+					WithArgs("id1", 1).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`insert into film_actor`).
+					WithArgs("id1", "unknown").WillReturnError(&pgconn.PgError{Code: "23503"})
+				mock.ExpectRollback()
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrValidation,
+		},
+		{
+			name: "version changed",
+			args: api_models.UpdateFilmParams{
+				FilmId:  "id1",
+				Rate:    api_models.NewOptional(5),
+				Version: 1,
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`update film set rate = \$1, version = version \+ 1 where id = \$2 and version = \$3`).
+					WithArgs(5, "id1", 1).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrConflict,
 		},
 		{
 			name: "no filmId",
 			args: api_models.UpdateFilmParams{
-				FilmId:      "",
-				Name:        "name",
-				Description: "desc",
-				ReleaseDate: time.Now(),
-				Rate:        10,
-				Actors:      []string{"id1", "id2"},
+				Name: api_models.NewOptional("name"),
 			},
 			mockBehaviour: func(params api_models.UpdateFilmParams) {
 			},
@@ -448,17 +608,146 @@ func TestRepository_UpdateFilm(t *testing.T) {
 		t.Run(testCase.name, func(t *testing.T) {
 			testCase.mockBehaviour(testCase.args)
 
-			err = r.UpdateFilm(testCase.args)
+			err = r.UpdateFilm(context.Background(), testCase.args)
 
 			if testCase.wantErr {
 				assert.Error(t, err)
-			} else {
-				if err = mock.ExpectationsWereMet(); err != nil {
-					t.Fatal(err)
+				if testCase.wantErrIs != nil {
+					assert.ErrorIs(t, err, testCase.wantErrIs)
 				}
+			} else {
 				assert.NoError(t, err)
 			}
-
+			if err = mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
 		})
 	}
 }
+
+func TestRepository_GetFilmDetails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	t.Run("default", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "version", "id"}).
+			AddRow("Avatar", "desc", "2009-12-10", 8, 3, "id")
+		mock.ExpectQuery(`select name, description, date_released, rate, version, id from film`).WithArgs("id").WillReturnRows(rows)
+		actors := sqlmock.NewRows([]string{"id", "name"}).
+			AddRow("a1", "Sam Worthington").
+			AddRow("a2", "Zoe Saldana")
+		mock.ExpectQuery(`select actor.id, actor.name\s+from film_actor`).WithArgs("id").WillReturnRows(actors)
+
+		film, err := r.GetFilmDetails(context.Background(), "id")
+
+		assert.NoError(t, err)
+		assert.Equal(t, api_models.FilmDetails{
+			FilmId:      "id",
+			Name:        "Avatar",
+			Description: "desc",
+			Rate:        8,
+			ReleaseDate: "2009-12-10",
+			Version:     3,
+			Actors: []api_models.ActorRef{
+				{ActorId: "a1", Name: "Sam Worthington"},
+				{ActorId: "a2", Name: "Zoe Saldana"},
+			},
+		}, film)
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("no actors", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "version", "id"}).
+			AddRow("Avatar", "desc", "2009-12-10", 8, 1, "id")
+		mock.ExpectQuery(`from film where id`).WithArgs("id").WillReturnRows(rows)
+		mock.ExpectQuery(`from film_actor`).WithArgs("id").WillReturnRows(sqlmock.NewRows([]string{"id", "name"}))
+
+		film, err := r.GetFilmDetails(context.Background(), "id")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []api_models.ActorRef{}, film.Actors)
+	})
+
+	t.Run("interrupted actor rows", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "version", "id"}).
+			AddRow("Avatar", "desc", "2009-12-10", 8, 3, "id")
+		mock.ExpectQuery(`from film where id`).WithArgs("id").WillReturnRows(rows)
+		actors := sqlmock.NewRows([]string{"id", "name"}).
+			AddRow("a1", "Sam Worthington").
+			AddRow("a2", "Zoe Saldana").
+			RowError(1, fmt.Errorf("connection reset"))
+		mock.ExpectQuery(`from film_actor`).WithArgs("id").WillReturnRows(actors)
+
+		_, err := r.GetFilmDetails(context.Background(), "id")
+
+		assert.Error(t, err)
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "version", "id"})
+		mock.ExpectQuery(`from film where id`).WithArgs("id").WillReturnRows(rows)
+
+		_, err := r.GetFilmDetails(context.Background(), "id")
+
+		assert.ErrorIs(t, err, api_models.ErrNotFound)
+	})
+
+	t.Run("canceled request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		mock.ExpectQuery(`from film where id`).WithArgs("id").
+			WillReturnRows(sqlmock.NewRows([]string{"name", "description", "date_released", "rate", "version", "id"}))
+
+		_, err := r.GetFilmDetails(ctx, "id")
+
+		assert.ErrorContains(t, err, context.Canceled.Error())
+	})
+
+	t.Run("no film id", func(t *testing.T) {
+		_, err := r.GetFilmDetails(context.Background(), "")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestRepository_GetFilmVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	t.Run("default", func(t *testing.T) {
+		mock.ExpectQuery(`select version from film where id = \$1`).WithArgs("id").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+
+		version, err := r.GetFilmVersion(context.Background(), "id")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, version)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mock.ExpectQuery(`select version from film`).WithArgs("id").
+			WillReturnRows(sqlmock.NewRows([]string{"version"}))
+
+		_, err := r.GetFilmVersion(context.Background(), "id")
+
+		assert.ErrorIs(t, err, api_models.ErrNotFound)
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func (r Repository) GetMFA(ctx context.Context, userId string) (api_models.MFA, error) {
+	if userId == "" {
+		return api_models.MFA{}, fmt.Errorf("repository error: invalid userId")
+	}
+
+	query := `select secret, enabled from user_mfa where user_id = $1`
+
+	var mfa api_models.MFA
+	err := r.db.QueryRowContext(ctx, query, userId).Scan(&mfa.Secret, &mfa.Enabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return api_models.MFA{}, nil
+	}
+	if err != nil {
+		return api_models.MFA{}, fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return mfa, nil
+}
+
+func (r Repository) SaveMFASecret(ctx context.Context, userId, secret string) error {
+	if userId == "" {
+		return fmt.Errorf("repository error: invalid userId")
+	}
+	if secret == "" {
+		return fmt.Errorf("repository error: invalid secret")
+	}
+
+	query := `insert into user_mfa (user_id, secret, enabled) values ($1, $2, false)
+	on conflict (user_id) do update set secret = excluded.secret, enabled = false, created_at = now()`
+
+	_, err := r.db.ExecContext(ctx, query, userId, secret)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r Repository) EnableMFA(ctx context.Context, userId string, recoveryCodeHashes []string) error {
+	if userId == "" {
+		return fmt.Errorf("repository error: invalid userId")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository error: transaction error: %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `update user_mfa set enabled = true where user_id = $1`, userId)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+	if affected == 0 {
+		return api_models.NewConflictError("two-factor authentication is not enrolled")
+	}
+
+	_, err = tx.ExecContext(ctx, `delete from user_recovery_code where user_id = $1`, userId)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	for _, codeHash := range recoveryCodeHashes {
+		_, err = tx.ExecContext(ctx, `insert into user_recovery_code (user_id, code_hash) values ($1, $2)`, userId, codeHash)
+		if err != nil {
+			return fmt.Errorf("repository error: %s", err.Error())
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("repository error: transaction error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r Repository) DisableMFA(ctx context.Context, userId string) error {
+	if userId == "" {
+		return fmt.Errorf("repository error: invalid userId")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("repository error: transaction error: %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `delete from user_recovery_code where user_id = $1`, userId)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	_, err = tx.ExecContext(ctx, `delete from user_mfa where user_id = $1`, userId)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("repository error: transaction error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r Repository) UseRecoveryCode(ctx context.Context, userId, codeHash string) (bool, error) {
+	if userId == "" || codeHash == "" {
+		return false, nil
+	}
+
+	query := `delete from user_recovery_code where user_id = $1 and code_hash = $2`
+
+	result, err := r.db.ExecContext(ctx, query, userId, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,354 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestRepository_GetMFA(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	testTable := []struct {
+		name          string
+		userId        string
+		mockBehaviour func(userId string)
+		want          api_models.MFA
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				rows := sqlmock.NewRows([]string{"secret", "enabled"}).AddRow("secret", true)
+				mock.ExpectQuery(`select secret, enabled from user_mfa`).
+					WithArgs(userId).WillReturnRows(rows)
+			},
+			want:    api_models.MFA{Secret: "secret", Enabled: true},
+			wantErr: false,
+		},
+		{
+			name:   "not enrolled",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				rows := sqlmock.NewRows([]string{"secret", "enabled"})
+				mock.ExpectQuery(`select secret, enabled from user_mfa`).
+					WithArgs(userId).WillReturnRows(rows)
+			},
+			want:    api_models.MFA{},
+			wantErr: false,
+		},
+		{
+			name:   "db error",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				mock.ExpectQuery(`select secret, enabled from user_mfa`).
+					WithArgs(userId).WillReturnError(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no userId",
+			userId: "",
+			mockBehaviour: func(userId string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.userId)
+
+			mfa, err := r.GetMFA(context.Background(), testCase.userId)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, mfa)
+			}
+		})
+	}
+}
+
+func TestRepository_SaveMFASecret(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	type args struct {
+		userId string
+		secret string
+	}
+
+	testTable := []struct {
+		name          string
+		args          args
+		mockBehaviour func(args args)
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: args{userId: "id", secret: "secret"},
+			mockBehaviour: func(args args) {
+				mock.ExpectExec(`insert into user_mfa`).
+					WithArgs(args.userId, args.secret).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "db error",
+			args: args{userId: "id", secret: "secret"},
+			mockBehaviour: func(args args) {
+				mock.ExpectExec(`insert into user_mfa`).
+					WithArgs(args.userId, args.secret).WillReturnError(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "no secret",
+			args: args{userId: "id"},
+			mockBehaviour: func(args args) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.args)
+
+			err := r.SaveMFASecret(context.Background(), testCase.args.userId, testCase.args.secret)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_EnableMFA(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	type args struct {
+		userId string
+		hashes []string
+	}
+
+	testTable := []struct {
+		name          string
+		args          args
+		mockBehaviour func(args args)
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: args{userId: "id", hashes: []string{"first", "second"}},
+			mockBehaviour: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`update user_mfa set enabled = true`).
+					WithArgs(args.userId).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`delete from user_recovery_code`).
+					WithArgs(args.userId).WillReturnResult(sqlmock.NewResult(0, 0))
+				for _, hash := range args.hashes {
+					mock.ExpectExec(`insert into user_recovery_code`).
+						WithArgs(args.userId, hash).WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name: "not enrolled",
+			args: args{userId: "id", hashes: []string{"first"}},
+			mockBehaviour: func(args args) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`update user_mfa set enabled = true`).
+					WithArgs(args.userId).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "no userId",
+			args: args{},
+			mockBehaviour: func(args args) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.args)
+
+			err := r.EnableMFA(context.Background(), testCase.args.userId, testCase.args.hashes)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_DisableMFA(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	testTable := []struct {
+		name          string
+		userId        string
+		mockBehaviour func(userId string)
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`delete from user_recovery_code`).
+					WithArgs(userId).WillReturnResult(sqlmock.NewResult(0, 10))
+				mock.ExpectExec(`delete from user_mfa`).
+					WithArgs(userId).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+		{
+			name:   "db error",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`delete from user_recovery_code`).
+					WithArgs(userId).WillReturnError(fmt.Errorf("error"))
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no userId",
+			userId: "",
+			mockBehaviour: func(userId string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.userId)
+
+			err := r.DisableMFA(context.Background(), testCase.userId)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_UseRecoveryCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		want          bool
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			mockBehaviour: func() {
+				mock.ExpectExec(`delete from user_recovery_code`).
+					WithArgs("id", "hash").WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "unknown code",
+			mockBehaviour: func() {
+				mock.ExpectExec(`delete from user_recovery_code`).
+					WithArgs("id", "hash").WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "db error",
+			mockBehaviour: func() {
+				mock.ExpectExec(`delete from user_recovery_code`).
+					WithArgs("id", "hash").WillReturnError(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			ok, err := r.UseRecoveryCode(context.Background(), "id", "hash")
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, ok)
+			}
+		})
+	}
+}
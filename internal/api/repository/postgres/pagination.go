@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	api_models "vk_test_task/internal/api/models"
+)
+
+// sortColumn - колонка, по которой строится keyset. Значения курсора приходят строками,
+// поэтому параметр приводится к типу колонки в самом запросе
+type sortColumn struct {
+	expr string
+	cast string
+}
+
+// keysetColumns возвращает колонки сортировки с id в конце, id делает порядок однозначным
+func keysetColumns(columns map[string]sortColumn, idColumn string, sort api_models.Sort) ([]sortColumn, []bool) {
+	var result []sortColumn
+	var desc []bool
+	for _, field := range sort {
+		column, ok := columns[field.Field]
+		if !ok {
+			continue
+		}
+		result = append(result, column)
+		desc = append(desc, field.Desc)
+	}
+
+	return append(result, sortColumn{expr: idColumn, cast: "text"}), append(desc, false)
+}
+
+func orderBy(columns []sortColumn, desc []bool) string {
+	parts := make([]string, 0, len(columns))
+	for i, column := range columns {
+		if desc[i] {
+			parts = append(parts, column.expr+" desc")
+		} else {
+			parts = append(parts, column.expr)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// keysetCondition строит условие "строка после курсора" для составного порядка:
+// (a > $1) or (a = $1 and b > $2) or ... Нумерация параметров начинается с firstArg
+func keysetCondition(columns []sortColumn, desc []bool, cursor *api_models.Cursor, firstArg int) (string, []interface{}, error) {
+	if cursor == nil {
+		return "true", nil, nil
+	}
+
+	values := append(append([]string{}, cursor.Values...), cursor.Id)
+	if len(values) != len(columns) {
+		return "", nil, api_models.NewFieldError("cursor", "malformed cursor")
+	}
+
+	args := make([]interface{}, 0, len(values))
+	placeholders := make([]string, 0, len(values))
+	for i, value := range values {
+		args = append(args, value)
+		placeholders = append(placeholders, fmt.Sprintf("$%d::text::%s", firstArg+i, columns[i].cast))
+	}
+
+	var or []string
+	for i := range columns {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, fmt.Sprintf("%s = %s", columns[j].expr, placeholders[j]))
+		}
+		op := ">"
+		if desc[i] {
+			op = "<"
+		}
+		and = append(and, fmt.Sprintf("%s %s %s", columns[i].expr, op, placeholders[i]))
+		or = append(or, "("+strings.Join(and, " and ")+")")
+	}
+
+	return "(" + strings.Join(or, " or ") + ")", args, nil
+}
+
+// estimateRows берет число строк из статистики планировщика, чтобы не считать count(*) по всей таблице.
+// Пока таблица ни разу не анализировалась, reltuples равен -1, тогда считаем честно
+func (r Repository) estimateRows(ctx context.Context, table string) (int64, error) {
+	var estimate sql.NullInt64
+	err := r.db.QueryRowContext(ctx, `select case when reltuples < 0 then null else reltuples::bigint end
+	from pg_class where oid = to_regclass($1)`, table).Scan(&estimate)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("repository error: %s", err.Error())
+	}
+	if estimate.Valid {
+		return estimate.Int64, nil
+	}
+
+	var count int64
+	if err = r.db.QueryRowContext(ctx, fmt.Sprintf(`select count(*) from %s`, table)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("repository error: %s", err.Error())
+	}
+	return count, nil
+}
@@ -1,11 +1,19 @@
 package postgres
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/XSAM/otelsql"
+	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"log/slog"
+	"strings"
 	"vk_test_task/config"
+	"vk_test_task/internal/metrics"
 	log "vk_test_task/pkg/logger"
 )
 
@@ -16,21 +24,102 @@ type Repository struct {
 }
 
 func NewRepository(cfg *config.Config, logger *slog.Logger) Repository {
-	db, err := sqlx.Connect("pgx", fmt.Sprintf("host=%s port=%s user=%s password=%s database=%s sslmode=%s",
+	db, err := Connect(cfg)
+	if err != nil {
+		log.Fatalf(logger, "postgres connection error: %s", err.Error())
+	}
+	logger.Debug("postgres database connected")
+
+	metrics.RegisterDBStats(db.DB, cfg.Postgres.Database)
+
+	return Repository{
+		db:  db,
+		cfg: cfg,
+	}
+}
+
+// Connect открывает пул соединений и проверяет, что postgres доступен. Используется репозиторием и миграциями
+func Connect(cfg *config.Config) (*sqlx.DB, error) {
+	// otelsql открывает спан на каждый запрос с его текстом, значения параметров в спан не попадают
+	sqlDB, err := otelsql.Open("pgx", fmt.Sprintf("host=%s port=%s user=%s password=%s database=%s sslmode=%s",
 		cfg.Postgres.Host,
 		cfg.Postgres.Port,
 		cfg.Postgres.User,
 		cfg.Postgres.Password,
 		cfg.Postgres.Database,
 		cfg.Postgres.SSLMode,
-	))
+	), otelsql.WithAttributes(semconv.DBSystemPostgreSQL), otelsql.WithSpanOptions(otelsql.SpanOptions{
+		DisableErrSkip:       true,
+		OmitConnResetSession: true,
+		OmitRows:             true,
+	}))
 	if err != nil {
-		log.Fatalf(logger, "postgres connection error: %s", err.Error())
+		return nil, err
 	}
-	logger.Debug("postgres database connected")
 
-	return Repository{
-		db:  db,
-		cfg: cfg,
+	db := sqlx.NewDb(sqlDB, "pgx")
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Ping проверяет, что postgres доступен, используется в /readyz
+func (r Repository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+	return nil
+}
+
+// jsonColumn разбирает колонку с json (например результат json_agg) в dst
+type jsonColumn struct {
+	dst interface{}
+}
+
+func (c jsonColumn) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case []byte:
+		return json.Unmarshal(value, c.dst)
+	case string:
+		return json.Unmarshal([]byte(value), c.dst)
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("unsupported json column type %T", src)
 	}
 }
+
+// setClause собирает "set" для update только из переданных полей, параметры нумеруются по порядку
+type setClause struct {
+	columns []string
+	args    []interface{}
+}
+
+func (s *setClause) add(column string, value interface{}) {
+	s.args = append(s.args, value)
+	s.columns = append(s.columns, fmt.Sprintf("%s = $%d", column, len(s.args)))
+}
+
+// bumpVersion увеличивает версию строки, вызывается последним, после всех add
+func (s *setClause) bumpVersion() {
+	s.columns = append(s.columns, "version = version + 1")
+}
+
+func (s *setClause) String() string {
+	return strings.Join(s.columns, ", ")
+}
+
+// isForeignKeyViolation - запись ссылается на несуществующую строку (например неизвестный actor id)
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// isUniqueViolation - нарушено ограничение уникальности constraint (например email уже занят)
+func isUniqueViolation(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == constraint
+}
@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRepository_Ping(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	t.Run("default", func(t *testing.T) {
+		mock.ExpectPing()
+
+		assert.NoError(t, r.Ping(context.Background()))
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+		assert.Error(t, r.Ping(context.Background()))
+		if err = mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
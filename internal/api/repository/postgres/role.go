@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func (r Repository) GetUserAccess(ctx context.Context, userId string) (api_models.UserAccess, error) {
+	if userId == "" {
+		return api_models.UserAccess{}, fmt.Errorf("repository error: invalid userId")
+	}
+
+	query := `select "user".disabled, user_role.role, role_permission.permission
+	from "user"
+	left join user_role on user_role.user_id = "user".user_id
+	left join role_permission on role_permission.role = user_role.role
+	where "user".user_id = $1
+	order by user_role.role, role_permission.permission`
+
+	rows, err := r.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return api_models.UserAccess{}, fmt.Errorf("repository error: %s", err.Error())
+	}
+	defer rows.Close()
+
+	access := api_models.UserAccess{
+		UserId:      userId,
+		Roles:       []string{},
+		Permissions: []string{},
+	}
+
+	found := false
+	for rows.Next() {
+		var role, permission *string
+
+		if err = rows.Scan(&access.Disabled, &role, &permission); err != nil {
+			return api_models.UserAccess{}, fmt.Errorf("repository error: %s", err.Error())
+		}
+		found = true
+
+		if role != nil && !slices.Contains(access.Roles, *role) {
+			access.Roles = append(access.Roles, *role)
+		}
+		if permission != nil && !slices.Contains(access.Permissions, *permission) {
+			access.Permissions = append(access.Permissions, *permission)
+		}
+	}
+
+	if !found {
+		return api_models.UserAccess{}, api_models.NewNotFoundError("user not found")
+	}
+
+	return access, nil
+}
+
+func (r Repository) GetRoles(ctx context.Context) (api_models.GetRolesResponse, error) {
+	query := `select role.name, role_permission.permission
+	from role
+	left join role_permission on role_permission.role = role.name
+	order by role.name, role_permission.permission`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return api_models.GetRolesResponse{}, fmt.Errorf("repository error: %s", err.Error())
+	}
+	defer rows.Close()
+
+	var response api_models.GetRolesResponse
+
+	for rows.Next() {
+		var name string
+		var permission *string
+
+		if err = rows.Scan(&name, &permission); err != nil {
+			return api_models.GetRolesResponse{}, fmt.Errorf("repository error: %s", err.Error())
+		}
+
+		if len(response.Response) == 0 || response.Response[len(response.Response)-1].Name != name {
+			response.Response = append(response.Response, api_models.Role{Name: name, Permissions: []string{}})
+		}
+		if permission != nil {
+			last := &response.Response[len(response.Response)-1]
+			last.Permissions = append(last.Permissions, *permission)
+		}
+	}
+
+	return response, nil
+}
+
+func (r Repository) GrantRole(ctx context.Context, userId, role string) error {
+	if userId == "" {
+		return fmt.Errorf("repository error: invalid userId")
+	}
+	if role == "" {
+		return fmt.Errorf("repository error: invalid role")
+	}
+
+	var userExists, roleExists bool
+	checkQuery := `select exists(select 1 from "user" where user_id = $1), exists(select 1 from role where name = $2)`
+
+	err := r.db.QueryRowContext(ctx, checkQuery, userId, role).Scan(&userExists, &roleExists)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+	if !userExists {
+		return api_models.NewNotFoundError("user not found")
+	}
+	if !roleExists {
+		return api_models.NewNotFoundError("role not found")
+	}
+
+	query := `insert into user_role (user_id, role) values ($1, $2) on conflict do nothing`
+
+	_, err = r.db.ExecContext(ctx, query, userId, role)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r Repository) RevokeRole(ctx context.Context, userId, role string) error {
+	if userId == "" {
+		return fmt.Errorf("repository error: invalid userId")
+	}
+	if role == "" {
+		return fmt.Errorf("repository error: invalid role")
+	}
+
+	query := `delete from user_role where user_id = $1 and role = $2`
+
+	result, err := r.db.ExecContext(ctx, query, userId, role)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+	if affected == 0 {
+		return api_models.NewNotFoundError("user has no such role")
+	}
+
+	return nil
+}
@@ -0,0 +1,287 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestRepository_GetUserAccess(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	type mockBehaviour func(userId string)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour mockBehaviour
+		userId        string
+		want          api_models.UserAccess
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				rows := sqlmock.NewRows([]string{"disabled", "role", "permission"}).
+					AddRow(false, "editor", "film:read").
+					AddRow(false, "editor", "film:update").
+					AddRow(false, "viewer", "film:read").
+					AddRow(false, "empty", nil)
+				mock.ExpectQuery(`select "user".disabled, user_role.role, role_permission.permission`).
+					WithArgs(userId).WillReturnRows(rows)
+			},
+			want: api_models.UserAccess{
+				UserId:      "id",
+				Roles:       []string{"editor", "viewer", "empty"},
+				Permissions: []string{"film:read", "film:update"},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "disabled without roles",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				rows := sqlmock.NewRows([]string{"disabled", "role", "permission"}).
+					AddRow(true, nil, nil)
+				mock.ExpectQuery(`select "user".disabled, user_role.role, role_permission.permission`).
+					WithArgs(userId).WillReturnRows(rows)
+			},
+			want: api_models.UserAccess{
+				UserId:      "id",
+				Roles:       []string{},
+				Permissions: []string{},
+				Disabled:    true,
+			},
+			wantErr: false,
+		},
+		{
+			name:   "user not found",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				rows := sqlmock.NewRows([]string{"disabled", "role", "permission"})
+				mock.ExpectQuery(`select "user".disabled, user_role.role, role_permission.permission`).
+					WithArgs(userId).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "db error",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				mock.ExpectQuery(`select "user".disabled, user_role.role, role_permission.permission`).
+					WithArgs(userId).WillReturnError(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no userId",
+			userId: "",
+			mockBehaviour: func(userId string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.userId)
+
+			access, err := r.GetUserAccess(context.Background(), testCase.userId)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, access)
+			}
+		})
+	}
+}
+
+func TestRepository_GetRoles(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	rows := sqlmock.NewRows([]string{"name", "permission"}).
+		AddRow("admin", "film:read").
+		AddRow("admin", "role:manage").
+		AddRow("empty", nil).
+		AddRow("viewer", "film:read")
+	mock.ExpectQuery(`select role.name, role_permission.permission`).WillReturnRows(rows)
+
+	response, err := r.GetRoles(context.Background())
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, []api_models.Role{
+		{Name: "admin", Permissions: []string{"film:read", "role:manage"}},
+		{Name: "empty", Permissions: []string{}},
+		{Name: "viewer", Permissions: []string{"film:read"}},
+	}, response.Response)
+}
+
+func TestRepository_GrantRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	type mockBehaviour func(userId, role string)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour mockBehaviour
+		userId        string
+		role          string
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			userId: "id",
+			role:   "editor",
+			mockBehaviour: func(userId, role string) {
+				rows := sqlmock.NewRows([]string{"user", "role"}).AddRow(true, true)
+				mock.ExpectQuery(`select exists`).WithArgs(userId, role).WillReturnRows(rows)
+				mock.ExpectExec(`insert into user_role`).WithArgs(userId, role).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name:   "unknown role",
+			userId: "id",
+			role:   "owner",
+			mockBehaviour: func(userId, role string) {
+				rows := sqlmock.NewRows([]string{"user", "role"}).AddRow(true, false)
+				mock.ExpectQuery(`select exists`).WithArgs(userId, role).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "unknown user",
+			userId: "id",
+			role:   "editor",
+			mockBehaviour: func(userId, role string) {
+				rows := sqlmock.NewRows([]string{"user", "role"}).AddRow(false, true)
+				mock.ExpectQuery(`select exists`).WithArgs(userId, role).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no role",
+			userId: "id",
+			role:   "",
+			mockBehaviour: func(userId, role string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.userId, testCase.role)
+
+			err := r.GrantRole(context.Background(), testCase.userId, testCase.role)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_RevokeRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	type mockBehaviour func(userId, role string)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour mockBehaviour
+		userId        string
+		role          string
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			userId: "id",
+			role:   "editor",
+			mockBehaviour: func(userId, role string) {
+				mock.ExpectExec(`delete from user_role`).WithArgs(userId, role).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name:   "no such role",
+			userId: "id",
+			role:   "editor",
+			mockBehaviour: func(userId, role string) {
+				mock.ExpectExec(`delete from user_role`).WithArgs(userId, role).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no userId",
+			userId: "",
+			role:   "editor",
+			mockBehaviour: func(userId, role string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.userId, testCase.role)
+
+			err := r.RevokeRole(context.Background(), testCase.userId, testCase.role)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func (r Repository) GetUserByLogin(ctx context.Context, login string) (api_models.User, error) {
+	if login == "" {
+		return api_models.User{}, fmt.Errorf("repository error: invalid login")
+	}
+
+	query := `select "user".user_id, "user".login, coalesce("user".email, ''), "user".disabled, user_role.role
+	from "user"
+	left join user_role on user_role.user_id = "user".user_id
+	where "user".login = $1
+	order by user_role.role`
+
+	users, err := r.queryUsers(ctx, query, login)
+	if err != nil {
+		return api_models.User{}, err
+	}
+
+	if len(users) == 0 {
+		return api_models.User{}, api_models.NewNotFoundError("user not found")
+	}
+
+	return users[0], nil
+}
+
+func (r Repository) GetUserById(ctx context.Context, userId string) (api_models.User, error) {
+	if userId == "" {
+		return api_models.User{}, fmt.Errorf("repository error: invalid userId")
+	}
+
+	query := `select "user".user_id, "user".login, coalesce("user".email, ''), "user".disabled, user_role.role
+	from "user"
+	left join user_role on user_role.user_id = "user".user_id
+	where "user".user_id = $1
+	order by user_role.role`
+
+	users, err := r.queryUsers(ctx, query, userId)
+	if err != nil {
+		return api_models.User{}, err
+	}
+
+	if len(users) == 0 {
+		return api_models.User{}, api_models.NewNotFoundError("user not found")
+	}
+
+	return users[0], nil
+}
+
+func (r Repository) ListUsers(ctx context.Context) ([]api_models.User, error) {
+	query := `select "user".user_id, "user".login, coalesce("user".email, ''), "user".disabled, user_role.role
+	from "user"
+	left join user_role on user_role.user_id = "user".user_id
+	order by "user".login, user_role.role`
+
+	return r.queryUsers(ctx, query)
+}
+
+func (r Repository) GetPasswordHash(ctx context.Context, userId string) (string, error) {
+	if userId == "" {
+		return "", fmt.Errorf("repository error: invalid userId")
+	}
+
+	query := `select password from "user" where user_id = $1`
+
+	var hashPassword string
+	err := r.db.QueryRowContext(ctx, query, userId).Scan(&hashPassword)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", api_models.NewNotFoundError("user not found")
+	}
+	if err != nil {
+		return "", fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return hashPassword, nil
+}
+
+func (r Repository) UpdatePassword(ctx context.Context, userId, hashPassword string) error {
+	if userId == "" {
+		return fmt.Errorf("repository error: invalid userId")
+	}
+	if hashPassword == "" {
+		return fmt.Errorf("repository error: invalid password")
+	}
+
+	query := `update "user" set password = $1 where user_id = $2`
+
+	return r.execUserUpdate(ctx, query, hashPassword, userId)
+}
+
+func (r Repository) SetUserDisabled(ctx context.Context, userId string, disabled bool) error {
+	if userId == "" {
+		return fmt.Errorf("repository error: invalid userId")
+	}
+
+	query := `update "user" set disabled = $1 where user_id = $2`
+
+	return r.execUserUpdate(ctx, query, disabled, userId)
+}
+
+func (r Repository) execUserUpdate(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("repository error: %s", err.Error())
+	}
+	if affected == 0 {
+		return api_models.NewNotFoundError("user not found")
+	}
+
+	return nil
+}
+
+func (r Repository) queryUsers(ctx context.Context, query string, args ...interface{}) ([]api_models.User, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repository error: %s", err.Error())
+	}
+	defer rows.Close()
+
+	var users []api_models.User
+
+	for rows.Next() {
+		var user api_models.User
+		var role *string
+
+		if err = rows.Scan(&user.UserId, &user.Login, &user.Email, &user.Disabled, &role); err != nil {
+			return nil, fmt.Errorf("repository error: %s", err.Error())
+		}
+
+		if len(users) == 0 || users[len(users)-1].UserId != user.UserId {
+			user.Roles = []string{}
+			users = append(users, user)
+		}
+		if role != nil {
+			last := &users[len(users)-1]
+			last.Roles = append(last.Roles, *role)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("repository error: %s", err.Error())
+	}
+
+	return users, nil
+}
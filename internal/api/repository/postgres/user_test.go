@@ -0,0 +1,422 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestRepository_GetUserByLogin(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	type mockBehaviour func(login string)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour mockBehaviour
+		login         string
+		want          api_models.User
+		wantErr       bool
+	}{
+		{
+			name:  "default",
+			login: "login",
+			mockBehaviour: func(login string) {
+				rows := sqlmock.NewRows([]string{"user_id", "login", "email", "disabled", "role"}).
+					AddRow("id", "login", "user@example.com", false, "admin").
+					AddRow("id", "login", "user@example.com", false, "viewer")
+				mock.ExpectQuery(`select "user".user_id, "user".login, coalesce\("user".email, ''\), "user".disabled, user_role.role`).
+					WithArgs(login).WillReturnRows(rows)
+			},
+			want: api_models.User{
+				UserId: "id",
+				Login:  "login",
+				Email:  "user@example.com",
+				Roles:  []string{"admin", "viewer"},
+			},
+			wantErr: false,
+		},
+		{
+			name:  "not found",
+			login: "login",
+			mockBehaviour: func(login string) {
+				rows := sqlmock.NewRows([]string{"user_id", "login", "email", "disabled", "role"})
+				mock.ExpectQuery(`select "user".user_id, "user".login, coalesce\("user".email, ''\), "user".disabled, user_role.role`).
+					WithArgs(login).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+		{
+			name:  "no login",
+			login: "",
+			mockBehaviour: func(login string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.login)
+
+			user, err := r.GetUserByLogin(context.Background(), testCase.login)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, user)
+			}
+		})
+	}
+}
+
+func TestRepository_GetUserById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	testTable := []struct {
+		name          string
+		userId        string
+		mockBehaviour func(userId string)
+		want          api_models.User
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				rows := sqlmock.NewRows([]string{"user_id", "login", "email", "disabled", "role"}).
+					AddRow("id", "login", "", false, "viewer")
+				mock.ExpectQuery(`select "user".user_id, "user".login, coalesce\("user".email, ''\), "user".disabled, user_role.role`).
+					WithArgs(userId).WillReturnRows(rows)
+			},
+			want: api_models.User{
+				UserId: "id",
+				Login:  "login",
+				Roles:  []string{"viewer"},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "not found",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				rows := sqlmock.NewRows([]string{"user_id", "login", "email", "disabled", "role"})
+				mock.ExpectQuery(`select "user".user_id, "user".login, coalesce\("user".email, ''\), "user".disabled, user_role.role`).
+					WithArgs(userId).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no userId",
+			userId: "",
+			mockBehaviour: func(userId string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.userId)
+
+			user, err := r.GetUserById(context.Background(), testCase.userId)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, user)
+			}
+		})
+	}
+}
+
+func TestRepository_ListUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		want          []api_models.User
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			mockBehaviour: func() {
+				rows := sqlmock.NewRows([]string{"user_id", "login", "email", "disabled", "role"}).
+					AddRow("1", "first", "", false, "admin").
+					AddRow("1", "first", "", false, "viewer").
+					AddRow("2", "second", "", true, nil)
+				mock.ExpectQuery(`select "user".user_id, "user".login, coalesce\("user".email, ''\), "user".disabled, user_role.role`).
+					WillReturnRows(rows)
+			},
+			want: []api_models.User{
+				{UserId: "1", Login: "first", Roles: []string{"admin", "viewer"}},
+				{UserId: "2", Login: "second", Roles: []string{}, Disabled: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "db error",
+			mockBehaviour: func() {
+				mock.ExpectQuery(`select "user".user_id, "user".login, coalesce\("user".email, ''\), "user".disabled, user_role.role`).
+					WillReturnError(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "interrupted rows",
+			mockBehaviour: func() {
+				rows := sqlmock.NewRows([]string{"user_id", "login", "email", "disabled", "role"}).
+					AddRow("1", "first", "", false, "admin").
+					AddRow("2", "second", "", false, "viewer").
+					RowError(1, fmt.Errorf("connection reset"))
+				mock.ExpectQuery(`select "user".user_id, "user".login, coalesce\("user".email, ''\), "user".disabled, user_role.role`).
+					WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			users, err := r.ListUsers(context.Background())
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, users)
+			}
+		})
+	}
+}
+
+func TestRepository_GetPasswordHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	testTable := []struct {
+		name          string
+		userId        string
+		mockBehaviour func(userId string)
+		want          string
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				rows := sqlmock.NewRows([]string{"password"}).AddRow("hash")
+				mock.ExpectQuery(`select password from "user"`).
+					WithArgs(userId).WillReturnRows(rows)
+			},
+			want:    "hash",
+			wantErr: false,
+		},
+		{
+			name:   "not found",
+			userId: "id",
+			mockBehaviour: func(userId string) {
+				rows := sqlmock.NewRows([]string{"password"})
+				mock.ExpectQuery(`select password from "user"`).
+					WithArgs(userId).WillReturnRows(rows)
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no userId",
+			userId: "",
+			mockBehaviour: func(userId string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.userId)
+
+			hashPassword, err := r.GetPasswordHash(context.Background(), testCase.userId)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, hashPassword)
+			}
+		})
+	}
+}
+
+func TestRepository_UpdatePassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	type args struct {
+		userId       string
+		hashPassword string
+	}
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func(args args)
+		args          args
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: args{userId: "id", hashPassword: "hash"},
+			mockBehaviour: func(args args) {
+				mock.ExpectExec(`update "user" set password`).
+					WithArgs(args.hashPassword, args.userId).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			args: args{userId: "id", hashPassword: "hash"},
+			mockBehaviour: func(args args) {
+				mock.ExpectExec(`update "user" set password`).
+					WithArgs(args.hashPassword, args.userId).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty password",
+			args: args{userId: "id"},
+			mockBehaviour: func(args args) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.args)
+
+			err := r.UpdatePassword(context.Background(), testCase.args.userId, testCase.args.hashPassword)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_SetUserDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	defer db.Close()
+
+	r := Repository{db: sqlx.NewDb(db, "pgx")}
+
+	type args struct {
+		userId   string
+		disabled bool
+	}
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func(args args)
+		args          args
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: args{userId: "id", disabled: true},
+			mockBehaviour: func(args args) {
+				mock.ExpectExec(`update "user" set disabled`).
+					WithArgs(args.disabled, args.userId).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "db error",
+			args: args{userId: "id", disabled: true},
+			mockBehaviour: func(args args) {
+				mock.ExpectExec(`update "user" set disabled`).
+					WithArgs(args.disabled, args.userId).WillReturnError(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "no userId",
+			args: args{},
+			mockBehaviour: func(args args) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.args)
+
+			err := r.SetUserDisabled(context.Background(), testCase.args.userId, testCase.args.disabled)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				if err = mock.ExpectationsWereMet(); err != nil {
+					t.Fatal(err)
+				}
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
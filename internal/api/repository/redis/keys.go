@@ -0,0 +1,279 @@
+package redis
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"sync"
+	"time"
+	"vk_test_task/config"
+	"vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+	"vk_test_task/internal/utils/encryption"
+)
+
+const (
+	signingKeyKey = "jwt-signing-key"
+	keysSetKey    = "jwt-keys"
+)
+
+var errUnreadableKey = errors.New("unable to decrypt key")
+
+type signingKey struct {
+	Kid       string `json:"kid"`
+	Seed      []byte `json:"seed"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+func (k signingKey) privateKey() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(k.Seed)
+}
+
+func (k signingKey) publicKey() ed25519.PublicKey {
+	return k.privateKey().Public().(ed25519.PublicKey)
+}
+
+// KeyStore хранит ключи подписи в редисе, чтобы все инстансы подписывали токены одним ключом,
+// и кеширует их в памяти. Ключ остается доступным для проверки, пока не истекут подписанные им токены.
+// Приватные ключи лежат в редисе только зашифрованными Server.KeyEncryptionKey
+type KeyStore struct {
+	db       *redis.Client
+	kek      []byte
+	rotation time.Duration
+	lifetime time.Duration
+
+	mu      sync.RWMutex
+	current *signingKey
+	keys    map[string]signingKey
+}
+
+func NewKeyStore(db *redis.Client, cfg *config.Config) (*KeyStore, error) {
+	kek, err := base64.StdEncoding.DecodeString(cfg.Server.KeyEncryptionKey)
+	if err != nil || len(kek) != 32 {
+		return nil, errors.New("Server.KeyEncryptionKey must be 32 bytes encoded in base64")
+	}
+
+	rotation := cfg.Server.KeyRotation
+	if rotation <= 0 {
+		rotation = common.KEY_ROTATION
+	}
+	tokenLifetime := max(cfg.Server.AccessLifetime, cfg.Server.RefreshLifetime, 0)
+
+	return &KeyStore{
+		db:       db,
+		kek:      kek,
+		rotation: time.Second * time.Duration(rotation),
+		// указатель на ключ подписи живет rotation, сам ключ - дольше на время жизни токенов и запас,
+		// поэтому указатель никогда не ссылается на уже удаленный ключ
+		lifetime: time.Second * time.Duration(rotation+tokenLifetime+common.KEY_EXPIRY_MARGIN),
+		keys:     map[string]signingKey{},
+	}, nil
+}
+
+func (s *KeyStore) SigningKey(ctx context.Context) (string, ed25519.PrivateKey, error) {
+	s.mu.RLock()
+	current := s.current
+	s.mu.RUnlock()
+
+	if current != nil && time.Now().Before(time.Unix(current.CreatedAt, 0).Add(s.rotation)) {
+		return current.Kid, current.privateKey(), nil
+	}
+
+	key, err := s.loadSigningKey(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	s.mu.Lock()
+	s.current = &key
+	s.keys[key.Kid] = key
+	s.mu.Unlock()
+
+	return key.Kid, key.privateKey(), nil
+}
+
+func (s *KeyStore) PublicKey(ctx context.Context, kid string) (ed25519.PublicKey, error) {
+	if kid == "" {
+		return nil, errors.New("invalid kid")
+	}
+
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	s.mu.RUnlock()
+
+	if !ok {
+		var err error
+		key, err = s.getKey(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		s.keys[kid] = key
+		s.mu.Unlock()
+	}
+
+	if time.Now().After(time.Unix(key.CreatedAt, 0).Add(s.lifetime)) {
+		s.mu.Lock()
+		delete(s.keys, kid)
+		s.mu.Unlock()
+		return nil, errors.New("key expired")
+	}
+
+	return key.publicKey(), nil
+}
+
+func (s *KeyStore) PublicKeys(ctx context.Context) ([]api_models.JWK, error) {
+	minCreatedAt := strconv.FormatInt(time.Now().Add(-s.lifetime).Unix(), 10)
+
+	err := s.db.ZRemRangeByScore(ctx, keysSetKey, "-inf", "("+minCreatedAt).Err()
+	if err != nil {
+		return nil, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	kids, err := s.db.ZRange(ctx, keysSetKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	keys := make([]api_models.JWK, 0, len(kids))
+	for _, kid := range kids {
+		publicKey, err := s.PublicKey(ctx, kid)
+		if err != nil {
+			continue
+		}
+
+		keys = append(keys, api_models.JWK{
+			Kty: "OKP",
+			Crv: "Ed25519",
+			Kid: kid,
+			Use: "sig",
+			Alg: "EdDSA",
+			X:   base64.RawURLEncoding.EncodeToString(publicKey),
+		})
+	}
+
+	return keys, nil
+}
+
+func (s *KeyStore) loadSigningKey(ctx context.Context) (signingKey, error) {
+
+	kid, err := s.db.Get(ctx, signingKeyKey).Result()
+	if err == nil {
+		key, err := s.getKey(ctx, kid)
+		if !errors.Is(err, errUnreadableKey) {
+			return key, err
+		}
+		// ключ записан без шифрования или другим KeyEncryptionKey - подписывать им нельзя, заменяем его новым
+		s.db.Del(ctx, signingKeyKey)
+	} else if !errors.Is(err, redis.Nil) {
+		return signingKey{}, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	key, err := s.createKey(ctx)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	ok, err := s.db.SetNX(ctx, signingKeyKey, key.Kid, s.rotation).Result()
+	if err != nil {
+		return signingKey{}, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	if !ok {
+		// другой инстанс успел сменить ключ раньше - используем его ключ
+		s.db.Del(ctx, keyKey(key.Kid))
+		s.db.ZRem(ctx, keysSetKey, key.Kid)
+
+		kid, err = s.db.Get(ctx, signingKeyKey).Result()
+		if err != nil {
+			return signingKey{}, fmt.Errorf("redis error: %s", err.Error())
+		}
+		return s.getKey(ctx, kid)
+	}
+
+	return key, nil
+}
+
+func (s *KeyStore) createKey(ctx context.Context) (signingKey, error) {
+
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return signingKey{}, err
+	}
+
+	key := signingKey{
+		Kid:       uuid.NewString(),
+		Seed:      seed,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	data, err := s.encode(key)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	if err = s.db.Set(ctx, keyKey(key.Kid), data, s.lifetime).Err(); err != nil {
+		return signingKey{}, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	err = s.db.ZAdd(ctx, keysSetKey, redis.Z{Score: float64(key.CreatedAt), Member: key.Kid}).Err()
+	if err != nil {
+		return signingKey{}, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return key, nil
+}
+
+func (s *KeyStore) getKey(ctx context.Context, kid string) (signingKey, error) {
+	data, err := s.db.Get(ctx, keyKey(kid)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return signingKey{}, errors.New("unknown key")
+	}
+	if err != nil {
+		return signingKey{}, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return s.decode(data)
+}
+
+// encode шифрует seed, kid используется как additional data: зашифрованный seed нельзя подставить другому kid
+func (s *KeyStore) encode(key signingKey) ([]byte, error) {
+	seed, err := encryption.Seal(s.kek, key.Seed, []byte(key.Kid))
+	if err != nil {
+		return nil, err
+	}
+
+	key.Seed = seed
+	return json.Marshal(key)
+}
+
+func (s *KeyStore) decode(data []byte) (signingKey, error) {
+	var key signingKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return signingKey{}, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	seed, err := encryption.Open(s.kek, key.Seed, []byte(key.Kid))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return signingKey{}, fmt.Errorf("%w %s", errUnreadableKey, key.Kid)
+	}
+
+	key.Seed = seed
+	return key, nil
+}
+
+func keyKey(kid string) string {
+	return fmt.Sprintf("jwt-key-%s", kid)
+}
+
+func (r Repository) GetPublicKeys(ctx context.Context) ([]api_models.JWK, error) {
+	return r.Keys.PublicKeys(ctx)
+}
@@ -0,0 +1,412 @@
+package redis
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"vk_test_task/config"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+)
+
+const testKEK = "MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="
+
+func newTestKeyStore(client *redis.Client, cfg *config.Config) *KeyStore {
+	c := *cfg
+	c.Server.KeyEncryptionKey = testKEK
+
+	s, _ := NewKeyStore(client, &c)
+	s.rotation = time.Hour
+	s.lifetime = 2 * time.Hour
+
+	key := testKey("kid", time.Now())
+	s.current = &key
+	s.keys[key.Kid] = key
+
+	return s
+}
+
+func testKey(kid string, createdAt time.Time) signingKey {
+	seed := make([]byte, ed25519.SeedSize)
+	copy(seed, kid)
+
+	return signingKey{Kid: kid, Seed: seed, CreatedAt: createdAt.Unix()}
+}
+
+func TestKeyStore_SigningKey(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	anyArgs := func(expected, actual []interface{}) error { return nil }
+
+	stored := testKey("stored", time.Now())
+	storedData, _ := newTestKeyStore(client, &config.Config{}).encode(stored)
+
+	plainData, _ := json.Marshal(testKey("plain", time.Now()))
+
+	type mockBehaviour func()
+
+	testTable := []struct {
+		name          string
+		current       *signingKey
+		mockBehaviour mockBehaviour
+		wantKid       string
+		wantErr       bool
+	}{
+		{
+			name:    "cached key",
+			current: &signingKey{Kid: "cached", Seed: stored.Seed, CreatedAt: time.Now().Unix()},
+			mockBehaviour: func() {
+			},
+			wantKid: "cached",
+			wantErr: false,
+		},
+		{
+			name:    "rotated by another instance",
+			current: &signingKey{Kid: "old", Seed: stored.Seed, CreatedAt: time.Now().Add(-2 * time.Hour).Unix()},
+			mockBehaviour: func() {
+				mock.ExpectGet(signingKeyKey).SetVal("stored")
+				mock.ExpectGet("jwt-key-stored").SetVal(string(storedData))
+			},
+			wantKid: "stored",
+			wantErr: false,
+		},
+		{
+			name: "new key",
+			mockBehaviour: func() {
+				mock.ExpectGet(signingKeyKey).RedisNil()
+				mock.CustomMatch(anyArgs).ExpectSet("jwt-key", "", time.Hour).SetVal("OK")
+				mock.CustomMatch(anyArgs).ExpectZAdd(keysSetKey, redis.Z{}).SetVal(1)
+				mock.CustomMatch(anyArgs).ExpectSetNX(signingKeyKey, "", time.Hour).SetVal(true)
+			},
+			wantErr: false,
+		},
+		{
+			name: "unencrypted signing key",
+			mockBehaviour: func() {
+				mock.ExpectGet(signingKeyKey).SetVal("plain")
+				mock.ExpectGet("jwt-key-plain").SetVal(string(plainData))
+				mock.ExpectDel(signingKeyKey).SetVal(1)
+				mock.CustomMatch(anyArgs).ExpectSet("jwt-key", "", time.Hour).SetVal("OK")
+				mock.CustomMatch(anyArgs).ExpectZAdd(keysSetKey, redis.Z{}).SetVal(1)
+				mock.CustomMatch(anyArgs).ExpectSetNX(signingKeyKey, "", time.Hour).SetVal(true)
+			},
+			wantErr: false,
+		},
+		{
+			name: "redis error",
+			mockBehaviour: func() {
+				mock.ExpectGet(signingKeyKey).SetErr(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			s := newTestKeyStore(client, &config.Config{})
+			s.current = testCase.current
+			if testCase.current != nil {
+				s.keys[testCase.current.Kid] = *testCase.current
+			}
+
+			testCase.mockBehaviour()
+
+			kid, key, err := s.SigningKey(context.Background())
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, key, ed25519.PrivateKeySize)
+				if testCase.wantKid != "" {
+					assert.Equal(t, testCase.wantKid, kid)
+				}
+
+				publicKey, err := s.PublicKey(context.Background(), kid)
+				assert.NoError(t, err)
+				assert.Equal(t, key.Public(), publicKey)
+			}
+		})
+	}
+}
+
+func TestKeyStore_PublicKey(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	s := newTestKeyStore(client, &config.Config{})
+
+	stored := testKey("stored", time.Now())
+	storedData, _ := s.encode(stored)
+
+	expired := testKey("expired", time.Now().Add(-3*time.Hour))
+	expiredData, _ := s.encode(expired)
+
+	// seed сохранен без шифрования
+	plain := testKey("plain", time.Now())
+	plainData, _ := json.Marshal(plain)
+
+	// зашифрованный seed другого ключа подставлен под чужой kid
+	swapped, _ := json.Marshal(signingKey{Kid: "swapped", Seed: sealedSeed(s, stored), CreatedAt: stored.CreatedAt})
+
+	type mockBehaviour func()
+
+	testTable := []struct {
+		name          string
+		kid           string
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "cached key",
+			kid:  "kid",
+			mockBehaviour: func() {
+			},
+			wantErr: false,
+		},
+		{
+			name: "key from redis",
+			kid:  "stored",
+			mockBehaviour: func() {
+				mock.ExpectGet("jwt-key-stored").SetVal(string(storedData))
+			},
+			wantErr: false,
+		},
+		{
+			name: "expired key",
+			kid:  "expired",
+			mockBehaviour: func() {
+				mock.ExpectGet("jwt-key-expired").SetVal(string(expiredData))
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown key",
+			kid:  "unknown",
+			mockBehaviour: func() {
+				mock.ExpectGet("jwt-key-unknown").RedisNil()
+			},
+			wantErr: true,
+		},
+		{
+			name: "unencrypted seed",
+			kid:  "plain",
+			mockBehaviour: func() {
+				mock.ExpectGet("jwt-key-plain").SetVal(string(plainData))
+			},
+			wantErr: true,
+		},
+		{
+			name: "seed of another key",
+			kid:  "swapped",
+			mockBehaviour: func() {
+				mock.ExpectGet("jwt-key-swapped").SetVal(string(swapped))
+			},
+			wantErr: true,
+		},
+		{
+			name: "no kid",
+			kid:  "",
+			mockBehaviour: func() {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			_, err := s.PublicKey(context.Background(), testCase.kid)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func sealedSeed(s *KeyStore, key signingKey) []byte {
+	data, _ := s.encode(key)
+
+	var stored signingKey
+	json.Unmarshal(data, &stored)
+	return stored.Seed
+}
+
+func TestNewKeyStore(t *testing.T) {
+	testTable := []struct {
+		name         string
+		server       config.Server
+		wantRotation time.Duration
+		wantErr      bool
+	}{
+		{
+			name:         "default",
+			server:       config.Server{KeyEncryptionKey: testKEK, KeyRotation: 3600, AccessLifetime: 60, RefreshLifetime: 600},
+			wantRotation: time.Hour,
+			wantErr:      false,
+		},
+		{
+			name:         "default rotation",
+			server:       config.Server{KeyEncryptionKey: testKEK},
+			wantRotation: time.Second * common.KEY_ROTATION,
+			wantErr:      false,
+		},
+		{
+			name:    "no key encryption key",
+			server:  config.Server{KeyRotation: 3600},
+			wantErr: true,
+		},
+		{
+			name:    "short key encryption key",
+			server:  config.Server{KeyEncryptionKey: "c2hvcnQ=", KeyRotation: 3600},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := NewKeyStore(nil, &config.Config{Server: testCase.server})
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.wantRotation, s.rotation)
+				// указатель на ключ подписи должен истекать раньше самого ключа
+				assert.Greater(t, s.lifetime, s.rotation+time.Second*time.Duration(testCase.server.RefreshLifetime))
+			}
+		})
+	}
+}
+
+func TestRepository_GetPublicKeys(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+	r.Keys = newTestKeyStore(client, r.Cfg)
+
+	anyArgs := func(expected, actual []interface{}) error { return nil }
+
+	mock.CustomMatch(anyArgs).ExpectZRemRangeByScore(keysSetKey, "", "").SetVal(0)
+	mock.ExpectZRange(keysSetKey, 0, -1).SetVal([]string{"kid", "missing"})
+	mock.ExpectGet("jwt-key-missing").RedisNil()
+
+	keys, err := r.GetPublicKeys(context.Background())
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, api_models.JWK{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		Kid: "kid",
+		Use: "sig",
+		Alg: "EdDSA",
+		X:   keys[0].X,
+	}, keys[0])
+	assert.NotEmpty(t, keys[0].X)
+}
+
+func TestRepository_VerifyAccessToken(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{
+		Server: config.Server{
+			AccessLifetime:  10,
+			RefreshLifetime: 10,
+		},
+	}}
+	r.Keys = newTestKeyStore(client, r.Cfg)
+
+	access, _, _ := r.CreateAccessToken(context.Background(), api_models.UserAccess{UserId: "userId"}, "sessionId")
+	refresh, _, _ := r.CreateRefreshToken(context.Background(), "userId", "sessionId")
+
+	other := Repository{DB: client, Cfg: r.Cfg}
+	other.Keys = newTestKeyStore(client, r.Cfg)
+	foreignKey := testKey("foreign", time.Now())
+	other.Keys.current = &foreignKey
+	foreign, _, _ := other.CreateAccessToken(context.Background(), api_models.UserAccess{UserId: "userId"}, "sessionId")
+
+	type mockBehaviour func()
+
+	testTable := []struct {
+		name          string
+		token         string
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name:  "default",
+			token: access,
+			mockBehaviour: func() {
+			},
+			wantErr: false,
+		},
+		{
+			name:  "refresh token",
+			token: refresh,
+			mockBehaviour: func() {
+			},
+			wantErr: true,
+		},
+		{
+			name:  "unknown key",
+			token: foreign,
+			mockBehaviour: func() {
+				mock.ExpectGet("jwt-key-foreign").RedisNil()
+			},
+			wantErr: true,
+		},
+		{
+			name:  "no token",
+			token: "",
+			mockBehaviour: func() {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			claims, err := r.VerifyAccessToken(context.Background(), testCase.token)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "userId", claims.UserId)
+				assert.Equal(t, "sessionId", claims.SessionId)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+	"vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+)
+
+func (r Repository) CreateMFAChallenge(ctx context.Context, challenge api_models.MFAChallenge) (string, error) {
+	if challenge.UserId == "" {
+		return "", fmt.Errorf("redis error: invalid userId")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return "", err
+	}
+
+	cmd := r.DB.Set(ctx, mfaChallengeKey(token), data, r.mfaChallengeLifetime())
+	if err = cmd.Err(); err != nil {
+		return "", fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return token, nil
+}
+
+func (r Repository) GetMFAChallenge(ctx context.Context, token string) (api_models.MFAChallenge, error) {
+	if token == "" {
+		return api_models.MFAChallenge{}, api_models.NewUnauthorizedError("invalid mfa token")
+	}
+
+	data, err := r.DB.Get(ctx, mfaChallengeKey(token)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return api_models.MFAChallenge{}, api_models.NewUnauthorizedError("invalid or expired mfa token")
+	}
+	if err != nil {
+		return api_models.MFAChallenge{}, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	var challenge api_models.MFAChallenge
+	if err = json.Unmarshal(data, &challenge); err != nil {
+		return api_models.MFAChallenge{}, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return challenge, nil
+}
+
+// RegisterMFAAttempt возвращает номер попытки для токена, счетчик живет столько же, сколько сам токен
+func (r Repository) RegisterMFAAttempt(ctx context.Context, token string) (int64, error) {
+	key := fmt.Sprintf("%s-attempts", mfaChallengeKey(token))
+
+	attempts, err := r.DB.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	err = r.DB.Expire(ctx, key, r.mfaChallengeLifetime()).Err()
+	if err != nil {
+		return 0, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return attempts, nil
+}
+
+// RegisterMFADisableAttempt считает попытки отключить 2FA для пользователя за время жизни mfa_token.
+// Возвращает, сколько ждать, если попыток больше maxAttempts
+func (r Repository) RegisterMFADisableAttempt(ctx context.Context, userId string, maxAttempts int64) (time.Duration, error) {
+	key := fmt.Sprintf("mfa-disable-attempts-%s", userId)
+	window := r.mfaChallengeLifetime()
+
+	attempts, err := r.DB.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis error: %s", err.Error())
+	}
+	if attempts == 1 {
+		if err = r.DB.Expire(ctx, key, window).Err(); err != nil {
+			return 0, fmt.Errorf("redis error: %s", err.Error())
+		}
+	}
+	if attempts <= maxAttempts {
+		return 0, nil
+	}
+
+	ttl, err := r.DB.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis error: %s", err.Error())
+	}
+	if ttl <= 0 {
+		return window, nil
+	}
+
+	return ttl, nil
+}
+
+func (r Repository) mfaChallengeLifetime() time.Duration {
+	lifetime := r.Cfg.MFA.ChallengeLifetime
+	if lifetime <= 0 {
+		lifetime = common.MFA_CHALLENGE_LIFETIME
+	}
+	return time.Second * time.Duration(lifetime)
+}
+
+func (r Repository) DeleteMFAChallenge(ctx context.Context, token string) error {
+	key := mfaChallengeKey(token)
+
+	err := r.DB.Del(ctx, key, fmt.Sprintf("%s-attempts", key)).Err()
+	if err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return nil
+}
+
+// MarkTOTPCodeUsed не дает использовать один и тот же код дважды, пока он еще действителен
+func (r Repository) MarkTOTPCodeUsed(ctx context.Context, userId, code string) (bool, error) {
+	ok, err := r.DB.SetNX(ctx, fmt.Sprintf("mfa-used-%s-%s", userId, code), 1, 2*time.Minute).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return ok, nil
+}
+
+func mfaChallengeKey(token string) string {
+	return fmt.Sprintf("mfa-challenge-%s", hashToken(token))
+}
@@ -0,0 +1,400 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"vk_test_task/config"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+)
+
+func TestRepository_CreateMFAChallenge(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{
+		MFA: config.MFA{
+			ChallengeLifetime: 300,
+		},
+	}}
+
+	anyArgs := func(expected, actual []interface{}) error { return nil }
+
+	testTable := []struct {
+		name          string
+		challenge     api_models.MFAChallenge
+		mockBehaviour func(challenge api_models.MFAChallenge)
+		wantErr       bool
+	}{
+		{
+			name:      "default",
+			challenge: api_models.MFAChallenge{UserId: "userId", Login: "login"},
+			mockBehaviour: func(challenge api_models.MFAChallenge) {
+				mock.CustomMatch(anyArgs).ExpectSet("mfa-challenge", "", 300*time.Second).SetVal("OK")
+			},
+			wantErr: false,
+		},
+		{
+			name:      "redis error",
+			challenge: api_models.MFAChallenge{UserId: "userId", Login: "login"},
+			mockBehaviour: func(challenge api_models.MFAChallenge) {
+				mock.CustomMatch(anyArgs).ExpectSet("mfa-challenge", "", 300*time.Second).SetErr(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name:      "no userId",
+			challenge: api_models.MFAChallenge{},
+			mockBehaviour: func(challenge api_models.MFAChallenge) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.challenge)
+
+			token, err := r.CreateMFAChallenge(context.Background(), testCase.challenge)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, token)
+			}
+		})
+	}
+}
+
+func TestRepository_RegisterMFAAttempt_DefaultLifetime(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	key := fmt.Sprintf("%s-attempts", mfaChallengeKey("token"))
+	mock.ExpectIncr(key).SetVal(1)
+	mock.ExpectExpire(key, common.MFA_CHALLENGE_LIFETIME*time.Second).SetVal(true)
+
+	attempts, err := r.RegisterMFAAttempt(context.Background(), "token")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), attempts)
+}
+
+func TestRepository_GetMFAChallenge(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	challenge := api_models.MFAChallenge{UserId: "userId", Login: "login", Device: "phone"}
+	data, _ := json.Marshal(challenge)
+
+	testTable := []struct {
+		name          string
+		token         string
+		mockBehaviour func(token string)
+		wantErr       bool
+	}{
+		{
+			name:  "default",
+			token: "token",
+			mockBehaviour: func(token string) {
+				mock.ExpectGet(mfaChallengeKey(token)).SetVal(string(data))
+			},
+			wantErr: false,
+		},
+		{
+			name:  "expired token",
+			token: "token",
+			mockBehaviour: func(token string) {
+				mock.ExpectGet(mfaChallengeKey(token)).RedisNil()
+			},
+			wantErr: true,
+		},
+		{
+			name:  "no token",
+			token: "",
+			mockBehaviour: func(token string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.token)
+
+			got, err := r.GetMFAChallenge(context.Background(), testCase.token)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, challenge, got)
+			}
+		})
+	}
+}
+
+func TestRepository_RegisterMFAAttempt(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{
+		MFA: config.MFA{
+			ChallengeLifetime: 300,
+		},
+	}}
+
+	testTable := []struct {
+		name          string
+		token         string
+		mockBehaviour func(token string)
+		want          int64
+		wantErr       bool
+	}{
+		{
+			name:  "default",
+			token: "token",
+			mockBehaviour: func(token string) {
+				key := fmt.Sprintf("%s-attempts", mfaChallengeKey(token))
+				mock.ExpectIncr(key).SetVal(2)
+				mock.ExpectExpire(key, 300*time.Second).SetVal(true)
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name:  "redis error",
+			token: "token",
+			mockBehaviour: func(token string) {
+				mock.ExpectIncr(fmt.Sprintf("%s-attempts", mfaChallengeKey(token))).SetErr(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.token)
+
+			attempts, err := r.RegisterMFAAttempt(context.Background(), testCase.token)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, attempts)
+			}
+		})
+	}
+}
+
+func TestRepository_RegisterMFADisableAttempt(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{
+		MFA: config.MFA{
+			ChallengeLifetime: 300,
+		},
+	}}
+
+	key := "mfa-disable-attempts-id"
+
+	testTable := []struct {
+		name           string
+		mockBehaviour  func()
+		wantRetryAfter time.Duration
+		wantErr        bool
+	}{
+		{
+			name: "first attempt",
+			mockBehaviour: func() {
+				mock.ExpectIncr(key).SetVal(1)
+				mock.ExpectExpire(key, 300*time.Second).SetVal(true)
+			},
+			wantRetryAfter: 0,
+		},
+		{
+			name: "last allowed attempt",
+			mockBehaviour: func() {
+				mock.ExpectIncr(key).SetVal(5)
+			},
+			wantRetryAfter: 0,
+		},
+		{
+			name: "too many attempts",
+			mockBehaviour: func() {
+				mock.ExpectIncr(key).SetVal(6)
+				mock.ExpectPTTL(key).SetVal(time.Minute)
+			},
+			wantRetryAfter: time.Minute,
+		},
+		{
+			name: "too many attempts without ttl",
+			mockBehaviour: func() {
+				mock.ExpectIncr(key).SetVal(6)
+				mock.ExpectPTTL(key).SetVal(-1)
+			},
+			wantRetryAfter: 300 * time.Second,
+		},
+		{
+			name: "redis error",
+			mockBehaviour: func() {
+				mock.ExpectIncr(key).SetErr(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			retryAfter, err := r.RegisterMFADisableAttempt(context.Background(), "id", 5)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.wantRetryAfter, retryAfter)
+			}
+		})
+	}
+}
+
+func TestRepository_DeleteMFAChallenge(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	testTable := []struct {
+		name          string
+		token         string
+		mockBehaviour func(token string)
+		wantErr       bool
+	}{
+		{
+			name:  "default",
+			token: "token",
+			mockBehaviour: func(token string) {
+				key := mfaChallengeKey(token)
+				mock.ExpectDel(key, fmt.Sprintf("%s-attempts", key)).SetVal(2)
+			},
+			wantErr: false,
+		},
+		{
+			name:  "redis error",
+			token: "token",
+			mockBehaviour: func(token string) {
+				key := mfaChallengeKey(token)
+				mock.ExpectDel(key, fmt.Sprintf("%s-attempts", key)).SetErr(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.token)
+
+			err := r.DeleteMFAChallenge(context.Background(), testCase.token)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_MarkTOTPCodeUsed(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		want          bool
+		wantErr       bool
+	}{
+		{
+			name: "first use",
+			mockBehaviour: func() {
+				mock.ExpectSetNX("mfa-used-userId-123456", 1, 2*time.Minute).SetVal(true)
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "already used",
+			mockBehaviour: func() {
+				mock.ExpectSetNX("mfa-used-userId-123456", 1, 2*time.Minute).SetVal(false)
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "redis error",
+			mockBehaviour: func() {
+				mock.ExpectSetNX("mfa-used-userId-123456", 1, 2*time.Minute).SetErr(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			ok, err := r.MarkTOTPCodeUsed(context.Background(), "userId", "123456")
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+)
+
+func (r Repository) CreatePasswordResetToken(ctx context.Context, userId string) (string, error) {
+	if userId == "" {
+		return "", fmt.Errorf("redis error: invalid userId")
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	lifetime := r.Cfg.Server.PasswordResetLifetime
+	if lifetime <= 0 {
+		lifetime = common.PASSWORD_RESET_LIFETIME
+	}
+
+	// в редисе хранится только хеш, чтобы утечка дампа не давала сбросить пароль
+	cmd := r.DB.Set(ctx, passwordResetKey(token), userId, time.Second*time.Duration(lifetime))
+	if err := cmd.Err(); err != nil {
+		return "", fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return token, nil
+}
+
+func (r Repository) ConsumePasswordResetToken(ctx context.Context, token string) (string, error) {
+	if token == "" {
+		return "", api_models.NewFieldError("token", "must not be empty")
+	}
+
+	userId, err := r.DB.GetDel(ctx, passwordResetKey(token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", api_models.NewFieldError("token", "invalid or expired token")
+	}
+	if err != nil {
+		return "", fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return userId, nil
+}
+
+// RegisterPasswordResetRequest считает запросы сброса пароля по логину и IP отдельно от неудачных входов,
+// чтобы сброс не приближал блокировку аккаунта. Возвращает, сколько ждать, если лимит за окно превышен
+func (r Repository) RegisterPasswordResetRequest(ctx context.Context, login, ip string) (time.Duration, error) {
+	retryAfter, err := r.countPasswordResetRequest(ctx, "login", login, common.PASSWORD_RESET_MAX_REQUESTS)
+	if err != nil || ip == "" {
+		return retryAfter, err
+	}
+
+	ipRetryAfter, err := r.countPasswordResetRequest(ctx, "ip", ip, common.PASSWORD_RESET_IP_MAX_REQUESTS)
+	if err != nil {
+		return 0, err
+	}
+
+	return max(retryAfter, ipRetryAfter), nil
+}
+
+func (r Repository) countPasswordResetRequest(ctx context.Context, kind, value string, maxRequests int64) (time.Duration, error) {
+	key := fmt.Sprintf("password-reset-requests-%s-%s", kind, value)
+	window := time.Second * common.PASSWORD_RESET_REQUESTS_WINDOW
+
+	requests, err := r.DB.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis error: %s", err.Error())
+	}
+	// окно отсчитывается от первого запроса и не продлевается следующими
+	if requests == 1 {
+		if err = r.DB.Expire(ctx, key, window).Err(); err != nil {
+			return 0, fmt.Errorf("redis error: %s", err.Error())
+		}
+	}
+	if requests <= maxRequests {
+		return 0, nil
+	}
+
+	ttl, err := r.DB.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis error: %s", err.Error())
+	}
+	if ttl <= 0 {
+		return window, nil
+	}
+
+	return ttl, nil
+}
+
+func passwordResetKey(token string) string {
+	return fmt.Sprintf("password-reset-%s", hashToken(token))
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
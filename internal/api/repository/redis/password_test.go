@@ -0,0 +1,242 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"vk_test_task/config"
+	"vk_test_task/internal/common"
+)
+
+func TestRepository_CreatePasswordResetToken(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{
+		Server: config.Server{
+			PasswordResetLifetime: 900,
+		},
+	}}
+
+	anyArgs := func(expected, actual []interface{}) error { return nil }
+
+	testTable := []struct {
+		name          string
+		userId        string
+		mockBehaviour func(userId string)
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			userId: "userId",
+			mockBehaviour: func(userId string) {
+				mock.CustomMatch(anyArgs).ExpectSet("password-reset", userId, 900*time.Second).SetVal("OK")
+			},
+			wantErr: false,
+		},
+		{
+			name:   "redis error",
+			userId: "userId",
+			mockBehaviour: func(userId string) {
+				mock.CustomMatch(anyArgs).ExpectSet("password-reset", userId, 900*time.Second).SetErr(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name:   "no userId",
+			userId: "",
+			mockBehaviour: func(userId string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.userId)
+
+			token, err := r.CreatePasswordResetToken(context.Background(), testCase.userId)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, token)
+			}
+		})
+	}
+}
+
+func TestRepository_CreatePasswordResetToken_DefaultLifetime(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	// ключ содержит хеш токена, поэтому сравнивается только срок жизни
+	ttlArgs := func(expected, actual []interface{}) error {
+		if fmt.Sprint(actual[3:]) != fmt.Sprint(expected[3:]) {
+			return fmt.Errorf("unexpected ttl %v", actual[3:])
+		}
+		return nil
+	}
+	mock.CustomMatch(ttlArgs).ExpectSet("password-reset", "userId", common.PASSWORD_RESET_LIFETIME*time.Second).SetVal("OK")
+
+	_, err := r.CreatePasswordResetToken(context.Background(), "userId")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, err)
+}
+
+func TestRepository_ConsumePasswordResetToken(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	testTable := []struct {
+		name          string
+		token         string
+		mockBehaviour func(token string)
+		wantErr       bool
+	}{
+		{
+			name:  "default",
+			token: "token",
+			mockBehaviour: func(token string) {
+				mock.ExpectGetDel(passwordResetKey(token)).SetVal("userId")
+			},
+			wantErr: false,
+		},
+		{
+			name:  "used or expired token",
+			token: "token",
+			mockBehaviour: func(token string) {
+				mock.ExpectGetDel(passwordResetKey(token)).RedisNil()
+			},
+			wantErr: true,
+		},
+		{
+			name:  "no token",
+			token: "",
+			mockBehaviour: func(token string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.token)
+
+			userId, err := r.ConsumePasswordResetToken(context.Background(), testCase.token)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "userId", userId)
+			}
+		})
+	}
+}
+
+func TestRepository_RegisterPasswordResetRequest(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	loginKey := "password-reset-requests-login-login"
+	ipKey := "password-reset-requests-ip-127.0.0.1"
+	window := time.Second * common.PASSWORD_RESET_REQUESTS_WINDOW
+
+	testTable := []struct {
+		name           string
+		ip             string
+		mockBehaviour  func()
+		wantRetryAfter time.Duration
+		wantErr        bool
+	}{
+		{
+			name: "first request",
+			ip:   "127.0.0.1",
+			mockBehaviour: func() {
+				mock.ExpectIncr(loginKey).SetVal(1)
+				mock.ExpectExpire(loginKey, window).SetVal(true)
+				mock.ExpectIncr(ipKey).SetVal(1)
+				mock.ExpectExpire(ipKey, window).SetVal(true)
+			},
+			wantRetryAfter: 0,
+		},
+		{
+			name: "login limit exceeded",
+			ip:   "127.0.0.1",
+			mockBehaviour: func() {
+				mock.ExpectIncr(loginKey).SetVal(common.PASSWORD_RESET_MAX_REQUESTS + 1)
+				mock.ExpectPTTL(loginKey).SetVal(time.Minute)
+				mock.ExpectIncr(ipKey).SetVal(2)
+			},
+			wantRetryAfter: time.Minute,
+		},
+		{
+			name: "ip limit exceeded",
+			ip:   "127.0.0.1",
+			mockBehaviour: func() {
+				mock.ExpectIncr(loginKey).SetVal(2)
+				mock.ExpectIncr(ipKey).SetVal(common.PASSWORD_RESET_IP_MAX_REQUESTS + 1)
+				mock.ExpectPTTL(ipKey).SetVal(2 * time.Minute)
+			},
+			wantRetryAfter: 2 * time.Minute,
+		},
+		{
+			name: "limit exceeded without ttl",
+			ip:   "",
+			mockBehaviour: func() {
+				mock.ExpectIncr(loginKey).SetVal(common.PASSWORD_RESET_MAX_REQUESTS + 1)
+				mock.ExpectPTTL(loginKey).SetVal(-1)
+			},
+			wantRetryAfter: window,
+		},
+		{
+			name: "redis error",
+			ip:   "127.0.0.1",
+			mockBehaviour: func() {
+				mock.ExpectIncr(loginKey).SetErr(fmt.Errorf("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			retryAfter, err := r.RegisterPasswordResetRequest(context.Background(), "login", testCase.ip)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.wantRetryAfter, retryAfter)
+			}
+		})
+	}
+}
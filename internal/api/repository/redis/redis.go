@@ -5,17 +5,29 @@ import (
 	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
 	"log/slog"
 	"time"
 	"vk_test_task/config"
 	"vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+	"vk_test_task/internal/metrics"
+	"vk_test_task/internal/tracing"
+	log "vk_test_task/pkg/logger"
 )
 
+// iat пишется с миллисекундами: токен, выпущенный в ту же секунду сразу после logout_all или смены ролей,
+// не должен считаться отозванным
+func init() {
+	jwt.TimePrecision = time.Millisecond
+}
+
 type Repository struct {
 	Cfg    *config.Config
 	Logger *slog.Logger
 	DB     *redis.Client
+	Keys   *KeyStore
 }
 
 func New(cfg *config.Config, logger *slog.Logger) Repository {
@@ -23,32 +35,52 @@ func New(cfg *config.Config, logger *slog.Logger) Repository {
 		Addr: fmt.Sprintf("%s:%s", cfg.Redis.Host, cfg.Redis.Port),
 		DB:   cfg.Redis.Database,
 	})
+	client.AddHook(metrics.RedisHook{})
+	client.AddHook(tracing.RedisHook{})
 
 	logger.Debug("redis database connected")
 
+	keys, err := NewKeyStore(client, cfg)
+	if err != nil {
+		log.Fatalf(logger, "key store error: %s", err.Error())
+	}
+
 	return Repository{
 		Cfg:    cfg,
 		Logger: logger,
 		DB:     client,
+		Keys:   keys,
 	}
 }
 
-func (r Repository) CreateAccessToken(userId string, isAdmin bool) (string, int64, error) {
-	if userId == "" {
+// Ping проверяет, что редис доступен, используется в /readyz
+func (r Repository) Ping(ctx context.Context) error {
+	if err := r.DB.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+	return nil
+}
+
+func (r Repository) CreateAccessToken(ctx context.Context, access api_models.UserAccess, sessionId string) (string, int64, error) {
+	if access.UserId == "" {
 		return "", 0, fmt.Errorf("redis error: invalid userId")
 	}
 
-	exp := time.Now().Add(time.Second * time.Duration(r.Cfg.Server.AccessLifetime)).Unix()
+	now := time.Now()
+	exp := now.Add(time.Second * time.Duration(r.Cfg.Server.AccessLifetime)).Unix()
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, api_models.AuthClaims{
-		UserId:  userId,
-		IsAdmin: isAdmin,
-		MapClaims: jwt.MapClaims{
-			"exp": exp,
+	tokenString, err := r.sign(ctx, api_models.AuthClaims{
+		UserId:      access.UserId,
+		SessionId:   sessionId,
+		Type:        common.AccessTokenType,
+		Roles:       access.Roles,
+		Permissions: access.Permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Unix(exp, 0)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
 		},
 	})
-
-	tokenString, err := token.SignedString([]byte(r.Cfg.Server.AccessSecret))
 	if err != nil {
 		return "", 0, err
 	}
@@ -56,17 +88,25 @@ func (r Repository) CreateAccessToken(userId string, isAdmin bool) (string, int6
 	return tokenString, exp, nil
 }
 
-func (r Repository) CreateRefreshToken(isAdmin bool) (string, int64, error) {
+func (r Repository) CreateRefreshToken(ctx context.Context, userId string, sessionId string) (string, int64, error) {
+	if userId == "" {
+		return "", 0, fmt.Errorf("redis error: invalid userId")
+	}
+	if sessionId == "" {
+		return "", 0, fmt.Errorf("redis error: invalid sessionId")
+	}
+
 	exp := time.Now().Add(time.Second * time.Duration(r.Cfg.Server.RefreshLifetime)).Unix()
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, api_models.RefreshClaims{
-		IsAdmin: isAdmin,
-		MapClaims: jwt.MapClaims{
-			"exp": exp,
+	tokenString, err := r.sign(ctx, api_models.RefreshClaims{
+		UserId:    userId,
+		SessionId: sessionId,
+		Type:      common.RefreshTokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Unix(exp, 0)),
+			ID:        uuid.NewString(),
 		},
 	})
-
-	tokenString, err := token.SignedString([]byte(r.Cfg.Server.RefreshSecret))
 	if err != nil {
 		return "", 0, err
 	}
@@ -74,80 +114,222 @@ func (r Repository) CreateRefreshToken(isAdmin bool) (string, int64, error) {
 	return tokenString, exp, nil
 }
 
-func (r Repository) VerifyRefreshToken(userId string, tokenString string) (bool, error) {
-	if userId == "" {
-		return false, errors.New("invalid userId")
+func (r Repository) VerifyAccessToken(ctx context.Context, tokenString string) (api_models.AuthClaims, error) {
+	if tokenString == "" {
+		return api_models.AuthClaims{}, api_models.NewUnauthorizedError("invalid token")
+	}
+
+	var claims api_models.AuthClaims
+	if err := r.parse(ctx, tokenString, &claims); err != nil {
+		return api_models.AuthClaims{}, err
+	}
+
+	if claims.Type != common.AccessTokenType {
+		return api_models.AuthClaims{}, api_models.NewUnauthorizedError("invalid token type")
 	}
+
+	if claims.UserId == "" {
+		return api_models.AuthClaims{}, api_models.NewUnauthorizedError("invalid token")
+	}
+
+	return claims, nil
+}
+
+func (r Repository) VerifyRefreshToken(ctx context.Context, tokenString string) (api_models.RefreshClaims, error) {
 	if tokenString == "" {
-		return false, errors.New("invalid token")
+		return api_models.RefreshClaims{}, api_models.NewUnauthorizedError("invalid token")
 	}
 
 	var claims api_models.RefreshClaims
-	token, err := jwt.ParseWithClaims(tokenString, &claims,
-		func(token *jwt.Token) (interface{}, error) { return []byte(r.Cfg.Server.RefreshSecret), nil })
-	if err != nil {
-		return false, err
+	if err := r.parse(ctx, tokenString, &claims); err != nil {
+		return api_models.RefreshClaims{}, err
 	}
 
-	if token.Valid == false {
-		return false, errors.New("invalid jwt token")
+	if claims.Type != common.RefreshTokenType {
+		return api_models.RefreshClaims{}, api_models.NewUnauthorizedError("invalid token type")
 	}
 
-	cmd := r.DB.Get(context.Background(), fmt.Sprintf("refresh-token-%s", userId))
+	if claims.UserId == "" {
+		return api_models.RefreshClaims{}, api_models.NewUnauthorizedError("invalid token")
+	}
 
-	if err = cmd.Err(); err != nil {
-		return false, fmt.Errorf("redis error: %s", err.Error())
+	// быстрая проверка без блокировок, окончательно токен сверяется при замене в RefreshTokensPair
+	current, err := r.sessionRefreshToken(ctx, claims.UserId, claims.SessionId)
+	if err != nil {
+		return api_models.RefreshClaims{}, err
 	}
 
-	if cmd.Val() != tokenString {
-		return false, errors.New("out of date token")
+	if current != tokenString {
+		// токен валиден, но уже был заменен - значит его переиспользуют, отзываем всю сессию
+		if err = r.RevokeSession(ctx, claims.UserId, claims.SessionId); err != nil {
+			return api_models.RefreshClaims{}, err
+		}
+		return api_models.RefreshClaims{}, api_models.NewUnauthorizedError("refresh token reuse detected")
 	}
 
-	return claims.IsAdmin, nil
+	return claims, nil
 }
 
-func (r Repository) UpdateAccessToken(userId string, refreshToken string) (string, int64, error) {
-	if userId == "" {
-		return "", 0, fmt.Errorf("redis error: invalid userId")
+// RefreshTokensPair выпускает новую пару и заменяет refresh токен сессии, только если в ней все еще лежит refreshToken.
+// Проверка и замена выполняются одним скриптом, поэтому из двух одновременных обновлений одним токеном проходит одно,
+// а второе считается переиспользованием и отзывает сессию
+func (r Repository) RefreshTokensPair(ctx context.Context, refreshToken string, claims api_models.RefreshClaims, access api_models.UserAccess, client api_models.ClientInfo) (string, string, int64, error) {
+	if claims.UserId != access.UserId {
+		return "", "", 0, fmt.Errorf("redis error: token belongs to another user")
 	}
-	if refreshToken == "" {
-		return "", 0, fmt.Errorf("redis error: invalid refreshToken")
+
+	accessToken, exp, err := r.CreateAccessToken(ctx, access, claims.SessionId)
+	if err != nil {
+		return "", "", 0, err
 	}
 
-	isAdmin, err := r.VerifyRefreshToken(userId, refreshToken)
+	newRefreshToken, _, err := r.CreateRefreshToken(ctx, claims.UserId, claims.SessionId)
 	if err != nil {
-		return "", 0, err
+		return "", "", 0, err
 	}
 
-	token, exp, err := r.CreateAccessToken(userId, isAdmin)
+	err = r.rotateSession(ctx, claims.UserId, claims.SessionId, refreshToken, newRefreshToken, client)
 	if err != nil {
-		return "", 0, err
+		return "", "", 0, err
 	}
 
-	return token, exp, nil
+	return accessToken, newRefreshToken, exp, nil
 }
 
-func (r Repository) CreateTokensPair(userId string, isAdmin bool) (string, string, int64, error) {
-	accessToken, exp, err := r.CreateAccessToken(userId, isAdmin)
+func (r Repository) CreateTokensPair(ctx context.Context, access api_models.UserAccess, client api_models.ClientInfo) (string, string, int64, error) {
+	sessionId := uuid.NewString()
+
+	accessToken, exp, err := r.CreateAccessToken(ctx, access, sessionId)
+	if err != nil {
+		return "", "", 0, err
+	}
+
+	refreshToken, _, err := r.CreateRefreshToken(ctx, access.UserId, sessionId)
 	if err != nil {
 		return "", "", 0, err
 	}
 
-	refreshToken, _, err := r.CreateRefreshToken(isAdmin)
+	err = r.createSession(ctx, access.UserId, sessionId, refreshToken, client)
 	if err != nil {
 		return "", "", 0, err
 	}
 
+	return accessToken, refreshToken, exp, nil
+}
+
+func (r Repository) RevokeAccessToken(ctx context.Context, tokenId string, exp int64) error {
+	if tokenId == "" {
+		return fmt.Errorf("redis error: invalid tokenId")
+	}
+
+	ttl := time.Until(time.Unix(exp, 0))
+	if ttl <= 0 {
+		return nil
+	}
+
+	cmd := r.DB.Set(ctx, fmt.Sprintf("access-denylist-%s", tokenId), 1, ttl)
+	if err := cmd.Err(); err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r Repository) RevokeAllAccessTokens(ctx context.Context, userId string) error {
+	if userId == "" {
+		return fmt.Errorf("redis error: invalid userId")
+	}
+
 	cmd := r.DB.Set(
-		context.Background(),
-		fmt.Sprintf("refresh-token-%s", userId),
-		refreshToken,
-		0,
+		ctx,
+		fmt.Sprintf("access-revoked-before-%s", userId),
+		time.Now().UnixMilli(),
+		time.Second*time.Duration(r.Cfg.Server.AccessLifetime),
 	)
+	if err := cmd.Err(); err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return nil
+}
 
-	if err = cmd.Err(); err != nil {
-		return "", "", 0, fmt.Errorf("redis error: %s", err.Error())
+func (r Repository) IsAccessTokenRevoked(ctx context.Context, claims api_models.AuthClaims) (bool, error) {
+	tokenId := claims.TokenId()
+	if tokenId == "" || claims.UserId == "" {
+		return true, nil
 	}
 
-	return accessToken, refreshToken, exp, nil
+	exists, err := r.DB.Exists(
+		ctx,
+		fmt.Sprintf("access-denylist-%s", tokenId),
+		fmt.Sprintf("access-revoked-session-%s", claims.SessionId),
+	).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis error: %s", err.Error())
+	}
+	if exists > 0 {
+		return true, nil
+	}
+
+	revokedBefore, err := r.DB.Get(ctx, fmt.Sprintf("access-revoked-before-%s", claims.UserId)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	issuedAt, err := claims.GetIssuedAt()
+	if err != nil || issuedAt == nil {
+		return true, nil
+	}
+
+	return issuedAt.UnixMilli() < revokedBefore, nil
+}
+
+func (r Repository) RevokeRefreshTokens(ctx context.Context, userId string) error {
+	if userId == "" {
+		return fmt.Errorf("redis error: invalid userId")
+	}
+
+	sessionIds, err := r.DB.SMembers(ctx, sessionsKey(userId)).Result()
+	if err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	for _, sessionId := range sessionIds {
+		if err = r.RevokeSession(ctx, userId, sessionId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r Repository) sign(ctx context.Context, claims jwt.Claims) (string, error) {
+	kid, key, err := r.Keys.SigningKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = kid
+
+	return token.SignedString(key)
+}
+
+func (r Repository) parse(ctx context.Context, tokenString string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return r.Keys.PublicKey(ctx, kid)
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}))
+	if err != nil {
+		return api_models.WrapError(api_models.CodeUnauthorized, "invalid token", err)
+	}
+
+	if token.Valid == false {
+		return api_models.NewUnauthorizedError("invalid token")
+	}
+
+	return nil
 }
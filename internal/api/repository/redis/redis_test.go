@@ -1,11 +1,15 @@
 package redis
 
 import (
+	"context"
 	"fmt"
 	"github.com/go-redis/redismock/v9"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 	"vk_test_task/config"
+	api_models "vk_test_task/internal/api/models"
 )
 
 func TestRepository_CreateAccessToken(t *testing.T) {
@@ -14,41 +18,46 @@ func TestRepository_CreateAccessToken(t *testing.T) {
 
 	r := Repository{DB: client, Cfg: &config.Config{
 		Server: config.Server{
-			AccessSecret:    "s",
 			AccessLifetime:  1,
-			RefreshSecret:   "r",
 			RefreshLifetime: 2,
 		},
 	}}
+	r.Keys = newTestKeyStore(client, r.Cfg)
 
 	testTable := []struct {
 		name    string
 		wantErr bool
-		userId  string
-		isAdmin bool
+		access  api_models.UserAccess
 	}{
 		{
 			name:    "default",
 			wantErr: false,
-			userId:  "id1",
-			isAdmin: true,
+			access: api_models.UserAccess{
+				UserId:      "id1",
+				Roles:       []string{"admin"},
+				Permissions: []string{"film:read"},
+			},
 		},
 		{
-			name:    "default",
+			name:    "no userId",
 			wantErr: true,
-			userId:  "",
-			isAdmin: true,
+			access:  api_models.UserAccess{},
 		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			_, _, err := r.CreateAccessToken(testCase.userId, testCase.isAdmin)
+			token, _, err := r.CreateAccessToken(context.Background(), testCase.access, "sessionId")
 
 			if testCase.wantErr == true {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+
+				claims, err := r.VerifyAccessToken(context.Background(), token)
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.access.Permissions, claims.Permissions)
+				assert.Equal(t, "sessionId", claims.SessionId)
 			}
 		})
 	}
@@ -60,33 +69,41 @@ func TestRepository_CreateRefreshToken(t *testing.T) {
 
 	r := Repository{DB: client, Cfg: &config.Config{
 		Server: config.Server{
-			AccessSecret:    "s",
 			AccessLifetime:  1,
-			RefreshSecret:   "r",
 			RefreshLifetime: 2,
 		},
 	}}
+	r.Keys = newTestKeyStore(client, r.Cfg)
 
 	testTable := []struct {
-		name    string
-		wantErr bool
-		isAdmin bool
+		name      string
+		wantErr   bool
+		userId    string
+		sessionId string
 	}{
 		{
-			name:    "default",
-			wantErr: false,
-			isAdmin: true,
+			name:      "default",
+			wantErr:   false,
+			userId:    "id1",
+			sessionId: "sessionId",
 		},
 		{
-			name:    "default",
-			wantErr: false,
-			isAdmin: false,
+			name:      "no userId",
+			wantErr:   true,
+			userId:    "",
+			sessionId: "sessionId",
+		},
+		{
+			name:      "no sessionId",
+			wantErr:   true,
+			userId:    "id1",
+			sessionId: "",
 		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			_, _, err := r.CreateRefreshToken(testCase.isAdmin)
+			_, _, err := r.CreateRefreshToken(context.Background(), testCase.userId, testCase.sessionId)
 
 			if testCase.wantErr == true {
 				assert.Error(t, err)
@@ -103,118 +120,177 @@ func TestRepository_VerifyRefreshToken(t *testing.T) {
 
 	r := Repository{DB: client, Cfg: &config.Config{
 		Server: config.Server{
-			AccessSecret:    "secret",
 			AccessLifetime:  1,
-			RefreshSecret:   "secret",
 			RefreshLifetime: 10000,
 		},
 	}}
+	r.Keys = newTestKeyStore(client, r.Cfg)
 
-	rtoken, _, _ := r.CreateRefreshToken(true)
+	current, _, _ := r.CreateRefreshToken(context.Background(), "userId", "sessionId")
+	rotated, _, _ := r.CreateRefreshToken(context.Background(), "userId", "sessionId")
+	access, _, _ := r.CreateAccessToken(context.Background(), api_models.UserAccess{UserId: "userId"}, "sessionId")
 
-	type mockBehaviour func(userId string, token string)
+	type mockBehaviour func(token string)
 
 	testTable := []struct {
 		name          string
 		wantErr       bool
-		userId        string
 		token         string
 		mockBehaviour mockBehaviour
 	}{
 		{
-			name:    "no userId",
+			name:    "default",
+			wantErr: false,
+			token:   current,
+			mockBehaviour: func(token string) {
+				mock.ExpectHGet("session-userId-sessionId", "refresh_token").SetVal(token)
+			},
+		},
+		{
+			name:    "reused token revokes session",
 			wantErr: true,
-			userId:  "",
-			mockBehaviour: func(userId string, token string) {
-
+			token:   rotated,
+			mockBehaviour: func(token string) {
+				mock.ExpectHGet("session-userId-sessionId", "refresh_token").SetVal(current)
+				mock.ExpectDel("session-userId-sessionId").SetVal(1)
+				mock.ExpectSRem("sessions-userId", "sessionId").SetVal(1)
+				mock.ExpectSet("access-revoked-session-sessionId", 1, time.Second).SetVal("OK")
+			},
+		},
+		{
+			name:    "revoked session",
+			wantErr: true,
+			token:   current,
+			mockBehaviour: func(token string) {
+				mock.ExpectHGet("session-userId-sessionId", "refresh_token").RedisNil()
 			},
-			token: rtoken,
 		},
 		{
 			name:    "no token",
 			wantErr: true,
-			userId:  "nmae",
-			mockBehaviour: func(userId string, token string) {
-
+			token:   "",
+			mockBehaviour: func(token string) {
+			},
+		},
+		{
+			name:    "access token",
+			wantErr: true,
+			token:   access,
+			mockBehaviour: func(token string) {
+			},
+		},
+		{
+			name:    "malformed token",
+			wantErr: true,
+			token:   "token",
+			mockBehaviour: func(token string) {
 			},
-			token: "",
 		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			_, err := r.VerifyRefreshToken(testCase.userId, testCase.token)
+			testCase.mockBehaviour(testCase.token)
 
-			testCase.mockBehaviour(testCase.userId, testCase.token)
+			claims, err := r.VerifyRefreshToken(context.Background(), testCase.token)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
 
 			if testCase.wantErr == true {
 				assert.Error(t, err)
 			} else {
-				if err = mock.ExpectationsWereMet(); err != nil {
-					t.Fatal(err)
-				}
 				assert.NoError(t, err)
+				assert.Equal(t, "userId", claims.UserId)
+				assert.Equal(t, "sessionId", claims.SessionId)
 			}
 		})
 	}
 }
 
-func TestRepository_UpdateAccessToken(t *testing.T) {
+func TestRepository_RefreshTokensPair(t *testing.T) {
 	client, mock := redismock.NewClientMock()
 	defer client.Close()
 
 	r := Repository{DB: client, Cfg: &config.Config{
 		Server: config.Server{
-			AccessSecret:    "secret",
 			AccessLifetime:  1,
-			RefreshSecret:   "secret",
 			RefreshLifetime: 10000,
 		},
 	}}
+	r.Keys = newTestKeyStore(client, r.Cfg)
 
-	rtoken, _, _ := r.CreateRefreshToken(true)
+	anyArgs := func(expected, actual []interface{}) error { return nil }
 
-	type mockBehaviour func(userId string, token string)
+	type mockBehaviour func()
 
 	testTable := []struct {
 		name          string
 		wantErr       bool
-		userId        string
-		token         string
+		claims        api_models.RefreshClaims
+		access        api_models.UserAccess
 		mockBehaviour mockBehaviour
 	}{
 		{
-			name:    "no userId",
+			name:    "default",
+			wantErr: false,
+			claims:  api_models.RefreshClaims{UserId: "userId", SessionId: "sessionId"},
+			access:  api_models.UserAccess{UserId: "userId"},
+			mockBehaviour: func() {
+				mock.CustomMatch(anyArgs).ExpectEvalSha(rotateSessionScript.Hash(),
+					[]string{"session-userId-sessionId", "sessions-userId"},
+					"refreshToken", "", "", "", 0, 10000, "sessionId").SetVal(int64(1))
+			},
+		},
+		{
+			name:    "rotated by concurrent refresh",
 			wantErr: true,
-			userId:  "",
-			mockBehaviour: func(userId string, token string) {
-
+			claims:  api_models.RefreshClaims{UserId: "userId", SessionId: "sessionId"},
+			access:  api_models.UserAccess{UserId: "userId"},
+			mockBehaviour: func() {
+				mock.CustomMatch(anyArgs).ExpectEvalSha(rotateSessionScript.Hash(),
+					[]string{"session-userId-sessionId", "sessions-userId"},
+					"refreshToken", "", "", "", 0, 10000, "sessionId").SetVal(int64(0))
+				mock.ExpectDel("session-userId-sessionId").SetVal(1)
+				mock.ExpectSRem("sessions-userId", "sessionId").SetVal(1)
+				mock.ExpectSet("access-revoked-session-sessionId", 1, time.Second).SetVal("OK")
 			},
-			token: rtoken,
 		},
 		{
-			name:    "no token",
+			name:    "session revoked concurrently",
 			wantErr: true,
-			userId:  "nmae",
-			mockBehaviour: func(userId string, token string) {
-
+			claims:  api_models.RefreshClaims{UserId: "userId", SessionId: "sessionId"},
+			access:  api_models.UserAccess{UserId: "userId"},
+			mockBehaviour: func() {
+				mock.CustomMatch(anyArgs).ExpectEvalSha(rotateSessionScript.Hash(),
+					[]string{"session-userId-sessionId", "sessions-userId"},
+					"refreshToken", "", "", "", 0, 10000, "sessionId").SetVal(int64(-1))
+			},
+		},
+		{
+			name:    "another user",
+			wantErr: true,
+			claims:  api_models.RefreshClaims{UserId: "userId", SessionId: "sessionId"},
+			access:  api_models.UserAccess{UserId: "another"},
+			mockBehaviour: func() {
 			},
-			token: "",
 		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			_, err := r.VerifyRefreshToken(testCase.userId, testCase.token)
+			testCase.mockBehaviour()
 
-			testCase.mockBehaviour(testCase.userId, testCase.token)
+			_, _, _, err := r.RefreshTokensPair(context.Background(), "refreshToken", testCase.claims, testCase.access, api_models.ClientInfo{})
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
 
 			if testCase.wantErr == true {
 				assert.Error(t, err)
 			} else {
-				if err = mock.ExpectationsWereMet(); err != nil {
-					t.Fatal(err)
-				}
 				assert.NoError(t, err)
 			}
 		})
@@ -227,56 +303,300 @@ func TestRepository_CreateTokensPair(t *testing.T) {
 
 	r := Repository{DB: client, Cfg: &config.Config{
 		Server: config.Server{
-			AccessSecret:    "secret",
 			AccessLifetime:  1,
-			RefreshSecret:   "secret",
 			RefreshLifetime: 10000,
 		},
 	}}
+	r.Keys = newTestKeyStore(client, r.Cfg)
+
+	anyArgs := func(expected, actual []interface{}) error { return nil }
 
-	type mockBehaviour func(userId string, isAdmin bool)
+	type mockBehaviour func()
 
 	testTable := []struct {
 		name          string
 		wantErr       bool
-		userId        string
-		isAdmin       bool
+		access        api_models.UserAccess
 		mockBehaviour mockBehaviour
 	}{
 		{
 			name:    "default",
+			wantErr: false,
+			access:  api_models.UserAccess{UserId: "userId"},
+			mockBehaviour: func() {
+				mock.CustomMatch(anyArgs).ExpectHSet("session",
+					"refresh_token", "", "device", "", "ip", "", "user_agent", "", "created_at", 0, "last_used_at", 0).SetVal(6)
+				mock.CustomMatch(anyArgs).ExpectExpire("session", 0).SetVal(true)
+				mock.CustomMatch(anyArgs).ExpectSAdd("sessions-userId", "").SetVal(1)
+				mock.ExpectExpire("sessions-userId", 10000*time.Second).SetVal(true)
+			},
+		},
+		{
+			name:    "redis error",
 			wantErr: true,
-			userId:  "userid",
-			mockBehaviour: func(userId string, isAdmin bool) {
-				mock.ExpectSet(fmt.Sprintf("refresh-token-%s", userId), "", 0)
+			access:  api_models.UserAccess{UserId: "userId"},
+			mockBehaviour: func() {
+				mock.CustomMatch(anyArgs).ExpectHSet("session",
+					"refresh_token", "", "device", "", "ip", "", "user_agent", "", "created_at", 0, "last_used_at", 0).
+					SetErr(fmt.Errorf("error"))
 			},
-			isAdmin: true,
 		},
+		{
+			name:    "no userId",
+			wantErr: true,
+			access:  api_models.UserAccess{},
+			mockBehaviour: func() {
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			_, _, _, err := r.CreateTokensPair(context.Background(), testCase.access, api_models.ClientInfo{})
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_RevokeAccessToken(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	type mockBehaviour func(tokenId string)
+
+	testTable := []struct {
+		name          string
+		wantErr       bool
+		tokenId       string
+		exp           int64
+		mockBehaviour mockBehaviour
+	}{
 		{
 			name:    "default",
+			wantErr: false,
+			tokenId: "jti",
+			exp:     time.Now().Add(time.Hour).Unix(),
+			mockBehaviour: func(tokenId string) {
+				mock.CustomMatch(func(expected, actual []interface{}) error { return nil }).
+					ExpectSet("access-denylist-jti", 1, time.Hour).SetVal("OK")
+			},
+		},
+		{
+			name:    "expired token",
+			wantErr: false,
+			tokenId: "jti",
+			exp:     time.Now().Add(-time.Hour).Unix(),
+			mockBehaviour: func(tokenId string) {
+			},
+		},
+		{
+			name:    "no tokenId",
+			wantErr: true,
+			tokenId: "",
+			mockBehaviour: func(tokenId string) {
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.tokenId)
+
+			err := r.RevokeAccessToken(context.Background(), testCase.tokenId, testCase.exp)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_IsAccessTokenRevoked(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	now := time.Now().UnixMilli()
+	claims := api_models.AuthClaims{
+		UserId:           "userId",
+		SessionId:        "sessionId",
+		RegisteredClaims: jwt.RegisteredClaims{ID: "jti", IssuedAt: jwt.NewNumericDate(time.UnixMilli(now))},
+	}
+
+	type mockBehaviour func()
+
+	testTable := []struct {
+		name          string
+		claims        api_models.AuthClaims
+		want          bool
+		wantErr       bool
+		mockBehaviour mockBehaviour
+	}{
+		{
+			name:   "not revoked",
+			claims: claims,
+			want:   false,
+			mockBehaviour: func() {
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(0)
+				mock.ExpectGet("access-revoked-before-userId").RedisNil()
+			},
+		},
+		{
+			name:   "denylisted",
+			claims: claims,
+			want:   true,
+			mockBehaviour: func() {
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(1)
+			},
+		},
+		{
+			name:   "issued before logout all",
+			claims: claims,
+			want:   true,
+			mockBehaviour: func() {
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(0)
+				mock.ExpectGet("access-revoked-before-userId").SetVal(fmt.Sprint(now + 10))
+			},
+		},
+		{
+			name:   "issued after logout all",
+			claims: claims,
+			want:   false,
+			mockBehaviour: func() {
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(0)
+				mock.ExpectGet("access-revoked-before-userId").SetVal(fmt.Sprint(now - 10))
+			},
+		},
+		{
+			name:   "issued in the same millisecond as logout all",
+			claims: claims,
+			want:   false,
+			mockBehaviour: func() {
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(0)
+				mock.ExpectGet("access-revoked-before-userId").SetVal(fmt.Sprint(now))
+			},
+		},
+		{
+			name:   "no jti",
+			claims: api_models.AuthClaims{UserId: "userId"},
+			want:   true,
+			mockBehaviour: func() {
+			},
+		},
+		{
+			name:    "redis error",
+			claims:  claims,
+			wantErr: true,
+			mockBehaviour: func() {
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetErr(fmt.Errorf("error"))
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			revoked, err := r.IsAccessTokenRevoked(context.Background(), testCase.claims)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, revoked)
+			}
+		})
+	}
+}
+
+func TestRepository_RevokeRefreshTokens(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	type mockBehaviour func(userId string)
+
+	testTable := []struct {
+		name          string
+		wantErr       bool
+		userId        string
+		mockBehaviour mockBehaviour
+	}{
+		{
+			name:    "default",
+			wantErr: false,
+			userId:  "userId",
+			mockBehaviour: func(userId string) {
+				mock.ExpectSMembers("sessions-userId").SetVal([]string{"sessionId"})
+				mock.ExpectDel("session-userId-sessionId").SetVal(1)
+				mock.ExpectSRem("sessions-userId", "sessionId").SetVal(1)
+				mock.ExpectSet("access-revoked-session-sessionId", 1, 0).SetVal("OK")
+			},
+		},
+		{
+			name:    "no userId",
 			wantErr: true,
 			userId:  "",
-			mockBehaviour: func(userId string, isAdmin bool) {
-				mock.ExpectSet(fmt.Sprintf("refresh-token-%s", userId), "", 0)
+			mockBehaviour: func(userId string) {
 			},
-			isAdmin: true,
 		},
 	}
 
 	for _, testCase := range testTable {
 		t.Run(testCase.name, func(t *testing.T) {
-			_, _, _, err := r.CreateTokensPair(testCase.userId, testCase.isAdmin)
+			testCase.mockBehaviour(testCase.userId)
+
+			err := r.RevokeRefreshTokens(context.Background(), testCase.userId)
 
-			testCase.mockBehaviour(testCase.userId, testCase.isAdmin)
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
 
 			if testCase.wantErr == true {
 				assert.Error(t, err)
 			} else {
-				if err = mock.ExpectationsWereMet(); err != nil {
-					t.Fatal(err)
-				}
 				assert.NoError(t, err)
 			}
 		})
 	}
 }
+
+func TestRepository_Ping(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client}
+
+	mock.ExpectPing().SetVal("PONG")
+	assert.NoError(t, r.Ping(context.Background()))
+
+	mock.ExpectPing().SetErr(fmt.Errorf("connection refused"))
+	assert.Error(t, r.Ping(context.Background()))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,189 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func sessionKey(userId, sessionId string) string {
+	return fmt.Sprintf("session-%s-%s", userId, sessionId)
+}
+
+func sessionsKey(userId string) string {
+	return fmt.Sprintf("sessions-%s", userId)
+}
+
+func (r Repository) GetSessions(ctx context.Context, userId string) ([]api_models.Session, error) {
+	if userId == "" {
+		return nil, fmt.Errorf("redis error: invalid userId")
+	}
+
+	sessionIds, err := r.DB.SMembers(ctx, sessionsKey(userId)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	sessions := make([]api_models.Session, 0, len(sessionIds))
+
+	for _, sessionId := range sessionIds {
+		values, err := r.DB.HGetAll(ctx, sessionKey(userId, sessionId)).Result()
+		if err != nil {
+			return nil, fmt.Errorf("redis error: %s", err.Error())
+		}
+
+		// сессия истекла по ttl, но осталась в списке
+		if len(values) == 0 {
+			if err = r.DB.SRem(ctx, sessionsKey(userId), sessionId).Err(); err != nil {
+				return nil, fmt.Errorf("redis error: %s", err.Error())
+			}
+			continue
+		}
+
+		createdAt, _ := strconv.ParseInt(values["created_at"], 10, 64)
+		lastUsedAt, _ := strconv.ParseInt(values["last_used_at"], 10, 64)
+
+		sessions = append(sessions, api_models.Session{
+			SessionId:  sessionId,
+			Device:     values["device"],
+			IP:         values["ip"],
+			UserAgent:  values["user_agent"],
+			CreatedAt:  time.Unix(createdAt, 0).UTC(),
+			LastUsedAt: time.Unix(lastUsedAt, 0).UTC(),
+		})
+	}
+
+	return sessions, nil
+}
+
+func (r Repository) RevokeSession(ctx context.Context, userId string, sessionId string) error {
+	if userId == "" {
+		return fmt.Errorf("redis error: invalid userId")
+	}
+	if sessionId == "" {
+		return fmt.Errorf("redis error: invalid sessionId")
+	}
+
+	deleted, err := r.DB.Del(ctx, sessionKey(userId, sessionId)).Result()
+	if err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	err = r.DB.SRem(ctx, sessionsKey(userId), sessionId).Err()
+	if err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	if deleted == 0 {
+		return nil
+	}
+
+	err = r.DB.Set(
+		ctx,
+		fmt.Sprintf("access-revoked-session-%s", sessionId),
+		1,
+		time.Second*time.Duration(r.Cfg.Server.AccessLifetime),
+	).Err()
+	if err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r Repository) createSession(ctx context.Context, userId, sessionId, refreshToken string, client api_models.ClientInfo) error {
+	now := time.Now().Unix()
+	lifetime := time.Second * time.Duration(r.Cfg.Server.RefreshLifetime)
+
+	err := r.DB.HSet(ctx, sessionKey(userId, sessionId),
+		"refresh_token", refreshToken,
+		"device", client.Device,
+		"ip", client.IP,
+		"user_agent", client.UserAgent,
+		"created_at", now,
+		"last_used_at", now,
+	).Err()
+	if err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	if err = r.DB.Expire(ctx, sessionKey(userId, sessionId), lifetime).Err(); err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	if err = r.DB.SAdd(ctx, sessionsKey(userId), sessionId).Err(); err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	if err = r.DB.Expire(ctx, sessionsKey(userId), lifetime).Err(); err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return nil
+}
+
+// rotateSessionScript заменяет refresh токен сессии, если текущий совпадает с ожидаемым.
+// Возвращает 1 при замене, 0 если токен уже заменен и -1 если сессия удалена или убрана из списка сессий пользователя.
+// Удаленную сессию скрипт не трогает: HSET на отсутствующий ключ создал бы ее заново, но уже невидимой в /sessions
+var rotateSessionScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 or redis.call('SISMEMBER', KEYS[2], ARGV[7]) == 0 then
+    return -1
+end
+if redis.call('HGET', KEYS[1], 'refresh_token') ~= ARGV[1] then
+    return 0
+end
+redis.call('HSET', KEYS[1], 'refresh_token', ARGV[2], 'ip', ARGV[3], 'user_agent', ARGV[4], 'last_used_at', ARGV[5])
+redis.call('EXPIRE', KEYS[1], ARGV[6])
+redis.call('EXPIRE', KEYS[2], ARGV[6])
+return 1
+`)
+
+func (r Repository) rotateSession(ctx context.Context, userId, sessionId, oldRefreshToken, newRefreshToken string, client api_models.ClientInfo) error {
+	rotated, err := rotateSessionScript.Run(ctx, r.DB,
+		[]string{sessionKey(userId, sessionId), sessionsKey(userId)},
+		oldRefreshToken,
+		newRefreshToken,
+		client.IP,
+		client.UserAgent,
+		time.Now().Unix(),
+		r.Cfg.Server.RefreshLifetime,
+		sessionId,
+	).Int()
+	if err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	if rotated < 0 {
+		return api_models.NewUnauthorizedError("revoked token")
+	}
+
+	if rotated == 0 {
+		// токен успел заменить параллельный запрос - это то же переиспользование, что и в VerifyRefreshToken
+		if err = r.RevokeSession(ctx, userId, sessionId); err != nil {
+			return err
+		}
+		return api_models.NewUnauthorizedError("refresh token reuse detected")
+	}
+
+	return nil
+}
+
+func (r Repository) sessionRefreshToken(ctx context.Context, userId, sessionId string) (string, error) {
+	if sessionId == "" {
+		return "", api_models.NewUnauthorizedError("invalid token")
+	}
+
+	token, err := r.DB.HGet(ctx, sessionKey(userId, sessionId), "refresh_token").Result()
+	if errors.Is(err, redis.Nil) {
+		return "", api_models.NewUnauthorizedError("revoked token")
+	}
+	if err != nil {
+		return "", fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return token, nil
+}
@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"vk_test_task/config"
+)
+
+func TestRepository_GetSessions(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	type mockBehaviour func(userId string)
+
+	testTable := []struct {
+		name          string
+		wantErr       bool
+		wantLen       int
+		userId        string
+		mockBehaviour mockBehaviour
+	}{
+		{
+			name:    "default",
+			wantErr: false,
+			wantLen: 1,
+			userId:  "userId",
+			mockBehaviour: func(userId string) {
+				mock.ExpectSMembers("sessions-userId").SetVal([]string{"s1", "s2"})
+				mock.ExpectHGetAll("session-userId-s1").SetVal(map[string]string{
+					"device":       "phone",
+					"ip":           "127.0.0.1",
+					"user_agent":   "agent",
+					"created_at":   "1",
+					"last_used_at": "2",
+				})
+				mock.ExpectHGetAll("session-userId-s2").SetVal(map[string]string{})
+				mock.ExpectSRem("sessions-userId", "s2").SetVal(1)
+			},
+		},
+		{
+			name:    "redis error",
+			wantErr: true,
+			userId:  "userId",
+			mockBehaviour: func(userId string) {
+				mock.ExpectSMembers("sessions-userId").SetErr(fmt.Errorf("error"))
+			},
+		},
+		{
+			name:    "no userId",
+			wantErr: true,
+			userId:  "",
+			mockBehaviour: func(userId string) {
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.userId)
+
+			sessions, err := r.GetSessions(context.Background(), testCase.userId)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, sessions, testCase.wantLen)
+			}
+		})
+	}
+}
+
+func TestRepository_RevokeSession(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{
+		Server: config.Server{
+			AccessLifetime: 10,
+		},
+	}}
+
+	type mockBehaviour func(userId, sessionId string)
+
+	testTable := []struct {
+		name          string
+		wantErr       bool
+		userId        string
+		sessionId     string
+		mockBehaviour mockBehaviour
+	}{
+		{
+			name:      "default",
+			wantErr:   false,
+			userId:    "userId",
+			sessionId: "sessionId",
+			mockBehaviour: func(userId, sessionId string) {
+				mock.ExpectDel("session-userId-sessionId").SetVal(1)
+				mock.ExpectSRem("sessions-userId", "sessionId").SetVal(1)
+				mock.ExpectSet("access-revoked-session-sessionId", 1, 10*time.Second).SetVal("OK")
+			},
+		},
+		{
+			name:      "unknown session",
+			wantErr:   false,
+			userId:    "userId",
+			sessionId: "sessionId",
+			mockBehaviour: func(userId, sessionId string) {
+				mock.ExpectDel("session-userId-sessionId").SetVal(0)
+				mock.ExpectSRem("sessions-userId", "sessionId").SetVal(0)
+			},
+		},
+		{
+			name:      "no sessionId",
+			wantErr:   true,
+			userId:    "userId",
+			sessionId: "",
+			mockBehaviour: func(userId, sessionId string) {
+			},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.userId, testCase.sessionId)
+
+			err := r.RevokeSession(context.Background(), testCase.userId, testCase.sessionId)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"vk_test_task/config"
+	"vk_test_task/internal/common"
+)
+
+// signInConfig - настройки SignIn, незаданные значения берутся из common, чтобы конфиг без секции SignIn
+// не отключал ограничение попыток
+func (r Repository) signInConfig() config.SignIn {
+	cfg := r.Cfg.SignIn
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = common.SIGNIN_MAX_ATTEMPTS
+	}
+	if cfg.IPMaxAttempts <= 0 {
+		cfg.IPMaxAttempts = common.SIGNIN_IP_MAX_ATTEMPTS
+	}
+	if cfg.AttemptsWindow <= 0 {
+		cfg.AttemptsWindow = common.SIGNIN_ATTEMPTS_WINDOW
+	}
+	if cfg.LockoutDuration <= 0 {
+		cfg.LockoutDuration = common.SIGNIN_LOCKOUT_DURATION
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = common.SIGNIN_BACKOFF_BASE
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = common.SIGNIN_BACKOFF_MAX
+	}
+	return cfg
+}
+
+func (r Repository) SignInRetryAfter(ctx context.Context, login, ip string) (time.Duration, error) {
+	keys := []string{
+		fmt.Sprintf("signin-lock-login-%s", login),
+		fmt.Sprintf("signin-backoff-login-%s", login),
+	}
+	if ip != "" {
+		keys = append(keys,
+			fmt.Sprintf("signin-lock-ip-%s", ip),
+			fmt.Sprintf("signin-backoff-ip-%s", ip),
+		)
+	}
+
+	var retryAfter time.Duration
+	for _, key := range keys {
+		ttl, err := r.DB.PTTL(ctx, key).Result()
+		if err != nil {
+			return 0, fmt.Errorf("redis error: %s", err.Error())
+		}
+
+		retryAfter = max(retryAfter, ttl)
+	}
+
+	return retryAfter, nil
+}
+
+func (r Repository) RegisterSignInFailure(ctx context.Context, login, ip string) error {
+	cfg := r.signInConfig()
+
+	err := r.registerFailure(ctx, cfg, "login", login, cfg.MaxAttempts)
+	if err != nil {
+		return err
+	}
+
+	if ip == "" {
+		return nil
+	}
+
+	return r.registerFailure(ctx, cfg, "ip", ip, cfg.IPMaxAttempts)
+}
+
+func (r Repository) ResetSignInFailures(ctx context.Context, login string) error {
+	if login == "" {
+		return fmt.Errorf("redis error: invalid login")
+	}
+
+	cmd := r.DB.Del(
+		ctx,
+		fmt.Sprintf("signin-failures-login-%s", login),
+		fmt.Sprintf("signin-backoff-login-%s", login),
+	)
+	if err := cmd.Err(); err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r Repository) UnlockAccount(ctx context.Context, login string) error {
+	if login == "" {
+		return fmt.Errorf("redis error: invalid login")
+	}
+
+	cmd := r.DB.Del(
+		ctx,
+		fmt.Sprintf("signin-failures-login-%s", login),
+		fmt.Sprintf("signin-backoff-login-%s", login),
+		fmt.Sprintf("signin-lock-login-%s", login),
+	)
+	if err := cmd.Err(); err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (r Repository) registerFailure(ctx context.Context, cfg config.SignIn, kind, value string, maxAttempts int64) error {
+	failuresKey := fmt.Sprintf("signin-failures-%s-%s", kind, value)
+
+	failures, err := r.DB.Incr(ctx, failuresKey).Result()
+	if err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	err = r.DB.Expire(ctx, failuresKey, time.Second*time.Duration(cfg.AttemptsWindow)).Err()
+	if err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	if failures >= maxAttempts {
+		// после блокировки счетчик начинается заново
+		err = r.DB.Set(ctx, fmt.Sprintf("signin-lock-%s-%s", kind, value), 1,
+			time.Second*time.Duration(cfg.LockoutDuration)).Err()
+		if err != nil {
+			return fmt.Errorf("redis error: %s", err.Error())
+		}
+
+		if err = r.DB.Del(ctx, failuresKey).Err(); err != nil {
+			return fmt.Errorf("redis error: %s", err.Error())
+		}
+
+		return nil
+	}
+
+	backoff := signInBackoff(failures, cfg.BackoffBase, cfg.BackoffMax)
+	if backoff <= 0 {
+		return nil
+	}
+
+	err = r.DB.Set(ctx, fmt.Sprintf("signin-backoff-%s-%s", kind, value), 1, backoff).Err()
+	if err != nil {
+		return fmt.Errorf("redis error: %s", err.Error())
+	}
+
+	return nil
+}
+
+// signInBackoff удваивает задержку с каждой неудачной попыткой: base, 2*base, 4*base... но не больше maxDelay
+func signInBackoff(failures, base, maxDelay int64) time.Duration {
+	if base <= 0 || failures <= 0 {
+		return 0
+	}
+
+	delay := base << min(failures-1, 20)
+	if maxDelay > 0 {
+		delay = min(delay, maxDelay)
+	}
+
+	return time.Second * time.Duration(delay)
+}
@@ -0,0 +1,264 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redismock/v9"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"vk_test_task/config"
+	"vk_test_task/internal/common"
+)
+
+func TestRepository_SignInRetryAfter(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	type args struct {
+		login string
+		ip    string
+	}
+
+	testTable := []struct {
+		name          string
+		args          args
+		mockBehaviour func(args args)
+		want          time.Duration
+		wantErr       bool
+	}{
+		{
+			name: "not limited",
+			args: args{login: "login", ip: "ip"},
+			mockBehaviour: func(args args) {
+				mock.ExpectPTTL("signin-lock-login-login").SetVal(-2)
+				mock.ExpectPTTL("signin-backoff-login-login").SetVal(-2)
+				mock.ExpectPTTL("signin-lock-ip-ip").SetVal(-2)
+				mock.ExpectPTTL("signin-backoff-ip-ip").SetVal(-2)
+			},
+			want:    0,
+			wantErr: false,
+		},
+		{
+			name: "longest limit wins",
+			args: args{login: "login", ip: "ip"},
+			mockBehaviour: func(args args) {
+				mock.ExpectPTTL("signin-lock-login-login").SetVal(-2)
+				mock.ExpectPTTL("signin-backoff-login-login").SetVal(2 * time.Second)
+				mock.ExpectPTTL("signin-lock-ip-ip").SetVal(time.Minute)
+				mock.ExpectPTTL("signin-backoff-ip-ip").SetVal(-2)
+			},
+			want:    time.Minute,
+			wantErr: false,
+		},
+		{
+			name: "no ip",
+			args: args{login: "login"},
+			mockBehaviour: func(args args) {
+				mock.ExpectPTTL("signin-lock-login-login").SetVal(time.Hour)
+				mock.ExpectPTTL("signin-backoff-login-login").SetVal(-2)
+			},
+			want:    time.Hour,
+			wantErr: false,
+		},
+		{
+			name: "redis error",
+			args: args{login: "login"},
+			mockBehaviour: func(args args) {
+				mock.ExpectPTTL("signin-lock-login-login").SetErr(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.args)
+
+			retryAfter, err := r.SignInRetryAfter(context.Background(), testCase.args.login, testCase.args.ip)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.want, retryAfter)
+			}
+		})
+	}
+}
+
+func TestRepository_RegisterSignInFailure(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{
+		SignIn: config.SignIn{
+			MaxAttempts:     5,
+			IPMaxAttempts:   20,
+			AttemptsWindow:  600,
+			LockoutDuration: 900,
+			BackoffBase:     1,
+			BackoffMax:      30,
+		},
+	}}
+
+	type args struct {
+		login string
+		ip    string
+	}
+
+	testTable := []struct {
+		name          string
+		args          args
+		mockBehaviour func(args args)
+		wantErr       bool
+	}{
+		{
+			name: "backoff",
+			args: args{login: "login", ip: "ip"},
+			mockBehaviour: func(args args) {
+				mock.ExpectIncr("signin-failures-login-login").SetVal(3)
+				mock.ExpectExpire("signin-failures-login-login", 600*time.Second).SetVal(true)
+				mock.ExpectSet("signin-backoff-login-login", 1, 4*time.Second).SetVal("OK")
+				mock.ExpectIncr("signin-failures-ip-ip").SetVal(1)
+				mock.ExpectExpire("signin-failures-ip-ip", 600*time.Second).SetVal(true)
+				mock.ExpectSet("signin-backoff-ip-ip", 1, time.Second).SetVal("OK")
+			},
+			wantErr: false,
+		},
+		{
+			name: "lockout",
+			args: args{login: "login"},
+			mockBehaviour: func(args args) {
+				mock.ExpectIncr("signin-failures-login-login").SetVal(5)
+				mock.ExpectExpire("signin-failures-login-login", 600*time.Second).SetVal(true)
+				mock.ExpectSet("signin-lock-login-login", 1, 900*time.Second).SetVal("OK")
+				mock.ExpectDel("signin-failures-login-login").SetVal(1)
+			},
+			wantErr: false,
+		},
+		{
+			name: "redis error",
+			args: args{login: "login"},
+			mockBehaviour: func(args args) {
+				mock.ExpectIncr("signin-failures-login-login").SetErr(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.args)
+
+			err := r.RegisterSignInFailure(context.Background(), testCase.args.login, testCase.args.ip)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRepository_RegisterSignInFailure_Defaults(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	// конфиг без секции SignIn не должен отключать задержки и блокировку
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	mock.ExpectIncr("signin-failures-login-login").SetVal(2)
+	mock.ExpectExpire("signin-failures-login-login", common.SIGNIN_ATTEMPTS_WINDOW*time.Second).SetVal(true)
+	mock.ExpectSet("signin-backoff-login-login", 1, 2*common.SIGNIN_BACKOFF_BASE*time.Second).SetVal("OK")
+	mock.ExpectIncr("signin-failures-ip-ip").SetVal(common.SIGNIN_IP_MAX_ATTEMPTS)
+	mock.ExpectExpire("signin-failures-ip-ip", common.SIGNIN_ATTEMPTS_WINDOW*time.Second).SetVal(true)
+	mock.ExpectSet("signin-lock-ip-ip", 1, common.SIGNIN_LOCKOUT_DURATION*time.Second).SetVal("OK")
+	mock.ExpectDel("signin-failures-ip-ip").SetVal(1)
+
+	err := r.RegisterSignInFailure(context.Background(), "login", "ip")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, err)
+
+	mock.ExpectIncr("signin-failures-login-login").SetVal(common.SIGNIN_MAX_ATTEMPTS)
+	mock.ExpectExpire("signin-failures-login-login", common.SIGNIN_ATTEMPTS_WINDOW*time.Second).SetVal(true)
+	mock.ExpectSet("signin-lock-login-login", 1, common.SIGNIN_LOCKOUT_DURATION*time.Second).SetVal("OK")
+	mock.ExpectDel("signin-failures-login-login").SetVal(1)
+
+	err = r.RegisterSignInFailure(context.Background(), "login", "")
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, err)
+}
+
+func TestRepository_UnlockAccount(t *testing.T) {
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	r := Repository{DB: client, Cfg: &config.Config{}}
+
+	testTable := []struct {
+		name          string
+		login         string
+		mockBehaviour func(login string)
+		wantErr       bool
+	}{
+		{
+			name:  "default",
+			login: "login",
+			mockBehaviour: func(login string) {
+				mock.ExpectDel("signin-failures-login-login", "signin-backoff-login-login", "signin-lock-login-login").SetVal(3)
+			},
+			wantErr: false,
+		},
+		{
+			name:  "no login",
+			login: "",
+			mockBehaviour: func(login string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour(testCase.login)
+
+			err := r.UnlockAccount(context.Background(), testCase.login)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			if testCase.wantErr == true {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSignInBackoff(t *testing.T) {
+	assert.Equal(t, time.Duration(0), signInBackoff(1, 0, 30))
+	assert.Equal(t, time.Second, signInBackoff(1, 1, 30))
+	assert.Equal(t, 8*time.Second, signInBackoff(4, 1, 30))
+	assert.Equal(t, 30*time.Second, signInBackoff(10, 1, 30))
+	assert.Equal(t, time.Duration(1<<20)*time.Second, signInBackoff(100, 1, 0))
+}
@@ -2,11 +2,39 @@
 
 package api
 
-// ifacemaker -f ./repository/redis/redis.go -s Repository -i TokenRepositoryInterface -p api -o ./tokenRepository.go
+import (
+	"context"
+	"time"
+	api_models "vk_test_task/internal/api/models"
+)
+
+// ifacemaker -f ./repository/redis/redis.go -f ./repository/redis/session.go -f ./repository/redis/keys.go -f ./repository/redis/throttle.go -f ./repository/redis/password.go -f ./repository/redis/mfa.go -s Repository -i TokenRepositoryInterface -p api -o ./tokenRepository.go
 type TokenRepositoryInterface interface {
-	CreateAccessToken(userId string, isAdmin bool) (string, int64, error)
-	CreateRefreshToken(isAdmin bool) (string, int64, error)
-	VerifyRefreshToken(userId string, tokenString string) (bool, error)
-	UpdateAccessToken(userId string, refreshToken string) (string, int64, error)
-	CreateTokensPair(userId string, isAdmin bool) (string, string, int64, error)
+	Ping(ctx context.Context) error
+	CreateAccessToken(ctx context.Context, access api_models.UserAccess, sessionId string) (string, int64, error)
+	CreateRefreshToken(ctx context.Context, userId string, sessionId string) (string, int64, error)
+	VerifyAccessToken(ctx context.Context, tokenString string) (api_models.AuthClaims, error)
+	VerifyRefreshToken(ctx context.Context, tokenString string) (api_models.RefreshClaims, error)
+	RefreshTokensPair(ctx context.Context, refreshToken string, claims api_models.RefreshClaims, access api_models.UserAccess, client api_models.ClientInfo) (string, string, int64, error)
+	CreateTokensPair(ctx context.Context, access api_models.UserAccess, client api_models.ClientInfo) (string, string, int64, error)
+	RevokeAccessToken(ctx context.Context, tokenId string, exp int64) error
+	RevokeAllAccessTokens(ctx context.Context, userId string) error
+	IsAccessTokenRevoked(ctx context.Context, claims api_models.AuthClaims) (bool, error)
+	RevokeRefreshTokens(ctx context.Context, userId string) error
+	GetSessions(ctx context.Context, userId string) ([]api_models.Session, error)
+	RevokeSession(ctx context.Context, userId string, sessionId string) error
+	GetPublicKeys(ctx context.Context) ([]api_models.JWK, error)
+	SignInRetryAfter(ctx context.Context, login, ip string) (time.Duration, error)
+	RegisterSignInFailure(ctx context.Context, login, ip string) error
+	ResetSignInFailures(ctx context.Context, login string) error
+	UnlockAccount(ctx context.Context, login string) error
+	CreatePasswordResetToken(ctx context.Context, userId string) (string, error)
+	ConsumePasswordResetToken(ctx context.Context, token string) (string, error)
+	RegisterPasswordResetRequest(ctx context.Context, login, ip string) (time.Duration, error)
+	CreateMFAChallenge(ctx context.Context, challenge api_models.MFAChallenge) (string, error)
+	GetMFAChallenge(ctx context.Context, token string) (api_models.MFAChallenge, error)
+	RegisterMFAAttempt(ctx context.Context, token string) (int64, error)
+	RegisterMFADisableAttempt(ctx context.Context, userId string, maxAttempts int64) (time.Duration, error)
+	DeleteMFAChallenge(ctx context.Context, token string) error
+	MarkTOTPCodeUsed(ctx context.Context, userId, code string) (bool, error)
 }
@@ -3,20 +3,46 @@
 package api
 
 import (
+	"context"
 	api_models "vk_test_task/internal/api/models"
 )
 
-// ifacemaker -f actor.go -f auth.go -f film.go -f postgres.go -s Repository -i RepositoryInterface -p api -o ../repository.go -y
+// ifacemaker -f actor.go -f apiKey.go -f auth.go -f film.go -f health.go -f mfa.go -f role.go -f user.go -f usecase.go -s UseCase -i UseCaseInterface -p api -o ../usecase.go
 type UseCaseInterface interface {
-	CreateActor(params api_models.CreateActorParams) (string, error)
-	GetActors() (api_models.GetActorsResponse, error)
-	UpdateActor(params api_models.UpdateActorParams) error
-	DeleteActor(params api_models.DeleteActorParams) error
-	SignIn(params api_models.AuthParams) (api_models.SignInUseCaseResponse, error)
-	SignUp(params api_models.AuthParams) error
-	CreateFilm(params api_models.CreateFilmParams) (string, error)
-	GetFilms(params api_models.GetFilmsParams) (api_models.GetFilmsResponse, error)
-	UpdateFilm(params api_models.UpdateFilmParams) error
-	DeleteFilm(params api_models.DeleteFilmParams) error
-	SearchFilm(params api_models.SearchFilmParams) (api_models.SearchFilmResponse, error)
+	CreateActor(ctx context.Context, params api_models.CreateActorParams) (string, error)
+	GetActors(ctx context.Context, params api_models.GetActorsParams) (api_models.GetActorsResponse, error)
+	GetActor(ctx context.Context, actorId string) (api_models.ActorDetails, error)
+	UpdateActor(ctx context.Context, params api_models.UpdateActorParams) error
+	DeleteActor(ctx context.Context, params api_models.DeleteActorParams) error
+	CreateAPIKey(ctx context.Context, claims api_models.AuthClaims, params api_models.CreateAPIKeyParams) (api_models.CreateAPIKeyResponse, error)
+	GetAPIKeys(ctx context.Context) (api_models.GetAPIKeysResponse, error)
+	RevokeAPIKey(ctx context.Context, params api_models.RevokeAPIKeyParams) error
+	AuthenticateAPIKey(ctx context.Context, key string) (api_models.AuthClaims, error)
+	SignIn(ctx context.Context, params api_models.AuthParams) (api_models.SignInUseCaseResponse, error)
+	SignUp(ctx context.Context, params api_models.AuthParams) error
+	Refresh(ctx context.Context, params api_models.RefreshParams) (api_models.SignInUseCaseResponse, error)
+	Logout(ctx context.Context, claims api_models.AuthClaims) error
+	LogoutAll(ctx context.Context, claims api_models.AuthClaims) error
+	GetSessions(ctx context.Context, claims api_models.AuthClaims) (api_models.GetSessionsResponse, error)
+	RevokeSession(ctx context.Context, claims api_models.AuthClaims, params api_models.RevokeSessionParams) error
+	GetJWKS(ctx context.Context) (api_models.JWKS, error)
+	CreateFilm(ctx context.Context, params api_models.CreateFilmParams) (string, error)
+	GetFilms(ctx context.Context, params api_models.GetFilmsParams) (api_models.GetFilmsResponse, error)
+	GetFilm(ctx context.Context, filmId string) (api_models.FilmDetails, error)
+	UpdateFilm(ctx context.Context, params api_models.UpdateFilmParams) error
+	DeleteFilm(ctx context.Context, params api_models.DeleteFilmParams) error
+	SearchFilm(ctx context.Context, params api_models.SearchFilmParams) (api_models.SearchFilmResponse, error)
+	Readiness(ctx context.Context) api_models.ReadinessResponse
+	Version() api_models.VersionResponse
+	EnrollMFA(ctx context.Context, claims api_models.AuthClaims) (api_models.EnrollMFAResponse, error)
+	ConfirmMFA(ctx context.Context, claims api_models.AuthClaims, params api_models.MFACodeParams) (api_models.RecoveryCodesResponse, error)
+	DisableMFA(ctx context.Context, claims api_models.AuthClaims, params api_models.MFACodeParams) error
+	SignInMFA(ctx context.Context, params api_models.SignInMFAParams) (api_models.SignInUseCaseResponse, error)
+	GetRoles(ctx context.Context) (api_models.GetRolesResponse, error)
+	GrantRole(ctx context.Context, params api_models.RoleParams) error
+	RevokeRole(ctx context.Context, params api_models.RoleParams) error
+	UnlockUser(ctx context.Context, params api_models.UnlockUserParams) error
+	ChangePassword(ctx context.Context, claims api_models.AuthClaims, params api_models.ChangePasswordParams) error
+	ForgotPassword(ctx context.Context, params api_models.ForgotPasswordParams) error
+	ResetPassword(ctx context.Context, params api_models.ResetPasswordParams) error
 }
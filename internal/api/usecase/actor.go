@@ -1,82 +1,137 @@
 package api_usecase
 
 import (
+	"context"
 	"fmt"
 	"github.com/google/uuid"
 	"time"
+	"unicode/utf8"
 	api_models "vk_test_task/internal/api/models"
 	"vk_test_task/internal/common"
 )
 
-func (u UseCase) CreateActor(params api_models.CreateActorParams) (string, error) {
+func (u UseCase) CreateActor(ctx context.Context, params api_models.CreateActorParams) (string, error) {
 	if params.Birth.Sub(time.Now()) > 0 {
-		return "", fmt.Errorf("usecase error: invalid actor birth")
+		return "", api_models.NewFieldError("birth", "must not be in the future")
 	}
 	if len(params.Name) < 1 {
-		return "", fmt.Errorf("usecase error: invalid actor name length")
+		return "", api_models.NewFieldError("name", "must not be empty")
+	}
+	if utf8.RuneCountInString(params.Name) > common.ACTOR_NAME_MAXSIZE {
+		return "", api_models.NewFieldError("name", fmt.Sprintf("must be at most %d characters", common.ACTOR_NAME_MAXSIZE))
 	}
 	if !(params.Name[0] <= 'Z' && params.Name[0] >= 'A') {
-		return "", fmt.Errorf("usecase error: invalid actor name")
+		return "", api_models.NewFieldError("name", "must start with a capital latin letter")
 	}
 	if params.Sex != common.ACTOR_SEX_MALE && params.Sex != common.ACTOR_SEX_FEMALE {
-		return "", fmt.Errorf("usecase error: invalid actor sex")
+		return "", api_models.NewFieldError("sex", "must be 1 (male) or 2 (female)")
 	}
 
 	actorId, err := uuid.NewUUID()
 	if err != nil {
-		return "", fmt.Errorf("usecase error: %s", err.Error())
+		return "", fmt.Errorf("usecase error: %w", err)
 	}
 	params.ActorId = actorId.String()
 
-	err = u.db.CreateActor(params)
+	err = u.db.CreateActor(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("usecase error: %s", err.Error())
+		return "", fmt.Errorf("usecase error: %w", err)
 	}
 
 	return params.ActorId, nil
 }
 
-func (u UseCase) GetActors() (api_models.GetActorsResponse, error) {
-	response, err := u.db.GetActors()
+func (u UseCase) GetActors(ctx context.Context, params api_models.GetActorsParams) (api_models.GetActorsResponse, error) {
+	if fieldErrors := params.Validate(); len(fieldErrors) > 0 {
+		return api_models.GetActorsResponse{}, api_models.NewValidationError("invalid query parameters", fieldErrors...)
+	}
+
+	page, err := u.newPage(api_models.ActorSort, params.Limit, params.Cursor)
 	if err != nil {
-		return api_models.GetActorsResponse{}, fmt.Errorf("usecase error: %s", err.Error())
+		return api_models.GetActorsResponse{}, err
+	}
+
+	response, err := u.db.GetActors(ctx, page)
+	if err != nil {
+		return api_models.GetActorsResponse{}, fmt.Errorf("usecase error: %w", err)
 	}
 	return response, nil
 }
 
-func (u UseCase) UpdateActor(params api_models.UpdateActorParams) error {
-	if params.Birth.Sub(time.Now()) > 0 {
-		return fmt.Errorf("usecase error: invalid actor birth")
+func (u UseCase) GetActor(ctx context.Context, actorId string) (api_models.ActorDetails, error) {
+	if actorId == "" {
+		return api_models.ActorDetails{}, api_models.NewFieldError("actor_id", "must not be empty")
 	}
-	if len(params.Name) < 1 {
-		return fmt.Errorf("usecase error: invalid actor name length")
+
+	response, err := u.db.GetActorDetails(ctx, actorId)
+	if err != nil {
+		return api_models.ActorDetails{}, fmt.Errorf("usecase error: %w", err)
 	}
-	if !(params.Name[0] <= 'Z' && params.Name[0] >= 'A') {
-		return fmt.Errorf("usecase error: invalid actor name")
+	return response, nil
+}
+
+func (u UseCase) UpdateActor(ctx context.Context, params api_models.UpdateActorParams) error {
+	if params.ActorId == "" {
+		return api_models.NewFieldError("actor_id", "must not be empty")
 	}
-	if params.Sex != common.ACTOR_SEX_MALE && params.Sex != common.ACTOR_SEX_FEMALE {
-		return fmt.Errorf("usecase error: invalid actor sex")
+	if params.Name.Null || params.Sex.Null || params.Birth.Null {
+		return api_models.NewValidationError("invalid actor", nullFieldErrors(map[string]bool{
+			"name":  params.Name.Null,
+			"sex":   params.Sex.Null,
+			"birth": params.Birth.Null,
+		})...)
 	}
-	if params.ActorId == "" {
-		return fmt.Errorf("usecase error: invalid id")
+	if params.Birth.Set && params.Birth.Value.Sub(time.Now()) > 0 {
+		return api_models.NewFieldError("birth", "must not be in the future")
+	}
+	if params.Name.Set {
+		if len(params.Name.Value) < 1 {
+			return api_models.NewFieldError("name", "must not be empty")
+		}
+		if utf8.RuneCountInString(params.Name.Value) > common.ACTOR_NAME_MAXSIZE {
+			return api_models.NewFieldError("name", fmt.Sprintf("must be at most %d characters", common.ACTOR_NAME_MAXSIZE))
+		}
+		if !(params.Name.Value[0] <= 'Z' && params.Name.Value[0] >= 'A') {
+			return api_models.NewFieldError("name", "must start with a capital latin letter")
+		}
+	}
+	if params.Sex.Set && params.Sex.Value != common.ACTOR_SEX_MALE && params.Sex.Value != common.ACTOR_SEX_FEMALE {
+		return api_models.NewFieldError("sex", "must be 1 (male) or 2 (female)")
 	}
 
-	err := u.db.UpdateActor(params)
+	version, err := u.db.GetActorVersion(ctx, params.ActorId)
 	if err != nil {
-		return fmt.Errorf("usecase error: %s", err.Error())
+		return fmt.Errorf("usecase error: %w", err)
+	}
+	if err = checkVersion(params.IfMatch, version, "actor"); err != nil {
+		return err
+	}
+	params.Version = version
+
+	err = u.db.UpdateActor(ctx, params)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
 	}
 
 	return nil
 }
 
-func (u UseCase) DeleteActor(params api_models.DeleteActorParams) error {
+func (u UseCase) DeleteActor(ctx context.Context, params api_models.DeleteActorParams) error {
 	if params.ActorId == "" {
-		return fmt.Errorf("usecase error: invalid actor id")
+		return api_models.NewFieldError("actor_id", "must not be empty")
+	}
+
+	version, err := u.db.GetActorVersion(ctx, params.ActorId)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+	if err = checkVersion(params.IfMatch, version, "actor"); err != nil {
+		return err
 	}
 
-	err := u.db.DeleteActor(params.ActorId)
+	err = u.db.DeleteActor(ctx, params.ActorId, version)
 	if err != nil {
-		return fmt.Errorf("usecase error: %s", err.Error())
+		return fmt.Errorf("usecase error: %w", err)
 	}
 
 	return nil
@@ -1,12 +1,15 @@
 package api_usecase
 
 import (
+	"context"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 	"time"
 	mock_api "vk_test_task/internal/api/mocks"
 	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
 )
 
 func TestUseCase_CreateActor(t *testing.T) {
@@ -21,6 +24,7 @@ func TestUseCase_CreateActor(t *testing.T) {
 		nil,
 		repo,
 		tokenRepo,
+		nil,
 	)
 
 	type mockBehaviour func(params api_models.CreateActorParams)
@@ -39,7 +43,7 @@ func TestUseCase_CreateActor(t *testing.T) {
 				Birth: time.Now(),
 			},
 			mockBehaviour: func(params api_models.CreateActorParams) {
-				repo.EXPECT().CreateActor(gomock.Any()).Return(nil)
+				repo.EXPECT().CreateActor(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -54,6 +58,29 @@ func TestUseCase_CreateActor(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "name too long",
+			args: api_models.CreateActorParams{
+				Name:  "N" + strings.Repeat("a", common.ACTOR_NAME_MAXSIZE),
+				Sex:   1,
+				Birth: time.Now(),
+			},
+			mockBehaviour: func(params api_models.CreateActorParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "multibyte name at max length",
+			args: api_models.CreateActorParams{
+				Name:  "N" + strings.Repeat("я", common.ACTOR_NAME_MAXSIZE-1),
+				Sex:   1,
+				Birth: time.Now(),
+			},
+			mockBehaviour: func(params api_models.CreateActorParams) {
+				repo.EXPECT().CreateActor(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalid sex",
 			args: api_models.CreateActorParams{
@@ -93,7 +120,7 @@ func TestUseCase_CreateActor(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mockBehaviour(test.args)
 
-			_, err := uc.CreateActor(test.args)
+			_, err := uc.CreateActor(context.Background(), test.args)
 
 			if test.wantErr {
 				assert.Error(t, err)
@@ -117,6 +144,7 @@ func TestUseCase_UpdateActor(t *testing.T) {
 		nil,
 		repo,
 		tokenRepo,
+		nil,
 	)
 
 	type mockBehaviour func(params api_models.UpdateActorParams)
@@ -126,27 +154,107 @@ func TestUseCase_UpdateActor(t *testing.T) {
 		args          api_models.UpdateActorParams
 		mockBehaviour mockBehaviour
 		wantErr       bool
+		wantErrIs     error
 	}{
 		{
 			name: "default",
 			args: api_models.UpdateActorParams{
 				ActorId: "id1",
-				Name:    "Name",
-				Sex:     1,
-				Birth:   time.Now(),
+				Name:    api_models.NewOptional("Name"),
+				Sex:     api_models.NewOptional(1),
+				Birth:   api_models.NewOptional(time.Now()),
+			},
+			mockBehaviour: func(params api_models.UpdateActorParams) {
+				repo.EXPECT().GetActorVersion(gomock.Any(), "id1").Return(3, nil)
+				params.Version = 3
+				repo.EXPECT().UpdateActor(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "name too long",
+			args: api_models.UpdateActorParams{
+				ActorId: "id1",
+				Name:    api_models.NewOptional("N" + strings.Repeat("a", common.ACTOR_NAME_MAXSIZE)),
 			},
 			mockBehaviour: func(params api_models.UpdateActorParams) {
-				repo.EXPECT().UpdateActor(params).Return(nil)
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrValidation,
+		},
+		{
+			name: "multibyte name at max length",
+			args: api_models.UpdateActorParams{
+				ActorId: "id1",
+				Name:    api_models.NewOptional("N" + strings.Repeat("я", common.ACTOR_NAME_MAXSIZE-1)),
+				Version: 3,
+			},
+			mockBehaviour: func(params api_models.UpdateActorParams) {
+				repo.EXPECT().GetActorVersion(gomock.Any(), "id1").Return(3, nil)
+				repo.EXPECT().UpdateActor(gomock.Any(), params).Return(nil)
 			},
 			wantErr: false,
 		},
+		{
+			name: "if-match matches",
+			args: api_models.UpdateActorParams{
+				ActorId: "id1",
+				Name:    api_models.NewOptional("Name"),
+				IfMatch: []int{2, 3},
+				Version: 3,
+			},
+			mockBehaviour: func(params api_models.UpdateActorParams) {
+				repo.EXPECT().GetActorVersion(gomock.Any(), "id1").Return(3, nil)
+				repo.EXPECT().UpdateActor(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "if-match is stale",
+			args: api_models.UpdateActorParams{
+				ActorId: "id1",
+				Name:    api_models.NewOptional("Name"),
+				IfMatch: []int{2},
+			},
+			mockBehaviour: func(params api_models.UpdateActorParams) {
+				repo.EXPECT().GetActorVersion(gomock.Any(), "id1").Return(3, nil)
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrPrecondition,
+		},
+		{
+			name: "changed concurrently",
+			args: api_models.UpdateActorParams{
+				ActorId: "id1",
+				Name:    api_models.NewOptional("Name"),
+			},
+			mockBehaviour: func(params api_models.UpdateActorParams) {
+				repo.EXPECT().GetActorVersion(gomock.Any(), "id1").Return(3, nil)
+				params.Version = 3
+				repo.EXPECT().UpdateActor(gomock.Any(), params).Return(api_models.NewConflictError("actor was modified concurrently"))
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrConflict,
+		},
+		{
+			name: "not found",
+			args: api_models.UpdateActorParams{
+				ActorId: "id1",
+				Name:    api_models.NewOptional("Name"),
+			},
+			mockBehaviour: func(params api_models.UpdateActorParams) {
+				repo.EXPECT().GetActorVersion(gomock.Any(), "id1").Return(0, api_models.NewNotFoundError("actor not found"))
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrNotFound,
+		},
 		{
 			name: "invalid actor id",
 			args: api_models.UpdateActorParams{
 				ActorId: "",
-				Name:    "Name",
-				Sex:     1,
-				Birth:   time.Now(),
+				Name:    api_models.NewOptional("Name"),
+				Sex:     api_models.NewOptional(1),
+				Birth:   api_models.NewOptional(time.Now()),
 			},
 			mockBehaviour: func(params api_models.UpdateActorParams) {
 			},
@@ -156,9 +264,9 @@ func TestUseCase_UpdateActor(t *testing.T) {
 			name: "invalid actor name",
 			args: api_models.UpdateActorParams{
 				ActorId: "asdfafa",
-				Name:    "name",
-				Sex:     1,
-				Birth:   time.Now(),
+				Name:    api_models.NewOptional("name"),
+				Sex:     api_models.NewOptional(1),
+				Birth:   api_models.NewOptional(time.Now()),
 			},
 			mockBehaviour: func(params api_models.UpdateActorParams) {
 			},
@@ -168,9 +276,9 @@ func TestUseCase_UpdateActor(t *testing.T) {
 			name: "invalid actor name",
 			args: api_models.UpdateActorParams{
 				ActorId: "asdasd",
-				Name:    "",
-				Sex:     1,
-				Birth:   time.Now(),
+				Name:    api_models.NewOptional(""),
+				Sex:     api_models.NewOptional(1),
+				Birth:   api_models.NewOptional(time.Now()),
 			},
 			mockBehaviour: func(params api_models.UpdateActorParams) {
 			},
@@ -180,9 +288,9 @@ func TestUseCase_UpdateActor(t *testing.T) {
 			name: "invalid actor sex",
 			args: api_models.UpdateActorParams{
 				ActorId: "asdasd",
-				Name:    "Aaa",
-				Sex:     -1,
-				Birth:   time.Now(),
+				Name:    api_models.NewOptional("Aaa"),
+				Sex:     api_models.NewOptional(-1),
+				Birth:   api_models.NewOptional(time.Now()),
 			},
 			mockBehaviour: func(params api_models.UpdateActorParams) {
 			},
@@ -192,9 +300,32 @@ func TestUseCase_UpdateActor(t *testing.T) {
 			name: "invalid actor name",
 			args: api_models.UpdateActorParams{
 				ActorId: "asdasd",
-				Name:    "Asdf",
-				Sex:     1,
-				Birth:   time.Now().Add(10 * time.Hour),
+				Name:    api_models.NewOptional("Asdf"),
+				Sex:     api_models.NewOptional(1),
+				Birth:   api_models.NewOptional(time.Now().Add(10 * time.Hour)),
+			},
+			mockBehaviour: func(params api_models.UpdateActorParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "only sex",
+			args: api_models.UpdateActorParams{
+				ActorId: "id1",
+				Sex:     api_models.NewOptional(2),
+			},
+			mockBehaviour: func(params api_models.UpdateActorParams) {
+				repo.EXPECT().GetActorVersion(gomock.Any(), "id1").Return(1, nil)
+				params.Version = 1
+				repo.EXPECT().UpdateActor(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "null birth",
+			args: api_models.UpdateActorParams{
+				ActorId: "id1",
+				Birth:   api_models.Optional[time.Time]{Set: true, Null: true},
 			},
 			mockBehaviour: func(params api_models.UpdateActorParams) {
 			},
@@ -206,10 +337,13 @@ func TestUseCase_UpdateActor(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mockBehaviour(test.args)
 
-			err := uc.UpdateActor(test.args)
+			err := uc.UpdateActor(context.Background(), test.args)
 
 			if test.wantErr {
 				assert.Error(t, err)
+				if test.wantErrIs != nil {
+					assert.ErrorIs(t, err, test.wantErrIs)
+				}
 			} else {
 				assert.NoError(t, err)
 			}
@@ -230,29 +364,61 @@ func TestUseCase_GetActors(t *testing.T) {
 		nil,
 		repo,
 		tokenRepo,
+		nil,
 	)
 
 	type mockBehaviour func()
 
+	after := api_models.Cursor{Sort: "name", Values: []string{"Brad Pitt"}, Id: "id"}
+
 	testTable := []struct {
 		name          string
+		params        api_models.GetActorsParams
 		mockBehaviour mockBehaviour
 		wantErr       bool
 	}{
 		{
 			name: "default",
 			mockBehaviour: func() {
-				repo.EXPECT().GetActors().Return(api_models.GetActorsResponse{}, nil)
+				repo.EXPECT().GetActors(gomock.Any(), api_models.Page{Sort: api_models.ActorSort, Limit: common.PAGE_SIZE_DEFAULT}).
+					Return(api_models.GetActorsResponse{}, nil)
 			},
 			wantErr: false,
 		},
+		{
+			name:   "next page",
+			params: api_models.GetActorsParams{Limit: 5, Cursor: after.Encode()},
+			mockBehaviour: func() {
+				repo.EXPECT().GetActors(gomock.Any(), api_models.Page{Sort: api_models.ActorSort, Limit: 5, After: &after}).
+					Return(api_models.GetActorsResponse{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:          "limit too large",
+			params:        api_models.GetActorsParams{Limit: common.PAGE_SIZE_MAX + 1},
+			mockBehaviour: func() {},
+			wantErr:       true,
+		},
+		{
+			name:          "malformed cursor",
+			params:        api_models.GetActorsParams{Cursor: "not a cursor"},
+			mockBehaviour: func() {},
+			wantErr:       true,
+		},
+		{
+			name:          "cursor from another sort",
+			params:        api_models.GetActorsParams{Cursor: api_models.Cursor{Sort: "-rate", Values: []string{"5"}, Id: "id"}.Encode()},
+			mockBehaviour: func() {},
+			wantErr:       true,
+		},
 	}
 
 	for _, test := range testTable {
 		t.Run(test.name, func(t *testing.T) {
 			test.mockBehaviour()
 
-			_, err := uc.GetActors()
+			_, err := uc.GetActors(context.Background(), test.params)
 
 			if test.wantErr {
 				assert.Error(t, err)
@@ -276,30 +442,98 @@ func TestUseCase_DeleteActors(t *testing.T) {
 		nil,
 		repo,
 		tokenRepo,
+		nil,
 	)
 
-	type mockBehaviour func(actorId string)
+	type mockBehaviour func(params api_models.DeleteActorParams)
 
 	testTable := []struct {
 		name          string
-		actorId       string
+		params        api_models.DeleteActorParams
 		mockBehaviour mockBehaviour
 		wantErr       bool
+		wantErrIs     error
+	}{
+		{
+			name:   "default",
+			params: api_models.DeleteActorParams{ActorId: "id"},
+			mockBehaviour: func(params api_models.DeleteActorParams) {
+				repo.EXPECT().GetActorVersion(gomock.Any(), params.ActorId).Return(2, nil)
+				repo.EXPECT().DeleteActor(gomock.Any(), params.ActorId, 2).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:   "if-match is stale",
+			params: api_models.DeleteActorParams{ActorId: "id", IfMatch: []int{1}},
+			mockBehaviour: func(params api_models.DeleteActorParams) {
+				repo.EXPECT().GetActorVersion(gomock.Any(), params.ActorId).Return(2, nil)
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrPrecondition,
+		},
+		{
+			name:   "invalid actorId",
+			params: api_models.DeleteActorParams{ActorId: ""},
+			mockBehaviour: func(params api_models.DeleteActorParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.params)
+
+			err := uc.DeleteActor(context.Background(), test.params)
+
+			if test.wantErr {
+				assert.Error(t, err)
+				if test.wantErrIs != nil {
+					assert.ErrorIs(t, err, test.wantErrIs)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+}
+
+func TestUseCase_GetActor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	testTable := []struct {
+		name          string
+		actorId       string
+		mockBehaviour func(actorId string)
+		wantErr       bool
 	}{
 		{
 			name:    "default",
 			actorId: "id",
 			mockBehaviour: func(actorId string) {
-				repo.EXPECT().DeleteActor(actorId).Return(nil)
+				repo.EXPECT().GetActorDetails(gomock.Any(), actorId).Return(api_models.ActorDetails{ActorId: actorId}, nil)
 			},
 			wantErr: false,
 		},
 		{
-			name:    "invalid actorId",
-			actorId: "",
-			mockBehaviour: func(actorId string) {
-			},
-			wantErr: true,
+			name:          "empty id",
+			actorId:       "",
+			mockBehaviour: func(actorId string) {},
+			wantErr:       true,
 		},
 	}
 
@@ -307,7 +541,7 @@ func TestUseCase_DeleteActors(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mockBehaviour(test.actorId)
 
-			err := uc.DeleteActor(api_models.DeleteActorParams{ActorId: test.actorId})
+			_, err := uc.GetActor(context.Background(), test.actorId)
 
 			if test.wantErr {
 				assert.Error(t, err)
@@ -316,5 +550,4 @@ func TestUseCase_DeleteActors(t *testing.T) {
 			}
 		})
 	}
-
 }
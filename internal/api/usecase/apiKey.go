@@ -0,0 +1,135 @@
+package api_usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+	"vk_test_task/internal/utils/encryption"
+)
+
+func (u UseCase) CreateAPIKey(ctx context.Context, claims api_models.AuthClaims, params api_models.CreateAPIKeyParams) (api_models.CreateAPIKeyResponse, error) {
+	if params.Name == "" || utf8.RuneCountInString(params.Name) > common.API_KEY_NAME_MAXSIZE {
+		return api_models.CreateAPIKeyResponse{}, api_models.NewFieldError("name", fmt.Sprintf("length must be between 1 and %d", common.API_KEY_NAME_MAXSIZE))
+	}
+	if len(params.Scopes) == 0 {
+		return api_models.CreateAPIKeyResponse{}, api_models.NewFieldError("scopes", "must not be empty")
+	}
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(time.Now()) {
+		return api_models.CreateAPIKeyResponse{}, api_models.NewFieldError("expires_at", "must be in the future")
+	}
+
+	// ключ не может получить больше прав, чем есть у создателя
+	scopes := make([]string, 0, len(params.Scopes))
+	for _, scope := range params.Scopes {
+		if !claims.HasPermission(scope) {
+			return api_models.CreateAPIKeyResponse{}, api_models.NewForbiddenError(fmt.Sprintf("scope %s is not granted to the user", scope))
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return api_models.CreateAPIKeyResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+	key := common.API_KEY_PREFIX + base64.RawURLEncoding.EncodeToString(raw)
+
+	id := uuid.NewString()
+
+	err := u.db.CreateAPIKey(ctx, api_models.APIKey{
+		Id:        id,
+		Name:      params.Name,
+		Prefix:    key[:len(common.API_KEY_PREFIX)+8],
+		UserId:    claims.UserId,
+		Scopes:    scopes,
+		ExpiresAt: params.ExpiresAt,
+	}, encryption.HashToken(key))
+	if err != nil {
+		return api_models.CreateAPIKeyResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	return api_models.CreateAPIKeyResponse{Id: id, Key: key}, nil
+}
+
+func (u UseCase) GetAPIKeys(ctx context.Context) (api_models.GetAPIKeysResponse, error) {
+	keys, err := u.db.GetAPIKeys(ctx)
+	if err != nil {
+		return api_models.GetAPIKeysResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	return api_models.GetAPIKeysResponse{Response: keys}, nil
+}
+
+func (u UseCase) RevokeAPIKey(ctx context.Context, params api_models.RevokeAPIKeyParams) error {
+	if params.Id == "" {
+		return api_models.NewFieldError("id", "must not be empty")
+	}
+
+	err := u.db.RevokeAPIKey(ctx, params.Id)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey возвращает claims с правами ключа, ограниченными текущими правами его владельца
+func (u UseCase) AuthenticateAPIKey(ctx context.Context, key string) (api_models.AuthClaims, error) {
+	if !strings.HasPrefix(key, common.API_KEY_PREFIX) {
+		return api_models.AuthClaims{}, api_models.NewUnauthorizedError("invalid api key")
+	}
+
+	apiKey, err := u.db.GetAPIKeyByHash(ctx, encryption.HashToken(key))
+	if errors.Is(err, api_models.ErrNotFound) {
+		return api_models.AuthClaims{}, api_models.NewUnauthorizedError("invalid api key")
+	}
+	if err != nil {
+		return api_models.AuthClaims{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	if apiKey.RevokedAt != nil {
+		return api_models.AuthClaims{}, api_models.NewUnauthorizedError("api key is revoked")
+	}
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+		return api_models.AuthClaims{}, api_models.NewUnauthorizedError("api key is expired")
+	}
+
+	access, err := u.db.GetUserAccess(ctx, apiKey.UserId)
+	if err != nil {
+		return api_models.AuthClaims{}, fmt.Errorf("usecase error: %w", err)
+	}
+	if access.Disabled {
+		return api_models.AuthClaims{}, api_models.NewForbiddenError("account is disabled")
+	}
+
+	permissions := make([]string, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		if slices.Contains(access.Permissions, scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	if err = u.db.TouchAPIKey(ctx, apiKey.Id); err != nil {
+		return api_models.AuthClaims{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	return api_models.AuthClaims{
+		UserId:      apiKey.UserId,
+		Type:        common.APIKeyTokenType,
+		Permissions: permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID: apiKey.Id,
+		},
+	}, nil
+}
@@ -0,0 +1,269 @@
+package api_usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+	"vk_test_task/internal/utils/encryption"
+)
+
+func TestUseCase_CreateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	claims := api_models.AuthClaims{UserId: "userId", Permissions: []string{"film:read", "film:create"}}
+	past := time.Now().Add(-time.Hour)
+
+	type mockBehaviour func(params api_models.CreateAPIKeyParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.CreateAPIKeyParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.CreateAPIKeyParams{Name: "importer", Scopes: []string{"film:create", "film:create"}},
+			mockBehaviour: func(params api_models.CreateAPIKeyParams) {
+				repo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key api_models.APIKey, keyHash string) error {
+					assert.Equal(t, "userId", key.UserId)
+					assert.Equal(t, []string{"film:create"}, key.Scopes)
+					assert.Len(t, keyHash, 64)
+					return nil
+				})
+			},
+			wantErr: false,
+		},
+		{
+			name: "scope not granted",
+			args: api_models.CreateAPIKeyParams{Name: "importer", Scopes: []string{"film:delete"}},
+			mockBehaviour: func(params api_models.CreateAPIKeyParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "no scopes",
+			args: api_models.CreateAPIKeyParams{Name: "importer"},
+			mockBehaviour: func(params api_models.CreateAPIKeyParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			args: api_models.CreateAPIKeyParams{Name: "importer", Scopes: []string{"film:read"}, ExpiresAt: &past},
+			mockBehaviour: func(params api_models.CreateAPIKeyParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "db error",
+			args: api_models.CreateAPIKeyParams{Name: "importer", Scopes: []string{"film:read"}},
+			mockBehaviour: func(params api_models.CreateAPIKeyParams) {
+				repo.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any(), gomock.Any()).Return(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			resp, err := uc.CreateAPIKey(context.Background(), claims, test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, resp.Id)
+				assert.True(t, strings.HasPrefix(resp.Key, common.API_KEY_PREFIX))
+			}
+		})
+	}
+}
+
+func TestUseCase_RevokeAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	type mockBehaviour func(params api_models.RevokeAPIKeyParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.RevokeAPIKeyParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.RevokeAPIKeyParams{Id: "id"},
+			mockBehaviour: func(params api_models.RevokeAPIKeyParams) {
+				repo.EXPECT().RevokeAPIKey(gomock.Any(), params.Id).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "not found",
+			args: api_models.RevokeAPIKeyParams{Id: "id"},
+			mockBehaviour: func(params api_models.RevokeAPIKeyParams) {
+				repo.EXPECT().RevokeAPIKey(gomock.Any(), params.Id).Return(fmt.Errorf("api key not found"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "no id",
+			args: api_models.RevokeAPIKeyParams{},
+			mockBehaviour: func(params api_models.RevokeAPIKeyParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			err := uc.RevokeAPIKey(context.Background(), test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUseCase_AuthenticateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	key := common.API_KEY_PREFIX + "secret"
+	past := time.Now().Add(-time.Hour)
+	apiKey := api_models.APIKey{Id: "id", UserId: "userId", Scopes: []string{"film:create", "film:delete"}}
+
+	testTable := []struct {
+		name            string
+		key             string
+		mockBehaviour   func(key string)
+		wantPermissions []string
+		wantErr         bool
+	}{
+		{
+			name: "default",
+			key:  key,
+			mockBehaviour: func(key string) {
+				repo.EXPECT().GetAPIKeyByHash(gomock.Any(), encryption.HashToken(key)).Return(apiKey, nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "userId").Return(api_models.UserAccess{
+					UserId:      "userId",
+					Permissions: []string{"film:read", "film:create"},
+				}, nil)
+				repo.EXPECT().TouchAPIKey(gomock.Any(), "id").Return(nil)
+			},
+			wantPermissions: []string{"film:create"},
+			wantErr:         false,
+		},
+		{
+			name: "revoked",
+			key:  key,
+			mockBehaviour: func(key string) {
+				revoked := apiKey
+				revoked.RevokedAt = &past
+				repo.EXPECT().GetAPIKeyByHash(gomock.Any(), encryption.HashToken(key)).Return(revoked, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			key:  key,
+			mockBehaviour: func(key string) {
+				expired := apiKey
+				expired.ExpiresAt = &past
+				repo.EXPECT().GetAPIKeyByHash(gomock.Any(), encryption.HashToken(key)).Return(expired, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "disabled owner",
+			key:  key,
+			mockBehaviour: func(key string) {
+				repo.EXPECT().GetAPIKeyByHash(gomock.Any(), encryption.HashToken(key)).Return(apiKey, nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "userId").Return(api_models.UserAccess{UserId: "userId", Disabled: true}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown key",
+			key:  key,
+			mockBehaviour: func(key string) {
+				repo.EXPECT().GetAPIKeyByHash(gomock.Any(), encryption.HashToken(key)).Return(api_models.APIKey{}, fmt.Errorf("api key not found"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "wrong format",
+			key:  "jwt",
+			mockBehaviour: func(key string) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.key)
+
+			claims, err := uc.AuthenticateAPIKey(context.Background(), test.key)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "userId", claims.UserId)
+				assert.Equal(t, common.APIKeyTokenType, claims.Type)
+				assert.Equal(t, test.wantPermissions, claims.Permissions)
+			}
+		})
+	}
+}
@@ -1,29 +1,91 @@
 package api_usecase
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"net/mail"
+	"slices"
+	"unicode/utf8"
 	api_models "vk_test_task/internal/api/models"
 	"vk_test_task/internal/common"
 	"vk_test_task/internal/utils/encryption"
 )
 
-func (u UseCase) SignIn(params api_models.AuthParams) (api_models.SignInUseCaseResponse, error) {
-	if len(params.Login) < common.LOGIN_MINSIZE || len(params.Password) < common.PASSWORD_MINSIZE {
-		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: wrong params")
+func (u UseCase) SignIn(ctx context.Context, params api_models.AuthParams) (api_models.SignInUseCaseResponse, error) {
+	if utf8.RuneCountInString(params.Login) < common.LOGIN_MINSIZE || utf8.RuneCountInString(params.Password) < common.PASSWORD_MINSIZE {
+		return api_models.SignInUseCaseResponse{}, api_models.NewValidationError("wrong params")
 	}
-	repoResponse, err := u.db.SignIn(params.Login)
+	retryAfter, err := u.rdb.SignInRetryAfter(ctx, params.Login, params.IP)
 	if err != nil {
-		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %s", err.Error())
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+	if retryAfter > 0 {
+		return api_models.SignInUseCaseResponse{}, api_models.TooManyRequestsError{RetryAfter: retryAfter}
+	}
+
+	repoResponse, err := u.db.SignIn(ctx, params.Login)
+	// неудачной попыткой считается только неизвестный логин, сбой базы или отмена запроса на счетчики не влияют
+	if errors.Is(err, api_models.ErrUnauthorized) {
+		return api_models.SignInUseCaseResponse{}, u.signInFailed(ctx, params, err)
+	}
+	if err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
 	}
 
 	if ok := encryption.CheckPasswordHash(params.Password, repoResponse.HashPassword); ok != true {
-		return api_models.SignInUseCaseResponse{}, fmt.Errorf("wrong login or password")
+		return api_models.SignInUseCaseResponse{}, u.signInFailed(ctx, params, api_models.NewUnauthorizedError("wrong login or password"))
+	}
+
+	if repoResponse.Disabled {
+		return api_models.SignInUseCaseResponse{}, api_models.NewForbiddenError("account is disabled")
 	}
 
-	accessToken, refreshToken, exp, err := u.rdb.CreateTokensPair(repoResponse.UserId, repoResponse.IsAdmin)
+	if err = u.rdb.ResetSignInFailures(ctx, params.Login); err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	access, err := u.db.GetUserAccess(ctx, repoResponse.UserId)
 	if err != nil {
-		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %s", err.Error())
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	mfa, err := u.db.GetMFA(ctx, repoResponse.UserId)
+	if err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	if mfa.Enabled {
+		token, err := u.rdb.CreateMFAChallenge(ctx, api_models.MFAChallenge{
+			UserId:    repoResponse.UserId,
+			Login:     params.Login,
+			Device:    params.Device,
+			IP:        params.IP,
+			UserAgent: params.UserAgent,
+		})
+		if err != nil {
+			return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+		}
+
+		return api_models.SignInUseCaseResponse{MFAToken: token}, nil
+	}
+
+	if u.cfg.MFA.RequireForAdmins && slices.Contains(access.Roles, common.ROLE_ADMIN) {
+		return api_models.SignInUseCaseResponse{}, api_models.NewForbiddenError("two-factor authentication is required for admin accounts")
+	}
+
+	return u.issueTokens(ctx, access, api_models.ClientInfo{
+		Device:    params.Device,
+		IP:        params.IP,
+		UserAgent: params.UserAgent,
+	})
+}
+
+func (u UseCase) issueTokens(ctx context.Context, access api_models.UserAccess, client api_models.ClientInfo) (api_models.SignInUseCaseResponse, error) {
+	accessToken, refreshToken, exp, err := u.rdb.CreateTokensPair(ctx, access, client)
+	if err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
 	}
 
 	return api_models.SignInUseCaseResponse{
@@ -33,31 +95,189 @@ func (u UseCase) SignIn(params api_models.AuthParams) (api_models.SignInUseCaseR
 	}, nil
 }
 
-func (u UseCase) SignUp(params api_models.AuthParams) error {
-	if len(params.Login) < common.LOGIN_MINSIZE {
-		return fmt.Errorf("usecase error: login is too short")
+func (u UseCase) signInFailed(ctx context.Context, params api_models.AuthParams, signInErr error) error {
+	if err := u.rdb.RegisterSignInFailure(ctx, params.Login, params.IP); err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return signInErr
+}
+
+func (u UseCase) SignUp(ctx context.Context, params api_models.AuthParams) error {
+	if utf8.RuneCountInString(params.Login) < common.LOGIN_MINSIZE {
+		return api_models.NewFieldError("login", fmt.Sprintf("must be at least %d characters", common.LOGIN_MINSIZE))
+	}
+	if utf8.RuneCountInString(params.Password) < common.PASSWORD_MINSIZE {
+		return api_models.NewFieldError("password", fmt.Sprintf("must be at least %d characters", common.PASSWORD_MINSIZE))
 	}
-	if len(params.Password) < common.PASSWORD_MINSIZE {
-		return fmt.Errorf("usecase error: password is too short")
+	if utf8.RuneCountInString(params.Password) > common.PASSWORD_MAXSIZE {
+		return api_models.NewFieldError("password", fmt.Sprintf("must be at most %d characters", common.PASSWORD_MAXSIZE))
 	}
-	if len(params.Password) > common.PASSWORD_MAXSIZE {
-		return fmt.Errorf("usecase error: password is too long")
+	if err := validateEmail(params.Email); err != nil {
+		return err
 	}
 
 	userId, err := uuid.NewUUID()
 	if err != nil {
-		return fmt.Errorf("usecase error: %s", err.Error())
+		return fmt.Errorf("usecase error: %w", err)
 	}
 
 	hashPassword, err := encryption.HashPassword(params.Password)
 	if err != nil {
-		return fmt.Errorf("usecase error: %s", err.Error())
+		return fmt.Errorf("usecase error: %w", err)
 	}
 
-	err = u.db.SignUp(params.Login, hashPassword, userId.String())
+	err = u.db.SignUp(ctx, params.Login, hashPassword, userId.String(), params.Email)
 	if err != nil {
-		return fmt.Errorf("usecase error: %s", err.Error())
+		return fmt.Errorf("usecase error: %w", err)
 	}
 
 	return nil
 }
+
+// validateEmail - email необязателен, но если указан, должен быть одним адресом без имени: на него уходят письма сброса пароля
+func validateEmail(email string) error {
+	if email == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(email) > common.EMAIL_MAXSIZE {
+		return api_models.NewFieldError("email", fmt.Sprintf("must be at most %d characters", common.EMAIL_MAXSIZE))
+	}
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email {
+		return api_models.NewFieldError("email", "must be a valid email address")
+	}
+	return nil
+}
+
+func (u UseCase) Refresh(ctx context.Context, params api_models.RefreshParams) (api_models.SignInUseCaseResponse, error) {
+	if params.RefreshToken == "" {
+		return api_models.SignInUseCaseResponse{}, api_models.NewFieldError("refresh_token", "must not be empty")
+	}
+
+	claims, err := u.rdb.VerifyRefreshToken(ctx, params.RefreshToken)
+	if err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	// права перечитываются при каждом обновлении, чтобы изменения ролей применялись без повторного входа
+	access, err := u.db.GetUserAccess(ctx, claims.UserId)
+	if err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	if access.Disabled {
+		return api_models.SignInUseCaseResponse{}, api_models.NewForbiddenError("account is disabled")
+	}
+
+	// роль admin могла быть выдана уже после входа без 2FA
+	if u.cfg.MFA.RequireForAdmins && slices.Contains(access.Roles, common.ROLE_ADMIN) {
+		mfa, err := u.db.GetMFA(ctx, claims.UserId)
+		if err != nil {
+			return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+		}
+		if !mfa.Enabled {
+			return api_models.SignInUseCaseResponse{}, api_models.NewForbiddenError("two-factor authentication is required for admin accounts")
+		}
+	}
+
+	client := api_models.ClientInfo{
+		IP:        params.IP,
+		UserAgent: params.UserAgent,
+	}
+
+	accessToken, refreshToken, exp, err := u.rdb.RefreshTokensPair(ctx, params.RefreshToken, claims, access, client)
+	if err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	return api_models.SignInUseCaseResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		Expiration:   exp,
+	}, nil
+}
+
+func (u UseCase) Logout(ctx context.Context, claims api_models.AuthClaims) error {
+	if claims.UserId == "" {
+		return api_models.NewUnauthorizedError("invalid user id")
+	}
+
+	err := u.rdb.RevokeSession(ctx, claims.UserId, claims.SessionId)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil {
+		return api_models.NewUnauthorizedError("invalid token expiration")
+	}
+
+	err = u.rdb.RevokeAccessToken(ctx, claims.TokenId(), exp.Unix())
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return nil
+}
+
+func (u UseCase) LogoutAll(ctx context.Context, claims api_models.AuthClaims) error {
+	err := u.Logout(ctx, claims)
+	if err != nil {
+		return err
+	}
+
+	err = u.rdb.RevokeRefreshTokens(ctx, claims.UserId)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	err = u.rdb.RevokeAllAccessTokens(ctx, claims.UserId)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return nil
+}
+
+func (u UseCase) GetSessions(ctx context.Context, claims api_models.AuthClaims) (api_models.GetSessionsResponse, error) {
+	if claims.UserId == "" {
+		return api_models.GetSessionsResponse{}, api_models.NewUnauthorizedError("invalid user id")
+	}
+
+	sessions, err := u.rdb.GetSessions(ctx, claims.UserId)
+	if err != nil {
+		return api_models.GetSessionsResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].SessionId == claims.SessionId
+	}
+
+	return api_models.GetSessionsResponse{Response: sessions}, nil
+}
+
+func (u UseCase) RevokeSession(ctx context.Context, claims api_models.AuthClaims, params api_models.RevokeSessionParams) error {
+	if claims.UserId == "" {
+		return api_models.NewUnauthorizedError("invalid user id")
+	}
+	if params.SessionId == "" {
+		return api_models.NewFieldError("session_id", "must not be empty")
+	}
+
+	err := u.rdb.RevokeSession(ctx, claims.UserId, params.SessionId)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return nil
+}
+
+func (u UseCase) GetJWKS(ctx context.Context) (api_models.JWKS, error) {
+	keys, err := u.rdb.GetPublicKeys(ctx)
+	if err != nil {
+		return api_models.JWKS{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	return api_models.JWKS{Keys: keys}, nil
+}
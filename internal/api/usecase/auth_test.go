@@ -1,9 +1,14 @@
 package api_usecase
 
 import (
+	"context"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
+	"vk_test_task/config"
 	mock_api "vk_test_task/internal/api/mocks"
 	api_models "vk_test_task/internal/api/models"
 	"vk_test_task/internal/utils/encryption"
@@ -17,10 +22,11 @@ func TestUseCase_SignIn(t *testing.T) {
 	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
 
 	uc := New(
-		nil,
+		&config.Config{MFA: config.MFA{RequireForAdmins: true}},
 		nil,
 		repo,
 		tokenRepo,
+		nil,
 	)
 
 	type mockBehaviour func(params api_models.AuthParams)
@@ -39,15 +45,80 @@ func TestUseCase_SignIn(t *testing.T) {
 			},
 			mockBehaviour: func(params api_models.AuthParams) {
 				pass, _ := encryption.HashPassword(params.Password)
-				repo.EXPECT().SignIn(params.Login).Return(api_models.SignInRepositoryResponse{
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), params.Login, "").Return(time.Duration(0), nil)
+				repo.EXPECT().SignIn(gomock.Any(), params.Login).Return(api_models.SignInRepositoryResponse{
+					UserId:       "id",
+					HashPassword: pass,
+				}, nil)
+				tokenRepo.EXPECT().ResetSignInFailures(gomock.Any(), params.Login).Return(nil)
+				access := api_models.UserAccess{UserId: "id", Roles: []string{"viewer"}}
+				repo.EXPECT().GetUserAccess(gomock.Any(), "id").Return(access, nil)
+				repo.EXPECT().GetMFA(gomock.Any(), "id").Return(api_models.MFA{}, nil)
+				tokenRepo.EXPECT().CreateTokensPair(gomock.Any(), access, api_models.ClientInfo{}).Return("", "", int64(1), nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "mfa enabled",
+			args: api_models.AuthParams{
+				Login:    "login",
+				Password: "password",
+				Device:   "phone",
+			},
+			mockBehaviour: func(params api_models.AuthParams) {
+				pass, _ := encryption.HashPassword(params.Password)
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), params.Login, "").Return(time.Duration(0), nil)
+				repo.EXPECT().SignIn(gomock.Any(), params.Login).Return(api_models.SignInRepositoryResponse{
 					UserId:       "id",
 					HashPassword: pass,
-					IsAdmin:      false,
 				}, nil)
-				tokenRepo.EXPECT().CreateTokensPair("id", false).Return("", "", int64(1), nil)
+				tokenRepo.EXPECT().ResetSignInFailures(gomock.Any(), params.Login).Return(nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "id").Return(api_models.UserAccess{UserId: "id", Roles: []string{"admin"}}, nil)
+				repo.EXPECT().GetMFA(gomock.Any(), "id").Return(api_models.MFA{Secret: "secret", Enabled: true}, nil)
+				tokenRepo.EXPECT().CreateMFAChallenge(gomock.Any(), api_models.MFAChallenge{
+					UserId: "id",
+					Login:  params.Login,
+					Device: params.Device,
+				}).Return("mfa token", nil)
 			},
 			wantErr: false,
 		},
+		{
+			name: "admin without mfa",
+			args: api_models.AuthParams{
+				Login:    "login",
+				Password: "password",
+			},
+			mockBehaviour: func(params api_models.AuthParams) {
+				pass, _ := encryption.HashPassword(params.Password)
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), params.Login, "").Return(time.Duration(0), nil)
+				repo.EXPECT().SignIn(gomock.Any(), params.Login).Return(api_models.SignInRepositoryResponse{
+					UserId:       "id",
+					HashPassword: pass,
+				}, nil)
+				tokenRepo.EXPECT().ResetSignInFailures(gomock.Any(), params.Login).Return(nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "id").Return(api_models.UserAccess{UserId: "id", Roles: []string{"admin"}}, nil)
+				repo.EXPECT().GetMFA(gomock.Any(), "id").Return(api_models.MFA{}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "disabled account",
+			args: api_models.AuthParams{
+				Login:    "login",
+				Password: "password",
+			},
+			mockBehaviour: func(params api_models.AuthParams) {
+				pass, _ := encryption.HashPassword(params.Password)
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), params.Login, "").Return(time.Duration(0), nil)
+				repo.EXPECT().SignIn(gomock.Any(), params.Login).Return(api_models.SignInRepositoryResponse{
+					UserId:       "id",
+					HashPassword: pass,
+					Disabled:     true,
+				}, nil)
+			},
+			wantErr: true,
+		},
 		{
 			name: "wrong password",
 			args: api_models.AuthParams{
@@ -56,11 +127,51 @@ func TestUseCase_SignIn(t *testing.T) {
 			},
 			mockBehaviour: func(params api_models.AuthParams) {
 				pass := "wrong pass"
-				repo.EXPECT().SignIn(params.Login).Return(api_models.SignInRepositoryResponse{
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), params.Login, "").Return(time.Duration(0), nil)
+				repo.EXPECT().SignIn(gomock.Any(), params.Login).Return(api_models.SignInRepositoryResponse{
 					UserId:       "id",
 					HashPassword: pass,
-					IsAdmin:      false,
 				}, nil)
+				tokenRepo.EXPECT().RegisterSignInFailure(gomock.Any(), params.Login, "").Return(nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown login",
+			args: api_models.AuthParams{
+				Login:    "login",
+				Password: "password",
+				IP:       "127.0.0.1",
+			},
+			mockBehaviour: func(params api_models.AuthParams) {
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), params.Login, params.IP).Return(time.Duration(0), nil)
+				repo.EXPECT().SignIn(gomock.Any(), params.Login).Return(api_models.SignInRepositoryResponse{}, api_models.NewUnauthorizedError("wrong login or password"))
+				tokenRepo.EXPECT().RegisterSignInFailure(gomock.Any(), params.Login, params.IP).Return(nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "db error is not a failed attempt",
+			args: api_models.AuthParams{
+				Login:    "login",
+				Password: "password",
+				IP:       "127.0.0.1",
+			},
+			mockBehaviour: func(params api_models.AuthParams) {
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), params.Login, params.IP).Return(time.Duration(0), nil)
+				repo.EXPECT().SignIn(gomock.Any(), params.Login).Return(api_models.SignInRepositoryResponse{}, fmt.Errorf("repository error: connection refused"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many attempts",
+			args: api_models.AuthParams{
+				Login:    "login",
+				Password: "password",
+				IP:       "127.0.0.1",
+			},
+			mockBehaviour: func(params api_models.AuthParams) {
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), params.Login, params.IP).Return(time.Minute, nil)
 			},
 			wantErr: true,
 		},
@@ -90,7 +201,7 @@ func TestUseCase_SignIn(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mockBehaviour(test.args)
 
-			_, err := uc.SignIn(test.args)
+			_, err := uc.SignIn(context.Background(), test.args)
 
 			if test.wantErr {
 				assert.Error(t, err)
@@ -114,6 +225,7 @@ func TestUseCase_SignUp(t *testing.T) {
 		nil,
 		repo,
 		tokenRepo,
+		nil,
 	)
 
 	type mockBehaviour func(params api_models.AuthParams)
@@ -131,7 +243,7 @@ func TestUseCase_SignUp(t *testing.T) {
 				Password: "password",
 			},
 			mockBehaviour: func(params api_models.AuthParams) {
-				repo.EXPECT().SignUp(params.Login, gomock.Any(), gomock.Any()).Return(nil)
+				repo.EXPECT().SignUp(gomock.Any(), params.Login, gomock.Any(), gomock.Any(), params.Email).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -155,13 +267,250 @@ func TestUseCase_SignUp(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "with email",
+			args: api_models.AuthParams{
+				Login:    "login",
+				Password: "password",
+				Email:    "user@example.com",
+			},
+			mockBehaviour: func(params api_models.AuthParams) {
+				repo.EXPECT().SignUp(gomock.Any(), params.Login, gomock.Any(), gomock.Any(), params.Email).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid email",
+			args: api_models.AuthParams{
+				Login:    "login",
+				Password: "password",
+				Email:    "not an email",
+			},
+			mockBehaviour: func(params api_models.AuthParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "email with display name",
+			args: api_models.AuthParams{
+				Login:    "login",
+				Password: "password",
+				Email:    "User <user@example.com>",
+			},
+			mockBehaviour: func(params api_models.AuthParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "email already exists",
+			args: api_models.AuthParams{
+				Login:    "login",
+				Password: "password",
+				Email:    "user@example.com",
+			},
+			mockBehaviour: func(params api_models.AuthParams) {
+				repo.EXPECT().SignUp(gomock.Any(), params.Login, gomock.Any(), gomock.Any(), params.Email).Return(api_models.NewConflictError("email already exists"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			err := uc.SignUp(context.Background(), test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+}
+
+func TestUseCase_Refresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		&config.Config{MFA: config.MFA{RequireForAdmins: true}},
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	type mockBehaviour func(params api_models.RefreshParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.RefreshParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.RefreshParams{
+				RefreshToken: "token",
+			},
+			mockBehaviour: func(params api_models.RefreshParams) {
+				claims := api_models.RefreshClaims{UserId: "id", SessionId: "sid"}
+				access := api_models.UserAccess{UserId: "id", Roles: []string{"viewer"}}
+				tokenRepo.EXPECT().VerifyRefreshToken(gomock.Any(), params.RefreshToken).Return(claims, nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "id").Return(access, nil)
+				tokenRepo.EXPECT().RefreshTokensPair(gomock.Any(), params.RefreshToken, claims, access, api_models.ClientInfo{}).Return("a", "r", int64(1), nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "admin with mfa",
+			args: api_models.RefreshParams{
+				RefreshToken: "token",
+			},
+			mockBehaviour: func(params api_models.RefreshParams) {
+				claims := api_models.RefreshClaims{UserId: "id", SessionId: "sid"}
+				access := api_models.UserAccess{UserId: "id", Roles: []string{"viewer", "admin"}}
+				tokenRepo.EXPECT().VerifyRefreshToken(gomock.Any(), params.RefreshToken).Return(claims, nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "id").Return(access, nil)
+				repo.EXPECT().GetMFA(gomock.Any(), "id").Return(api_models.MFA{Secret: "secret", Enabled: true}, nil)
+				tokenRepo.EXPECT().RefreshTokensPair(gomock.Any(), params.RefreshToken, claims, access, api_models.ClientInfo{}).Return("a", "r", int64(1), nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "admin without mfa",
+			args: api_models.RefreshParams{
+				RefreshToken: "token",
+			},
+			mockBehaviour: func(params api_models.RefreshParams) {
+				claims := api_models.RefreshClaims{UserId: "id", SessionId: "sid"}
+				access := api_models.UserAccess{UserId: "id", Roles: []string{"viewer", "admin"}}
+				tokenRepo.EXPECT().VerifyRefreshToken(gomock.Any(), params.RefreshToken).Return(claims, nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "id").Return(access, nil)
+				repo.EXPECT().GetMFA(gomock.Any(), "id").Return(api_models.MFA{}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "disabled account",
+			args: api_models.RefreshParams{
+				RefreshToken: "token",
+			},
+			mockBehaviour: func(params api_models.RefreshParams) {
+				claims := api_models.RefreshClaims{UserId: "id", SessionId: "sid"}
+				access := api_models.UserAccess{UserId: "id", Disabled: true}
+				tokenRepo.EXPECT().VerifyRefreshToken(gomock.Any(), params.RefreshToken).Return(claims, nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "id").Return(access, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "revoked token",
+			args: api_models.RefreshParams{
+				RefreshToken: "token",
+			},
+			mockBehaviour: func(params api_models.RefreshParams) {
+				tokenRepo.EXPECT().VerifyRefreshToken(gomock.Any(), params.RefreshToken).Return(api_models.RefreshClaims{}, fmt.Errorf("revoked token"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty token",
+			args: api_models.RefreshParams{
+				RefreshToken: "",
+			},
+			mockBehaviour: func(params api_models.RefreshParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			_, err := uc.Refresh(context.Background(), test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+}
+
+func TestUseCase_Logout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	exp := time.Now().Add(time.Hour).Unix()
+
+	type mockBehaviour func(claims api_models.AuthClaims)
+
+	testTable := []struct {
+		name          string
+		args          api_models.AuthClaims
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.AuthClaims{
+				UserId:           "id",
+				SessionId:        "sid",
+				RegisteredClaims: jwt.RegisteredClaims{ID: "jti", ExpiresAt: jwt.NewNumericDate(time.Unix(exp, 0))},
+			},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				tokenRepo.EXPECT().RevokeSession(gomock.Any(), "id", "sid").Return(nil)
+				tokenRepo.EXPECT().RevokeAccessToken(gomock.Any(), "jti", exp).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "redis error",
+			args: api_models.AuthClaims{
+				UserId:           "id",
+				SessionId:        "sid",
+				RegisteredClaims: jwt.RegisteredClaims{ID: "jti", ExpiresAt: jwt.NewNumericDate(time.Unix(exp, 0))},
+			},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				tokenRepo.EXPECT().RevokeSession(gomock.Any(), "id", "sid").Return(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "no userId",
+			args: api_models.AuthClaims{},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, test := range testTable {
 		t.Run(test.name, func(t *testing.T) {
 			test.mockBehaviour(test.args)
 
-			err := uc.SignUp(test.args)
+			err := uc.Logout(context.Background(), test.args)
 
 			if test.wantErr {
 				assert.Error(t, err)
@@ -172,3 +521,259 @@ func TestUseCase_SignUp(t *testing.T) {
 	}
 
 }
+
+func TestUseCase_LogoutAll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	exp := time.Now().Add(time.Hour).Unix()
+
+	type mockBehaviour func(claims api_models.AuthClaims)
+
+	testTable := []struct {
+		name          string
+		args          api_models.AuthClaims
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.AuthClaims{
+				UserId:           "id",
+				SessionId:        "sid",
+				RegisteredClaims: jwt.RegisteredClaims{ID: "jti", ExpiresAt: jwt.NewNumericDate(time.Unix(exp, 0))},
+			},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				tokenRepo.EXPECT().RevokeSession(gomock.Any(), "id", "sid").Return(nil)
+				tokenRepo.EXPECT().RevokeAccessToken(gomock.Any(), "jti", exp).Return(nil)
+				tokenRepo.EXPECT().RevokeRefreshTokens(gomock.Any(), "id").Return(nil)
+				tokenRepo.EXPECT().RevokeAllAccessTokens(gomock.Any(), "id").Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "redis error",
+			args: api_models.AuthClaims{
+				UserId:           "id",
+				SessionId:        "sid",
+				RegisteredClaims: jwt.RegisteredClaims{ID: "jti", ExpiresAt: jwt.NewNumericDate(time.Unix(exp, 0))},
+			},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				tokenRepo.EXPECT().RevokeSession(gomock.Any(), "id", "sid").Return(nil)
+				tokenRepo.EXPECT().RevokeAccessToken(gomock.Any(), "jti", exp).Return(nil)
+				tokenRepo.EXPECT().RevokeRefreshTokens(gomock.Any(), "id").Return(nil)
+				tokenRepo.EXPECT().RevokeAllAccessTokens(gomock.Any(), "id").Return(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			err := uc.LogoutAll(context.Background(), test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+}
+
+func TestUseCase_GetSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	type mockBehaviour func(claims api_models.AuthClaims)
+
+	testTable := []struct {
+		name          string
+		args          api_models.AuthClaims
+		mockBehaviour mockBehaviour
+		wantCurrent   []bool
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.AuthClaims{UserId: "id", SessionId: "s2"},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				tokenRepo.EXPECT().GetSessions(gomock.Any(), "id").Return([]api_models.Session{
+					{SessionId: "s1"},
+					{SessionId: "s2"},
+				}, nil)
+			},
+			wantCurrent: []bool{false, true},
+			wantErr:     false,
+		},
+		{
+			name: "redis error",
+			args: api_models.AuthClaims{UserId: "id", SessionId: "s2"},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+				tokenRepo.EXPECT().GetSessions(gomock.Any(), "id").Return(nil, fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "no userId",
+			args: api_models.AuthClaims{},
+			mockBehaviour: func(claims api_models.AuthClaims) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			response, err := uc.GetSessions(context.Background(), test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				for i, current := range test.wantCurrent {
+					assert.Equal(t, current, response.Response[i].Current)
+				}
+			}
+		})
+	}
+
+}
+
+func TestUseCase_RevokeSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	type mockBehaviour func(claims api_models.AuthClaims, params api_models.RevokeSessionParams)
+
+	testTable := []struct {
+		name          string
+		claims        api_models.AuthClaims
+		params        api_models.RevokeSessionParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name:   "default",
+			claims: api_models.AuthClaims{UserId: "id"},
+			params: api_models.RevokeSessionParams{SessionId: "sid"},
+			mockBehaviour: func(claims api_models.AuthClaims, params api_models.RevokeSessionParams) {
+				tokenRepo.EXPECT().RevokeSession(gomock.Any(), "id", "sid").Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:   "no session id",
+			claims: api_models.AuthClaims{UserId: "id"},
+			params: api_models.RevokeSessionParams{},
+			mockBehaviour: func(claims api_models.AuthClaims, params api_models.RevokeSessionParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.claims, test.params)
+
+			err := uc.RevokeSession(context.Background(), test.claims, test.params)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+}
+
+func TestUseCase_GetJWKS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			mockBehaviour: func() {
+				tokenRepo.EXPECT().GetPublicKeys(gomock.Any()).Return([]api_models.JWK{{Kid: "kid"}}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "redis error",
+			mockBehaviour: func() {
+				tokenRepo.EXPECT().GetPublicKeys(gomock.Any()).Return(nil, fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour()
+
+			response, err := uc.GetJWKS(context.Background())
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, response.Keys, 1)
+			}
+		})
+	}
+}
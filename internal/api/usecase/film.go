@@ -1,110 +1,194 @@
 package api_usecase
 
 import (
+	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"slices"
+	"strings"
+	"unicode/utf8"
 	api_models "vk_test_task/internal/api/models"
 	"vk_test_task/internal/common"
 )
 
-func (u UseCase) CreateFilm(params api_models.CreateFilmParams) (string, error) {
-	if len(params.Name) > common.FILM_NAME_MAXSIZE ||
-		len(params.Name) < common.FILM_NAME_MINSIZE {
-		return "", fmt.Errorf("usecase error: invalid film name")
+func (u UseCase) CreateFilm(ctx context.Context, params api_models.CreateFilmParams) (string, error) {
+	if utf8.RuneCountInString(params.Name) > common.FILM_NAME_MAXSIZE ||
+		utf8.RuneCountInString(params.Name) < common.FILM_NAME_MINSIZE {
+		return "", api_models.NewFieldError("name", fmt.Sprintf("length must be between %d and %d", common.FILM_NAME_MINSIZE, common.FILM_NAME_MAXSIZE))
 	}
 
-	if len(params.Description) > common.FILM_DESCRIPTION_MAXSIZE {
-		return "", fmt.Errorf("usecase error: description is too long")
+	if utf8.RuneCountInString(params.Description) > common.FILM_DESCRIPTION_MAXSIZE {
+		return "", api_models.NewFieldError("description", fmt.Sprintf("must be at most %d characters", common.FILM_DESCRIPTION_MAXSIZE))
 	}
 
 	if params.Rate < 0 || params.Rate > 10 {
-		return "", fmt.Errorf("usecase error: invalid film rate")
+		return "", api_models.NewFieldError("rate", "must be between 0 and 10")
 	}
 
 	filmId, err := uuid.NewUUID()
 	if err != nil {
-		return "", fmt.Errorf("usecase error: %s", err.Error())
+		return "", fmt.Errorf("usecase error: %w", err)
 	}
 	params.FilmId = filmId.String()
 
-	err = u.db.CreateFilm(params)
+	err = u.db.CreateFilm(ctx, params)
 	if err != nil {
-		return "", fmt.Errorf("usecase error: %s", err.Error())
+		return "", fmt.Errorf("usecase error: %w", err)
 	}
 
 	return params.FilmId, nil
 }
 
-func (u UseCase) GetFilms(params api_models.GetFilmsParams) (api_models.GetFilmsResponse, error) {
-	if params.IsAscending != common.SORT_FILM_ASC &&
-		params.IsAscending != common.SORT_FILM_DESC {
-		return api_models.GetFilmsResponse{}, fmt.Errorf("usecase error: invalid sort asc parameter")
+func (u UseCase) GetFilms(ctx context.Context, params api_models.GetFilmsParams) (api_models.GetFilmsResponse, error) {
+	if fieldErrors := params.Validate(); len(fieldErrors) > 0 {
+		return api_models.GetFilmsResponse{}, api_models.NewValidationError("invalid query parameters", fieldErrors...)
 	}
-	if params.SortBy != common.SORT_FILM_BY_RATE &&
-		params.SortBy != common.SORT_FILM_BY_NAME &&
-		params.SortBy != common.SORT_FILM_BY_RELEASE_DATE {
-		return api_models.GetFilmsResponse{}, fmt.Errorf("usecase error: invalid sort by parameter")
+	if len(params.Sort) == 0 {
+		params.Sort = api_models.DefaultFilmSort
 	}
 
-	response, err := u.db.GetFilms(params)
+	page, err := u.newPage(params.Sort, params.Limit, params.Cursor)
 	if err != nil {
-		return api_models.GetFilmsResponse{}, fmt.Errorf("usecase error: %s", err.Error())
+		return api_models.GetFilmsResponse{}, err
+	}
+
+	response, err := u.db.GetFilms(ctx, page)
+	if err != nil {
+		return api_models.GetFilmsResponse{}, fmt.Errorf("usecase error: %w", err)
 	}
 	return response, nil
 }
 
-func (u UseCase) UpdateFilm(params api_models.UpdateFilmParams) error {
+func (u UseCase) GetFilm(ctx context.Context, filmId string) (api_models.FilmDetails, error) {
+	if filmId == "" {
+		return api_models.FilmDetails{}, api_models.NewFieldError("film_id", "must not be empty")
+	}
+
+	response, err := u.db.GetFilmDetails(ctx, filmId)
+	if err != nil {
+		return api_models.FilmDetails{}, fmt.Errorf("usecase error: %w", err)
+	}
+	return response, nil
+}
+
+func (u UseCase) UpdateFilm(ctx context.Context, params api_models.UpdateFilmParams) error {
 	if params.FilmId == "" {
-		return fmt.Errorf("usecase error: invalid id")
+		return api_models.NewFieldError("film_id", "must not be empty")
 	}
-	if len(params.Name) > common.FILM_NAME_MAXSIZE {
-		return fmt.Errorf("usecase error: invalid film name")
+	if params.Name.Null || params.ReleaseDate.Null || params.Rate.Null {
+		return api_models.NewValidationError("invalid film", nullFieldErrors(map[string]bool{
+			"name":         params.Name.Null,
+			"release_date": params.ReleaseDate.Null,
+			"rate":         params.Rate.Null,
+		})...)
 	}
-	if len(params.Description) > common.FILM_DESCRIPTION_MAXSIZE {
-		return fmt.Errorf("usecase error: film description is too long")
+	if params.Name.Set && (utf8.RuneCountInString(params.Name.Value) > common.FILM_NAME_MAXSIZE ||
+		utf8.RuneCountInString(params.Name.Value) < common.FILM_NAME_MINSIZE) {
+		return api_models.NewFieldError("name", fmt.Sprintf("length must be between %d and %d", common.FILM_NAME_MINSIZE, common.FILM_NAME_MAXSIZE))
 	}
-	if params.Rate < 0 || params.Rate > 10 {
-		return fmt.Errorf("usecase error: invalid film rate")
+	if utf8.RuneCountInString(params.Description.Value) > common.FILM_DESCRIPTION_MAXSIZE {
+		return api_models.NewFieldError("description", fmt.Sprintf("must be at most %d characters", common.FILM_DESCRIPTION_MAXSIZE))
+	}
+	if params.Rate.Value < 0 || params.Rate.Value > 10 {
+		return api_models.NewFieldError("rate", "must be between 0 and 10")
+	}
+	if params.Actors.Set && (len(params.AddActors) > 0 || len(params.RemoveActors) > 0) {
+		return api_models.NewFieldError("actors", "cannot be combined with add_actors or remove_actors")
+	}
+	for _, actorId := range params.AddActors {
+		if slices.Contains(params.RemoveActors, actorId) {
+			return api_models.NewFieldError("add_actors", fmt.Sprintf("actor %s is also in remove_actors", actorId))
+		}
 	}
 
-	err := u.db.UpdateFilm(params)
+	version, err := u.db.GetFilmVersion(ctx, params.FilmId)
 	if err != nil {
-		return fmt.Errorf("usecase error: %s", err.Error())
+		return fmt.Errorf("usecase error: %w", err)
+	}
+	if err = checkVersion(params.IfMatch, version, "film"); err != nil {
+		return err
+	}
+	// дальше версия - условие в where, если фильм успели изменить, репозиторий вернет конфликт
+	params.Version = version
+
+	err = u.db.UpdateFilm(ctx, params)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
 	}
 
 	return nil
 }
 
-func (u UseCase) DeleteFilm(params api_models.DeleteFilmParams) error {
+func (u UseCase) DeleteFilm(ctx context.Context, params api_models.DeleteFilmParams) error {
 	if params.FilmId == "" {
-		return fmt.Errorf("usecase error: invalid film id")
+		return api_models.NewFieldError("film_id", "must not be empty")
+	}
+
+	version, err := u.db.GetFilmVersion(ctx, params.FilmId)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+	if err = checkVersion(params.IfMatch, version, "film"); err != nil {
+		return err
 	}
 
-	err := u.db.DeleteFilm(params.FilmId)
+	err = u.db.DeleteFilm(ctx, params.FilmId, version)
 	if err != nil {
-		return fmt.Errorf("usecase error: %s", err.Error())
+		return fmt.Errorf("usecase error: %w", err)
 	}
 
 	return nil
 }
 
-func (u UseCase) SearchFilm(params api_models.SearchFilmParams) (api_models.SearchFilmResponse, error) {
+func (u UseCase) SearchFilm(ctx context.Context, params api_models.SearchFilmParams) (api_models.SearchFilmResponse, error) {
 	if params.ActorName == "" && params.Name == "" {
-		return api_models.SearchFilmResponse{}, fmt.Errorf("usecase error: invalid params")
+		return api_models.SearchFilmResponse{}, api_models.NewFieldError("name", "name or actor_name is required")
+	}
+	if fieldErrors := params.Validate(); len(fieldErrors) > 0 {
+		return api_models.SearchFilmResponse{}, api_models.NewValidationError("invalid query parameters", fieldErrors...)
+	}
+	if len(params.Sort) == 0 {
+		params.Sort = api_models.DefaultFilmSort
+	}
+
+	page, err := u.newPage(params.Sort, params.Limit, params.Cursor)
+	if err != nil {
+		return api_models.SearchFilmResponse{}, err
 	}
 
 	var response api_models.SearchFilmResponse
-	var err error
 
 	if name := params.Name; name != "" {
-		response, err = u.db.SearchFilmByName(name)
+		response, err = u.db.SearchFilmByName(ctx, name, page)
 	} else {
-		response, err = u.db.SearchFilmByActorName(params.ActorName)
+		response, err = u.db.SearchFilmByActorName(ctx, params.ActorName, page)
 	}
 
 	if err != nil {
-		return api_models.SearchFilmResponse{}, fmt.Errorf("usecase error: %s", err.Error())
+		return api_models.SearchFilmResponse{}, fmt.Errorf("usecase error: %w", err)
 	}
 
 	return response, nil
 }
+
+// nullFieldErrors - ошибки для полей, которым в патче передали null, хотя очистить их нельзя
+func nullFieldErrors(fields map[string]bool) []api_models.FieldError {
+	var fieldErrors []api_models.FieldError
+	for field, null := range fields {
+		if null {
+			fieldErrors = append(fieldErrors, api_models.FieldError{Field: field, Message: "must not be null"})
+		}
+	}
+	slices.SortFunc(fieldErrors, func(a, b api_models.FieldError) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+	return fieldErrors
+}
+
+// checkVersion сверяет версии из If-Match с текущей, достаточно совпадения с любой. Пустой expected - клиент условие не передал
+func checkVersion(expected []int, current int, entity string) error {
+	if len(expected) > 0 && !slices.Contains(expected, current) {
+		return api_models.NewPreconditionError(fmt.Sprintf("%s version mismatch", entity))
+	}
+	return nil
+}
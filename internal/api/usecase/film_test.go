@@ -1,12 +1,17 @@
 package api_usecase
 
 import (
+	"context"
+	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 	"time"
+	"vk_test_task/config"
 	mock_api "vk_test_task/internal/api/mocks"
 	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
 )
 
 func TestUseCase_CreateFilm(t *testing.T) {
@@ -21,6 +26,7 @@ func TestUseCase_CreateFilm(t *testing.T) {
 		nil,
 		repo,
 		tokenRepo,
+		nil,
 	)
 
 	type mockBehaviour func(params api_models.CreateFilmParams)
@@ -41,7 +47,7 @@ func TestUseCase_CreateFilm(t *testing.T) {
 				Actors:      []string{"id1", "id2"},
 			},
 			mockBehaviour: func(params api_models.CreateFilmParams) {
-				repo.EXPECT().CreateFilm(gomock.Any()).Return(nil)
+				repo.EXPECT().CreateFilm(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			wantErr: false,
 		},
@@ -58,6 +64,20 @@ func TestUseCase_CreateFilm(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "multibyte name and description at max length",
+			args: api_models.CreateFilmParams{
+				Name:        strings.Repeat("ф", common.FILM_NAME_MAXSIZE),
+				Description: strings.Repeat("о", common.FILM_DESCRIPTION_MAXSIZE),
+				ReleaseDate: time.Now(),
+				Rate:        10,
+				Actors:      []string{"id1", "id2"},
+			},
+			mockBehaviour: func(params api_models.CreateFilmParams) {
+				repo.EXPECT().CreateFilm(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantErr: false,
+		},
 		{
 			name: "invalid rate",
 			args: api_models.CreateFilmParams{
@@ -77,7 +97,7 @@ func TestUseCase_CreateFilm(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mockBehaviour(test.args)
 
-			_, err := uc.CreateFilm(test.args)
+			_, err := uc.CreateFilm(context.Background(), test.args)
 
 			if test.wantErr {
 				assert.Error(t, err)
@@ -101,6 +121,7 @@ func TestUseCase_UpdateFilm(t *testing.T) {
 		nil,
 		repo,
 		tokenRepo,
+		nil,
 	)
 
 	type mockBehaviour func(params api_models.UpdateFilmParams)
@@ -110,31 +131,75 @@ func TestUseCase_UpdateFilm(t *testing.T) {
 		args          api_models.UpdateFilmParams
 		mockBehaviour mockBehaviour
 		wantErr       bool
+		wantErrIs     error
 	}{
 		{
 			name: "default",
 			args: api_models.UpdateFilmParams{
 				FilmId:      "id",
-				Name:        "Name",
-				Description: "desc",
-				ReleaseDate: time.Now(),
-				Rate:        10,
-				Actors:      []string{"id1", "id2"},
+				Name:        api_models.NewOptional("Name"),
+				Description: api_models.NewOptional("desc"),
+				ReleaseDate: api_models.NewOptional(time.Now()),
+				Rate:        api_models.NewOptional(10),
+				Actors:      api_models.NewOptional([]string{"id1", "id2"}),
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+				repo.EXPECT().GetFilmVersion(gomock.Any(), "id").Return(1, nil)
+				params.Version = 1
+				repo.EXPECT().UpdateFilm(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "if-match matches",
+			args: api_models.UpdateFilmParams{
+				FilmId:  "id",
+				Rate:    api_models.NewOptional(7),
+				IfMatch: []int{4},
+				Version: 4,
 			},
 			mockBehaviour: func(params api_models.UpdateFilmParams) {
-				repo.EXPECT().UpdateFilm(params).Return(nil)
+				repo.EXPECT().GetFilmVersion(gomock.Any(), "id").Return(4, nil)
+				repo.EXPECT().UpdateFilm(gomock.Any(), params).Return(nil)
 			},
 			wantErr: false,
 		},
+		{
+			name: "if-match is stale",
+			args: api_models.UpdateFilmParams{
+				FilmId:  "id",
+				Rate:    api_models.NewOptional(7),
+				IfMatch: []int{3},
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+				repo.EXPECT().GetFilmVersion(gomock.Any(), "id").Return(4, nil)
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrPrecondition,
+		},
+		{
+			name: "changed concurrently",
+			args: api_models.UpdateFilmParams{
+				FilmId: "id",
+				Rate:   api_models.NewOptional(7),
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+				repo.EXPECT().GetFilmVersion(gomock.Any(), "id").Return(4, nil)
+				params.Version = 4
+				repo.EXPECT().UpdateFilm(gomock.Any(), params).Return(api_models.NewConflictError("film was modified concurrently"))
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrConflict,
+		},
 		{
 			name: "invalid filmId",
 			args: api_models.UpdateFilmParams{
 				FilmId:      "",
-				Name:        "Name",
-				Description: "desc",
-				ReleaseDate: time.Now(),
-				Rate:        10,
-				Actors:      []string{"id1", "id2"},
+				Name:        api_models.NewOptional("Name"),
+				Description: api_models.NewOptional("desc"),
+				ReleaseDate: api_models.NewOptional(time.Now()),
+				Rate:        api_models.NewOptional(10),
+				Actors:      api_models.NewOptional([]string{"id1", "id2"}),
 			},
 			mockBehaviour: func(params api_models.UpdateFilmParams) {
 			},
@@ -144,11 +209,71 @@ func TestUseCase_UpdateFilm(t *testing.T) {
 			name: "invalid rate",
 			args: api_models.UpdateFilmParams{
 				FilmId:      "id",
-				Name:        "Name",
-				Description: "desc",
-				ReleaseDate: time.Now(),
-				Rate:        19999999,
-				Actors:      []string{"id1", "id2"},
+				Name:        api_models.NewOptional("Name"),
+				Description: api_models.NewOptional("desc"),
+				ReleaseDate: api_models.NewOptional(time.Now()),
+				Rate:        api_models.NewOptional(19999999),
+				Actors:      api_models.NewOptional([]string{"id1", "id2"}),
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero rate and cleared description",
+			args: api_models.UpdateFilmParams{
+				FilmId:      "id",
+				Description: api_models.Optional[string]{Set: true, Null: true},
+				Rate:        api_models.NewOptional(0),
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+				repo.EXPECT().GetFilmVersion(gomock.Any(), "id").Return(1, nil)
+				params.Version = 1
+				repo.EXPECT().UpdateFilm(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "single actors",
+			args: api_models.UpdateFilmParams{
+				FilmId:       "id",
+				AddActors:    []string{"id3"},
+				RemoveActors: []string{"id1"},
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+				repo.EXPECT().GetFilmVersion(gomock.Any(), "id").Return(1, nil)
+				params.Version = 1
+				repo.EXPECT().UpdateFilm(gomock.Any(), params).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "null name",
+			args: api_models.UpdateFilmParams{
+				FilmId: "id",
+				Name:   api_models.Optional[string]{Set: true, Null: true},
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "actors with add_actors",
+			args: api_models.UpdateFilmParams{
+				FilmId:    "id",
+				Actors:    api_models.NewOptional([]string{"id1"}),
+				AddActors: []string{"id2"},
+			},
+			mockBehaviour: func(params api_models.UpdateFilmParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "same actor added and removed",
+			args: api_models.UpdateFilmParams{
+				FilmId:       "id",
+				AddActors:    []string{"id1"},
+				RemoveActors: []string{"id1"},
 			},
 			mockBehaviour: func(params api_models.UpdateFilmParams) {
 			},
@@ -160,10 +285,13 @@ func TestUseCase_UpdateFilm(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mockBehaviour(test.args)
 
-			err := uc.UpdateFilm(test.args)
+			err := uc.UpdateFilm(context.Background(), test.args)
 
 			if test.wantErr {
 				assert.Error(t, err)
+				if test.wantErrIs != nil {
+					assert.ErrorIs(t, err, test.wantErrIs)
+				}
 			} else {
 				assert.NoError(t, err)
 			}
@@ -180,14 +308,17 @@ func TestUseCase_GetFilms(t *testing.T) {
 	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
 
 	uc := New(
-		nil,
+		&config.Config{Pagination: config.Pagination{DefaultPageSize: 10, MaxPageSize: 50}},
 		nil,
 		repo,
 		tokenRepo,
+		nil,
 	)
 
 	type mockBehaviour func(params api_models.GetFilmsParams)
 
+	after := api_models.Cursor{Sort: "name", Values: []string{"Avatar"}, Id: "id"}
+
 	testTable := []struct {
 		name          string
 		args          api_models.GetFilmsParams
@@ -197,29 +328,67 @@ func TestUseCase_GetFilms(t *testing.T) {
 		{
 			name: "default",
 			args: api_models.GetFilmsParams{
-				SortBy:      1,
-				IsAscending: 1,
+				Sort: api_models.Sort{{Field: "name"}},
+			},
+			mockBehaviour: func(params api_models.GetFilmsParams) {
+				repo.EXPECT().GetFilms(gomock.Any(), api_models.Page{Sort: params.Sort, Limit: 10}).Return(api_models.GetFilmsResponse{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "default sort",
+			args: api_models.GetFilmsParams{},
+			mockBehaviour: func(params api_models.GetFilmsParams) {
+				repo.EXPECT().GetFilms(gomock.Any(), api_models.Page{Sort: api_models.DefaultFilmSort, Limit: 10}).
+					Return(api_models.GetFilmsResponse{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "next page",
+			args: api_models.GetFilmsParams{
+				Sort:   api_models.Sort{{Field: "name"}},
+				Limit:  50,
+				Cursor: after.Encode(),
 			},
 			mockBehaviour: func(params api_models.GetFilmsParams) {
-				repo.EXPECT().GetFilms(params).Return(api_models.GetFilmsResponse{}, nil)
+				repo.EXPECT().GetFilms(gomock.Any(), api_models.Page{Sort: params.Sort, Limit: 50, After: &after}).
+					Return(api_models.GetFilmsResponse{}, nil)
 			},
 			wantErr: false,
 		},
 		{
-			name: "invalid sort by",
+			name: "limit above maximum",
 			args: api_models.GetFilmsParams{
-				SortBy:      9999,
-				IsAscending: 1,
+				Limit: 51,
 			},
 			mockBehaviour: func(params api_models.GetFilmsParams) {
 			},
 			wantErr: true,
 		},
 		{
-			name: "invalid sort asc",
+			name: "cursor from another sort",
 			args: api_models.GetFilmsParams{
-				SortBy:      1,
-				IsAscending: -1111,
+				Sort:   api_models.Sort{{Field: "name", Desc: true}},
+				Cursor: after.Encode(),
+			},
+			mockBehaviour: func(params api_models.GetFilmsParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid sort field",
+			args: api_models.GetFilmsParams{
+				Sort: api_models.Sort{{Field: "budget"}},
+			},
+			mockBehaviour: func(params api_models.GetFilmsParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate sort field",
+			args: api_models.GetFilmsParams{
+				Sort: api_models.Sort{{Field: "rate"}, {Field: "rate", Desc: true}},
 			},
 			mockBehaviour: func(params api_models.GetFilmsParams) {
 			},
@@ -231,7 +400,7 @@ func TestUseCase_GetFilms(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mockBehaviour(test.args)
 
-			_, err := uc.GetFilms(test.args)
+			_, err := uc.GetFilms(context.Background(), test.args)
 
 			if test.wantErr {
 				assert.Error(t, err)
@@ -255,28 +424,49 @@ func TestUseCase_DeleteFilm(t *testing.T) {
 		nil,
 		repo,
 		tokenRepo,
+		nil,
 	)
 
-	type mockBehaviour func(filmId string)
+	type mockBehaviour func(params api_models.DeleteFilmParams)
 
 	testTable := []struct {
 		name          string
-		filmId        string
+		params        api_models.DeleteFilmParams
 		mockBehaviour mockBehaviour
 		wantErr       bool
+		wantErrIs     error
 	}{
 		{
 			name:   "default",
-			filmId: "id",
-			mockBehaviour: func(filmId string) {
-				repo.EXPECT().DeleteFilm(filmId).Return(nil)
+			params: api_models.DeleteFilmParams{FilmId: "id"},
+			mockBehaviour: func(params api_models.DeleteFilmParams) {
+				repo.EXPECT().GetFilmVersion(gomock.Any(), params.FilmId).Return(2, nil)
+				repo.EXPECT().DeleteFilm(gomock.Any(), params.FilmId, 2).Return(nil)
 			},
 			wantErr: false,
 		},
+		{
+			name:   "if-match is stale",
+			params: api_models.DeleteFilmParams{FilmId: "id", IfMatch: []int{1}},
+			mockBehaviour: func(params api_models.DeleteFilmParams) {
+				repo.EXPECT().GetFilmVersion(gomock.Any(), params.FilmId).Return(2, nil)
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrPrecondition,
+		},
+		{
+			name:   "not found",
+			params: api_models.DeleteFilmParams{FilmId: "id"},
+			mockBehaviour: func(params api_models.DeleteFilmParams) {
+				repo.EXPECT().GetFilmVersion(gomock.Any(), params.FilmId).Return(0, api_models.NewNotFoundError("film not found"))
+			},
+			wantErr:   true,
+			wantErrIs: api_models.ErrNotFound,
+		},
 		{
 			name:   "invalid filmId",
-			filmId: "",
-			mockBehaviour: func(filmId string) {
+			params: api_models.DeleteFilmParams{FilmId: ""},
+			mockBehaviour: func(params api_models.DeleteFilmParams) {
 			},
 			wantErr: true,
 		},
@@ -284,12 +474,15 @@ func TestUseCase_DeleteFilm(t *testing.T) {
 
 	for _, test := range testTable {
 		t.Run(test.name, func(t *testing.T) {
-			test.mockBehaviour(test.filmId)
+			test.mockBehaviour(test.params)
 
-			err := uc.DeleteFilm(api_models.DeleteFilmParams{FilmId: test.filmId})
+			err := uc.DeleteFilm(context.Background(), test.params)
 
 			if test.wantErr {
 				assert.Error(t, err)
+				if test.wantErrIs != nil {
+					assert.ErrorIs(t, err, test.wantErrIs)
+				}
 			} else {
 				assert.NoError(t, err)
 			}
@@ -310,6 +503,7 @@ func TestUseCase_SearchFilm(t *testing.T) {
 		nil,
 		repo,
 		tokenRepo,
+		nil,
 	)
 
 	type mockBehaviour func(params api_models.SearchFilmParams)
@@ -327,7 +521,7 @@ func TestUseCase_SearchFilm(t *testing.T) {
 				ActorName: "",
 			},
 			mockBehaviour: func(params api_models.SearchFilmParams) {
-				repo.EXPECT().SearchFilmByName(params.Name).Return(api_models.SearchFilmResponse{}, nil)
+				repo.EXPECT().SearchFilmByName(gomock.Any(), params.Name, api_models.Page{Sort: api_models.DefaultFilmSort, Limit: common.PAGE_SIZE_DEFAULT}).Return(api_models.SearchFilmResponse{}, nil)
 			},
 			wantErr: false,
 		},
@@ -338,10 +532,33 @@ func TestUseCase_SearchFilm(t *testing.T) {
 				ActorName: "actorname",
 			},
 			mockBehaviour: func(params api_models.SearchFilmParams) {
-				repo.EXPECT().SearchFilmByActorName(params.ActorName).Return(api_models.SearchFilmResponse{}, nil)
+				repo.EXPECT().SearchFilmByActorName(gomock.Any(), params.ActorName, api_models.Page{Sort: api_models.DefaultFilmSort, Limit: common.PAGE_SIZE_DEFAULT}).Return(api_models.SearchFilmResponse{}, nil)
 			},
 			wantErr: false,
 		},
+		{
+			name: "sorted page",
+			args: api_models.SearchFilmParams{
+				Name:  "name",
+				Sort:  api_models.Sort{{Field: "release_date", Desc: true}},
+				Limit: 5,
+			},
+			mockBehaviour: func(params api_models.SearchFilmParams) {
+				repo.EXPECT().SearchFilmByName(gomock.Any(), params.Name, api_models.Page{Sort: params.Sort, Limit: 5}).
+					Return(api_models.SearchFilmResponse{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "malformed cursor",
+			args: api_models.SearchFilmParams{
+				Name:   "name",
+				Cursor: "%%%",
+			},
+			mockBehaviour: func(params api_models.SearchFilmParams) {
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid params",
 			args: api_models.SearchFilmParams{
@@ -358,7 +575,7 @@ func TestUseCase_SearchFilm(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.mockBehaviour(test.args)
 
-			_, err := uc.SearchFilm(test.args)
+			_, err := uc.SearchFilm(context.Background(), test.args)
 
 			if test.wantErr {
 				assert.Error(t, err)
@@ -369,3 +586,89 @@ func TestUseCase_SearchFilm(t *testing.T) {
 	}
 
 }
+
+func TestUseCase_GetFilm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	testTable := []struct {
+		name          string
+		filmId        string
+		mockBehaviour func(filmId string)
+		wantErr       error
+	}{
+		{
+			name:   "default",
+			filmId: "id",
+			mockBehaviour: func(filmId string) {
+				repo.EXPECT().GetFilmDetails(gomock.Any(), filmId).Return(api_models.FilmDetails{FilmId: filmId}, nil)
+			},
+		},
+		{
+			name:   "not found",
+			filmId: "id",
+			mockBehaviour: func(filmId string) {
+				repo.EXPECT().GetFilmDetails(gomock.Any(), filmId).Return(api_models.FilmDetails{}, fmt.Errorf("film %w", api_models.ErrNotFound))
+			},
+			wantErr: api_models.ErrNotFound,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.filmId)
+
+			_, err := uc.GetFilm(context.Background(), test.filmId)
+
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUseCase_FilmErrorCodes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	t.Run("validation", func(t *testing.T) {
+		_, err := uc.CreateFilm(context.Background(), api_models.CreateFilmParams{Name: "name", Rate: 11})
+
+		var domainErr *api_models.Error
+		assert.ErrorAs(t, err, &domainErr)
+		assert.Equal(t, api_models.CodeValidation, domainErr.Code)
+		assert.Equal(t, []api_models.FieldError{{Field: "rate", Message: "must be between 0 and 10"}}, domainErr.Details)
+	})
+
+	t.Run("repository error keeps its code", func(t *testing.T) {
+		repo.EXPECT().GetFilmDetails(gomock.Any(), "id").Return(api_models.FilmDetails{}, api_models.NewNotFoundError("film not found"))
+
+		_, err := uc.GetFilm(context.Background(), "id")
+
+		assert.ErrorIs(t, err, api_models.ErrNotFound)
+	})
+}
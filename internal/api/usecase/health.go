@@ -0,0 +1,83 @@
+package api_usecase
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync"
+	"time"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+)
+
+// Readiness параллельно проверяет postgres и редис, каждую зависимость не дольше Server.ReadinessTimeout.
+// Сервис готов, только если доступны все зависимости
+func (u UseCase) Readiness(ctx context.Context) api_models.ReadinessResponse {
+	timeout := time.Second * common.READINESS_CHECK_TIMEOUT
+	if u.cfg != nil && u.cfg.Server.ReadinessTimeout > 0 {
+		timeout = time.Second * time.Duration(u.cfg.Server.ReadinessTimeout)
+	}
+
+	checks := map[string]func(context.Context) error{
+		"postgres": u.db.Ping,
+		"redis":    u.rdb.Ping,
+	}
+
+	response := api_models.ReadinessResponse{
+		Status: api_models.StatusUp,
+		Checks: make(map[string]api_models.DependencyStatus, len(checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, ping := range checks {
+		wg.Add(1)
+		go func(name string, ping func(context.Context) error) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := ping(checkCtx)
+			status := api_models.DependencyStatus{Status: api_models.StatusUp, LatencyMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				status.Status, status.Err = api_models.StatusDown, err
+				// адреса и тексты ошибок драйверов наружу не отдаем
+				status.Error = "unreachable"
+				if errors.Is(checkCtx.Err(), context.DeadlineExceeded) {
+					status.Error = "timeout"
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			response.Checks[name] = status
+			if err != nil {
+				response.Status = api_models.StatusDown
+			}
+		}(name, ping)
+	}
+	wg.Wait()
+
+	return response
+}
+
+// Version отдает версию из конфига и коммит со временем сборки из -ldflags. Без -ldflags коммит берется из информации
+// о VCS, которую go build встраивает в бинарник. Время сборки берется только из -ldflags: vcs.time - время коммита, а не сборки
+func (u UseCase) Version() api_models.VersionResponse {
+	response := api_models.VersionResponse{Commit: common.GitCommit, BuildTime: common.BuildTime}
+	if u.cfg != nil {
+		response.Version = u.cfg.Server.Version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" && response.Commit == "" {
+				response.Commit = setting.Value
+			}
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,105 @@
+package api_usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"vk_test_task/config"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+)
+
+func TestUseCase_Readiness(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		&config.Config{Server: config.Server{ReadinessTimeout: 1}},
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		wantStatus    string
+		wantChecks    map[string]string
+	}{
+		{
+			name: "all up",
+			mockBehaviour: func() {
+				repo.EXPECT().Ping(gomock.Any()).Return(nil)
+				tokenRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+			},
+			wantStatus: api_models.StatusUp,
+			wantChecks: map[string]string{"postgres": api_models.StatusUp, "redis": api_models.StatusUp},
+		},
+		{
+			name: "postgres down",
+			mockBehaviour: func() {
+				repo.EXPECT().Ping(gomock.Any()).Return(fmt.Errorf("connection refused"))
+				tokenRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+			},
+			wantStatus: api_models.StatusDown,
+			wantChecks: map[string]string{"postgres": api_models.StatusDown, "redis": api_models.StatusUp},
+		},
+		{
+			name: "redis hangs",
+			mockBehaviour: func() {
+				repo.EXPECT().Ping(gomock.Any()).Return(nil)
+				tokenRepo.EXPECT().Ping(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				})
+			},
+			wantStatus: api_models.StatusDown,
+			wantChecks: map[string]string{"postgres": api_models.StatusUp, "redis": api_models.StatusDown},
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour()
+
+			response := uc.Readiness(context.Background())
+
+			assert.Equal(t, test.wantStatus, response.Status)
+			for name, status := range test.wantChecks {
+				assert.Equal(t, status, response.Checks[name].Status, name)
+			}
+		})
+	}
+
+	t.Run("timeout is reported", func(t *testing.T) {
+		repo.EXPECT().Ping(gomock.Any()).DoAndReturn(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		tokenRepo.EXPECT().Ping(gomock.Any()).Return(nil)
+
+		response := uc.Readiness(context.Background())
+
+		assert.Equal(t, "timeout", response.Checks["postgres"].Error)
+		assert.Error(t, response.Checks["postgres"].Err)
+	})
+}
+
+func TestUseCase_Version(t *testing.T) {
+	uc := New(&config.Config{Server: config.Server{Version: "1.2.3"}}, nil, nil, nil, nil)
+
+	assert.Equal(t, "1.2.3", uc.Version().Version)
+	// без -ldflags время сборки неизвестно, время коммита из VCS за него не выдается
+	assert.Equal(t, "", uc.Version().BuildTime)
+
+	common.BuildTime = "2024-01-01T00:00:00Z"
+	defer func() { common.BuildTime = "" }()
+	assert.Equal(t, "2024-01-01T00:00:00Z", uc.Version().BuildTime)
+}
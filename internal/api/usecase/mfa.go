@@ -0,0 +1,196 @@
+package api_usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+	"vk_test_task/internal/utils/totp"
+)
+
+func (u UseCase) EnrollMFA(ctx context.Context, claims api_models.AuthClaims) (api_models.EnrollMFAResponse, error) {
+	mfa, err := u.db.GetMFA(ctx, claims.UserId)
+	if err != nil {
+		return api_models.EnrollMFAResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+	if mfa.Enabled {
+		return api_models.EnrollMFAResponse{}, api_models.NewConflictError("two-factor authentication is already enabled")
+	}
+
+	user, err := u.db.GetUserById(ctx, claims.UserId)
+	if err != nil {
+		return api_models.EnrollMFAResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return api_models.EnrollMFAResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	if err = u.db.SaveMFASecret(ctx, claims.UserId, secret); err != nil {
+		return api_models.EnrollMFAResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	return api_models.EnrollMFAResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(u.cfg.MFA.Issuer, user.Login, secret),
+	}, nil
+}
+
+func (u UseCase) ConfirmMFA(ctx context.Context, claims api_models.AuthClaims, params api_models.MFACodeParams) (api_models.RecoveryCodesResponse, error) {
+	mfa, err := u.db.GetMFA(ctx, claims.UserId)
+	if err != nil {
+		return api_models.RecoveryCodesResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+	if mfa.Secret == "" {
+		return api_models.RecoveryCodesResponse{}, api_models.NewConflictError("two-factor authentication is not enrolled")
+	}
+	if mfa.Enabled {
+		return api_models.RecoveryCodesResponse{}, api_models.NewConflictError("two-factor authentication is already enabled")
+	}
+
+	if !totp.Validate(mfa.Secret, params.Code, time.Now()) {
+		return api_models.RecoveryCodesResponse{}, api_models.NewFieldError("code", "wrong code")
+	}
+
+	if _, err = u.rdb.MarkTOTPCodeUsed(ctx, claims.UserId, params.Code); err != nil {
+		return api_models.RecoveryCodesResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	codes, hashes, err := totp.NewRecoveryCodes(common.MFA_RECOVERY_CODES)
+	if err != nil {
+		return api_models.RecoveryCodesResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	if err = u.db.EnableMFA(ctx, claims.UserId, hashes); err != nil {
+		return api_models.RecoveryCodesResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	return api_models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+func (u UseCase) DisableMFA(ctx context.Context, claims api_models.AuthClaims, params api_models.MFACodeParams) error {
+	if u.cfg.MFA.RequireForAdmins && slices.Contains(claims.Roles, common.ROLE_ADMIN) {
+		return api_models.NewForbiddenError("two-factor authentication is required for admin accounts")
+	}
+
+	// код из 6 цифр перебирается быстро, поэтому попытки ограничены так же, как при входе
+	retryAfter, err := u.rdb.RegisterMFADisableAttempt(ctx, claims.UserId, u.mfaMaxAttempts())
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+	if retryAfter > 0 {
+		return api_models.TooManyRequestsError{RetryAfter: retryAfter}
+	}
+
+	ok, err := u.verifyMFACode(ctx, claims.UserId, params.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return api_models.NewFieldError("code", "wrong code")
+	}
+
+	if err = u.db.DisableMFA(ctx, claims.UserId); err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return nil
+}
+
+func (u UseCase) SignInMFA(ctx context.Context, params api_models.SignInMFAParams) (api_models.SignInUseCaseResponse, error) {
+	if params.MFAToken == "" || params.Code == "" {
+		return api_models.SignInUseCaseResponse{}, api_models.NewValidationError("wrong params")
+	}
+
+	challenge, err := u.rdb.GetMFAChallenge(ctx, params.MFAToken)
+	if err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	retryAfter, err := u.rdb.SignInRetryAfter(ctx, challenge.Login, params.IP)
+	if err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+	if retryAfter > 0 {
+		return api_models.SignInUseCaseResponse{}, api_models.TooManyRequestsError{RetryAfter: retryAfter}
+	}
+
+	attempts, err := u.rdb.RegisterMFAAttempt(ctx, params.MFAToken)
+	if err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	// после исчерпания попыток токен сгорает и нужно заново ввести пароль
+	if attempts > u.mfaMaxAttempts() {
+		if err = u.rdb.DeleteMFAChallenge(ctx, params.MFAToken); err != nil {
+			return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+		}
+		return api_models.SignInUseCaseResponse{}, api_models.NewUnauthorizedError("too many attempts, sign in again")
+	}
+
+	ok, err := u.verifyMFACode(ctx, challenge.UserId, params.Code)
+	if err != nil {
+		return api_models.SignInUseCaseResponse{}, err
+	}
+	if !ok {
+		if err = u.rdb.RegisterSignInFailure(ctx, challenge.Login, params.IP); err != nil {
+			return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+		}
+		return api_models.SignInUseCaseResponse{}, api_models.NewUnauthorizedError("wrong code")
+	}
+
+	if err = u.rdb.DeleteMFAChallenge(ctx, params.MFAToken); err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+
+	access, err := u.db.GetUserAccess(ctx, challenge.UserId)
+	if err != nil {
+		return api_models.SignInUseCaseResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+	if access.Disabled {
+		return api_models.SignInUseCaseResponse{}, api_models.NewForbiddenError("account is disabled")
+	}
+
+	return u.issueTokens(ctx, access, api_models.ClientInfo{
+		Device:    challenge.Device,
+		IP:        params.IP,
+		UserAgent: params.UserAgent,
+	})
+}
+
+func (u UseCase) mfaMaxAttempts() int64 {
+	maxAttempts := u.cfg.MFA.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = common.MFA_MAX_ATTEMPTS
+	}
+	return maxAttempts
+}
+
+// verifyMFACode принимает как код из приложения, так и одноразовый резервный код
+func (u UseCase) verifyMFACode(ctx context.Context, userId, code string) (bool, error) {
+	mfa, err := u.db.GetMFA(ctx, userId)
+	if err != nil {
+		return false, fmt.Errorf("usecase error: %w", err)
+	}
+	if !mfa.Enabled {
+		return false, api_models.NewConflictError("two-factor authentication is not enabled")
+	}
+
+	if totp.Validate(mfa.Secret, code, time.Now()) {
+		ok, err := u.rdb.MarkTOTPCodeUsed(ctx, userId, code)
+		if err != nil {
+			return false, fmt.Errorf("usecase error: %w", err)
+		}
+		return ok, nil
+	}
+
+	ok, err := u.db.UseRecoveryCode(ctx, userId, totp.HashRecoveryCode(code))
+	if err != nil {
+		return false, fmt.Errorf("usecase error: %w", err)
+	}
+
+	return ok, nil
+}
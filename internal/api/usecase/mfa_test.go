@@ -0,0 +1,361 @@
+package api_usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+	"vk_test_task/config"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+	"vk_test_task/internal/utils/totp"
+)
+
+func TestUseCase_EnrollMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		&config.Config{MFA: config.MFA{Issuer: "issuer"}},
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	claims := api_models.AuthClaims{UserId: "id"}
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			mockBehaviour: func() {
+				repo.EXPECT().GetMFA(gomock.Any(), claims.UserId).Return(api_models.MFA{}, nil)
+				repo.EXPECT().GetUserById(gomock.Any(), claims.UserId).Return(api_models.User{UserId: "id", Login: "login"}, nil)
+				repo.EXPECT().SaveMFASecret(gomock.Any(), claims.UserId, gomock.Any()).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "already enabled",
+			mockBehaviour: func() {
+				repo.EXPECT().GetMFA(gomock.Any(), claims.UserId).Return(api_models.MFA{Secret: "secret", Enabled: true}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "db error",
+			mockBehaviour: func() {
+				repo.EXPECT().GetMFA(gomock.Any(), claims.UserId).Return(api_models.MFA{}, fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour()
+
+			resp, err := uc.EnrollMFA(context.Background(), claims)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, resp.Secret)
+				assert.True(t, strings.HasPrefix(resp.ProvisioningURI, "otpauth://totp/issuer:login?"))
+			}
+		})
+	}
+}
+
+func TestUseCase_ConfirmMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	claims := api_models.AuthClaims{UserId: "id"}
+	secret, _ := totp.GenerateSecret()
+	code, _ := totp.Code(secret, time.Now())
+
+	type mockBehaviour func(params api_models.MFACodeParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.MFACodeParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.MFACodeParams{Code: code},
+			mockBehaviour: func(params api_models.MFACodeParams) {
+				repo.EXPECT().GetMFA(gomock.Any(), claims.UserId).Return(api_models.MFA{Secret: secret}, nil)
+				tokenRepo.EXPECT().MarkTOTPCodeUsed(gomock.Any(), claims.UserId, params.Code).Return(true, nil)
+				repo.EXPECT().EnableMFA(gomock.Any(), claims.UserId, gomock.Len(common.MFA_RECOVERY_CODES)).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "wrong code",
+			args: api_models.MFACodeParams{Code: "000000x"},
+			mockBehaviour: func(params api_models.MFACodeParams) {
+				repo.EXPECT().GetMFA(gomock.Any(), claims.UserId).Return(api_models.MFA{Secret: secret}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "not enrolled",
+			args: api_models.MFACodeParams{Code: code},
+			mockBehaviour: func(params api_models.MFACodeParams) {
+				repo.EXPECT().GetMFA(gomock.Any(), claims.UserId).Return(api_models.MFA{}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "already enabled",
+			args: api_models.MFACodeParams{Code: code},
+			mockBehaviour: func(params api_models.MFACodeParams) {
+				repo.EXPECT().GetMFA(gomock.Any(), claims.UserId).Return(api_models.MFA{Secret: secret, Enabled: true}, nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			resp, err := uc.ConfirmMFA(context.Background(), claims, test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, resp.RecoveryCodes, common.MFA_RECOVERY_CODES)
+			}
+		})
+	}
+}
+
+func TestUseCase_DisableMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		&config.Config{MFA: config.MFA{RequireForAdmins: true}},
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	secret, _ := totp.GenerateSecret()
+	code, _ := totp.Code(secret, time.Now())
+
+	type args struct {
+		claims api_models.AuthClaims
+		params api_models.MFACodeParams
+	}
+
+	testTable := []struct {
+		name          string
+		args          args
+		mockBehaviour func(args args)
+		wantErr       bool
+	}{
+		{
+			name: "totp code",
+			args: args{claims: api_models.AuthClaims{UserId: "id"}, params: api_models.MFACodeParams{Code: code}},
+			mockBehaviour: func(args args) {
+				tokenRepo.EXPECT().RegisterMFADisableAttempt(gomock.Any(), "id", int64(common.MFA_MAX_ATTEMPTS)).Return(time.Duration(0), nil)
+				repo.EXPECT().GetMFA(gomock.Any(), "id").Return(api_models.MFA{Secret: secret, Enabled: true}, nil)
+				tokenRepo.EXPECT().MarkTOTPCodeUsed(gomock.Any(), "id", args.params.Code).Return(true, nil)
+				repo.EXPECT().DisableMFA(gomock.Any(), "id").Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "recovery code",
+			args: args{claims: api_models.AuthClaims{UserId: "id"}, params: api_models.MFACodeParams{Code: "abcd-efgh"}},
+			mockBehaviour: func(args args) {
+				tokenRepo.EXPECT().RegisterMFADisableAttempt(gomock.Any(), "id", int64(common.MFA_MAX_ATTEMPTS)).Return(time.Duration(0), nil)
+				repo.EXPECT().GetMFA(gomock.Any(), "id").Return(api_models.MFA{Secret: secret, Enabled: true}, nil)
+				repo.EXPECT().UseRecoveryCode(gomock.Any(), "id", totp.HashRecoveryCode(args.params.Code)).Return(true, nil)
+				repo.EXPECT().DisableMFA(gomock.Any(), "id").Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "wrong code",
+			args: args{claims: api_models.AuthClaims{UserId: "id"}, params: api_models.MFACodeParams{Code: "abcd-efgh"}},
+			mockBehaviour: func(args args) {
+				tokenRepo.EXPECT().RegisterMFADisableAttempt(gomock.Any(), "id", int64(common.MFA_MAX_ATTEMPTS)).Return(time.Duration(0), nil)
+				repo.EXPECT().GetMFA(gomock.Any(), "id").Return(api_models.MFA{Secret: secret, Enabled: true}, nil)
+				repo.EXPECT().UseRecoveryCode(gomock.Any(), "id", totp.HashRecoveryCode(args.params.Code)).Return(false, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many attempts",
+			args: args{claims: api_models.AuthClaims{UserId: "id"}, params: api_models.MFACodeParams{Code: code}},
+			mockBehaviour: func(args args) {
+				tokenRepo.EXPECT().RegisterMFADisableAttempt(gomock.Any(), "id", int64(common.MFA_MAX_ATTEMPTS)).Return(time.Minute, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "admin",
+			args: args{claims: api_models.AuthClaims{UserId: "id", Roles: []string{"admin"}}, params: api_models.MFACodeParams{Code: code}},
+			mockBehaviour: func(args args) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			err := uc.DisableMFA(context.Background(), test.args.claims, test.args.params)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUseCase_SignInMFA(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		&config.Config{},
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	secret, _ := totp.GenerateSecret()
+	code, _ := totp.Code(secret, time.Now())
+	challenge := api_models.MFAChallenge{UserId: "id", Login: "login", Device: "phone"}
+	access := api_models.UserAccess{UserId: "id", Roles: []string{"admin"}}
+
+	type mockBehaviour func(params api_models.SignInMFAParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.SignInMFAParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.SignInMFAParams{MFAToken: "token", Code: code, IP: "127.0.0.1"},
+			mockBehaviour: func(params api_models.SignInMFAParams) {
+				tokenRepo.EXPECT().GetMFAChallenge(gomock.Any(), params.MFAToken).Return(challenge, nil)
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), challenge.Login, params.IP).Return(time.Duration(0), nil)
+				tokenRepo.EXPECT().RegisterMFAAttempt(gomock.Any(), params.MFAToken).Return(int64(1), nil)
+				repo.EXPECT().GetMFA(gomock.Any(), "id").Return(api_models.MFA{Secret: secret, Enabled: true}, nil)
+				tokenRepo.EXPECT().MarkTOTPCodeUsed(gomock.Any(), "id", params.Code).Return(true, nil)
+				tokenRepo.EXPECT().DeleteMFAChallenge(gomock.Any(), params.MFAToken).Return(nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "id").Return(access, nil)
+				tokenRepo.EXPECT().CreateTokensPair(gomock.Any(), access, api_models.ClientInfo{Device: "phone", IP: params.IP}).
+					Return("access", "refresh", int64(1), nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "reused code",
+			args: api_models.SignInMFAParams{MFAToken: "token", Code: code, IP: "127.0.0.1"},
+			mockBehaviour: func(params api_models.SignInMFAParams) {
+				tokenRepo.EXPECT().GetMFAChallenge(gomock.Any(), params.MFAToken).Return(challenge, nil)
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), challenge.Login, params.IP).Return(time.Duration(0), nil)
+				tokenRepo.EXPECT().RegisterMFAAttempt(gomock.Any(), params.MFAToken).Return(int64(2), nil)
+				repo.EXPECT().GetMFA(gomock.Any(), "id").Return(api_models.MFA{Secret: secret, Enabled: true}, nil)
+				tokenRepo.EXPECT().MarkTOTPCodeUsed(gomock.Any(), "id", params.Code).Return(false, nil)
+				tokenRepo.EXPECT().RegisterSignInFailure(gomock.Any(), challenge.Login, params.IP).Return(nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many attempts",
+			args: api_models.SignInMFAParams{MFAToken: "token", Code: code},
+			mockBehaviour: func(params api_models.SignInMFAParams) {
+				tokenRepo.EXPECT().GetMFAChallenge(gomock.Any(), params.MFAToken).Return(challenge, nil)
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), challenge.Login, params.IP).Return(time.Duration(0), nil)
+				tokenRepo.EXPECT().RegisterMFAAttempt(gomock.Any(), params.MFAToken).Return(int64(common.MFA_MAX_ATTEMPTS+1), nil)
+				tokenRepo.EXPECT().DeleteMFAChallenge(gomock.Any(), params.MFAToken).Return(nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "account locked",
+			args: api_models.SignInMFAParams{MFAToken: "token", Code: code, IP: "127.0.0.1"},
+			mockBehaviour: func(params api_models.SignInMFAParams) {
+				tokenRepo.EXPECT().GetMFAChallenge(gomock.Any(), params.MFAToken).Return(challenge, nil)
+				tokenRepo.EXPECT().SignInRetryAfter(gomock.Any(), challenge.Login, params.IP).Return(time.Minute, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired token",
+			args: api_models.SignInMFAParams{MFAToken: "token", Code: code},
+			mockBehaviour: func(params api_models.SignInMFAParams) {
+				tokenRepo.EXPECT().GetMFAChallenge(gomock.Any(), params.MFAToken).Return(api_models.MFAChallenge{}, fmt.Errorf("invalid or expired mfa token"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty params",
+			args: api_models.SignInMFAParams{},
+			mockBehaviour: func(params api_models.SignInMFAParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			_, err := uc.SignInMFA(context.Background(), test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
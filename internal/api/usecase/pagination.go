@@ -0,0 +1,42 @@
+package api_usecase
+
+import (
+	"fmt"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+)
+
+// newPage проверяет limit по настройкам пагинации и разбирает курсор.
+// Курсор привязан к сортировке, с которой он получен: значения другой сортировки дали бы неверную страницу
+func (u UseCase) newPage(sort api_models.Sort, limit int, cursor string) (api_models.Page, error) {
+	defaultSize, maxSize := common.PAGE_SIZE_DEFAULT, common.PAGE_SIZE_MAX
+	if u.cfg != nil && u.cfg.Pagination.DefaultPageSize > 0 {
+		defaultSize = u.cfg.Pagination.DefaultPageSize
+	}
+	if u.cfg != nil && u.cfg.Pagination.MaxPageSize > 0 {
+		maxSize = u.cfg.Pagination.MaxPageSize
+	}
+
+	if limit == 0 {
+		limit = min(defaultSize, maxSize)
+	}
+	if limit < 0 || limit > maxSize {
+		return api_models.Page{}, api_models.NewFieldError("limit", fmt.Sprintf("must be between 1 and %d", maxSize))
+	}
+
+	page := api_models.Page{Sort: sort, Limit: limit}
+	if cursor == "" {
+		return page, nil
+	}
+
+	after, err := api_models.DecodeCursor(cursor)
+	if err != nil {
+		return api_models.Page{}, err
+	}
+	if after.Sort != sort.String() || len(after.Values) != len(sort) {
+		return api_models.Page{}, api_models.NewFieldError("cursor", "cursor does not match sort")
+	}
+	page.After = &after
+
+	return page, nil
+}
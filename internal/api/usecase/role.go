@@ -0,0 +1,53 @@
+package api_usecase
+
+import (
+	"context"
+	"fmt"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func (u UseCase) GetRoles(ctx context.Context) (api_models.GetRolesResponse, error) {
+	response, err := u.db.GetRoles(ctx)
+	if err != nil {
+		return api_models.GetRolesResponse{}, fmt.Errorf("usecase error: %w", err)
+	}
+	return response, nil
+}
+
+func (u UseCase) GrantRole(ctx context.Context, params api_models.RoleParams) error {
+	if params.UserId == "" {
+		return api_models.NewFieldError("user_id", "must not be empty")
+	}
+	if params.Role == "" {
+		return api_models.NewFieldError("role", "must not be empty")
+	}
+
+	err := u.db.GrantRole(ctx, params.UserId, params.Role)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return nil
+}
+
+func (u UseCase) RevokeRole(ctx context.Context, params api_models.RoleParams) error {
+	if params.UserId == "" {
+		return api_models.NewFieldError("user_id", "must not be empty")
+	}
+	if params.Role == "" {
+		return api_models.NewFieldError("role", "must not be empty")
+	}
+
+	err := u.db.RevokeRole(ctx, params.UserId, params.Role)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	// права лежат в access токенах, поэтому отзываем их - клиенты получат новые права через /refresh
+	err = u.rdb.RevokeAllAccessTokens(ctx, params.UserId)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return nil
+}
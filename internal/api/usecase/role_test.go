@@ -0,0 +1,196 @@
+package api_usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestUseCase_GetRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	testTable := []struct {
+		name          string
+		mockBehaviour func()
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			mockBehaviour: func() {
+				repo.EXPECT().GetRoles(gomock.Any()).Return(api_models.GetRolesResponse{}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "repo error",
+			mockBehaviour: func() {
+				repo.EXPECT().GetRoles(gomock.Any()).Return(api_models.GetRolesResponse{}, fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour()
+
+			_, err := uc.GetRoles(context.Background())
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUseCase_GrantRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	type mockBehaviour func(params api_models.RoleParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.RoleParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.RoleParams{UserId: "id", Role: "editor"},
+			mockBehaviour: func(params api_models.RoleParams) {
+				repo.EXPECT().GrantRole(gomock.Any(), params.UserId, params.Role).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "repo error",
+			args: api_models.RoleParams{UserId: "id", Role: "owner"},
+			mockBehaviour: func(params api_models.RoleParams) {
+				repo.EXPECT().GrantRole(gomock.Any(), params.UserId, params.Role).Return(fmt.Errorf("role not found"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "no role",
+			args: api_models.RoleParams{UserId: "id"},
+			mockBehaviour: func(params api_models.RoleParams) {
+			},
+			wantErr: true,
+		},
+		{
+			name: "no user",
+			args: api_models.RoleParams{Role: "editor"},
+			mockBehaviour: func(params api_models.RoleParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			err := uc.GrantRole(context.Background(), test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUseCase_RevokeRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	type mockBehaviour func(params api_models.RoleParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.RoleParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.RoleParams{UserId: "id", Role: "editor"},
+			mockBehaviour: func(params api_models.RoleParams) {
+				repo.EXPECT().RevokeRole(gomock.Any(), params.UserId, params.Role).Return(nil)
+				tokenRepo.EXPECT().RevokeAllAccessTokens(gomock.Any(), params.UserId).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "repo error",
+			args: api_models.RoleParams{UserId: "id", Role: "editor"},
+			mockBehaviour: func(params api_models.RoleParams) {
+				repo.EXPECT().RevokeRole(gomock.Any(), params.UserId, params.Role).Return(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "no role",
+			args: api_models.RoleParams{UserId: "id"},
+			mockBehaviour: func(params api_models.RoleParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			err := uc.RevokeRole(context.Background(), test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
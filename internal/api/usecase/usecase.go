@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"vk_test_task/config"
 	"vk_test_task/internal/api"
+	"vk_test_task/internal/utils/mailer"
 )
 
 type UseCase struct {
@@ -11,13 +12,21 @@ type UseCase struct {
 	logger *slog.Logger
 	db     api.RepositoryInterface
 	rdb    api.TokenRepositoryInterface
+	mailer mailer.Mailer
+
+	// background запускает работу, которая не должна задерживать ответ (отправка писем), в тестах выполняет ее сразу
+	background func(func())
 }
 
-func New(cfg *config.Config, logger *slog.Logger, db api.RepositoryInterface, tokenRepo api.TokenRepositoryInterface) UseCase {
+func New(cfg *config.Config, logger *slog.Logger, db api.RepositoryInterface, tokenRepo api.TokenRepositoryInterface, mailer mailer.Mailer) UseCase {
 	return UseCase{
 		cfg:    cfg,
 		logger: logger,
 		db:     db,
 		rdb:    tokenRepo,
+		mailer: mailer,
+		background: func(fn func()) {
+			go fn()
+		},
 	}
 }
@@ -0,0 +1,172 @@
+package api_usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+	"vk_test_task/internal/utils/encryption"
+	log "vk_test_task/pkg/logger"
+)
+
+func (u UseCase) UnlockUser(ctx context.Context, params api_models.UnlockUserParams) error {
+	if params.Login == "" {
+		return api_models.NewFieldError("login", "must not be empty")
+	}
+
+	err := u.rdb.UnlockAccount(ctx, params.Login)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return nil
+}
+
+func (u UseCase) ChangePassword(ctx context.Context, claims api_models.AuthClaims, params api_models.ChangePasswordParams) error {
+	if err := validatePassword(params.NewPassword); err != nil {
+		return err
+	}
+
+	hashPassword, err := u.db.GetPasswordHash(ctx, claims.UserId)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	if ok := encryption.CheckPasswordHash(params.OldPassword, hashPassword); ok != true {
+		return api_models.NewFieldError("old_password", "wrong password")
+	}
+
+	if err = u.setPassword(ctx, claims.UserId, params.NewPassword); err != nil {
+		return err
+	}
+
+	// текущая сессия остается, остальные отзываются
+	sessions, err := u.rdb.GetSessions(ctx, claims.UserId)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	for _, session := range sessions {
+		if session.SessionId == claims.SessionId {
+			continue
+		}
+		if err = u.rdb.RevokeSession(ctx, claims.UserId, session.SessionId); err != nil {
+			return fmt.Errorf("usecase error: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ForgotPassword отвечает одинаково и за одно и то же время для любого логина: поиск пользователя и отправка письма
+// идут в фоне. У запросов свой лимит по логину и IP, на блокировку входа они не влияют, поэтому
+// заблокированный пользователь может сбросить пароль
+func (u UseCase) ForgotPassword(ctx context.Context, params api_models.ForgotPasswordParams) error {
+	if params.Login == "" {
+		return api_models.NewFieldError("login", "must not be empty")
+	}
+
+	retryAfter, err := u.rdb.RegisterPasswordResetRequest(ctx, params.Login, params.IP)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+	if retryAfter > 0 {
+		return api_models.TooManyRequestsError{RetryAfter: retryAfter}
+	}
+
+	// письмо отправляется уже после ответа, поэтому контекст запроса не должен его отменять
+	bgCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second*common.PASSWORD_RESET_SEND_TIMEOUT)
+	u.background(func() {
+		defer cancel()
+
+		if err := u.sendPasswordReset(bgCtx, params.Login); err != nil {
+			log.FromContext(bgCtx, u.logger).ErrorContext(bgCtx, "password reset mail error", slog.String("error", err.Error()))
+		}
+	})
+
+	return nil
+}
+
+func (u UseCase) sendPasswordReset(ctx context.Context, login string) error {
+	user, err := u.db.GetUserByLogin(ctx, login)
+	if err != nil || user.Email == "" || user.Disabled {
+		return nil
+	}
+
+	token, err := u.rdb.CreatePasswordResetToken(ctx, user.UserId)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	body := fmt.Sprintf("Password reset token: %s", token)
+	if u.cfg.Mail.ResetURL != "" {
+		body = fmt.Sprintf("To reset your password follow the link: %s?token=%s", u.cfg.Mail.ResetURL, token)
+	}
+
+	if err = u.mailer.Send(user.Email, "Password reset", body); err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return nil
+}
+
+func (u UseCase) ResetPassword(ctx context.Context, params api_models.ResetPasswordParams) error {
+	if err := validatePassword(params.NewPassword); err != nil {
+		return err
+	}
+
+	userId, err := u.rdb.ConsumePasswordResetToken(ctx, params.Token)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	if err = u.setPassword(ctx, userId, params.NewPassword); err != nil {
+		return err
+	}
+
+	if err = u.rdb.RevokeRefreshTokens(ctx, userId); err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	if err = u.rdb.RevokeAllAccessTokens(ctx, userId); err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	// владение почтой подтверждено, поэтому неудачные попытки и блокировка входа снимаются
+	user, err := u.db.GetUserById(ctx, userId)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+	if err = u.rdb.UnlockAccount(ctx, user.Login); err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return nil
+}
+
+func (u UseCase) setPassword(ctx context.Context, userId, password string) error {
+	hashPassword, err := encryption.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	if err = u.db.UpdatePassword(ctx, userId, hashPassword); err != nil {
+		return fmt.Errorf("usecase error: %w", err)
+	}
+
+	return nil
+}
+
+func validatePassword(password string) error {
+	if utf8.RuneCountInString(password) < common.PASSWORD_MINSIZE {
+		return api_models.NewFieldError("password", fmt.Sprintf("must be at least %d characters", common.PASSWORD_MINSIZE))
+	}
+	if utf8.RuneCountInString(password) > common.PASSWORD_MAXSIZE {
+		return api_models.NewFieldError("password", fmt.Sprintf("must be at most %d characters", common.PASSWORD_MAXSIZE))
+	}
+
+	return nil
+}
@@ -0,0 +1,364 @@
+package api_usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+	"vk_test_task/config"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+	"vk_test_task/internal/utils/encryption"
+)
+
+func TestUseCase_UnlockUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	type mockBehaviour func(params api_models.UnlockUserParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.UnlockUserParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.UnlockUserParams{Login: "login"},
+			mockBehaviour: func(params api_models.UnlockUserParams) {
+				tokenRepo.EXPECT().UnlockAccount(gomock.Any(), params.Login).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "redis error",
+			args: api_models.UnlockUserParams{Login: "login"},
+			mockBehaviour: func(params api_models.UnlockUserParams) {
+				tokenRepo.EXPECT().UnlockAccount(gomock.Any(), params.Login).Return(fmt.Errorf("error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "no login",
+			args: api_models.UnlockUserParams{},
+			mockBehaviour: func(params api_models.UnlockUserParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			err := uc.UnlockUser(context.Background(), test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+type mailerStub struct {
+	to   string
+	body string
+	err  error
+}
+
+func (m *mailerStub) Send(to, subject, body string) error {
+	m.to = to
+	m.body = body
+	return m.err
+}
+
+func TestUseCase_ChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	claims := api_models.AuthClaims{UserId: "id", SessionId: "current"}
+	hash, _ := encryption.HashPassword("password")
+
+	type mockBehaviour func(params api_models.ChangePasswordParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.ChangePasswordParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.ChangePasswordParams{OldPassword: "password", NewPassword: "new password"},
+			mockBehaviour: func(params api_models.ChangePasswordParams) {
+				repo.EXPECT().GetPasswordHash(gomock.Any(), "id").Return(hash, nil)
+				repo.EXPECT().UpdatePassword(gomock.Any(), "id", gomock.Any()).Return(nil)
+				tokenRepo.EXPECT().GetSessions(gomock.Any(), "id").Return([]api_models.Session{
+					{SessionId: "current"},
+					{SessionId: "other"},
+				}, nil)
+				tokenRepo.EXPECT().RevokeSession(gomock.Any(), "id", "other").Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "wrong old password",
+			args: api_models.ChangePasswordParams{OldPassword: "wrong", NewPassword: "new password"},
+			mockBehaviour: func(params api_models.ChangePasswordParams) {
+				repo.EXPECT().GetPasswordHash(gomock.Any(), "id").Return(hash, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "short new password",
+			args: api_models.ChangePasswordParams{OldPassword: "password", NewPassword: "new"},
+			mockBehaviour: func(params api_models.ChangePasswordParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			err := uc.ChangePassword(context.Background(), claims, test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUseCase_ForgotPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+	mailer := &mailerStub{}
+
+	uc := New(
+		&config.Config{Mail: config.Mail{ResetURL: "https://example.com/reset"}},
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+		repo,
+		tokenRepo,
+		mailer,
+	)
+	var sent bool
+	uc.background = func(fn func()) {
+		sent = true
+		fn()
+	}
+
+	type mockBehaviour func(params api_models.ForgotPasswordParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.ForgotPasswordParams
+		mockBehaviour mockBehaviour
+		mailErr       error
+		wantMail      bool
+		wantSent      bool
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.ForgotPasswordParams{Login: "login", IP: "127.0.0.1"},
+			mockBehaviour: func(params api_models.ForgotPasswordParams) {
+				tokenRepo.EXPECT().RegisterPasswordResetRequest(gomock.Any(), params.Login, params.IP).Return(time.Duration(0), nil)
+				repo.EXPECT().GetUserByLogin(gomock.Any(), params.Login).Return(api_models.User{UserId: "id", Email: "user@example.com"}, nil)
+				tokenRepo.EXPECT().CreatePasswordResetToken(gomock.Any(), "id").Return("token", nil)
+			},
+			wantMail: true,
+			wantSent: true,
+			wantErr:  false,
+		},
+		{
+			name: "unknown login",
+			args: api_models.ForgotPasswordParams{Login: "unknown"},
+			mockBehaviour: func(params api_models.ForgotPasswordParams) {
+				tokenRepo.EXPECT().RegisterPasswordResetRequest(gomock.Any(), params.Login, "").Return(time.Duration(0), nil)
+				repo.EXPECT().GetUserByLogin(gomock.Any(), params.Login).Return(api_models.User{}, fmt.Errorf("user not found"))
+			},
+			wantSent: true,
+			wantErr:  false,
+		},
+		{
+			name: "no email",
+			args: api_models.ForgotPasswordParams{Login: "login"},
+			mockBehaviour: func(params api_models.ForgotPasswordParams) {
+				tokenRepo.EXPECT().RegisterPasswordResetRequest(gomock.Any(), params.Login, "").Return(time.Duration(0), nil)
+				repo.EXPECT().GetUserByLogin(gomock.Any(), params.Login).Return(api_models.User{UserId: "id"}, nil)
+			},
+			wantSent: true,
+			wantErr:  false,
+		},
+		{
+			name: "mail error is not returned",
+			args: api_models.ForgotPasswordParams{Login: "login"},
+			mockBehaviour: func(params api_models.ForgotPasswordParams) {
+				tokenRepo.EXPECT().RegisterPasswordResetRequest(gomock.Any(), params.Login, "").Return(time.Duration(0), nil)
+				repo.EXPECT().GetUserByLogin(gomock.Any(), params.Login).Return(api_models.User{UserId: "id", Email: "user@example.com"}, nil)
+				tokenRepo.EXPECT().CreatePasswordResetToken(gomock.Any(), "id").Return("token", nil)
+			},
+			mailErr:  fmt.Errorf("smtp error"),
+			wantMail: true,
+			wantSent: true,
+			wantErr:  false,
+		},
+		{
+			name: "throttled",
+			args: api_models.ForgotPasswordParams{Login: "login", IP: "127.0.0.1"},
+			mockBehaviour: func(params api_models.ForgotPasswordParams) {
+				tokenRepo.EXPECT().RegisterPasswordResetRequest(gomock.Any(), params.Login, params.IP).Return(time.Minute, nil)
+			},
+			wantSent: false,
+			wantErr:  true,
+		},
+		{
+			name: "no login",
+			args: api_models.ForgotPasswordParams{},
+			mockBehaviour: func(params api_models.ForgotPasswordParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			*mailer = mailerStub{err: test.mailErr}
+			sent = false
+			test.mockBehaviour(test.args)
+
+			err := uc.ForgotPassword(context.Background(), test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.wantSent, sent)
+
+			if test.wantMail {
+				assert.Equal(t, "user@example.com", mailer.to)
+				assert.Contains(t, mailer.body, "https://example.com/reset?token=token")
+			} else {
+				assert.Empty(t, mailer.to)
+			}
+		})
+	}
+}
+
+func TestUseCase_ResetPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokenRepo := mock_api.NewMockTokenRepositoryInterface(ctrl)
+
+	uc := New(
+		nil,
+		nil,
+		repo,
+		tokenRepo,
+		nil,
+	)
+
+	type mockBehaviour func(params api_models.ResetPasswordParams)
+
+	testTable := []struct {
+		name          string
+		args          api_models.ResetPasswordParams
+		mockBehaviour mockBehaviour
+		wantErr       bool
+	}{
+		{
+			name: "default",
+			args: api_models.ResetPasswordParams{Token: "token", NewPassword: "new password"},
+			mockBehaviour: func(params api_models.ResetPasswordParams) {
+				tokenRepo.EXPECT().ConsumePasswordResetToken(gomock.Any(), params.Token).Return("id", nil)
+				repo.EXPECT().UpdatePassword(gomock.Any(), "id", gomock.Any()).Return(nil)
+				tokenRepo.EXPECT().RevokeRefreshTokens(gomock.Any(), "id").Return(nil)
+				tokenRepo.EXPECT().RevokeAllAccessTokens(gomock.Any(), "id").Return(nil)
+				repo.EXPECT().GetUserById(gomock.Any(), "id").Return(api_models.User{UserId: "id", Login: "login"}, nil)
+				tokenRepo.EXPECT().UnlockAccount(gomock.Any(), "login").Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "unlock error",
+			args: api_models.ResetPasswordParams{Token: "token", NewPassword: "new password"},
+			mockBehaviour: func(params api_models.ResetPasswordParams) {
+				tokenRepo.EXPECT().ConsumePasswordResetToken(gomock.Any(), params.Token).Return("id", nil)
+				repo.EXPECT().UpdatePassword(gomock.Any(), "id", gomock.Any()).Return(nil)
+				tokenRepo.EXPECT().RevokeRefreshTokens(gomock.Any(), "id").Return(nil)
+				tokenRepo.EXPECT().RevokeAllAccessTokens(gomock.Any(), "id").Return(nil)
+				repo.EXPECT().GetUserById(gomock.Any(), "id").Return(api_models.User{UserId: "id", Login: "login"}, nil)
+				tokenRepo.EXPECT().UnlockAccount(gomock.Any(), "login").Return(fmt.Errorf("redis error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid token",
+			args: api_models.ResetPasswordParams{Token: "token", NewPassword: "new password"},
+			mockBehaviour: func(params api_models.ResetPasswordParams) {
+				tokenRepo.EXPECT().ConsumePasswordResetToken(gomock.Any(), params.Token).Return("", fmt.Errorf("invalid or expired token"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "short password",
+			args: api_models.ResetPasswordParams{Token: "token", NewPassword: "new"},
+			mockBehaviour: func(params api_models.ResetPasswordParams) {
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testTable {
+		t.Run(test.name, func(t *testing.T) {
+			test.mockBehaviour(test.args)
+
+			err := uc.ResetPassword(context.Background(), test.args)
+
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package common
+
+// GitCommit и BuildTime задаются при сборке:
+// go build -ldflags "-X vk_test_task/internal/common.GitCommit=$(git rev-parse HEAD) -X vk_test_task/internal/common.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	GitCommit string
+	BuildTime string
+)
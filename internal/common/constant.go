@@ -13,13 +13,62 @@ const (
 	LOGIN_MINSIZE    = 5
 	PASSWORD_MAXSIZE = 100
 	PASSWORD_MINSIZE = 5
+	EMAIL_MAXSIZE    = 256
 
-	SORT_FILM_BY_NAME         = 1
-	SORT_FILM_BY_RATE         = 2
-	SORT_FILM_BY_RELEASE_DATE = 3
-	SORT_FILM_ASC             = 1
-	SORT_FILM_DESC            = 2
+	SERVER_READ_TIMEOUT     = 10
+	SERVER_WRITE_TIMEOUT    = 30
+	SERVER_IDLE_TIMEOUT     = 120
+	SERVER_SHUTDOWN_TIMEOUT = 30
+	READINESS_CHECK_TIMEOUT = 2
+
+	SIGNIN_MAX_ATTEMPTS     = 5
+	SIGNIN_IP_MAX_ATTEMPTS  = 50
+	SIGNIN_ATTEMPTS_WINDOW  = 900
+	SIGNIN_LOCKOUT_DURATION = 900
+	SIGNIN_BACKOFF_BASE     = 1
+	SIGNIN_BACKOFF_MAX      = 30
+
+	KEY_ROTATION      = 86400
+	KEY_EXPIRY_MARGIN = 60
+
+	PASSWORD_RESET_LIFETIME        = 900
+	PASSWORD_RESET_SEND_TIMEOUT    = 30
+	PASSWORD_RESET_MAX_REQUESTS    = 3
+	PASSWORD_RESET_IP_MAX_REQUESTS = 20
+	PASSWORD_RESET_REQUESTS_WINDOW = 900
+
+	MFA_CHALLENGE_LIFETIME = 300
+	MFA_MAX_ATTEMPTS       = 5
+	MFA_RECOVERY_CODES     = 10
+
+	TRACING_SERVICE_NAME = "vk_test_task"
+	TRACING_SAMPLE_RATIO = 1.0
+
+	PAGE_SIZE_DEFAULT = 20
+	PAGE_SIZE_MAX     = 100
+
+	API_KEY_NAME_MAXSIZE = 128
+	API_KEY_PREFIX       = "vk_"
 
 	AccessTokenType  = "access"
 	RefreshTokenType = "refresh"
+	APIKeyTokenType  = "api_key"
+
+	ROLE_VIEWER    = "viewer"
+	ROLE_EDITOR    = "editor"
+	ROLE_MODERATOR = "moderator"
+	ROLE_ADMIN     = "admin"
+
+	PERMISSION_FILM_READ    = "film:read"
+	PERMISSION_FILM_CREATE  = "film:create"
+	PERMISSION_FILM_UPDATE  = "film:update"
+	PERMISSION_FILM_DELETE  = "film:delete"
+	PERMISSION_ACTOR_READ   = "actor:read"
+	PERMISSION_ACTOR_CREATE = "actor:create"
+	PERMISSION_ACTOR_UPDATE = "actor:update"
+	PERMISSION_ACTOR_DELETE = "actor:delete"
+	PERMISSION_ROLE_MANAGE  = "role:manage"
+	PERMISSION_USER_MANAGE  = "user:manage"
+
+	PERMISSION_API_KEY_MANAGE = "api_key:manage"
 )
@@ -0,0 +1,32 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"vk_test_task/internal/utils/recorder"
+)
+
+// Route считает запросы и их длительность. route - шаблон пути из ServeMux ("/films/{id}"),
+// а не сам путь, иначе каждый id давал бы отдельный временной ряд
+func Route(pattern string, next http.HandlerFunc) http.HandlerFunc {
+	route := pattern
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		route = path
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+
+		rec := recorder.New(w)
+		start := time.Now()
+
+		next.ServeHTTP(rec, r)
+
+		status := strconv.Itoa(rec.Status)
+		httpRequests.WithLabelValues(route, r.Method, status).Inc()
+		httpDuration.WithLabelValues(route, r.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoute(t *testing.T) {
+	testTable := []struct {
+		name       string
+		status     int
+		wantLabels map[string]string
+	}{
+		{
+			name:       "default",
+			status:     http.StatusOK,
+			wantLabels: map[string]string{"route": "/films/{id}", "method": http.MethodGet, "status": "200"},
+		},
+		{
+			name:       "not found",
+			status:     http.StatusNotFound,
+			wantLabels: map[string]string{"route": "/films/{id}", "method": http.MethodGet, "status": "404"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			requestsBefore := sample(t, "http_requests_total", testCase.wantLabels)
+			durationBefore := sample(t, "http_request_duration_seconds", testCase.wantLabels)
+			inFlightBefore := sample(t, "http_requests_in_flight", nil)
+
+			var inFlight float64
+			handler := Route("GET /films/{id}", func(w http.ResponseWriter, r *http.Request) {
+				inFlight = sample(t, "http_requests_in_flight", nil)
+				w.WriteHeader(testCase.status)
+			})
+			// в route попадает шаблон, а не сам путь с id
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/films/42", nil))
+
+			assert.Equal(t, requestsBefore+1, sample(t, "http_requests_total", testCase.wantLabels))
+			assert.Equal(t, durationBefore+1, sample(t, "http_request_duration_seconds", testCase.wantLabels))
+			assert.Equal(t, inFlightBefore+1, inFlight)
+			assert.Equal(t, inFlightBefore, sample(t, "http_requests_in_flight", nil))
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// Registry - реестр метрик сервиса, отдается в /metrics. Свой реестр вместо глобального,
+// чтобы в /metrics не попадали метрики сторонних библиотек
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route, method and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests currently being served.",
+	})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Postgres repository call latency by repository method and outcome (ok, domain error code, error).",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "outcome"})
+
+	redisCommands = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "redis_commands_total",
+		Help: "Redis commands by command name and outcome (ok, nil, error).",
+	}, []string{"command", "outcome"})
+
+	authFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "auth_failures_total",
+		Help: "Rejected requests in the auth middleware by reason.",
+	}, []string{"reason"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		httpInFlight,
+		dbQueryDuration,
+		redisCommands,
+		authFailures,
+	)
+}
+
+// Handler отдает метрики в текстовом формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RegisterDBStats экспортирует статистику пула соединений (открытые, занятые, ожидания и т.д.).
+// Повторная регистрация того же пула игнорируется
+func RegisterDBStats(db *sql.DB, name string) {
+	err := Registry.Register(collectors.NewDBStatsCollector(db, name))
+
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if err != nil && !errors.As(err, &alreadyRegistered) {
+		panic(err)
+	}
+}
+
+// Причины отказа в авторизации для auth_failures_total
+const (
+	AuthMissingToken         = "missing_token"
+	AuthInvalidToken         = "invalid_token"
+	AuthRevokedToken         = "revoked_token"
+	AuthRevocationCheckError = "revocation_check_error"
+	AuthInvalidAPIKey        = "invalid_api_key"
+	AuthAPIKeyNotAccepted    = "api_key_not_accepted"
+	AuthForbidden            = "forbidden"
+)
+
+func AuthFailure(reason string) {
+	authFailures.WithLabelValues(reason).Inc()
+}
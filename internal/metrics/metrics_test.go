@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// sample собирает Registry и возвращает значение счетчика или гейджа либо число наблюдений гистограммы
+// для ряда с точно такими метками. Метрики глобальные, поэтому тесты сравнивают значения до и после
+func sample(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := Registry.Gather()
+	assert.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if !hasLabels(metric, labels) {
+				continue
+			}
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				return metric.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				return metric.GetGauge().GetValue()
+			case dto.MetricType_HISTOGRAM:
+				return float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+func hasLabels(metric *dto.Metric, labels map[string]string) bool {
+	if len(metric.GetLabel()) != len(labels) {
+		return false
+	}
+	for _, label := range metric.GetLabel() {
+		if value, ok := labels[label.GetName()]; !ok || value != label.GetValue() {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAuthFailure(t *testing.T) {
+	labels := map[string]string{"reason": AuthRevokedToken}
+	before := sample(t, "auth_failures_total", labels)
+
+	AuthFailure(AuthRevokedToken)
+	AuthFailure(AuthRevokedToken)
+
+	assert.Equal(t, before+2, sample(t, "auth_failures_total", labels))
+}
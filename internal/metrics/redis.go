@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"net"
+)
+
+// RedisHook считает команды редиса по результату: ok, nil (ключа нет) и error
+type RedisHook struct{}
+
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil {
+			redisCommands.WithLabelValues("dial", "error").Inc()
+		}
+		return conn, err
+	}
+}
+
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		err := next(ctx, cmd)
+		observeRedisCommand(cmd)
+		return err
+	}
+}
+
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		err := next(ctx, cmds)
+		for _, cmd := range cmds {
+			observeRedisCommand(cmd)
+		}
+		return err
+	}
+}
+
+func observeRedisCommand(cmd redis.Cmder) {
+	outcome := "ok"
+	switch err := cmd.Err(); {
+	case errors.Is(err, redis.Nil):
+		outcome = "nil"
+	case err != nil:
+		outcome = "error"
+	}
+	redisCommands.WithLabelValues(cmd.Name(), outcome).Inc()
+}
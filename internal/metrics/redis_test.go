@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"testing"
+)
+
+func TestRedisHook(t *testing.T) {
+	ctx := context.Background()
+	hook := RedisHook{}
+
+	testTable := []struct {
+		name        string
+		err         error
+		wantOutcome string
+	}{
+		{
+			name:        "default",
+			wantOutcome: "ok",
+		},
+		{
+			name:        "missing key",
+			err:         redis.Nil,
+			wantOutcome: "nil",
+		},
+		{
+			name:        "error",
+			err:         errors.New("connection refused"),
+			wantOutcome: "error",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			labels := map[string]string{"command": "get", "outcome": testCase.wantOutcome}
+			before := sample(t, "redis_commands_total", labels)
+
+			process := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+				cmd.SetErr(testCase.err)
+				return testCase.err
+			})
+			err := process(ctx, redis.NewStringCmd(ctx, "get", "key"))
+
+			assert.Equal(t, testCase.err, err)
+			assert.Equal(t, before+1, sample(t, "redis_commands_total", labels))
+		})
+	}
+
+	t.Run("pipeline", func(t *testing.T) {
+		okLabels := map[string]string{"command": "set", "outcome": "ok"}
+		nilLabels := map[string]string{"command": "get", "outcome": "nil"}
+		okBefore := sample(t, "redis_commands_total", okLabels)
+		nilBefore := sample(t, "redis_commands_total", nilLabels)
+
+		process := hook.ProcessPipelineHook(func(ctx context.Context, cmds []redis.Cmder) error {
+			cmds[1].SetErr(redis.Nil)
+			return nil
+		})
+		err := process(ctx, []redis.Cmder{redis.NewStatusCmd(ctx, "set", "key", "value"), redis.NewStringCmd(ctx, "get", "key")})
+
+		assert.NoError(t, err)
+		assert.Equal(t, okBefore+1, sample(t, "redis_commands_total", okLabels))
+		assert.Equal(t, nilBefore+1, sample(t, "redis_commands_total", nilLabels))
+	})
+
+	t.Run("dial error", func(t *testing.T) {
+		labels := map[string]string{"command": "dial", "outcome": "error"}
+		before := sample(t, "redis_commands_total", labels)
+
+		dial := hook.DialHook(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		})
+		_, err := dial(ctx, "tcp", "localhost:6379")
+
+		assert.Error(t, err)
+		assert.Equal(t, before+1, sample(t, "redis_commands_total", labels))
+	})
+}
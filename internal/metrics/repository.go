@@ -0,0 +1,220 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+	"vk_test_task/internal/api"
+	api_models "vk_test_task/internal/api/models"
+)
+
+// repository оборачивает RepositoryInterface и пишет длительность каждого вызова в db_query_duration_seconds
+type repository struct {
+	next api.RepositoryInterface
+}
+
+func InstrumentRepository(next api.RepositoryInterface) api.RepositoryInterface {
+	return &repository{next: next}
+}
+
+func observeQuery(method string, start time.Time, err *error) {
+	dbQueryDuration.WithLabelValues(method, queryOutcome(*err)).Observe(time.Since(start).Seconds())
+}
+
+// queryOutcome - ok, код доменной ошибки (not_found, conflict и т.д.) или error, если упала сама база.
+// Доменные ошибки - нормальный ответ репозитория, их нельзя смешивать с отказами postgres
+func queryOutcome(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	var domainErr *api_models.Error
+	if errors.As(err, &domainErr) && domainErr.Code != api_models.CodeInternal {
+		return string(domainErr.Code)
+	}
+	return "error"
+}
+
+func (r *repository) CreateActor(ctx context.Context, params api_models.CreateActorParams) (err error) {
+	defer observeQuery("CreateActor", time.Now(), &err)
+	return r.next.CreateActor(ctx, params)
+}
+
+func (r *repository) GetActors(ctx context.Context, page api_models.Page) (result api_models.GetActorsResponse, err error) {
+	defer observeQuery("GetActors", time.Now(), &err)
+	return r.next.GetActors(ctx, page)
+}
+
+func (r *repository) GetActorDetails(ctx context.Context, actorId string) (result api_models.ActorDetails, err error) {
+	defer observeQuery("GetActorDetails", time.Now(), &err)
+	return r.next.GetActorDetails(ctx, actorId)
+}
+
+func (r *repository) UpdateActor(ctx context.Context, params api_models.UpdateActorParams) (err error) {
+	defer observeQuery("UpdateActor", time.Now(), &err)
+	return r.next.UpdateActor(ctx, params)
+}
+
+func (r *repository) GetActorVersion(ctx context.Context, actorId string) (result int, err error) {
+	defer observeQuery("GetActorVersion", time.Now(), &err)
+	return r.next.GetActorVersion(ctx, actorId)
+}
+
+func (r *repository) DeleteActor(ctx context.Context, actorId string, version int) (err error) {
+	defer observeQuery("DeleteActor", time.Now(), &err)
+	return r.next.DeleteActor(ctx, actorId, version)
+}
+
+func (r *repository) CreateAPIKey(ctx context.Context, key api_models.APIKey, keyHash string) (err error) {
+	defer observeQuery("CreateAPIKey", time.Now(), &err)
+	return r.next.CreateAPIKey(ctx, key, keyHash)
+}
+
+func (r *repository) GetAPIKeys(ctx context.Context) (result []api_models.APIKey, err error) {
+	defer observeQuery("GetAPIKeys", time.Now(), &err)
+	return r.next.GetAPIKeys(ctx)
+}
+
+func (r *repository) GetAPIKeyByHash(ctx context.Context, keyHash string) (result api_models.APIKey, err error) {
+	defer observeQuery("GetAPIKeyByHash", time.Now(), &err)
+	return r.next.GetAPIKeyByHash(ctx, keyHash)
+}
+
+func (r *repository) TouchAPIKey(ctx context.Context, id string) (err error) {
+	defer observeQuery("TouchAPIKey", time.Now(), &err)
+	return r.next.TouchAPIKey(ctx, id)
+}
+
+func (r *repository) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	defer observeQuery("RevokeAPIKey", time.Now(), &err)
+	return r.next.RevokeAPIKey(ctx, id)
+}
+
+func (r *repository) SignIn(ctx context.Context, login string) (result api_models.SignInRepositoryResponse, err error) {
+	defer observeQuery("SignIn", time.Now(), &err)
+	return r.next.SignIn(ctx, login)
+}
+
+func (r *repository) SignUp(ctx context.Context, login, hashPassword, userId, email string, roles ...string) (err error) {
+	defer observeQuery("SignUp", time.Now(), &err)
+	return r.next.SignUp(ctx, login, hashPassword, userId, email, roles...)
+}
+
+func (r *repository) CreateFilm(ctx context.Context, params api_models.CreateFilmParams) (err error) {
+	defer observeQuery("CreateFilm", time.Now(), &err)
+	return r.next.CreateFilm(ctx, params)
+}
+
+func (r *repository) GetFilms(ctx context.Context, page api_models.Page) (result api_models.GetFilmsResponse, err error) {
+	defer observeQuery("GetFilms", time.Now(), &err)
+	return r.next.GetFilms(ctx, page)
+}
+
+func (r *repository) GetFilmDetails(ctx context.Context, filmId string) (result api_models.FilmDetails, err error) {
+	defer observeQuery("GetFilmDetails", time.Now(), &err)
+	return r.next.GetFilmDetails(ctx, filmId)
+}
+
+func (r *repository) UpdateFilm(ctx context.Context, params api_models.UpdateFilmParams) (err error) {
+	defer observeQuery("UpdateFilm", time.Now(), &err)
+	return r.next.UpdateFilm(ctx, params)
+}
+
+func (r *repository) GetFilmVersion(ctx context.Context, filmId string) (result int, err error) {
+	defer observeQuery("GetFilmVersion", time.Now(), &err)
+	return r.next.GetFilmVersion(ctx, filmId)
+}
+
+func (r *repository) DeleteFilm(ctx context.Context, filmId string, version int) (err error) {
+	defer observeQuery("DeleteFilm", time.Now(), &err)
+	return r.next.DeleteFilm(ctx, filmId, version)
+}
+
+func (r *repository) SearchFilmByName(ctx context.Context, name string, page api_models.Page) (result api_models.SearchFilmResponse, err error) {
+	defer observeQuery("SearchFilmByName", time.Now(), &err)
+	return r.next.SearchFilmByName(ctx, name, page)
+}
+
+func (r *repository) SearchFilmByActorName(ctx context.Context, actorName string, page api_models.Page) (result api_models.SearchFilmResponse, err error) {
+	defer observeQuery("SearchFilmByActorName", time.Now(), &err)
+	return r.next.SearchFilmByActorName(ctx, actorName, page)
+}
+
+func (r *repository) GetMFA(ctx context.Context, userId string) (result api_models.MFA, err error) {
+	defer observeQuery("GetMFA", time.Now(), &err)
+	return r.next.GetMFA(ctx, userId)
+}
+
+func (r *repository) SaveMFASecret(ctx context.Context, userId, secret string) (err error) {
+	defer observeQuery("SaveMFASecret", time.Now(), &err)
+	return r.next.SaveMFASecret(ctx, userId, secret)
+}
+
+func (r *repository) EnableMFA(ctx context.Context, userId string, recoveryCodeHashes []string) (err error) {
+	defer observeQuery("EnableMFA", time.Now(), &err)
+	return r.next.EnableMFA(ctx, userId, recoveryCodeHashes)
+}
+
+func (r *repository) DisableMFA(ctx context.Context, userId string) (err error) {
+	defer observeQuery("DisableMFA", time.Now(), &err)
+	return r.next.DisableMFA(ctx, userId)
+}
+
+func (r *repository) UseRecoveryCode(ctx context.Context, userId, codeHash string) (result bool, err error) {
+	defer observeQuery("UseRecoveryCode", time.Now(), &err)
+	return r.next.UseRecoveryCode(ctx, userId, codeHash)
+}
+
+func (r *repository) GetUserAccess(ctx context.Context, userId string) (result api_models.UserAccess, err error) {
+	defer observeQuery("GetUserAccess", time.Now(), &err)
+	return r.next.GetUserAccess(ctx, userId)
+}
+
+func (r *repository) GetRoles(ctx context.Context) (result api_models.GetRolesResponse, err error) {
+	defer observeQuery("GetRoles", time.Now(), &err)
+	return r.next.GetRoles(ctx)
+}
+
+func (r *repository) GrantRole(ctx context.Context, userId, role string) (err error) {
+	defer observeQuery("GrantRole", time.Now(), &err)
+	return r.next.GrantRole(ctx, userId, role)
+}
+
+func (r *repository) RevokeRole(ctx context.Context, userId, role string) (err error) {
+	defer observeQuery("RevokeRole", time.Now(), &err)
+	return r.next.RevokeRole(ctx, userId, role)
+}
+
+func (r *repository) GetUserByLogin(ctx context.Context, login string) (result api_models.User, err error) {
+	defer observeQuery("GetUserByLogin", time.Now(), &err)
+	return r.next.GetUserByLogin(ctx, login)
+}
+
+func (r *repository) GetUserById(ctx context.Context, userId string) (result api_models.User, err error) {
+	defer observeQuery("GetUserById", time.Now(), &err)
+	return r.next.GetUserById(ctx, userId)
+}
+
+func (r *repository) ListUsers(ctx context.Context) (result []api_models.User, err error) {
+	defer observeQuery("ListUsers", time.Now(), &err)
+	return r.next.ListUsers(ctx)
+}
+
+func (r *repository) GetPasswordHash(ctx context.Context, userId string) (result string, err error) {
+	defer observeQuery("GetPasswordHash", time.Now(), &err)
+	return r.next.GetPasswordHash(ctx, userId)
+}
+
+func (r *repository) UpdatePassword(ctx context.Context, userId, hashPassword string) (err error) {
+	defer observeQuery("UpdatePassword", time.Now(), &err)
+	return r.next.UpdatePassword(ctx, userId, hashPassword)
+}
+
+func (r *repository) SetUserDisabled(ctx context.Context, userId string, disabled bool) (err error) {
+	defer observeQuery("SetUserDisabled", time.Now(), &err)
+	return r.next.SetUserDisabled(ctx, userId, disabled)
+}
+
+func (r *repository) Ping(ctx context.Context) (err error) {
+	defer observeQuery("Ping", time.Now(), &err)
+	return r.next.Ping(ctx)
+}
@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestInstrumentRepository(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	r := InstrumentRepository(repo)
+
+	testTable := []struct {
+		name        string
+		err         error
+		wantOutcome string
+	}{
+		{
+			name:        "default",
+			wantOutcome: "ok",
+		},
+		{
+			name:        "not found",
+			err:         api_models.NewNotFoundError("actor not found"),
+			wantOutcome: "not_found",
+		},
+		{
+			name:        "conflict",
+			err:         fmt.Errorf("repository error: %w", api_models.NewConflictError("actor was modified concurrently")),
+			wantOutcome: "conflict",
+		},
+		{
+			name:        "database error",
+			err:         fmt.Errorf("repository error: %w", sql.ErrConnDone),
+			wantOutcome: "error",
+		},
+		{
+			name:        "internal error",
+			err:         api_models.WrapError(api_models.CodeInternal, "internal error", sql.ErrConnDone),
+			wantOutcome: "error",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			labels := map[string]string{"method": "GetActorDetails", "outcome": testCase.wantOutcome}
+			before := sample(t, "db_query_duration_seconds", labels)
+
+			repo.EXPECT().GetActorDetails(gomock.Any(), "id").Return(api_models.ActorDetails{ActorId: "id"}, testCase.err)
+
+			result, err := r.GetActorDetails(context.Background(), "id")
+
+			assert.Equal(t, testCase.err, err)
+			assert.Equal(t, "id", result.ActorId)
+			assert.Equal(t, before+1, sample(t, "db_query_duration_seconds", labels))
+		})
+	}
+}
@@ -1,50 +1,191 @@
 package middleware
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"log/slog"
 	"net/http"
+	"strings"
+	"time"
+	"vk_test_task/internal/api"
 	"vk_test_task/internal/api/models"
+	"vk_test_task/internal/metrics"
+	"vk_test_task/internal/utils/recorder"
+	log "vk_test_task/pkg/logger"
 )
 
-func JWTAdminAuth(secret string, logger *slog.Logger, next http.HandlerFunc) http.HandlerFunc {
+type claimsKey struct{}
+
+func ContextWithClaims(ctx context.Context, claims api_models.AuthClaims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+func ClaimsFromContext(ctx context.Context) (api_models.AuthClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(api_models.AuthClaims)
+	return claims, ok
+}
+
+// Auth пропускает запрос, если access токен (Authorization: Bearer <jwt>) валиден, не отозван и содержит permission,
+// либо если API ключ (X-API-Key) действителен и permission входит в его scopes.
+// Пустой permission означает, что достаточно любого авторизованного пользователя, API ключи для таких запросов не принимаются
+func Auth(tokens api.TokenRepositoryInterface, uc api.UseCaseInterface, logger *slog.Logger, permission string, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		accessToken := r.Header.Get("Authorization")
 		var claims api_models.AuthClaims
+		var err error
 
-		token, err := jwt.ParseWithClaims(accessToken, &claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
+		if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+			claims, err = uc.AuthenticateAPIKey(r.Context(), apiKey)
+			if err != nil {
+				metrics.AuthFailure(metrics.AuthInvalidAPIKey)
+				writeError(w, r, logger, fmt.Sprintf("%s request unathorized", r.URL), err)
+				return
+			}
 
-		if err != nil || token.Valid != true || claims.IsAdmin != true {
-			w.WriteHeader(http.StatusUnauthorized)
-			errText := fmt.Sprintf("%s request unathorized", r.URL)
-			logger.Error(errText)
+			if permission == "" {
+				metrics.AuthFailure(metrics.AuthAPIKeyNotAccepted)
+				writeError(w, r, logger, fmt.Sprintf("%s request forbidden", r.URL),
+					api_models.NewForbiddenError("api keys are not accepted"))
+				return
+			}
+		} else {
+			accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+			claims, err = tokens.VerifyAccessToken(r.Context(), accessToken)
+			if err != nil {
+				if accessToken == "" {
+					metrics.AuthFailure(metrics.AuthMissingToken)
+				} else {
+					metrics.AuthFailure(metrics.AuthInvalidToken)
+				}
+				writeError(w, r, logger, fmt.Sprintf("%s request unathorized", r.URL),
+					api_models.WrapError(api_models.CodeUnauthorized, "invalid access token", err))
+				return
+			}
+
+			if !checkRevoked(w, r, tokens, logger, claims) {
+				return
+			}
+		}
+
+		if permission != "" && !claims.HasPermission(permission) {
+			metrics.AuthFailure(metrics.AuthForbidden)
+			writeError(w, r, logger, fmt.Sprintf("%s request forbidden", r.URL),
+				api_models.NewForbiddenError(fmt.Sprintf("%s permission required", permission)))
 			return
 		}
 
+		log.AddAttrs(r.Context(), slog.String("user_id", claims.UserId))
+
+		next.ServeHTTP(w, r.WithContext(ContextWithClaims(r.Context(), claims)))
+	}
+}
+
+func checkRevoked(w http.ResponseWriter, r *http.Request, tokens api.TokenRepositoryInterface, logger *slog.Logger, claims api_models.AuthClaims) bool {
+	revoked, err := tokens.IsAccessTokenRevoked(r.Context(), claims)
+	if err != nil {
+		metrics.AuthFailure(metrics.AuthRevocationCheckError)
+		writeError(w, r, logger, fmt.Sprintf("%s token revocation check error", r.URL), err)
+		return false
+	}
+
+	if revoked {
+		metrics.AuthFailure(metrics.AuthRevokedToken)
+		writeError(w, r, logger, fmt.Sprintf("%s request unathorized", r.URL),
+			api_models.NewUnauthorizedError("token is revoked"))
+		return false
+	}
+
+	return true
+}
+
+// writeError отвечает тем же конвертом ошибки, что и хендлеры
+func writeError(w http.ResponseWriter, r *http.Request, logger *slog.Logger, errText string, err error) {
+	log.FromContext(r.Context(), logger).ErrorContext(r.Context(), errText, slog.String("error", err.Error()))
+
+	status, response := api_models.NewErrorResponse(err, r.Header.Get("X-Request-ID"))
+	jsonResponse, _ := json.Marshal(response)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonResponse)
+}
+
+// Deprecated помечает старые пути, которые остаются алиасами новых маршрутов на один релиз
+func Deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", successor))
 		next.ServeHTTP(w, r)
 	}
 }
 
-func JWTUserAuth(secret string, logger *slog.Logger, next http.HandlerFunc) http.HandlerFunc {
+// Timeout ограничивает время обработки запроса: по истечении timeout контекст запроса отменяется
+// и вместе с ним запросы к БД и редису
+func Timeout(timeout time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestLog берет X-Request-ID клиента или присваивает новый, возвращает его в ответе и кладет в контекст логгер
+// с request_id и route. После ответа пишет одну запись о запросе со статусом и временем обработки
+func RequestLog(logger *slog.Logger, pattern string, next http.HandlerFunc) http.HandlerFunc {
+	return RequestLogLevel(logger, pattern, slog.LevelInfo, next)
+}
+
+// RequestLogLevel - RequestLog, который пишет запись об успешном запросе с уровнем level. Нужен для проб оркестратора,
+// которые дергаются каждые несколько секунд и иначе забивают лог. Ответы 5xx всегда пишутся с уровнем Error
+func RequestLogLevel(logger *slog.Logger, pattern string, level slog.Level, next http.HandlerFunc) http.HandlerFunc {
+	route := pattern
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		route = path
+	}
+
 	return func(w http.ResponseWriter, r *http.Request) {
-		accessToken := r.Header.Get("Authorization")
+		requestId := r.Header.Get("X-Request-ID")
+		if !validRequestId(requestId) {
+			requestId = uuid.NewString()
+			// хендлеры и writeError читают id из заголовка запроса
+			r.Header.Set("X-Request-ID", requestId)
+		}
+		w.Header().Set("X-Request-ID", requestId)
 
-		var claims api_models.AuthClaims
+		ctx := log.WithContext(r.Context(), logger.With(
+			slog.String("request_id", requestId),
+			slog.String("method", r.Method),
+			slog.String("route", route),
+		))
 
-		token, err := jwt.ParseWithClaims(accessToken, &claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
+		rec := recorder.New(w)
+		start := time.Now()
 
-		if err != nil || token.Valid != true {
-			w.WriteHeader(http.StatusUnauthorized)
-			errText := fmt.Sprintf("%s request unathorized", r.URL)
-			logger.Error(errText)
-			return
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		recordLevel := level
+		if rec.Status >= http.StatusInternalServerError {
+			recordLevel = slog.LevelError
 		}
+		log.FromContext(ctx, logger).LogAttrs(ctx, recordLevel, "request completed",
+			slog.Int("status", rec.Status),
+			slog.Duration("latency", time.Since(start)),
+		)
+	}
+}
 
-		next.ServeHTTP(w, r)
+// validRequestId - id клиента принимается, только если он короткий и без спецсимволов, иначе он попал бы в логи как есть
+func validRequestId(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
 	}
+	return true
 }
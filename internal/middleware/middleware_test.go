@@ -0,0 +1,508 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-redis/redismock/v9"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/lmittmann/tint"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"vk_test_task/config"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+	redis_repo "vk_test_task/internal/api/repository/redis"
+	api_usecase "vk_test_task/internal/api/usecase"
+	"vk_test_task/internal/utils/encryption"
+)
+
+// revocationTokens проверяет отзыв настоящим репозиторием редиса поверх мока,
+// чтобы тесты проходили весь путь от заголовка до ключей отзыва
+type revocationTokens struct {
+	*mock_api.MockTokenRepositoryInterface
+	revocation redis_repo.Repository
+}
+
+func (t revocationTokens) IsAccessTokenRevoked(ctx context.Context, claims api_models.AuthClaims) (bool, error) {
+	return t.revocation.IsAccessTokenRevoked(ctx, claims)
+}
+
+func TestAuth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client, mock := redismock.NewClientMock()
+	defer client.Close()
+
+	tokens := revocationTokens{
+		MockTokenRepositoryInterface: mock_api.NewMockTokenRepositoryInterface(ctrl),
+		revocation:                   redis_repo.Repository{DB: client, Cfg: &config.Config{}},
+	}
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+
+	now := time.Now().UnixMilli()
+	claims := api_models.AuthClaims{
+		UserId:           "userId",
+		SessionId:        "sessionId",
+		Permissions:      []string{"film:read"},
+		RegisteredClaims: jwt.RegisteredClaims{ID: "jti", IssuedAt: jwt.NewNumericDate(time.UnixMilli(now))},
+	}
+
+	type mockBehaviour func()
+
+	testTable := []struct {
+		name          string
+		token         string
+		permission    string
+		mockBehaviour mockBehaviour
+		wantStatus    int
+		wantCode      api_models.ErrorCode
+	}{
+		{
+			name:       "default",
+			token:      "token",
+			permission: "film:read",
+			mockBehaviour: func() {
+				tokens.EXPECT().VerifyAccessToken(gomock.Any(), "token").Return(claims, nil)
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(0)
+				mock.ExpectGet("access-revoked-before-userId").RedisNil()
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "any authorized user",
+			token:      "token",
+			permission: "",
+			mockBehaviour: func() {
+				tokens.EXPECT().VerifyAccessToken(gomock.Any(), "token").Return(claims, nil)
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(0)
+				mock.ExpectGet("access-revoked-before-userId").RedisNil()
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing token",
+			token:      "",
+			permission: "film:read",
+			mockBehaviour: func() {
+				tokens.EXPECT().VerifyAccessToken(gomock.Any(), "").Return(api_models.AuthClaims{},
+					api_models.NewUnauthorizedError("invalid token"))
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   api_models.CodeUnauthorized,
+		},
+		{
+			name:       "invalid token",
+			token:      "invalid",
+			permission: "film:read",
+			mockBehaviour: func() {
+				tokens.EXPECT().VerifyAccessToken(gomock.Any(), "invalid").Return(api_models.AuthClaims{},
+					fmt.Errorf("token signature is invalid"))
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   api_models.CodeUnauthorized,
+		},
+		{
+			name:       "missing permission",
+			token:      "token",
+			permission: "film:write",
+			mockBehaviour: func() {
+				tokens.EXPECT().VerifyAccessToken(gomock.Any(), "token").Return(claims, nil)
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(0)
+				mock.ExpectGet("access-revoked-before-userId").RedisNil()
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   api_models.CodeForbidden,
+		},
+		{
+			name:       "revoked jti",
+			token:      "token",
+			permission: "film:read",
+			mockBehaviour: func() {
+				tokens.EXPECT().VerifyAccessToken(gomock.Any(), "token").Return(claims, nil)
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(1)
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   api_models.CodeUnauthorized,
+		},
+		{
+			name:       "issued before logout all",
+			token:      "token",
+			permission: "film:read",
+			mockBehaviour: func() {
+				tokens.EXPECT().VerifyAccessToken(gomock.Any(), "token").Return(claims, nil)
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(0)
+				mock.ExpectGet("access-revoked-before-userId").SetVal(fmt.Sprint(now + 1))
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   api_models.CodeUnauthorized,
+		},
+		{
+			name:       "issued after logout all",
+			token:      "token",
+			permission: "film:read",
+			mockBehaviour: func() {
+				tokens.EXPECT().VerifyAccessToken(gomock.Any(), "token").Return(claims, nil)
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(0)
+				mock.ExpectGet("access-revoked-before-userId").SetVal(fmt.Sprint(now))
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "revoked session",
+			token:      "token",
+			permission: "film:read",
+			mockBehaviour: func() {
+				tokens.EXPECT().VerifyAccessToken(gomock.Any(), "token").Return(claims, nil)
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetVal(1)
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   api_models.CodeUnauthorized,
+		},
+		{
+			name:       "revocation check error",
+			token:      "token",
+			permission: "film:read",
+			mockBehaviour: func() {
+				tokens.EXPECT().VerifyAccessToken(gomock.Any(), "token").Return(claims, nil)
+				mock.ExpectExists("access-denylist-jti", "access-revoked-session-sessionId").SetErr(fmt.Errorf("connection refused"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   api_models.CodeInternal,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			var gotClaims api_models.AuthClaims
+			handler := Auth(tokens, uc, l, testCase.permission, func(w http.ResponseWriter, r *http.Request) {
+				gotClaims, _ = ClaimsFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/film", nil)
+			if testCase.token != "" {
+				req.Header.Set("Authorization", "Bearer "+testCase.token)
+			}
+			res := httptest.NewRecorder()
+
+			handler.ServeHTTP(res, req)
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, testCase.wantStatus, res.Code)
+			if testCase.wantStatus == http.StatusOK {
+				assert.Equal(t, claims.UserId, gotClaims.UserId)
+				return
+			}
+
+			var response api_models.ErrorResponse
+			assert.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+			assert.Equal(t, testCase.wantCode, response.Code)
+		})
+	}
+}
+
+func TestAuth_APIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := mock_api.NewMockRepositoryInterface(ctrl)
+	tokens := mock_api.NewMockTokenRepositoryInterface(ctrl)
+	// права ключа пересекаются с правами владельца в usecase, поэтому он настоящий
+	uc := api_usecase.New(&config.Config{}, nil, repo, tokens, nil)
+	l := slog.New(tint.NewHandler(os.Stderr, &tint.Options{}))
+
+	key := "vk_key"
+	keyHash := encryption.HashToken(key)
+	revokedAt := time.Now().Add(-time.Hour)
+
+	apiKey := api_models.APIKey{Id: "keyId", UserId: "userId", Scopes: []string{"film:read", "film:create"}}
+	owner := api_models.UserAccess{UserId: "userId", Permissions: []string{"film:read", "film:create"}}
+
+	type mockBehaviour func()
+
+	testTable := []struct {
+		name          string
+		key           string
+		permission    string
+		mockBehaviour mockBehaviour
+		wantStatus    int
+		wantCode      api_models.ErrorCode
+	}{
+		{
+			name:       "valid key",
+			key:        key,
+			permission: "film:create",
+			mockBehaviour: func() {
+				repo.EXPECT().GetAPIKeyByHash(gomock.Any(), keyHash).Return(apiKey, nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "userId").Return(owner, nil)
+				repo.EXPECT().TouchAPIKey(gomock.Any(), "keyId").Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "scope not granted to key",
+			key:        key,
+			permission: "film:delete",
+			mockBehaviour: func() {
+				repo.EXPECT().GetAPIKeyByHash(gomock.Any(), keyHash).Return(apiKey, nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "userId").Return(owner, nil)
+				repo.EXPECT().TouchAPIKey(gomock.Any(), "keyId").Return(nil)
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   api_models.CodeForbidden,
+		},
+		{
+			name:       "owner lost permission",
+			key:        key,
+			permission: "film:create",
+			mockBehaviour: func() {
+				repo.EXPECT().GetAPIKeyByHash(gomock.Any(), keyHash).Return(apiKey, nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "userId").
+					Return(api_models.UserAccess{UserId: "userId", Permissions: []string{"film:read"}}, nil)
+				repo.EXPECT().TouchAPIKey(gomock.Any(), "keyId").Return(nil)
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   api_models.CodeForbidden,
+		},
+		{
+			name:       "unknown key",
+			key:        "vk_unknown",
+			permission: "film:read",
+			mockBehaviour: func() {
+				repo.EXPECT().GetAPIKeyByHash(gomock.Any(), encryption.HashToken("vk_unknown")).
+					Return(api_models.APIKey{}, api_models.NewNotFoundError("api key not found"))
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   api_models.CodeUnauthorized,
+		},
+		{
+			name:       "key without prefix",
+			key:        "key",
+			permission: "film:read",
+			mockBehaviour: func() {
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   api_models.CodeUnauthorized,
+		},
+		{
+			name:       "revoked key",
+			key:        key,
+			permission: "film:read",
+			mockBehaviour: func() {
+				revoked := apiKey
+				revoked.RevokedAt = &revokedAt
+				repo.EXPECT().GetAPIKeyByHash(gomock.Any(), keyHash).Return(revoked, nil)
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   api_models.CodeUnauthorized,
+		},
+		{
+			name:       "route without permission",
+			key:        key,
+			permission: "",
+			mockBehaviour: func() {
+				repo.EXPECT().GetAPIKeyByHash(gomock.Any(), keyHash).Return(apiKey, nil)
+				repo.EXPECT().GetUserAccess(gomock.Any(), "userId").Return(owner, nil)
+				repo.EXPECT().TouchAPIKey(gomock.Any(), "keyId").Return(nil)
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   api_models.CodeForbidden,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			testCase.mockBehaviour()
+
+			var gotClaims api_models.AuthClaims
+			handler := Auth(tokens, uc, l, testCase.permission, func(w http.ResponseWriter, r *http.Request) {
+				gotClaims, _ = ClaimsFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/film", nil)
+			req.Header.Set("X-API-Key", testCase.key)
+			res := httptest.NewRecorder()
+
+			handler.ServeHTTP(res, req)
+
+			assert.Equal(t, testCase.wantStatus, res.Code)
+			if testCase.wantStatus == http.StatusOK {
+				assert.Equal(t, "userId", gotClaims.UserId)
+				assert.Equal(t, []string{"film:read", "film:create"}, gotClaims.Permissions)
+				return
+			}
+
+			var response api_models.ErrorResponse
+			assert.NoError(t, json.NewDecoder(res.Body).Decode(&response))
+			assert.Equal(t, testCase.wantCode, response.Code)
+		})
+	}
+}
+
+func TestRequestLogLevel(t *testing.T) {
+	testTable := []struct {
+		name      string
+		level     slog.Level
+		status    int
+		wantLevel string
+	}{
+		{
+			name:      "request log",
+			level:     slog.LevelInfo,
+			status:    http.StatusOK,
+			wantLevel: "INFO",
+		},
+		{
+			name:      "probe",
+			level:     slog.LevelDebug,
+			status:    http.StatusOK,
+			wantLevel: "DEBUG",
+		},
+		{
+			name:      "failed probe",
+			level:     slog.LevelDebug,
+			status:    http.StatusServiceUnavailable,
+			wantLevel: "ERROR",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			handler := RequestLogLevel(l, "GET /readyz", testCase.level, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(testCase.status)
+			})
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			var record map[string]any
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, testCase.wantLevel, record["level"])
+			assert.Equal(t, "/readyz", record["route"])
+			assert.Equal(t, float64(testCase.status), record["status"])
+		})
+	}
+}
+
+func TestValidRequestId(t *testing.T) {
+	testTable := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{
+			name: "uuid",
+			id:   "3f2b6c1e-8d4a-4f7e-9b0c-1a2b3c4d5e6f",
+			want: true,
+		},
+		{
+			name: "allowed symbols",
+			id:   "trace_01.retry-2",
+			want: true,
+		},
+		{
+			name: "max length",
+			id:   strings.Repeat("a", 128),
+			want: true,
+		},
+		{
+			name: "empty",
+			id:   "",
+			want: false,
+		},
+		{
+			name: "too long",
+			id:   strings.Repeat("a", 129),
+			want: false,
+		},
+		{
+			name: "newline",
+			id:   "id\nlevel=ERROR msg=forged",
+			want: false,
+		},
+		{
+			name: "spaces and quotes",
+			id:   `id" admin="true`,
+			want: false,
+		},
+		{
+			name: "non ascii",
+			id:   "идентификатор",
+			want: false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, validRequestId(testCase.id))
+		})
+	}
+}
+
+func TestRequestLog_RequestId(t *testing.T) {
+	testTable := []struct {
+		name      string
+		requestId string
+		wantSame  bool
+	}{
+		{
+			name:      "client id",
+			requestId: "client-id",
+			wantSame:  true,
+		},
+		{
+			name:      "invalid client id is replaced",
+			requestId: "bad id",
+			wantSame:  false,
+		},
+		{
+			name:     "missing id is generated",
+			wantSame: false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			var handlerId string
+			handler := RequestLog(l, "GET /films", func(w http.ResponseWriter, r *http.Request) {
+				handlerId = r.Header.Get("X-Request-ID")
+			})
+			req := httptest.NewRequest(http.MethodGet, "/films", nil)
+			if testCase.requestId != "" {
+				req.Header.Set("X-Request-ID", testCase.requestId)
+			}
+			res := httptest.NewRecorder()
+			handler.ServeHTTP(res, req)
+
+			responseId := res.Header().Get("X-Request-ID")
+			assert.True(t, validRequestId(responseId))
+			assert.Equal(t, responseId, handlerId)
+			assert.Equal(t, testCase.wantSame, responseId == testCase.requestId)
+
+			var record map[string]any
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, responseId, record["request_id"])
+		})
+	}
+}
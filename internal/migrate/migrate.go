@@ -0,0 +1,302 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"io/fs"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// lockId - ключ pg_advisory_lock: миграции, запущенные одновременно несколькими экземплярами, выполняются по очереди
+const lockId = 7245061830
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	logger     *slog.Logger
+	migrations []Migration
+}
+
+// New читает миграции из fsys. У каждой версии должны быть и up, и down файл
+func New(db *sqlx.DB, fsys fs.FS, logger *slog.Logger) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate error: %s", err.Error())
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		if version <= 0 {
+			return nil, fmt.Errorf("migrate error: %s: version must be positive", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migrate error: %s", err.Error())
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migrate error: version %d has two names: %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migrate error: version %d must have both up and down files", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return &Migrator{db: db, logger: logger, migrations: migrations}, nil
+}
+
+// Up применяет все непримененные миграции
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.run(ctx, func(applied map[int64]time.Time) int64 {
+		// база могла быть обновлена более новым бинарником, его миграции Up не откатывает
+		latest := currentVersion(applied)
+		if len(m.migrations) > 0 && m.migrations[len(m.migrations)-1].Version > latest {
+			latest = m.migrations[len(m.migrations)-1].Version
+		}
+		return latest
+	})
+}
+
+// Down откатывает последнюю примененную миграцию
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.run(ctx, func(applied map[int64]time.Time) int64 {
+		current := currentVersion(applied)
+
+		var previous int64
+		for version := range applied {
+			if version < current && version > previous {
+				previous = version
+			}
+		}
+		return previous
+	})
+}
+
+// To применяет или откатывает миграции так, чтобы последней примененной стала version. 0 откатывает все
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("migrate error: unknown version %d", version)
+	}
+
+	return m.run(ctx, func(map[int64]time.Time) int64 {
+		return version
+	})
+}
+
+// Status - все известные миграции с отметкой, применены ли они
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.createTable(ctx, m.db); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if appliedAt, ok := applied[migration.Version]; ok {
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// run держит advisory lock на отдельном соединении (блокировка живет в сессии), под ним перечитывает
+// примененные версии и доводит схему до версии, которую вернул target
+func (m *Migrator) run(ctx context.Context, target func(applied map[int64]time.Time) int64) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate error: %s", err.Error())
+	}
+	defer conn.Close()
+
+	if _, err = conn.ExecContext(ctx, "select pg_advisory_lock($1)", lockId); err != nil {
+		return fmt.Errorf("migrate error: lock: %s", err.Error())
+	}
+	defer func() {
+		// контекст может быть уже отменен, а снять блокировку нужно в любом случае
+		if _, err := conn.ExecContext(context.Background(), "select pg_advisory_unlock($1)", lockId); err != nil {
+			m.logger.Error(fmt.Sprintf("migrate unlock error: %s", err.Error()))
+		}
+	}()
+
+	if err = m.createTable(ctx, conn); err != nil {
+		return err
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	version := target(applied)
+
+	// откатить версию, примененную более новым бинарником, нечем
+	for appliedVersion := range applied {
+		if appliedVersion > version && m.find(appliedVersion) == nil {
+			return fmt.Errorf("migrate error: version %d is applied but has no migration files", appliedVersion)
+		}
+	}
+
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok || migration.Version > version {
+			continue
+		}
+		if err = m.apply(ctx, conn, migration, true); err != nil {
+			return err
+		}
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+			continue
+		}
+		if err = m.apply(ctx, conn, migration, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// apply выполняет миграцию и запись о ней в одной транзакции, поэтому упавшая миграция не оставляет схему наполовину измененной
+func (m *Migrator) apply(ctx context.Context, conn *sqlx.Conn, migration Migration, up bool) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migrate error: %s", err.Error())
+	}
+	defer tx.Rollback()
+
+	direction, script := "up", migration.Up
+	if !up {
+		direction, script = "down", migration.Down
+	}
+
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migrate error: %d_%s %s: %s", migration.Version, migration.Name, direction, err.Error())
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx, "insert into schema_migrations (version, name) values ($1, $2)", migration.Version, migration.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, "delete from schema_migrations where version = $1", migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate error: %s", err.Error())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("migrate error: %s", err.Error())
+	}
+
+	m.logger.Info(fmt.Sprintf("migration %d_%s %s applied", migration.Version, migration.Name, direction))
+	return nil
+}
+
+func (m *Migrator) createTable(ctx context.Context, db sqlx.ExecerContext) error {
+	_, err := db.ExecContext(ctx, `create table if not exists schema_migrations
+(
+    version    bigint       not null
+        primary key,
+    name       varchar(256) not null,
+    applied_at timestamp    not null default now()
+)`)
+	if err != nil {
+		return fmt.Errorf("migrate error: %s", err.Error())
+	}
+	return nil
+}
+
+func (m *Migrator) applied(ctx context.Context, db sqlx.QueryerContext) (map[int64]time.Time, error) {
+	rows, err := db.QueryContext(ctx, "select version, applied_at from schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("migrate error: %s", err.Error())
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var appliedAt time.Time
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("migrate error: %s", err.Error())
+		}
+		applied[version] = appliedAt
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("migrate error: %s", err.Error())
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) find(version int64) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+func currentVersion(applied map[int64]time.Time) int64 {
+	var current int64
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	return current
+}
@@ -0,0 +1,200 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var testFS = fstest.MapFS{
+	"0001_init.up.sql":     {Data: []byte("create table a (id int)")},
+	"0001_init.down.sql":   {Data: []byte("drop table a")},
+	"0002_second.up.sql":   {Data: []byte("create table b (id int)")},
+	"0002_second.down.sql": {Data: []byte("drop table b")},
+	"migrations.go":        {Data: []byte("package sql_migrations")},
+}
+
+func newTestMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("An error occurred while creating mock: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := New(sqlx.NewDb(db, "pgx"), testFS, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m, mock
+}
+
+func expectLock(mock sqlmock.Sqlmock, applied ...int64) {
+	mock.ExpectExec("select pg_advisory_lock").WithArgs(lockId).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("create table if not exists schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"version", "applied_at"})
+	for _, version := range applied {
+		rows.AddRow(version, time.Now())
+	}
+	mock.ExpectQuery("select version, applied_at from schema_migrations").WillReturnRows(rows)
+}
+
+func expectUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec("select pg_advisory_unlock").WithArgs(lockId).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestNew(t *testing.T) {
+	t.Run("sorted", func(t *testing.T) {
+		m, err := New(nil, testFS, nil)
+
+		assert.NoError(t, err)
+		assert.Len(t, m.migrations, 2)
+		assert.Equal(t, int64(1), m.migrations[0].Version)
+		assert.Equal(t, "second", m.migrations[1].Name)
+		assert.Equal(t, "drop table b", m.migrations[1].Down)
+	})
+
+	t.Run("missing down", func(t *testing.T) {
+		_, err := New(nil, fstest.MapFS{"0001_init.up.sql": {Data: []byte("select 1")}}, nil)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("two names", func(t *testing.T) {
+		_, err := New(nil, fstest.MapFS{
+			"0001_init.up.sql":    {Data: []byte("select 1")},
+			"0001_other.down.sql": {Data: []byte("select 1")},
+		}, nil)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestMigrator_Up(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	t.Run("pending", func(t *testing.T) {
+		expectLock(mock, 1)
+		mock.ExpectBegin()
+		mock.ExpectExec("create table b").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("insert into schema_migrations").WithArgs(int64(2), "second").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+		expectUnlock(mock)
+
+		assert.NoError(t, m.Up(context.Background()))
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("up to date", func(t *testing.T) {
+		expectLock(mock, 1, 2)
+		expectUnlock(mock)
+
+		assert.NoError(t, m.Up(context.Background()))
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("newer database", func(t *testing.T) {
+		expectLock(mock, 1, 2, 3)
+		expectUnlock(mock)
+
+		assert.NoError(t, m.Up(context.Background()))
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("failed migration", func(t *testing.T) {
+		expectLock(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec("create table a").WillReturnError(errors.New("syntax error"))
+		mock.ExpectRollback()
+		expectUnlock(mock)
+
+		assert.Error(t, m.Up(context.Background()))
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestMigrator_Down(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	t.Run("default", func(t *testing.T) {
+		expectLock(mock, 1, 2)
+		mock.ExpectBegin()
+		mock.ExpectExec("drop table b").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("delete from schema_migrations").WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectUnlock(mock)
+
+		assert.NoError(t, m.Down(context.Background()))
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("unknown applied version", func(t *testing.T) {
+		expectLock(mock, 1, 2, 3)
+		expectUnlock(mock)
+
+		assert.Error(t, m.Down(context.Background()))
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestMigrator_To(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	t.Run("all down", func(t *testing.T) {
+		expectLock(mock, 1, 2)
+		mock.ExpectBegin()
+		mock.ExpectExec("drop table b").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("delete from schema_migrations").WithArgs(int64(2)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec("drop table a").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("delete from schema_migrations").WithArgs(int64(1)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectUnlock(mock)
+
+		assert.NoError(t, m.To(context.Background(), 0))
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		assert.Error(t, m.To(context.Background(), 5))
+	})
+}
+
+func TestMigrator_Status(t *testing.T) {
+	m, mock := newTestMigrator(t)
+
+	mock.ExpectExec("create table if not exists schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("select version, applied_at from schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"version", "applied_at"}).AddRow(int64(1), time.Now()))
+
+	statuses, err := m.Status(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+	assert.Nil(t, statuses[1].AppliedAt)
+}
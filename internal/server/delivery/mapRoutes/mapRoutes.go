@@ -8,26 +8,84 @@ import (
 	"vk_test_task/config"
 	_ "vk_test_task/docs"
 	"vk_test_task/internal/api"
+	"vk_test_task/internal/common"
+	"vk_test_task/internal/metrics"
 	"vk_test_task/internal/middleware"
+	"vk_test_task/internal/tracing"
 )
 
-func MapApiRoutes(cfg *config.Config, logger *slog.Logger, h api.HandlerInterface) {
-	secret := cfg.Server.AccessSecret
+func MapApiRoutes(mux *http.ServeMux, cfg *config.Config, logger *slog.Logger, h api.HandlerInterface, uc api.UseCaseInterface, tokens api.TokenRepositoryInterface) {
+	auth := func(permission string, next http.HandlerFunc) http.HandlerFunc {
+		return middleware.Auth(tokens, uc, logger, permission, next)
+	}
+	// handle регистрирует маршрут, открывает на запрос спан, считает по нему метрики с шаблоном пути в метке route
+	// и пишет запись о запросе с X-Request-ID
+	handle := func(pattern string, handler http.HandlerFunc) {
+		mux.HandleFunc(pattern, tracing.Route(pattern, metrics.Route(pattern, middleware.RequestLog(logger, pattern, handler))))
+	}
+	// probe - то же для проб оркестратора, но запись об успешном запросе пишется с уровнем Debug
+	probe := func(pattern string, handler http.HandlerFunc) {
+		mux.HandleFunc(pattern, tracing.Route(pattern, metrics.Route(pattern, middleware.RequestLogLevel(logger, pattern, slog.LevelDebug, handler))))
+	}
 
-	http.HandleFunc("/actor/create", middleware.JWTAdminAuth(secret, logger, h.CreateActor()))
-	http.HandleFunc("/actor/get", middleware.JWTUserAuth(secret, logger, h.GetActors()))
-	http.HandleFunc("/actor/update", middleware.JWTAdminAuth(secret, logger, h.UpdateActor()))
-	http.HandleFunc("/actor/delete", middleware.JWTAdminAuth(secret, logger, h.DeleteActor()))
+	handle("GET /actors", auth(common.PERMISSION_ACTOR_READ, h.GetActors()))
+	handle("POST /actors", auth(common.PERMISSION_ACTOR_CREATE, h.CreateActor()))
+	handle("GET /actors/{id}", auth(common.PERMISSION_ACTOR_READ, h.GetActor()))
+	handle("PATCH /actors/{id}", auth(common.PERMISSION_ACTOR_UPDATE, h.UpdateActor()))
+	handle("DELETE /actors/{id}", auth(common.PERMISSION_ACTOR_DELETE, h.DeleteActor()))
 
-	http.HandleFunc("/film/create", middleware.JWTAdminAuth(secret, logger, h.CreateFilm()))
-	http.HandleFunc("/film/get", middleware.JWTUserAuth(secret, logger, h.GetFilms()))
-	http.HandleFunc("/film/update", middleware.JWTAdminAuth(secret, logger, h.UpdateFilm()))
-	http.HandleFunc("/film/delete", middleware.JWTAdminAuth(secret, logger, h.DeleteFilm()))
-	http.HandleFunc("/film/search", middleware.JWTUserAuth(secret, logger, h.SearchFilm()))
+	handle("GET /films", auth(common.PERMISSION_FILM_READ, h.GetFilms()))
+	handle("POST /films", auth(common.PERMISSION_FILM_CREATE, h.CreateFilm()))
+	handle("GET /films/search", auth(common.PERMISSION_FILM_READ, h.SearchFilm()))
+	handle("GET /films/{id}", auth(common.PERMISSION_FILM_READ, h.GetFilm()))
+	handle("PATCH /films/{id}", auth(common.PERMISSION_FILM_UPDATE, h.UpdateFilm()))
+	handle("DELETE /films/{id}", auth(common.PERMISSION_FILM_DELETE, h.DeleteFilm()))
 
-	http.HandleFunc("/sign_in", h.SignIn())
-	http.HandleFunc("/sign_up", h.SignUp())
+	// TODO: удалить в следующем релизе
+	handle("POST /actor/create", middleware.Deprecated("/actors", auth(common.PERMISSION_ACTOR_CREATE, h.CreateActor())))
+	handle("GET /actor/get", middleware.Deprecated("/actors", auth(common.PERMISSION_ACTOR_READ, h.GetActors())))
+	handle("POST /actor/update", middleware.Deprecated("/actors/{id}", auth(common.PERMISSION_ACTOR_UPDATE, h.UpdateActor())))
+	handle("POST /actor/delete", middleware.Deprecated("/actors/{id}", auth(common.PERMISSION_ACTOR_DELETE, h.DeleteActor())))
+
+	handle("POST /film/create", middleware.Deprecated("/films", auth(common.PERMISSION_FILM_CREATE, h.CreateFilm())))
+	handle("GET /film/get", middleware.Deprecated("/films", auth(common.PERMISSION_FILM_READ, h.GetFilmsLegacy())))
+	handle("POST /film/update", middleware.Deprecated("/films/{id}", auth(common.PERMISSION_FILM_UPDATE, h.UpdateFilm())))
+	handle("POST /film/delete", middleware.Deprecated("/films/{id}", auth(common.PERMISSION_FILM_DELETE, h.DeleteFilm())))
+	handle("GET /film/search", middleware.Deprecated("/films/search", auth(common.PERMISSION_FILM_READ, h.SearchFilm())))
+
+	handle("POST /sign_in", h.SignIn())
+	handle("POST /sign_in/mfa", h.SignInMFA())
+	handle("POST /sign_up", h.SignUp())
+	handle("POST /refresh", h.Refresh())
+	handle("POST /logout", auth("", h.Logout()))
+	handle("POST /logout_all", auth("", h.LogoutAll()))
+	handle("GET /sessions", auth("", h.GetSessions()))
+	handle("POST /sessions/revoke", auth("", h.RevokeSession()))
+	handle("GET /.well-known/jwks.json", h.JWKS())
+
+	handle("POST /mfa/enroll", auth("", h.EnrollMFA()))
+	handle("POST /mfa/confirm", auth("", h.ConfirmMFA()))
+	handle("POST /mfa/disable", auth("", h.DisableMFA()))
+
+	handle("GET /role/get", auth(common.PERMISSION_ROLE_MANAGE, h.GetRoles()))
+	handle("POST /role/grant", auth(common.PERMISSION_ROLE_MANAGE, h.GrantRole()))
+	handle("POST /role/revoke", auth(common.PERMISSION_ROLE_MANAGE, h.RevokeRole()))
+
+	handle("POST /api_key/create", auth(common.PERMISSION_API_KEY_MANAGE, h.CreateAPIKey()))
+	handle("GET /api_key/get", auth(common.PERMISSION_API_KEY_MANAGE, h.GetAPIKeys()))
+	handle("POST /api_key/revoke", auth(common.PERMISSION_API_KEY_MANAGE, h.RevokeAPIKey()))
+
+	handle("POST /user/unlock", auth(common.PERMISSION_USER_MANAGE, h.UnlockUser()))
+	handle("POST /password/change", auth("", h.ChangePassword()))
+	handle("POST /password/forgot", h.ForgotPassword())
+	handle("POST /password/reset", h.ResetPassword())
+
+	// пробы оркестратора и информация о сборке доступны без авторизации
+	probe("GET /healthz", h.Healthz())
+	probe("GET /readyz", h.Readyz())
+	handle("GET /version", h.Version())
+	mux.Handle("GET /metrics", metrics.Handler())
 
 	swagUrl := fmt.Sprintf("http://localhost:%s/swagger/doc.json", cfg.Server.Port)
-	http.Handle("/swagger/*", httpSwagger.Handler(httpSwagger.URL(swagUrl)))
+	mux.Handle("GET /swagger/", httpSwagger.Handler(httpSwagger.URL(swagUrl)))
 }
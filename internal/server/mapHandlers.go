@@ -2,22 +2,30 @@ package server
 
 import (
 	"log/slog"
+	"net/http"
 	"vk_test_task/config"
 	api_delivery "vk_test_task/internal/api/delivery"
 	api_repository "vk_test_task/internal/api/repository/postgres"
 	"vk_test_task/internal/api/repository/redis"
 	api_usecase "vk_test_task/internal/api/usecase"
+	"vk_test_task/internal/metrics"
 	"vk_test_task/internal/server/delivery/mapRoutes"
+	"vk_test_task/internal/tracing"
+	"vk_test_task/internal/utils/mailer"
 )
 
-func MapHandlers(cfg *config.Config, logger *slog.Logger) {
+func MapHandlers(cfg *config.Config, logger *slog.Logger) http.Handler {
 	apiRepo := api_repository.NewRepository(cfg, logger)
 
 	redisRepo := redis.New(cfg, logger)
 
-	apiUc := api_usecase.New(cfg, logger, apiRepo, redisRepo)
+	apiUc := tracing.InstrumentUseCase(api_usecase.New(cfg, logger, metrics.InstrumentRepository(apiRepo), redisRepo, mailer.New(cfg, logger)))
 
 	apiHandler := api_delivery.New(cfg, logger, apiUc)
 
-	mapRoutes.MapApiRoutes(cfg, logger, apiHandler)
+	mux := http.NewServeMux()
+
+	mapRoutes.MapApiRoutes(mux, cfg, logger, apiHandler, apiUc, redisRepo)
+
+	return mux
 }
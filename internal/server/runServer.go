@@ -1,19 +1,82 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	"vk_test_task/config"
+	"vk_test_task/internal/common"
+	"vk_test_task/internal/middleware"
+	"vk_test_task/internal/tracing"
 	logger2 "vk_test_task/pkg/logger"
 )
 
 func Run(cfg *config.Config, logger *slog.Logger) {
-	MapHandlers(cfg, logger)
+	shutdownTracing, err := tracing.Init(cfg)
+	if err != nil {
+		logger2.Fatalf(logger, "tracing init error: %s", err.Error())
+	}
+
+	writeTimeout := seconds(cfg.Server.WriteTimeout, common.SERVER_WRITE_TIMEOUT)
+
+	server := &http.Server{
+		Addr: fmt.Sprintf(":%s", cfg.Server.Port),
+		// после WriteTimeout ответ уже не записать, поэтому запросы к БД и редису отменяются вместе с контекстом
+		Handler:           middleware.Timeout(writeTimeout, MapHandlers(cfg, logger)),
+		ReadHeaderTimeout: seconds(cfg.Server.ReadTimeout, common.SERVER_READ_TIMEOUT),
+		ReadTimeout:       seconds(cfg.Server.ReadTimeout, common.SERVER_READ_TIMEOUT),
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       seconds(cfg.Server.IdleTimeout, common.SERVER_IDLE_TIMEOUT),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
 
 	logger.Info("server successfully started")
 
-	if err := http.ListenAndServe(fmt.Sprintf(":%s", cfg.Server.Port), nil); err != nil {
+	select {
+	case err := <-serveErr:
 		logger2.Fatalf(logger, "server run fatal error: %s", err.Error())
+	case <-ctx.Done():
+	}
+	// повторный сигнал завершает процесс сразу, не дожидаясь запросов
+	stop()
+
+	logger.Info("shutting down server, waiting for active requests")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(),
+		seconds(cfg.Server.ShutdownTimeout, common.SERVER_SHUTDOWN_TIMEOUT))
+	defer cancel()
+
+	// спаны последних запросов досылаются после остановки сервера, в том числе если Shutdown не дождался запросов
+	defer func() {
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			logger.Error(fmt.Sprintf("tracing shutdown error: %s", err.Error()))
+		}
+	}()
+
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error(fmt.Sprintf("server shutdown error: %s", err.Error()))
+		return
+	}
+
+	logger.Info("server stopped")
+}
+
+func seconds(value, fallback int64) time.Duration {
+	if value <= 0 {
+		value = fallback
 	}
+	return time.Second * time.Duration(value)
 }
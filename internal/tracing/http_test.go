@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoute(t *testing.T) {
+	const (
+		traceId  = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parentId = "00f067aa0ba902b7"
+	)
+
+	testTable := []struct {
+		name        string
+		traceparent string
+		status      int
+		wantCode    codes.Code
+	}{
+		{
+			name:     "default",
+			status:   http.StatusOK,
+			wantCode: codes.Unset,
+		},
+		{
+			name:     "client error",
+			status:   http.StatusNotFound,
+			wantCode: codes.Unset,
+		},
+		{
+			name:     "server error",
+			status:   http.StatusInternalServerError,
+			wantCode: codes.Error,
+		},
+		{
+			name:        "with traceparent",
+			traceparent: "00-" + traceId + "-" + parentId + "-01",
+			status:      http.StatusOK,
+			wantCode:    codes.Unset,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			recorder := newRecorder(t)
+
+			var handlerSpan trace.SpanContext
+			handler := Route("GET /films/{id}", func(w http.ResponseWriter, r *http.Request) {
+				handlerSpan = trace.SpanContextFromContext(r.Context())
+				w.WriteHeader(testCase.status)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/films/42", nil)
+			if testCase.traceparent != "" {
+				req.Header.Set("traceparent", testCase.traceparent)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := recorder.Ended()
+			assert.Len(t, spans, 1)
+			span := spans[0]
+
+			assert.Equal(t, "GET /films/{id}", span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Equal(t, testCase.wantCode, span.Status().Code)
+			assert.Equal(t, span.SpanContext(), handlerSpan)
+
+			route, _ := attributeValue(span, semconv.HTTPRouteKey)
+			assert.Equal(t, "/films/{id}", route.AsString())
+			path, _ := attributeValue(span, semconv.URLPathKey)
+			assert.Equal(t, "/films/42", path.AsString())
+			status, _ := attributeValue(span, semconv.HTTPResponseStatusCodeKey)
+			assert.Equal(t, int64(testCase.status), status.AsInt64())
+
+			if testCase.traceparent != "" {
+				assert.Equal(t, traceId, span.SpanContext().TraceID().String())
+				assert.Equal(t, parentId, span.Parent().SpanID().String())
+				assert.True(t, span.Parent().IsRemote())
+			} else {
+				assert.False(t, span.Parent().IsValid())
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net"
+	"testing"
+)
+
+func TestRedisHook(t *testing.T) {
+	hook := RedisHook{}
+
+	testTable := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{
+			name:     "default",
+			wantCode: codes.Unset,
+		},
+		{
+			name:     "missing key",
+			err:      redis.Nil,
+			wantCode: codes.Unset,
+		},
+		{
+			name:     "error",
+			err:      errors.New("connection refused"),
+			wantCode: codes.Error,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			recorder := newRecorder(t)
+			ctx, parent := Tracer().Start(context.Background(), "parent")
+
+			var commandSpan trace.SpanContext
+			process := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+				commandSpan = trace.SpanContextFromContext(ctx)
+				return testCase.err
+			})
+			err := process(ctx, redis.NewStringCmd(ctx, "get", "session:secret"))
+			parent.End()
+
+			assert.Equal(t, testCase.err, err)
+
+			spans := recorder.Ended()
+			assert.Len(t, spans, 2)
+			span := spans[0]
+
+			assert.Equal(t, "redis.get", span.Name())
+			assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+			assert.Equal(t, testCase.wantCode, span.Status().Code)
+			assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+			assert.Equal(t, span.SpanContext(), commandSpan)
+
+			system, _ := attributeValue(span, semconv.DBSystemKey)
+			assert.Equal(t, "redis", system.AsString())
+			operation, _ := attributeValue(span, semconv.DBOperationNameKey)
+			assert.Equal(t, "get", operation.AsString())
+			// аргументы команды (ключи с токенами) в спан не попадают
+			_, ok := attributeValue(span, semconv.DBQueryTextKey)
+			assert.False(t, ok)
+		})
+	}
+
+	t.Run("pipeline", func(t *testing.T) {
+		recorder := newRecorder(t)
+		ctx := context.Background()
+
+		process := hook.ProcessPipelineHook(func(ctx context.Context, cmds []redis.Cmder) error {
+			return nil
+		})
+		err := process(ctx, []redis.Cmder{redis.NewStatusCmd(ctx, "set", "key", "value"), redis.NewIntCmd(ctx, "expire", "key", 10)})
+
+		assert.NoError(t, err)
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "redis.pipeline", spans[0].Name())
+		query, _ := attributeValue(spans[0], semconv.DBQueryTextKey)
+		assert.Equal(t, "set expire", query.AsString())
+	})
+
+	t.Run("dial error", func(t *testing.T) {
+		recorder := newRecorder(t)
+
+		dial := hook.DialHook(func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return nil, errors.New("connection refused")
+		})
+		_, err := dial(context.Background(), "tcp", "localhost:6379")
+
+		assert.Error(t, err)
+		spans := recorder.Ended()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "redis.dial", spans[0].Name())
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Len(t, spans[0].Events(), 1)
+	})
+}
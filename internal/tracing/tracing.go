@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"vk_test_task/config"
+	"vk_test_task/internal/api/models"
+	"vk_test_task/internal/common"
+)
+
+// Tracer - трейсер сервиса, пока Init не вызван (или Tracing.Exporter: off), спаны не записываются
+func Tracer() trace.Tracer {
+	return otel.Tracer(common.TRACING_SERVICE_NAME)
+}
+
+// Init настраивает экспорт спанов по Tracing.Exporter: otlp (OTLP/HTTP на Tracing.Endpoint), stdout или off (по умолчанию).
+// Входящий и исходящий контекст трейса передается в заголовке traceparent (W3C Trace Context).
+// Возвращает функцию, которая досылает накопленные спаны при остановке сервера
+func Init(cfg *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	switch cfg.Tracing.Exporter {
+	case "otlp":
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Tracing.Endpoint)}
+		if cfg.Tracing.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(context.Background(), options...)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case "", "off":
+		return func(context.Context) error { return nil }, nil
+	default:
+		return nil, fmt.Errorf("tracing error: unknown exporter %q", cfg.Tracing.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing error: %s", err.Error())
+	}
+
+	sampleRatio := cfg.Tracing.SampleRatio
+	if sampleRatio <= 0 {
+		sampleRatio = common.TRACING_SAMPLE_RATIO
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		// если у входящего запроса уже есть решение о семплировании (флаг в traceparent), используется оно
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(common.TRACING_SERVICE_NAME),
+			semconv.ServiceVersion(cfg.Server.Version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// SetErrorStatus помечает спан ошибкой. Ошибки клиента (4xx) ошибкой спана не считаются,
+// их код пишется в атрибут error.type
+func SetErrorStatus(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	status, response := api_models.NewErrorResponse(err, "")
+	span.SetAttributes(semconv.ErrorTypeKey.String(string(response.Code)))
+	if status >= http.StatusInternalServerError {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
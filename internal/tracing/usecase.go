@@ -0,0 +1,373 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"vk_test_task/internal/api"
+	api_models "vk_test_task/internal/api/models"
+)
+
+// useCase оборачивает UseCaseInterface и открывает спан на каждый вызов, чтобы в трейсе было видно,
+// сколько времени занимает бизнес-логика между запросами к БД и редису
+type useCase struct {
+	next api.UseCaseInterface
+}
+
+func InstrumentUseCase(next api.UseCaseInterface) api.UseCaseInterface {
+	return &useCase{next: next}
+}
+
+func startUseCaseSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, "usecase."+method)
+}
+
+func (u *useCase) CreateActor(ctx context.Context, params api_models.CreateActorParams) (result string, err error) {
+	ctx, span := startUseCaseSpan(ctx, "CreateActor")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.CreateActor(ctx, params)
+}
+
+func (u *useCase) GetActors(ctx context.Context, params api_models.GetActorsParams) (result api_models.GetActorsResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "GetActors")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.GetActors(ctx, params)
+}
+
+func (u *useCase) GetActor(ctx context.Context, actorId string) (result api_models.ActorDetails, err error) {
+	ctx, span := startUseCaseSpan(ctx, "GetActor")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.GetActor(ctx, actorId)
+}
+
+func (u *useCase) UpdateActor(ctx context.Context, params api_models.UpdateActorParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "UpdateActor")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.UpdateActor(ctx, params)
+}
+
+func (u *useCase) DeleteActor(ctx context.Context, params api_models.DeleteActorParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "DeleteActor")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.DeleteActor(ctx, params)
+}
+
+func (u *useCase) CreateAPIKey(ctx context.Context, claims api_models.AuthClaims, params api_models.CreateAPIKeyParams) (result api_models.CreateAPIKeyResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "CreateAPIKey")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.CreateAPIKey(ctx, claims, params)
+}
+
+func (u *useCase) GetAPIKeys(ctx context.Context) (result api_models.GetAPIKeysResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "GetAPIKeys")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.GetAPIKeys(ctx)
+}
+
+func (u *useCase) RevokeAPIKey(ctx context.Context, params api_models.RevokeAPIKeyParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "RevokeAPIKey")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.RevokeAPIKey(ctx, params)
+}
+
+func (u *useCase) AuthenticateAPIKey(ctx context.Context, key string) (result api_models.AuthClaims, err error) {
+	ctx, span := startUseCaseSpan(ctx, "AuthenticateAPIKey")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.AuthenticateAPIKey(ctx, key)
+}
+
+func (u *useCase) SignIn(ctx context.Context, params api_models.AuthParams) (result api_models.SignInUseCaseResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "SignIn")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.SignIn(ctx, params)
+}
+
+func (u *useCase) SignUp(ctx context.Context, params api_models.AuthParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "SignUp")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.SignUp(ctx, params)
+}
+
+func (u *useCase) Refresh(ctx context.Context, params api_models.RefreshParams) (result api_models.SignInUseCaseResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "Refresh")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.Refresh(ctx, params)
+}
+
+func (u *useCase) Logout(ctx context.Context, claims api_models.AuthClaims) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "Logout")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.Logout(ctx, claims)
+}
+
+func (u *useCase) LogoutAll(ctx context.Context, claims api_models.AuthClaims) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "LogoutAll")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.LogoutAll(ctx, claims)
+}
+
+func (u *useCase) GetSessions(ctx context.Context, claims api_models.AuthClaims) (result api_models.GetSessionsResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "GetSessions")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.GetSessions(ctx, claims)
+}
+
+func (u *useCase) RevokeSession(ctx context.Context, claims api_models.AuthClaims, params api_models.RevokeSessionParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "RevokeSession")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.RevokeSession(ctx, claims, params)
+}
+
+func (u *useCase) GetJWKS(ctx context.Context) (result api_models.JWKS, err error) {
+	ctx, span := startUseCaseSpan(ctx, "GetJWKS")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.GetJWKS(ctx)
+}
+
+func (u *useCase) CreateFilm(ctx context.Context, params api_models.CreateFilmParams) (result string, err error) {
+	ctx, span := startUseCaseSpan(ctx, "CreateFilm")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.CreateFilm(ctx, params)
+}
+
+func (u *useCase) GetFilms(ctx context.Context, params api_models.GetFilmsParams) (result api_models.GetFilmsResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "GetFilms")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.GetFilms(ctx, params)
+}
+
+func (u *useCase) GetFilm(ctx context.Context, filmId string) (result api_models.FilmDetails, err error) {
+	ctx, span := startUseCaseSpan(ctx, "GetFilm")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.GetFilm(ctx, filmId)
+}
+
+func (u *useCase) UpdateFilm(ctx context.Context, params api_models.UpdateFilmParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "UpdateFilm")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.UpdateFilm(ctx, params)
+}
+
+func (u *useCase) DeleteFilm(ctx context.Context, params api_models.DeleteFilmParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "DeleteFilm")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.DeleteFilm(ctx, params)
+}
+
+func (u *useCase) SearchFilm(ctx context.Context, params api_models.SearchFilmParams) (result api_models.SearchFilmResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "SearchFilm")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.SearchFilm(ctx, params)
+}
+
+func (u *useCase) Readiness(ctx context.Context) api_models.ReadinessResponse {
+	ctx, span := startUseCaseSpan(ctx, "Readiness")
+	defer span.End()
+
+	return u.next.Readiness(ctx)
+}
+
+func (u *useCase) Version() api_models.VersionResponse {
+	return u.next.Version()
+}
+
+func (u *useCase) EnrollMFA(ctx context.Context, claims api_models.AuthClaims) (result api_models.EnrollMFAResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "EnrollMFA")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.EnrollMFA(ctx, claims)
+}
+
+func (u *useCase) ConfirmMFA(ctx context.Context, claims api_models.AuthClaims, params api_models.MFACodeParams) (result api_models.RecoveryCodesResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "ConfirmMFA")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.ConfirmMFA(ctx, claims, params)
+}
+
+func (u *useCase) DisableMFA(ctx context.Context, claims api_models.AuthClaims, params api_models.MFACodeParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "DisableMFA")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.DisableMFA(ctx, claims, params)
+}
+
+func (u *useCase) SignInMFA(ctx context.Context, params api_models.SignInMFAParams) (result api_models.SignInUseCaseResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "SignInMFA")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.SignInMFA(ctx, params)
+}
+
+func (u *useCase) GetRoles(ctx context.Context) (result api_models.GetRolesResponse, err error) {
+	ctx, span := startUseCaseSpan(ctx, "GetRoles")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.GetRoles(ctx)
+}
+
+func (u *useCase) GrantRole(ctx context.Context, params api_models.RoleParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "GrantRole")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.GrantRole(ctx, params)
+}
+
+func (u *useCase) RevokeRole(ctx context.Context, params api_models.RoleParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "RevokeRole")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.RevokeRole(ctx, params)
+}
+
+func (u *useCase) UnlockUser(ctx context.Context, params api_models.UnlockUserParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "UnlockUser")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.UnlockUser(ctx, params)
+}
+
+func (u *useCase) ChangePassword(ctx context.Context, claims api_models.AuthClaims, params api_models.ChangePasswordParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "ChangePassword")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.ChangePassword(ctx, claims, params)
+}
+
+func (u *useCase) ForgotPassword(ctx context.Context, params api_models.ForgotPasswordParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "ForgotPassword")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.ForgotPassword(ctx, params)
+}
+
+func (u *useCase) ResetPassword(ctx context.Context, params api_models.ResetPasswordParams) (err error) {
+	ctx, span := startUseCaseSpan(ctx, "ResetPassword")
+	defer func() {
+		SetErrorStatus(span, err)
+		span.End()
+	}()
+
+	return u.next.ResetPassword(ctx, params)
+}
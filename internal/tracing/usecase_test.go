@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"testing"
+	mock_api "vk_test_task/internal/api/mocks"
+	api_models "vk_test_task/internal/api/models"
+)
+
+func TestInstrumentUseCase(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := mock_api.NewMockUseCaseInterface(ctrl)
+	u := InstrumentUseCase(uc)
+
+	testTable := []struct {
+		name          string
+		err           error
+		wantCode      codes.Code
+		wantErrorType string
+	}{
+		{
+			name:     "default",
+			wantCode: codes.Unset,
+		},
+		{
+			name:          "not found",
+			err:           fmt.Errorf("usecase error: %w", api_models.NewNotFoundError("actor not found")),
+			wantCode:      codes.Unset,
+			wantErrorType: string(api_models.CodeNotFound),
+		},
+		{
+			name:          "internal error",
+			err:           fmt.Errorf("usecase error: %w", sql.ErrConnDone),
+			wantCode:      codes.Error,
+			wantErrorType: string(api_models.CodeInternal),
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			recorder := newRecorder(t)
+			ctx, parent := Tracer().Start(context.Background(), "GET /actors/{id}")
+
+			var usecaseSpan trace.SpanContext
+			uc.EXPECT().GetActor(gomock.Any(), "id").DoAndReturn(func(ctx context.Context, actorId string) (api_models.ActorDetails, error) {
+				usecaseSpan = trace.SpanContextFromContext(ctx)
+				return api_models.ActorDetails{ActorId: actorId}, testCase.err
+			})
+
+			result, err := u.GetActor(ctx, "id")
+			parent.End()
+
+			assert.Equal(t, testCase.err, err)
+			assert.Equal(t, "id", result.ActorId)
+
+			spans := recorder.Ended()
+			assert.Len(t, spans, 2)
+			span := spans[0]
+
+			assert.Equal(t, "usecase.GetActor", span.Name())
+			assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+			assert.Equal(t, span.SpanContext(), usecaseSpan)
+			assert.Equal(t, testCase.wantCode, span.Status().Code)
+
+			errorType, ok := attributeValue(span, semconv.ErrorTypeKey)
+			assert.Equal(t, testCase.wantErrorType != "", ok)
+			assert.Equal(t, testCase.wantErrorType, errorType.AsString())
+		})
+	}
+}
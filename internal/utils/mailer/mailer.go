@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strings"
+	"vk_test_task/config"
+)
+
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// New выбирает реализацию по Mail.Driver: smtp для продакшена, log (по умолчанию) для разработки
+func New(cfg *config.Config, logger *slog.Logger) Mailer {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return SMTPMailer{cfg: cfg.Mail}
+	default:
+		return LogMailer{logger: logger}
+	}
+}
+
+type SMTPMailer struct {
+	cfg config.Mail
+}
+
+func (m SMTPMailer) Send(to, subject, body string) error {
+	if strings.ContainsAny(to+subject, "\r\n") {
+		return fmt.Errorf("mailer error: invalid header value")
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		m.cfg.From, to, subject, body)
+
+	err := smtp.SendMail(net.JoinHostPort(m.cfg.Host, m.cfg.Port), auth, m.cfg.From, []string{to}, []byte(message))
+	if err != nil {
+		return fmt.Errorf("mailer error: %s", err.Error())
+	}
+
+	return nil
+}
+
+type LogMailer struct {
+	logger *slog.Logger
+}
+
+func (m LogMailer) Send(to, subject, body string) error {
+	m.logger.Info("mail sent", "to", to, "subject", subject, "body", body)
+	return nil
+}
@@ -0,0 +1,18 @@
+package recorder
+
+import "net/http"
+
+// StatusRecorder запоминает код ответа для метрик, трейсов и логов. Если хендлер не вызвал WriteHeader, это 200
+type StatusRecorder struct {
+	http.ResponseWriter
+	Status int
+}
+
+func New(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, Status: http.StatusOK}
+}
+
+func (r *StatusRecorder) WriteHeader(status int) {
+	r.Status = status
+	r.ResponseWriter.WriteHeader(status)
+}
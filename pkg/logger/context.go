@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+)
+
+type loggerKey struct{}
+
+// requestLogger - логгер запроса. Хранится по указателю, чтобы атрибуты, добавленные глубже по цепочке
+// (например user_id после авторизации), попали и в итоговую запись о запросе
+type requestLogger struct {
+	logger *slog.Logger
+}
+
+// WithContext кладет в контекст логгер запроса
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, &requestLogger{logger: logger})
+}
+
+// FromContext возвращает логгер запроса, а вне запроса - fallback
+func FromContext(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*requestLogger); ok {
+		return l.logger
+	}
+	return fallback
+}
+
+// AddAttrs добавляет атрибуты к логгеру запроса, вне запроса ничего не делает
+func AddAttrs(ctx context.Context, args ...any) {
+	if l, ok := ctx.Value(loggerKey{}).(*requestLogger); ok {
+		l.logger = l.logger.With(args...)
+	}
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// generation - хендлер одной конфигурации. Handle держит RLock на время записи, поэтому Reload,
+// взяв Lock, дожидается записей, начатых до замены, и только потом закрывает убранные выводы
+type generation struct {
+	handler slog.Handler
+	mu      sync.RWMutex
+	retired bool
+}
+
+// retire дожидается записей в эту конфигурацию, после него Handle перечитывает текущую
+func (g *generation) retire() {
+	g.mu.Lock()
+	g.retired = true
+	g.mu.Unlock()
+}
+
+// root - текущая конфигурация, которую Reload подменяет целиком
+type root struct {
+	current atomic.Pointer[generation]
+}
+
+// set ставит новый хендлер и возвращает прежнюю конфигурацию
+func (r *root) set(handler slog.Handler) *generation {
+	return r.current.Swap(&generation{handler: handler})
+}
+
+// dynamicHandler пишет в текущий root. Атрибуты и группы из With/WithGroup запоминаются
+// и после Reload заново применяются к новому хендлеру, результат кешируется до следующего Reload
+type dynamicHandler struct {
+	root   *root
+	parent *dynamicHandler
+	attrs  []slog.Attr
+	group  string
+	cache  atomic.Pointer[cachedHandler]
+}
+
+type cachedHandler struct {
+	base    *generation
+	handler slog.Handler
+}
+
+func (h *dynamicHandler) resolve(base *generation) slog.Handler {
+	if cached := h.cache.Load(); cached != nil && cached.base == base {
+		return cached.handler
+	}
+
+	var handler slog.Handler
+	switch {
+	case h.parent == nil:
+		handler = base.handler
+	case h.group != "":
+		handler = h.parent.resolve(base).WithGroup(h.group)
+	default:
+		handler = h.parent.resolve(base).WithAttrs(h.attrs)
+	}
+
+	h.cache.Store(&cachedHandler{base: base, handler: handler})
+	return handler
+}
+
+func (h *dynamicHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.resolve(h.root.current.Load()).Enabled(ctx, level)
+}
+
+func (h *dynamicHandler) Handle(ctx context.Context, record slog.Record) error {
+	for {
+		base := h.root.current.Load()
+
+		base.mu.RLock()
+		// конфигурацию успели заменить и ее выводы могут быть уже закрыты - пишем в новую
+		if base.retired {
+			base.mu.RUnlock()
+			continue
+		}
+		err := h.resolve(base).Handle(ctx, record)
+		base.mu.RUnlock()
+
+		return err
+	}
+}
+
+func (h *dynamicHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &dynamicHandler{root: h.root, parent: h, attrs: attrs}
+}
+
+func (h *dynamicHandler) WithGroup(name string) slog.Handler {
+	return &dynamicHandler{root: h.root, parent: h, group: name}
+}
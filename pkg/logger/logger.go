@@ -6,38 +6,136 @@ import (
 	"io"
 	"log/slog"
 	"os"
-	"time"
+	"strings"
+	"sync"
+	"vk_test_task/config"
 )
 
-func NewLogger(inFile bool) *slog.Logger {
-	var w io.Writer
-	if inFile {
-		if os.Getenv("IN_DOCKER") != "TRUE" {
-			filename := fmt.Sprintf("logs/%s-log", time.Now().Format(time.RFC3339))
-			f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE, 0666)
+var (
+	mu      sync.Mutex
+	current = &dynamicHandler{root: new(root)}
+	outputs = map[config.LogSink]*output{}
+)
+
+// NewLogger собирает логгер по конфигу и делает его slog.Default. Ошибка в конфиге логгера фатальна,
+// так как без логов сервер запускать нельзя
+func NewLogger(cfg config.Logger) *slog.Logger {
+	if err := Reload(cfg); err != nil {
+		panic(err)
+	}
+
+	logger := slog.New(current)
+	slog.SetDefault(logger)
+
+	return logger
+}
+
+// Reload пересобирает выводы и уровень по новому конфигу без перезапуска. Логгеры, полученные раньше,
+// в том числе через With, сразу пишут по-новому. Выводы, которые остались в конфиге, не переоткрываются,
+// убранные закрываются после того, как допишутся начатые в них записи. При ошибке остается прежняя конфигурация
+func Reload(cfg config.Logger) error {
+	level, err := parseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+
+	sinks := cfg.Sinks
+	if len(sinks) == 0 {
+		sinks = []config.LogSink{{Type: "stderr"}}
+		if cfg.InFile {
+			sinks = append(sinks, config.LogSink{Type: "file", Path: "logs/api.log"})
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	handlers := make([]slog.Handler, 0, len(sinks))
+	used := make(map[config.LogSink]*output, len(sinks))
+	opened := make([]*output, 0, len(sinks))
+	for _, sink := range sinks {
+		format := sink.Format
+		if format == "" {
+			format = cfg.Format
+		}
+
+		key := outputKey(sink)
+		out, ok := used[key]
+		if !ok {
+			out, ok = outputs[key]
+		}
+		if !ok {
+			out, err = openOutput(sink)
 			if err != nil {
-				panic(err)
+				closeOutputs(opened)
+				return err
 			}
-			w = io.MultiWriter(os.Stderr, f)
-		} else {
-			w = os.Stderr
+			opened = append(opened, out)
+		}
+		used[key] = out
+
+		handler, err := newSinkHandler(out, format, level)
+		if err != nil {
+			closeOutputs(opened)
+			return err
 		}
-	} else {
-		w = os.Stderr
+		handlers = append(handlers, handler)
 	}
 
-	level := slog.LevelDebug
-	if _, ok := os.LookupEnv("DEBUG_LEVEL"); ok {
-		level = slog.LevelInfo
+	var handler slog.Handler = multiHandler(handlers)
+	if len(handlers) == 1 {
+		handler = handlers[0]
 	}
 
-	logger := slog.New(tint.NewHandler(w, &tint.Options{Level: level}))
-	slog.SetDefault(slog.New(tint.NewHandler(w, &tint.Options{
-		Level:      slog.LevelDebug,
-		TimeFormat: time.Kitchen,
-	})))
+	previous := current.root.set(traceHandler{handler})
 
-	return logger
+	var removed []*output
+	for key, out := range outputs {
+		if _, ok := used[key]; !ok {
+			removed = append(removed, out)
+		}
+	}
+	outputs = used
+
+	if previous != nil {
+		previous.retire()
+	}
+	closeOutputs(removed)
+
+	return nil
+}
+
+func closeOutputs(outs []*output) {
+	for _, out := range outs {
+		out.Close()
+	}
+}
+
+func parseLevel(name string) (slog.Level, error) {
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("logger error: unknown level %q", name)
+	}
+	return level, nil
+}
+
+// newFormatHandler - tint для терминала, text (logfmt) и json для файлов и сбора логов.
+// Пароли и токены во всех форматах заменяются на REDACTED
+func newFormatHandler(w io.Writer, format string, level slog.Level) (slog.Handler, error) {
+	switch strings.ToLower(format) {
+	case "", "tint":
+		return tint.NewHandler(w, &tint.Options{Level: level, ReplaceAttr: redact}), nil
+	case "text":
+		return slog.NewTextHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: redact}), nil
+	case "json":
+		return slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: redact}), nil
+	default:
+		return nil, fmt.Errorf("logger error: unknown format %q", format)
+	}
 }
 
 func Fatalf(logger *slog.Logger, format string, args ...any) {
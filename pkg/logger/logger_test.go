@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"vk_test_task/config"
+)
+
+func TestParseLevel(t *testing.T) {
+	testTable := []struct {
+		name    string
+		level   string
+		want    slog.Level
+		wantErr bool
+	}{
+		{
+			name:  "default",
+			level: "",
+			want:  slog.LevelInfo,
+		},
+		{
+			name:  "debug",
+			level: "debug",
+			want:  slog.LevelDebug,
+		},
+		{
+			name:  "upper case",
+			level: "WARN",
+			want:  slog.LevelWarn,
+		},
+		{
+			name:  "error",
+			level: "error",
+			want:  slog.LevelError,
+		},
+		{
+			name:    "unknown level",
+			level:   "verbose",
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			level, err := parseLevel(testCase.level)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, level)
+		})
+	}
+}
+
+// readLog возвращает непустые строки файла лога
+func readLog(t *testing.T, path string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	assert.NoError(t, err)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return strings.Split(string(bytes.TrimSpace(data)), "\n")
+}
+
+func resetLogger(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NoError(t, Reload(config.Logger{}))
+	})
+}
+
+func TestReload(t *testing.T) {
+	resetLogger(t)
+
+	dir := t.TempDir()
+	first := config.LogSink{Type: "file", Path: filepath.Join(dir, "first.log"), Format: "json"}
+	second := config.LogSink{Type: "file", Path: filepath.Join(dir, "second.log"), Format: "text"}
+
+	assert.NoError(t, Reload(config.Logger{Sinks: []config.LogSink{first}}))
+	logger := slog.New(current)
+	requestLogger := logger.With(slog.String("request_id", "id"))
+
+	logger.Debug("skipped")
+	logger.Info("first config")
+	assert.Len(t, readLog(t, first.Path), 1)
+	firstOutput := outputs[outputKey(first)]
+
+	t.Run("keeps unchanged sinks", func(t *testing.T) {
+		assert.NoError(t, Reload(config.Logger{Level: "debug", Sinks: []config.LogSink{first, second}}))
+
+		assert.Same(t, firstOutput, outputs[outputKey(first)])
+		assert.Len(t, outputs, 2)
+
+		requestLogger.Debug("second config")
+		assert.Len(t, readLog(t, first.Path), 2)
+		assert.Contains(t, readLog(t, first.Path)[1], `"request_id":"id"`)
+		assert.Len(t, readLog(t, second.Path), 1)
+	})
+
+	t.Run("format change reuses the file", func(t *testing.T) {
+		text := first
+		text.Format = "text"
+		assert.NoError(t, Reload(config.Logger{Level: "debug", Sinks: []config.LogSink{text, second}}))
+
+		assert.Same(t, firstOutput, outputs[outputKey(first)])
+		logger.Info("text format")
+		assert.Contains(t, readLog(t, first.Path)[2], "msg=")
+	})
+
+	t.Run("invalid config keeps previous", func(t *testing.T) {
+		third := config.LogSink{Type: "file", Path: filepath.Join(dir, "third.log")}
+
+		assert.Error(t, Reload(config.Logger{Level: "verbose", Sinks: []config.LogSink{third}}))
+		assert.Error(t, Reload(config.Logger{Sinks: []config.LogSink{third, {Type: "kafka"}}}))
+		assert.Error(t, Reload(config.Logger{Sinks: []config.LogSink{third}, Format: "xml"}))
+
+		assert.Len(t, outputs, 2)
+		logger.Debug("still second config")
+		assert.Len(t, readLog(t, first.Path), 4)
+		assert.Nil(t, readLog(t, third.Path))
+	})
+
+	t.Run("removes dropped sinks", func(t *testing.T) {
+		assert.NoError(t, Reload(config.Logger{Sinks: []config.LogSink{second}}))
+
+		assert.Len(t, outputs, 1)
+		assert.NotContains(t, outputs, outputKey(first))
+
+		logger.Info("third config")
+		assert.Len(t, readLog(t, first.Path), 4)
+		assert.Len(t, readLog(t, second.Path), 4)
+	})
+}
+
+// blockingHandler держит запись, пока тест не отпустит ее
+type blockingHandler struct {
+	slog.Handler
+	started chan struct{}
+	release chan struct{}
+}
+
+func (h blockingHandler) Handle(ctx context.Context, record slog.Record) error {
+	close(h.started)
+	<-h.release
+	return h.Handler.Handle(ctx, record)
+}
+
+func TestReload_WaitsForInFlightRecords(t *testing.T) {
+	resetLogger(t)
+
+	dir := t.TempDir()
+	first := config.LogSink{Type: "file", Path: filepath.Join(dir, "first.log")}
+	second := config.LogSink{Type: "file", Path: filepath.Join(dir, "second.log")}
+	assert.NoError(t, Reload(config.Logger{Format: "json", Sinks: []config.LogSink{first}}))
+
+	out := outputs[outputKey(first)]
+	handler, err := newSinkHandler(out, "json", slog.LevelInfo)
+	assert.NoError(t, err)
+	blocking := blockingHandler{Handler: handler, started: make(chan struct{}), release: make(chan struct{})}
+	current.root.set(blocking)
+
+	logger := slog.New(current)
+	logged := make(chan struct{})
+	go func() {
+		logger.Info("in flight")
+		close(logged)
+	}()
+	<-blocking.started
+
+	reloaded := make(chan struct{})
+	go func() {
+		assert.NoError(t, Reload(config.Logger{Format: "json", Sinks: []config.LogSink{second}}))
+		close(reloaded)
+	}()
+
+	// новая конфигурация уже действует, но старый файл не закрыт, пока в него пишется запись
+	assert.Eventually(t, func() bool {
+		return current.root.current.Load().handler != blocking
+	}, time.Second, time.Millisecond)
+	select {
+	case <-reloaded:
+		t.Fatal("reload closed the sink before the in-flight record was written")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(blocking.release)
+	<-logged
+	<-reloaded
+
+	assert.Len(t, readLog(t, first.Path), 1)
+	logger.Info("after reload")
+	assert.Len(t, readLog(t, second.Path), 1)
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const redacted = "REDACTED"
+
+// sensitiveParts - ключ, содержащий любую из подстрок, считается секретом
+var sensitiveParts = []string{"password", "token", "secret", "authorization", "cookie", "api_key"}
+
+// sensitiveKeys - короткие ключи, которые нельзя искать подстрокой ("code" есть в "error_code")
+var sensitiveKeys = map[string]bool{"code": true, "key": true, "recovery_codes": true}
+
+// isSensitive проверяет ключ без учета регистра, "-" считается "_", чтобы заголовки вроде X-API-Key тоже скрывались
+func isSensitive(key string) bool {
+	key = strings.ReplaceAll(strings.ToLower(key), "-", "_")
+	if sensitiveKeys[key] {
+		return true
+	}
+	for _, part := range sensitiveParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact - ReplaceAttr для хендлеров: значения секретных ключей, в том числе внутри групп, заменяются на REDACTED
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindGroup && isSensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	return a
+}
+
+// Params превращает структуру параметров запроса в группу атрибутов с ключами из json тегов,
+// чтобы секретные поля прошли через redact, а не попали в лог целиком через %v
+func Params(v any) slog.LogValuer {
+	return params{v: v}
+}
+
+type params struct {
+	v any
+}
+
+func (p params) LogValue() slog.Value {
+	data, err := json.Marshal(p.v)
+	if err != nil {
+		return slog.StringValue("unloggable params")
+	}
+
+	var decoded any
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		return slog.StringValue("unloggable params")
+	}
+
+	return jsonValue(decoded)
+}
+
+// jsonValue превращает разобранный JSON в значение slog. Объекты становятся группами, массивы с объектами - группами
+// с индексами в ключах, чтобы redact дошел до вложенных полей. Секретные ключи скрываются сразу: ReplaceAttr
+// не вызывается для групп, и объект или массив под ключом вроде recovery_codes иначе попал бы в лог
+func jsonValue(v any) slog.Value {
+	switch value := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		attrs := make([]slog.Attr, 0, len(keys))
+		for _, key := range keys {
+			attrs = append(attrs, jsonAttr(key, value[key]))
+		}
+		return slog.GroupValue(attrs...)
+	case []any:
+		if !hasNested(value) {
+			return slog.AnyValue(value)
+		}
+		attrs := make([]slog.Attr, 0, len(value))
+		for i, item := range value {
+			attrs = append(attrs, jsonAttr(strconv.Itoa(i), item))
+		}
+		return slog.GroupValue(attrs...)
+	default:
+		return slog.AnyValue(v)
+	}
+}
+
+func jsonAttr(key string, v any) slog.Attr {
+	if isSensitive(key) {
+		return slog.String(key, redacted)
+	}
+	return slog.Attr{Key: key, Value: jsonValue(v)}
+}
+
+// hasNested - в массиве есть объекты или массивы. Массив из одних скаляров пишется как есть
+func hasNested(values []any) bool {
+	for _, v := range values {
+		switch v.(type) {
+		case map[string]any, []any:
+			return true
+		}
+	}
+	return false
+}
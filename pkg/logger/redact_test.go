@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"net/http"
+	"testing"
+)
+
+// logged пишет одну запись через JSON хендлер с redact и возвращает ее без служебных полей
+func logged(t *testing.T, attrs ...slog.Attr) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: redact}))
+	l.LogAttrs(context.Background(), slog.LevelInfo, "request params", attrs...)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	delete(record, "time")
+	delete(record, "level")
+	delete(record, "msg")
+	return record
+}
+
+func TestRedact(t *testing.T) {
+	testTable := []struct {
+		name  string
+		attrs []slog.Attr
+		want  map[string]any
+	}{
+		{
+			name:  "default",
+			attrs: []slog.Attr{slog.String("login", "user"), slog.String("password", "qwerty")},
+			want:  map[string]any{"login": "user", "password": redacted},
+		},
+		{
+			name: "headers",
+			attrs: []slog.Attr{
+				slog.String("Authorization", "Bearer token"),
+				slog.String("Cookie", "session=1"),
+				slog.String("X-API-Key", "key"),
+				slog.String("User-Agent", "curl"),
+			},
+			want: map[string]any{"Authorization": redacted, "Cookie": redacted, "X-API-Key": redacted, "User-Agent": "curl"},
+		},
+		{
+			name:  "inside group",
+			attrs: []slog.Attr{slog.Group("params", slog.String("refresh_token", "token"), slog.Int("rate", 7))},
+			want:  map[string]any{"params": map[string]any{"refresh_token": redacted, "rate": float64(7)}},
+		},
+		{
+			name:  "short keys match exactly",
+			attrs: []slog.Attr{slog.String("code", "123456"), slog.String("error_code", "not_found"), slog.String("keyword", "drama")},
+			want:  map[string]any{"code": redacted, "error_code": "not_found", "keyword": "drama"},
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, logged(t, testCase.attrs...))
+		})
+	}
+}
+
+func TestParams(t *testing.T) {
+	type user struct {
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+
+	testTable := []struct {
+		name   string
+		params any
+		want   any
+	}{
+		{
+			name:   "default",
+			params: user{Login: "user", Password: "qwerty"},
+			want:   map[string]any{"login": "user", "password": redacted},
+		},
+		{
+			name: "nested object",
+			params: map[string]any{
+				"user":    user{Login: "user", Password: "qwerty"},
+				"session": map[string]any{"device": "phone", "refresh_token": "token"},
+			},
+			want: map[string]any{
+				"user":    map[string]any{"login": "user", "password": redacted},
+				"session": map[string]any{"device": "phone", "refresh_token": redacted},
+			},
+		},
+		{
+			name:   "array of objects",
+			params: map[string]any{"users": []user{{Login: "first", Password: "1"}, {Login: "second", Password: "2"}}},
+			want: map[string]any{"users": map[string]any{
+				"0": map[string]any{"login": "first", "password": redacted},
+				"1": map[string]any{"login": "second", "password": redacted},
+			}},
+		},
+		{
+			name:   "nested arrays",
+			params: map[string]any{"batches": [][]user{{{Login: "user", Password: "qwerty"}}}},
+			want: map[string]any{"batches": map[string]any{
+				"0": map[string]any{"0": map[string]any{"login": "user", "password": redacted}},
+			}},
+		},
+		{
+			name:   "array of scalars",
+			params: map[string]any{"actors": []string{"a1", "a2"}},
+			want:   map[string]any{"actors": []any{"a1", "a2"}},
+		},
+		{
+			name:   "sensitive array",
+			params: map[string]any{"recovery_codes": []string{"code1", "code2"}, "tokens": []map[string]string{{"id": "1"}}},
+			want:   map[string]any{"recovery_codes": redacted, "tokens": redacted},
+		},
+		{
+			name: "headers",
+			params: map[string]any{"headers": http.Header{
+				"Authorization": {"Bearer token"},
+				"X-Api-Key":     {"key"},
+				"Accept":        {"application/json"},
+			}},
+			want: map[string]any{"headers": map[string]any{
+				"Authorization": redacted,
+				"X-Api-Key":     redacted,
+				"Accept":        []any{"application/json"},
+			}},
+		},
+		{
+			name:   "unloggable",
+			params: map[string]any{"callback": func() {}},
+			want:   "unloggable params",
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			record := logged(t, slog.Any("params", Params(testCase.params)))
+			assert.Equal(t, testCase.want, record["params"])
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"log/slog"
+	"log/syslog"
+	"os"
+	"sync"
+	"vk_test_task/config"
+)
+
+// output - открытый вывод: файл, соединение с syslog или stderr. Выводы, которые есть и в старом, и в новом конфиге,
+// переживают Reload, чтобы не переоткрывать файл и соединение на каждое изменение конфига
+type output struct {
+	writer io.Writer
+	closer io.Closer
+	router *syslogRouter
+}
+
+// outputKey - настройки вывода без формата: формат влияет только на хендлер, поэтому выводы,
+// отличающиеся одним форматом, пишут в один файл через один lumberjack
+func outputKey(sink config.LogSink) config.LogSink {
+	sink.Format = ""
+	if sink.Type == "" {
+		sink.Type = "stderr"
+	}
+	return sink
+}
+
+// openOutput открывает вывод, closer закрывает файл или соединение с syslog, когда вывод убран из конфига
+func openOutput(sink config.LogSink) (*output, error) {
+	switch sink.Type {
+	case "", "stderr":
+		return &output{writer: os.Stderr}, nil
+	case "file":
+		if sink.Path == "" {
+			return nil, fmt.Errorf("logger error: file sink requires Path")
+		}
+		// lumberjack начинает новый файл по достижении MaxSize мегабайт, старые удаляет по MaxAge и MaxBackups
+		file := &lumberjack.Logger{
+			Filename:   sink.Path,
+			MaxSize:    sink.MaxSize,
+			MaxAge:     sink.MaxAge,
+			MaxBackups: sink.MaxBackups,
+			Compress:   sink.Compress,
+			LocalTime:  true,
+		}
+		return &output{writer: file, closer: file}, nil
+	case "syslog":
+		writer, err := syslog.Dial(sink.Network, sink.Address, syslog.LOG_INFO|syslog.LOG_DAEMON, sink.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("logger error: syslog: %s", err.Error())
+		}
+		router := &syslogRouter{writer: writer}
+		return &output{writer: router, closer: writer, router: router}, nil
+	default:
+		return nil, fmt.Errorf("logger error: unknown sink type %q", sink.Type)
+	}
+}
+
+func (o *output) Close() error {
+	if o.closer == nil {
+		return nil
+	}
+	return o.closer.Close()
+}
+
+// newSinkHandler создает хендлер вывода с форматом и уровнем текущего конфига
+func newSinkHandler(out *output, format string, level slog.Level) (slog.Handler, error) {
+	handler, err := newFormatHandler(out.writer, format, level)
+	if err != nil {
+		return nil, err
+	}
+	if out.router != nil {
+		return syslogHandler{Handler: handler, router: out.router}, nil
+	}
+	return handler, nil
+}
+
+// multiHandler пишет запись во все выводы, у каждого свой уровень и формат
+type multiHandler []slog.Handler
+
+func (m multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range m {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, h := range m {
+		if h.Enabled(ctx, record.Level) {
+			errs = append(errs, h.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, 0, len(m))
+	for _, h := range m {
+		handlers = append(handlers, h.WithAttrs(attrs))
+	}
+	return handlers
+}
+
+func (m multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, 0, len(m))
+	for _, h := range m {
+		handlers = append(handlers, h.WithGroup(name))
+	}
+	return handlers
+}
+
+// syslogRouter отправляет строку в syslog с важностью текущей записи. Хендлеры slog пишут запись
+// одним вызовом Write, поэтому достаточно выставить уровень под мьютексом перед Handle
+type syslogRouter struct {
+	mu     sync.Mutex
+	level  slog.Level
+	writer *syslog.Writer
+}
+
+func (r *syslogRouter) Write(p []byte) (int, error) {
+	message := string(p)
+
+	var err error
+	switch {
+	case r.level >= slog.LevelError:
+		err = r.writer.Err(message)
+	case r.level >= slog.LevelWarn:
+		err = r.writer.Warning(message)
+	case r.level >= slog.LevelInfo:
+		err = r.writer.Info(message)
+	default:
+		err = r.writer.Debug(message)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+type syslogHandler struct {
+	slog.Handler
+	router *syslogRouter
+}
+
+func (h syslogHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.router.mu.Lock()
+	defer h.router.mu.Unlock()
+
+	h.router.level = record.Level
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h syslogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return syslogHandler{Handler: h.Handler.WithAttrs(attrs), router: h.router}
+}
+
+func (h syslogHandler) WithGroup(name string) slog.Handler {
+	return syslogHandler{Handler: h.Handler.WithGroup(name), router: h.router}
+}
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"vk_test_task/config"
+)
+
+func TestOpenOutput(t *testing.T) {
+	dir := t.TempDir()
+
+	testTable := []struct {
+		name       string
+		sink       config.LogSink
+		wantCloser bool
+		wantRouter bool
+		wantErr    bool
+	}{
+		{
+			name: "default",
+			sink: config.LogSink{},
+		},
+		{
+			name: "stderr",
+			sink: config.LogSink{Type: "stderr"},
+		},
+		{
+			name:       "file",
+			sink:       config.LogSink{Type: "file", Path: filepath.Join(dir, "api.log"), MaxSize: 1},
+			wantCloser: true,
+		},
+		{
+			name:    "file without path",
+			sink:    config.LogSink{Type: "file"},
+			wantErr: true,
+		},
+		{
+			// по UDP соединение устанавливается без ответа сервера
+			name:       "syslog",
+			sink:       config.LogSink{Type: "syslog", Network: "udp", Address: "127.0.0.1:514", Tag: "api"},
+			wantCloser: true,
+			wantRouter: true,
+		},
+		{
+			name:    "unknown type",
+			sink:    config.LogSink{Type: "kafka"},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			out, err := openOutput(testCase.sink)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			defer out.Close()
+
+			assert.Equal(t, testCase.wantCloser, out.closer != nil)
+			assert.Equal(t, testCase.wantRouter, out.router != nil)
+			if !testCase.wantCloser {
+				assert.Equal(t, os.Stderr, out.writer)
+			}
+		})
+	}
+}
+
+func TestOutputKey(t *testing.T) {
+	file := config.LogSink{Type: "file", Path: "logs/api.log", Format: "json"}
+	text := file
+	text.Format = "text"
+	rotated := file
+	rotated.MaxSize = 10
+
+	assert.Equal(t, outputKey(file), outputKey(text))
+	assert.NotEqual(t, outputKey(file), outputKey(rotated))
+	assert.Equal(t, outputKey(config.LogSink{}), outputKey(config.LogSink{Type: "stderr", Format: "tint"}))
+}
+
+func TestNewSinkHandler(t *testing.T) {
+	testTable := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "default",
+			format: "",
+			want:   "request completed",
+		},
+		{
+			name:   "text",
+			format: "text",
+			want:   `msg="request completed" password=REDACTED`,
+		},
+		{
+			name:   "json",
+			format: "JSON",
+			want:   `"msg":"request completed","password":"REDACTED"`,
+		},
+		{
+			name:    "unknown format",
+			format:  "xml",
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			handler, err := newSinkHandler(&output{writer: &buf}, testCase.format, slog.LevelInfo)
+
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			l := slog.New(handler)
+			l.Debug("skipped")
+			l.Info("request completed", slog.String("password", "qwerty"))
+
+			assert.Contains(t, buf.String(), testCase.want)
+			assert.NotContains(t, buf.String(), "qwerty")
+			assert.NotContains(t, buf.String(), "skipped")
+		})
+	}
+
+	t.Run("syslog", func(t *testing.T) {
+		handler, err := newSinkHandler(&output{writer: &bytes.Buffer{}, router: &syslogRouter{}}, "text", slog.LevelInfo)
+
+		assert.NoError(t, err)
+		assert.IsType(t, syslogHandler{}, handler)
+	})
+}
+
+func TestMultiHandler(t *testing.T) {
+	var debug, errorOnly bytes.Buffer
+	handler := multiHandler{
+		slog.NewTextHandler(&debug, &slog.HandlerOptions{Level: slog.LevelDebug}),
+		slog.NewTextHandler(&errorOnly, &slog.HandlerOptions{Level: slog.LevelError}),
+	}
+	l := slog.New(handler).With(slog.String("request_id", "id"))
+
+	assert.True(t, handler.Enabled(context.Background(), slog.LevelDebug))
+	assert.False(t, multiHandler{slog.NewTextHandler(&errorOnly, &slog.HandlerOptions{Level: slog.LevelError})}.Enabled(context.Background(), slog.LevelInfo))
+
+	l.Debug("debug record")
+	l.Error("error record")
+
+	assert.Contains(t, debug.String(), "debug record")
+	assert.Contains(t, debug.String(), "error record")
+	assert.NotContains(t, errorOnly.String(), "debug record")
+	assert.Contains(t, errorOnly.String(), "msg=\"error record\" request_id=id")
+}
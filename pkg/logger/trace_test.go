@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"log/slog"
+	"testing"
+)
+
+func TestTraceHandler(t *testing.T) {
+	provider := trace.NewTracerProvider(trace.WithSpanProcessor(tracetest.NewSpanRecorder()))
+	ctx, span := provider.Tracer("test").Start(context.Background(), "GET /films/{id}")
+	defer span.End()
+
+	testTable := []struct {
+		name      string
+		ctx       context.Context
+		log       func(l *slog.Logger, ctx context.Context)
+		wantTrace bool
+	}{
+		{
+			name: "default",
+			ctx:  ctx,
+			log: func(l *slog.Logger, ctx context.Context) {
+				l.InfoContext(ctx, "request completed")
+			},
+			wantTrace: true,
+		},
+		{
+			name: "logger with attrs",
+			ctx:  ctx,
+			log: func(l *slog.Logger, ctx context.Context) {
+				l.With(slog.String("request_id", "id")).InfoContext(ctx, "request completed")
+			},
+			wantTrace: true,
+		},
+		{
+			name: "without span",
+			ctx:  context.Background(),
+			log: func(l *slog.Logger, ctx context.Context) {
+				l.InfoContext(ctx, "request completed")
+			},
+			wantTrace: false,
+		},
+		{
+			name: "without context",
+			ctx:  ctx,
+			log: func(l *slog.Logger, ctx context.Context) {
+				l.Info("request completed")
+			},
+			wantTrace: false,
+		},
+	}
+
+	for _, testCase := range testTable {
+		t.Run(testCase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			testCase.log(slog.New(traceHandler{slog.NewJSONHandler(&buf, nil)}), testCase.ctx)
+
+			var record map[string]any
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			if !testCase.wantTrace {
+				assert.NotContains(t, record, "trace_id")
+				assert.NotContains(t, record, "span_id")
+				return
+			}
+
+			assert.Equal(t, span.SpanContext().TraceID().String(), record["trace_id"])
+			assert.Equal(t, span.SpanContext().SpanID().String(), record["span_id"])
+		})
+	}
+}
@@ -0,0 +1,4 @@
+drop table film_actor;
+drop table "user";
+drop table film;
+drop table actor;
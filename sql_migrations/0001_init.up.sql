@@ -1,5 +1,5 @@
-
-create table actor
+-- исходная схема. if not exists - чтобы базы, созданные раньше через docker-entrypoint, приняли миграции без пересоздания
+create table if not exists actor
 (
     name  varchar(128),
     sex   integer,
@@ -8,10 +8,7 @@ create table actor
         primary key
 );
 
-alter table actor
-    owner to postgres;
-
-create table film
+create table if not exists film
 (
     name          varchar(256),
     description   varchar(1024),
@@ -21,10 +18,7 @@ create table film
         primary key
 );
 
-alter table film
-    owner to postgres;
-
-create table "user"
+create table if not exists "user"
 (
     id       serial
         primary key,
@@ -34,10 +28,7 @@ create table "user"
     user_id  varchar(64)
 );
 
-alter table "user"
-    owner to postgres;
-
-create table film_actor
+create table if not exists film_actor
 (
     film_id  varchar(64) not null
         references film
@@ -48,6 +39,3 @@ create table film_actor
         constraint film_actor_actor_id_fkey1
             references actor
 );
-
-alter table film_actor
-    owner to postgres;
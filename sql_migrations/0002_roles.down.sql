@@ -0,0 +1,20 @@
+alter table "user"
+    add column is_admin boolean not null default false;
+
+update "user"
+set is_admin = true
+where user_id in (select user_id from user_role where role = 'admin');
+
+alter table "user"
+    alter column is_admin drop default;
+
+drop table user_role;
+drop table role_permission;
+drop table permission;
+drop table role;
+
+alter table "user"
+    drop constraint user_user_id_key;
+
+alter table "user"
+    alter column user_id drop not null;
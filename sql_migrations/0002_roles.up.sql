@@ -0,0 +1,114 @@
+create table if not exists role
+(
+    name varchar(64) not null
+        primary key
+);
+
+create table if not exists permission
+(
+    name varchar(64) not null
+        primary key
+);
+
+create table if not exists role_permission
+(
+    role       varchar(64) not null
+        references role,
+    permission varchar(64) not null
+        references permission,
+    primary key (role, permission)
+);
+
+alter table "user"
+    alter column user_id set not null;
+
+do
+$$
+    begin
+        if not exists (select 1 from pg_constraint where conname = 'user_user_id_key') then
+            alter table "user"
+                add constraint user_user_id_key unique (user_id);
+        end if;
+    end
+$$;
+
+create table if not exists user_role
+(
+    user_id varchar(64) not null
+        references "user" (user_id),
+    role    varchar(64) not null
+        references role,
+    primary key (user_id, role)
+);
+
+insert into role (name)
+values ('viewer'),
+       ('editor'),
+       ('moderator'),
+       ('admin')
+on conflict do nothing;
+
+insert into permission (name)
+values ('film:read'),
+       ('film:create'),
+       ('film:update'),
+       ('film:delete'),
+       ('actor:read'),
+       ('actor:create'),
+       ('actor:update'),
+       ('actor:delete'),
+       ('role:manage'),
+       ('user:manage')
+on conflict do nothing;
+
+insert into role_permission (role, permission)
+values ('viewer', 'film:read'),
+       ('viewer', 'actor:read'),
+
+       ('editor', 'film:read'),
+       ('editor', 'film:create'),
+       ('editor', 'film:update'),
+       ('editor', 'actor:read'),
+       ('editor', 'actor:create'),
+       ('editor', 'actor:update'),
+
+       ('moderator', 'film:read'),
+       ('moderator', 'film:create'),
+       ('moderator', 'film:update'),
+       ('moderator', 'film:delete'),
+       ('moderator', 'actor:read'),
+       ('moderator', 'actor:create'),
+       ('moderator', 'actor:update'),
+       ('moderator', 'actor:delete'),
+
+       ('admin', 'film:read'),
+       ('admin', 'film:create'),
+       ('admin', 'film:update'),
+       ('admin', 'film:delete'),
+       ('admin', 'actor:read'),
+       ('admin', 'actor:create'),
+       ('admin', 'actor:update'),
+       ('admin', 'actor:delete'),
+       ('admin', 'role:manage'),
+       ('admin', 'user:manage')
+on conflict do nothing;
+
+-- is_admin заменяется ролью admin
+do
+$$
+    begin
+        if exists (select 1
+                   from information_schema.columns
+                   where table_name = 'user'
+                     and column_name = 'is_admin') then
+            insert into user_role (user_id, role)
+            select user_id, 'admin'
+            from "user"
+            where is_admin
+            on conflict do nothing;
+
+            alter table "user"
+                drop column is_admin;
+        end if;
+    end
+$$;
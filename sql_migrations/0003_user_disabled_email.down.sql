@@ -0,0 +1,5 @@
+alter table "user"
+    drop column email;
+
+alter table "user"
+    drop column disabled;
@@ -0,0 +1,15 @@
+alter table "user"
+    add column if not exists disabled boolean not null default false;
+
+alter table "user"
+    add column if not exists email varchar(256);
+
+do
+$$
+    begin
+        if not exists (select 1 from pg_constraint where conname = 'user_email_key') then
+            alter table "user"
+                add constraint user_email_key unique (email);
+        end if;
+    end
+$$;
@@ -0,0 +1,2 @@
+drop table user_recovery_code;
+drop table user_mfa;
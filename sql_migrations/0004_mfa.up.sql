@@ -0,0 +1,17 @@
+create table if not exists user_mfa
+(
+    user_id    varchar(64) not null
+        primary key
+        references "user" (user_id),
+    secret     varchar(64) not null,
+    enabled    boolean     not null default false,
+    created_at timestamp   not null default now()
+);
+
+create table if not exists user_recovery_code
+(
+    user_id   varchar(64) not null
+        references "user" (user_id),
+    code_hash varchar(64) not null,
+    primary key (user_id, code_hash)
+);
@@ -0,0 +1,10 @@
+drop table api_key_scope;
+drop table api_key;
+
+delete
+from role_permission
+where permission = 'api_key:manage';
+
+delete
+from permission
+where name = 'api_key:manage';
@@ -0,0 +1,32 @@
+create table if not exists api_key
+(
+    id           varchar(64)  not null
+        primary key,
+    name         varchar(128) not null,
+    prefix       varchar(16)  not null,
+    key_hash     varchar(64)  not null
+        unique,
+    user_id      varchar(64)  not null
+        references "user" (user_id),
+    expires_at   timestamp,
+    last_used_at timestamp,
+    revoked_at   timestamp,
+    created_at   timestamp    not null default now()
+);
+
+create table if not exists api_key_scope
+(
+    key_id     varchar(64) not null
+        references api_key,
+    permission varchar(64) not null
+        references permission,
+    primary key (key_id, permission)
+);
+
+insert into permission (name)
+values ('api_key:manage')
+on conflict do nothing;
+
+insert into role_permission (role, permission)
+values ('admin', 'api_key:manage')
+on conflict do nothing;
@@ -0,0 +1,13 @@
+drop index film_date_released_id_idx;
+drop index film_name_id_idx;
+drop index film_rate_id_idx;
+drop index actor_name_id_idx;
+
+alter table film
+    alter column rate drop default,
+    alter column rate drop not null,
+    alter column date_released drop not null,
+    alter column name drop not null;
+
+alter table actor
+    alter column name drop not null;
@@ -0,0 +1,31 @@
+-- строки без обязательных полей из исходной схемы получают значения по умолчанию
+update actor
+set name = ''
+where name is null;
+
+update film
+set name = ''
+where name is null;
+
+update film
+set date_released = '1970-01-01'
+where date_released is null;
+
+update film
+set rate = 0
+where rate is null;
+
+alter table actor
+    alter column name set not null;
+
+alter table film
+    alter column name set not null,
+    alter column date_released set not null,
+    alter column rate set not null,
+    alter column rate set default 0;
+
+-- индексы под keyset пагинацию: поле сортировки + id
+create index if not exists actor_name_id_idx on actor (name, id);
+create index if not exists film_rate_id_idx on film (rate, id);
+create index if not exists film_name_id_idx on film (name, id);
+create index if not exists film_date_released_id_idx on film (date_released, id);
@@ -0,0 +1,2 @@
+alter table film_actor
+    drop constraint film_actor_pkey;
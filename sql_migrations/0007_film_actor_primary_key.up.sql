@@ -0,0 +1,17 @@
+-- повторные связи фильма с актером оставляются в одном экземпляре
+delete
+from film_actor a
+    using film_actor b
+where a.ctid < b.ctid
+  and a.film_id = b.film_id
+  and a.actor_id = b.actor_id;
+
+do
+$$
+    begin
+        if not exists (select 1 from pg_constraint where conname = 'film_actor_pkey') then
+            alter table film_actor
+                add constraint film_actor_pkey primary key (film_id, actor_id);
+        end if;
+    end
+$$;
@@ -0,0 +1,5 @@
+alter table film
+    drop column version;
+
+alter table actor
+    drop column version;
@@ -0,0 +1,6 @@
+-- версия для оптимистичной блокировки, растет при каждом изменении
+alter table actor
+    add column if not exists version integer not null default 1;
+
+alter table film
+    add column if not exists version integer not null default 1;
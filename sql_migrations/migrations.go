@@ -0,0 +1,8 @@
+package sql_migrations
+
+import "embed"
+
+// FS - миграции схемы, вшитые в бинарник. Файлы называются <версия>_<имя>.up.sql и <версия>_<имя>.down.sql
+//
+//go:embed *.sql
+var FS embed.FS